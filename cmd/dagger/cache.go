@@ -0,0 +1,448 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/juju/ansiterm/tabwriter"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/spf13/cobra"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/engine/client"
+)
+
+var (
+	cacheJSONOutput bool
+	cacheFilters    []string
+	cacheSortBy     string
+	cacheLimit      int
+	cacheOlderThan  string
+	cacheKeepBytes  string
+)
+
+func init() {
+	cacheCmd.PersistentFlags().BoolVar(&cacheJSONOutput, "json", false, "Output in JSON format")
+	cacheCmd.PersistentFlags().StringArrayVarP(&cacheFilters, "filter", "f", nil, "Only include entries matching a filter, e.g. type=cache-mount (can be repeated)")
+
+	cacheListCmd.Flags().StringVar(&cacheSortBy, "sort", "", "Sort entries by size, last-used or created")
+	cacheListCmd.Flags().IntVar(&cacheLimit, "limit", 0, "Maximum number of entries to list")
+
+	cachePruneCmd.Flags().StringVar(&cacheOlderThan, "older-than", "", `Only prune entries not used for this long, e.g. "24h" or "7d"`)
+	cachePruneCmd.Flags().StringVar(&cacheKeepBytes, "keep-bytes", "", `Only prune while the cache is larger than this, e.g. "50GB" or "1.5GB"`)
+
+	cacheCmd.AddCommand(
+		cacheListCmd,
+		cacheDiskUsageCmd,
+		cachePruneCmd,
+	)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and prune the engine's local cache",
+	Long: `Inspect and prune the engine's local cache.
+
+Entries can be narrowed down with --filter KEY=VALUE, where KEY is one of:
+
+- type: the type of entry, one of cache-mount, image-layer, exec,
+  local-import, git-checkout, internal or other
+- description: a glob matching the entry's description
+- in-use: whether the entry is actively being used (true or false)
+- min-size: the minimum space used by the entry, e.g. 100MB
+- last-used-before: an RFC 3339 timestamp, or a duration ago (e.g. 7d)
+`,
+	Example: strings.TrimSpace(`
+dagger cache ls --sort size --limit 10
+dagger cache ls --filter type=cache-mount --json
+dagger cache du
+dagger cache prune --filter type=exec --older-than 24h
+dagger cache prune --filter min-size=1GB --keep-bytes 50GB
+`),
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "ls [options]",
+	Aliases: []string{"list"},
+	Short:   "List the entries in the cache",
+	Args:    cobra.NoArgs,
+	RunE: cacheRunE(func(ctx context.Context, cmd *cobra.Command, dag *dagger.Client) error {
+		args, err := cacheEntrySetArgs(cacheFilters)
+		if err != nil {
+			return err
+		}
+		if cacheSortBy != "" {
+			args["sortBy"] = cacheEnumArg(cacheSortBy)
+		}
+		if cacheLimit != 0 {
+			args["limit"] = cacheLimit
+		}
+		set, err := queryCacheEntrySet(ctx, dag, args)
+		if err != nil {
+			return err
+		}
+
+		w := cmd.OutOrStdout()
+		if cacheJSONOutput {
+			return writeCacheJSON(w, set.Entries)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintln(tw, "TYPE\tSIZE\tLAST USED\tIN USE\tKEY\tNAMESPACE\tDESCRIPTION")
+		for _, ent := range set.Entries {
+			inUse := ""
+			if ent.ActivelyUsed {
+				inUse = "yes"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				cacheEnumName(ent.RecordType),
+				units.HumanSize(float64(ent.DiskSpaceBytes)),
+				ent.lastUsed(),
+				inUse,
+				ent.CacheMountKey,
+				ent.CacheMountNamespace,
+				ent.Description,
+			)
+		}
+		return tw.Flush()
+	}),
+}
+
+var cacheDiskUsageCmd = &cobra.Command{
+	Use:   "du [options]",
+	Short: "Show the disk space used by the cache",
+	Args:  cobra.NoArgs,
+	RunE: cacheRunE(func(ctx context.Context, cmd *cobra.Command, dag *dagger.Client) error {
+		args, err := cacheEntrySetArgs(cacheFilters)
+		if err != nil {
+			return err
+		}
+		set, err := queryCacheEntrySet(ctx, dag, args)
+		if err != nil {
+			return err
+		}
+		usage := cacheUsageByType(set)
+
+		w := cmd.OutOrStdout()
+		if cacheJSONOutput {
+			return writeCacheJSON(w, usage)
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.DiscardEmptyColumns)
+		fmt.Fprintln(tw, "TYPE\tENTRIES\tSIZE")
+		for _, typ := range usage.Types {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", cacheEnumName(typ.Type), typ.EntryCount, units.HumanSize(float64(typ.DiskSpaceBytes)))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\n", "total", usage.EntryCount, units.HumanSize(float64(usage.DiskSpaceBytes)))
+		return tw.Flush()
+	}),
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune [options]",
+	Short: "Prune releasable entries from the cache",
+	Long: `Prune releasable entries from the cache.
+
+Entries that are actively being used are never pruned. Only the type, min-size
+and last-used-before filters are supported when pruning.`,
+	Args: cobra.NoArgs,
+	RunE: cacheRunE(func(ctx context.Context, cmd *cobra.Command, dag *dagger.Client) error {
+		args, err := cachePruneArgs(cacheFilters, cacheOlderThan, cacheKeepBytes)
+		if err != nil {
+			return err
+		}
+
+		before, err := queryCacheEntrySet(ctx, dag, nil)
+		if err != nil {
+			return err
+		}
+		query, vars := cacheQuery("prune", args, "")
+		if err := dag.Do(ctx, &dagger.Request{Query: query, Variables: vars}, &dagger.Response{}); err != nil {
+			return fmt.Errorf("failed to prune cache: %w", err)
+		}
+		after, err := queryCacheEntrySet(ctx, dag, nil)
+		if err != nil {
+			return err
+		}
+
+		// the cache may change concurrently, so this is only an estimate
+		result := cachePruneResult{
+			EntriesPruned: max(before.EntryCount-after.EntryCount, 0),
+			BytesFreed:    max(before.DiskSpaceBytes-after.DiskSpaceBytes, 0),
+		}
+		w := cmd.OutOrStdout()
+		if cacheJSONOutput {
+			return writeCacheJSON(w, result)
+		}
+		fmt.Fprintf(w, "Pruned %d entries, freeing %s\n", result.EntriesPruned, units.HumanSize(float64(result.BytesFreed)))
+		return nil
+	}),
+}
+
+type cacheRun func(ctx context.Context, cmd *cobra.Command, dag *dagger.Client) error
+
+func cacheRunE(run cacheRun) func(*cobra.Command, []string) error {
+	return func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		return withEngine(ctx, client.Params{}, func(ctx context.Context, engineClient *client.Client) error {
+			return run(ctx, cmd, engineClient.Dagger())
+		})
+	}
+}
+
+type cacheEntrySet struct {
+	EntryCount     int          `json:"entryCount"`
+	DiskSpaceBytes int          `json:"diskSpaceBytes"`
+	Entries        []cacheEntry `json:"entries"`
+}
+
+type cacheEntry struct {
+	Description               string `json:"description"`
+	RecordType                string `json:"recordType"`
+	CacheMountKey             string `json:"cacheMountKey,omitempty"`
+	CacheMountNamespace       string `json:"cacheMountNamespace,omitempty"`
+	DiskSpaceBytes            int    `json:"diskSpaceBytes"`
+	ActivelyUsed              bool   `json:"activelyUsed"`
+	CreatedTimeUnixNano       int    `json:"createdTimeUnixNano"`
+	MostRecentUseTimeUnixNano int    `json:"mostRecentUseTimeUnixNano"`
+}
+
+func (ent cacheEntry) lastUsed() string {
+	if ent.MostRecentUseTimeUnixNano == 0 {
+		return "never"
+	}
+	return units.HumanDuration(time.Since(time.Unix(0, int64(ent.MostRecentUseTimeUnixNano)))) + " ago"
+}
+
+type cacheUsage struct {
+	EntryCount     int              `json:"entryCount"`
+	DiskSpaceBytes int              `json:"diskSpaceBytes"`
+	Types          []cacheTypeUsage `json:"types"`
+}
+
+type cacheTypeUsage struct {
+	Type           string `json:"type"`
+	EntryCount     int    `json:"entryCount"`
+	DiskSpaceBytes int    `json:"diskSpaceBytes"`
+}
+
+// cacheUsageByType totals up the entries of each type, largest first.
+func cacheUsageByType(set *cacheEntrySet) cacheUsage {
+	usage := cacheUsage{
+		EntryCount:     set.EntryCount,
+		DiskSpaceBytes: set.DiskSpaceBytes,
+		Types:          []cacheTypeUsage{},
+	}
+	byType := map[string]*cacheTypeUsage{}
+	for _, ent := range set.Entries {
+		typ, ok := byType[ent.RecordType]
+		if !ok {
+			typ = &cacheTypeUsage{Type: ent.RecordType}
+			byType[ent.RecordType] = typ
+		}
+		typ.EntryCount++
+		typ.DiskSpaceBytes += ent.DiskSpaceBytes
+	}
+	for _, typ := range byType {
+		usage.Types = append(usage.Types, *typ)
+	}
+	sort.Slice(usage.Types, func(i, j int) bool {
+		if usage.Types[i].DiskSpaceBytes != usage.Types[j].DiskSpaceBytes {
+			return usage.Types[i].DiskSpaceBytes > usage.Types[j].DiskSpaceBytes
+		}
+		return usage.Types[i].Type < usage.Types[j].Type
+	})
+	return usage
+}
+
+type cachePruneResult struct {
+	EntriesPruned int `json:"entriesPruned"`
+	BytesFreed    int `json:"bytesFreed"`
+}
+
+// cacheEntrySetArgs converts --filter flags to arguments of the entrySet API.
+func cacheEntrySetArgs(filters []string) (map[string]any, error) {
+	args := map[string]any{}
+	for _, filter := range filters {
+		k, v, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: expected key=value", filter)
+		}
+		switch k {
+		case "type":
+			args["type"] = cacheEnumArg(v)
+		case "description":
+			args["description"] = v
+		case "in-use":
+			inUse, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			args["inUse"] = inUse
+		case "min-size":
+			minSize, err := units.RAMInBytes(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			args["minSize"] = minSize
+		case "last-used-before":
+			args["lastUsedBefore"] = v
+		default:
+			return nil, fmt.Errorf("invalid filter %q: unknown key %q", filter, k)
+		}
+	}
+	return args, nil
+}
+
+// cachePruneArgs converts the filters and flags of `dagger cache prune` to the
+// arguments of prune.
+func cachePruneArgs(filters []string, olderThan string, keepBytes string) (map[string]any, error) {
+	args := map[string]any{}
+	if olderThan != "" {
+		args["olderThan"] = olderThan
+	}
+	for _, filter := range filters {
+		k, v, ok := strings.Cut(filter, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter %q: expected key=value", filter)
+		}
+		switch k {
+		case "type":
+			args["type"] = cacheEnumArg(v)
+		case "min-size":
+			minSize, err := units.RAMInBytes(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter %q: %w", filter, err)
+			}
+			args["minSize"] = minSize
+		case "last-used-before":
+			if olderThan != "" {
+				return nil, fmt.Errorf("invalid filter %q: cannot be combined with --older-than", filter)
+			}
+			if ts, err := time.Parse(time.RFC3339, v); err == nil {
+				// prune only takes an age, so convert the timestamp to one
+				args["olderThan"] = max(time.Since(ts), 0).String()
+			} else {
+				args["olderThan"] = v
+			}
+		default:
+			return nil, fmt.Errorf("invalid filter %q: only type, min-size and last-used-before filters are supported when pruning", filter)
+		}
+	}
+	if keepBytes != "" {
+		// parse it the same way as keepBytes in engine.toml
+		var space config.DiskSpace
+		if err := space.UnmarshalText([]byte(keepBytes)); err != nil {
+			return nil, fmt.Errorf("invalid --keep-bytes %q: %w", keepBytes, err)
+		}
+		if space.Percentage != 0 {
+			return nil, fmt.Errorf("invalid --keep-bytes %q: must be a size, not a percentage", keepBytes)
+		}
+		args["keepBytes"] = space.Bytes
+	}
+	return args, nil
+}
+
+// cacheEntrySetArgTypes are the GraphQL types of the arguments of entrySet
+// and prune.
+var cacheEntrySetArgTypes = map[string]string{
+	"type":           "DaggerEngineCacheEntryType",
+	"description":    "String",
+	"inUse":          "Boolean",
+	"minSize":        "Int",
+	"lastUsedBefore": "String",
+	"sortBy":         "DaggerEngineCacheEntrySortBy",
+	"limit":          "Int",
+	"olderThan":      "String",
+	"keepBytes":      "Int",
+}
+
+// cacheQuery builds a query for a field of the local cache, passing the given
+// arguments as variables.
+func cacheQuery(field string, args map[string]any, selection string) (string, map[string]any) {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params, fieldArgs []string
+	for _, name := range names {
+		params = append(params, fmt.Sprintf("$%s: %s!", name, cacheEntrySetArgTypes[name]))
+		fieldArgs = append(fieldArgs, fmt.Sprintf("%s: $%s", name, name))
+	}
+
+	var query strings.Builder
+	query.WriteString("query")
+	if len(params) > 0 {
+		query.WriteString("(" + strings.Join(params, ", ") + ")")
+	}
+	query.WriteString("{daggerEngine{localCache{" + field)
+	if len(fieldArgs) > 0 {
+		query.WriteString("(" + strings.Join(fieldArgs, ", ") + ")")
+	}
+	if selection != "" {
+		query.WriteString("{" + selection + "}")
+	}
+	query.WriteString("}}}")
+	return query.String(), args
+}
+
+func queryCacheEntrySet(ctx context.Context, dag *dagger.Client, args map[string]any) (*cacheEntrySet, error) {
+	query, vars := cacheQuery("entrySet", args, `
+		entryCount
+		diskSpaceBytes
+		entries {
+			description
+			recordType
+			cacheMountKey
+			cacheMountNamespace
+			diskSpaceBytes
+			activelyUsed
+			createdTimeUnixNano
+			mostRecentUseTimeUnixNano
+		}`)
+	var res struct {
+		DaggerEngine struct {
+			LocalCache struct {
+				EntrySet cacheEntrySet
+			}
+		}
+	}
+	err := dag.Do(ctx, &dagger.Request{Query: query, Variables: vars}, &dagger.Response{Data: &res})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+	set := &res.DaggerEngine.LocalCache.EntrySet
+	if set.Entries == nil {
+		set.Entries = []cacheEntry{}
+	}
+	return set, nil
+}
+
+// cacheEnumArg converts a flag value like "cache-mount" to the enum value
+// "CACHE_MOUNT".
+func cacheEnumArg(v string) string {
+	return strings.ToUpper(strings.ReplaceAll(v, "-", "_"))
+}
+
+// cacheEnumName converts an enum value like "CACHE_MOUNT" back to the form
+// used in flags, "cache-mount".
+func cacheEnumName(v string) string {
+	return strings.ToLower(strings.ReplaceAll(v, "_", "-"))
+}
+
+func writeCacheJSON(w io.Writer, v any) error {
+	bs, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", bs)
+	return err
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheEntrySetArgs(t *testing.T) {
+	args, err := cacheEntrySetArgs([]string{
+		"type=cache-mount",
+		"description=pulled from *",
+		"in-use=false",
+		"min-size=1MB",
+		"last-used-before=7d",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"type":           "CACHE_MOUNT",
+		"description":    "pulled from *",
+		"inUse":          false,
+		"minSize":        int64(1024 * 1024),
+		"lastUsedBefore": "7d",
+	}, args)
+
+	_, err = cacheEntrySetArgs([]string{"type"})
+	require.ErrorContains(t, err, "expected key=value")
+	_, err = cacheEntrySetArgs([]string{"color=red"})
+	require.ErrorContains(t, err, `unknown key "color"`)
+	_, err = cacheEntrySetArgs([]string{"in-use=maybe"})
+	require.ErrorContains(t, err, `invalid filter "in-use=maybe"`)
+}
+
+func TestCachePruneArgs(t *testing.T) {
+	args, err := cachePruneArgs([]string{"type=exec", "min-size=1MB"}, "24h", "1.5GB")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"type":      "EXEC",
+		"minSize":   int64(1024 * 1024),
+		"olderThan": "24h",
+		"keepBytes": int64(1.5 * 1024 * 1024 * 1024),
+	}, args)
+
+	args, err = cachePruneArgs([]string{"last-used-before=7d"}, "", "50GB")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"olderThan": "7d",
+		"keepBytes": int64(50 * 1024 * 1024 * 1024),
+	}, args)
+
+	args, err = cachePruneArgs([]string{"last-used-before=" + time.Now().Add(-time.Hour).Format(time.RFC3339)}, "", "")
+	require.NoError(t, err)
+	olderThan, err := time.ParseDuration(args["olderThan"].(string))
+	require.NoError(t, err)
+	require.InDelta(t, time.Hour, olderThan, float64(time.Minute))
+
+	_, err = cachePruneArgs([]string{"description=foo"}, "", "")
+	require.ErrorContains(t, err, "only type, min-size and last-used-before filters are supported")
+	_, err = cachePruneArgs([]string{"last-used-before=7d"}, "24h", "")
+	require.ErrorContains(t, err, "cannot be combined with --older-than")
+	_, err = cachePruneArgs(nil, "", "lots")
+	require.ErrorContains(t, err, `invalid --keep-bytes "lots"`)
+	_, err = cachePruneArgs(nil, "", "10%")
+	require.ErrorContains(t, err, "must be a size, not a percentage")
+}
+
+func TestCacheQuery(t *testing.T) {
+	query, vars := cacheQuery("prune", map[string]any{}, "")
+	require.Equal(t, "query{daggerEngine{localCache{prune}}}", query)
+	require.Empty(t, vars)
+
+	query, vars = cacheQuery("entrySet", map[string]any{
+		"type":  "EXEC",
+		"limit": 5,
+	}, "entryCount")
+	require.Equal(t,
+		"query($limit: Int!, $type: DaggerEngineCacheEntryType!){daggerEngine{localCache{entrySet(limit: $limit, type: $type){entryCount}}}}",
+		query)
+	require.Equal(t, map[string]any{"type": "EXEC", "limit": 5}, vars)
+}
+
+func TestCacheUsageByType(t *testing.T) {
+	usage := cacheUsageByType(&cacheEntrySet{
+		EntryCount:     4,
+		DiskSpaceBytes: 111,
+		Entries: []cacheEntry{
+			{RecordType: "EXEC", DiskSpaceBytes: 10},
+			{RecordType: "CACHE_MOUNT", DiskSpaceBytes: 100},
+			{RecordType: "EXEC", DiskSpaceBytes: 0},
+			{RecordType: "OTHER", DiskSpaceBytes: 1},
+		},
+	})
+	require.Equal(t, cacheUsage{
+		EntryCount:     4,
+		DiskSpaceBytes: 111,
+		Types: []cacheTypeUsage{
+			{Type: "CACHE_MOUNT", EntryCount: 1, DiskSpaceBytes: 100},
+			{Type: "EXEC", EntryCount: 2, DiskSpaceBytes: 10},
+			{Type: "OTHER", EntryCount: 1, DiskSpaceBytes: 1},
+		},
+	}, usage)
+}
@@ -12,7 +12,6 @@ import (
 	"net"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strconv"
@@ -421,19 +420,16 @@ func (v *fileValue) Get(_ context.Context, dag *dagger.Client, _ *dagger.ModuleS
 	return dag.Host().File(vStr), nil
 }
 
-// secretValue is a pflag.Value that builds a dagger.Secret from a name and a
-// plaintext value.
+// secretValue is a pflag.Value that builds a dagger.Secret from a secret
+// source and a reference to the plaintext value within it.
+//
+// The plaintext is only resolved on the client, once the secret is needed.
+// See secretProviders for the supported sources.
 type secretValue struct {
 	secretSource string
 	sourceVal    string
 }
 
-const (
-	envSecretSource     = "env"
-	fileSecretSource    = "file"
-	commandSecretSource = "cmd"
-)
-
 func (v *secretValue) Type() string {
 	return Secret
 }
@@ -445,6 +441,9 @@ func (v *secretValue) Set(s string) error {
 		val = secretSource
 		secretSource = envSecretSource
 	}
+	if _, ok := secretProviders[secretSource]; !ok {
+		return fmt.Errorf("unsupported secret arg source: %q", secretSource)
+	}
 	v.secretSource = secretSource
 	v.sourceVal = val
 
@@ -459,51 +458,24 @@ func (v *secretValue) String() string {
 }
 
 func (v *secretValue) Get(ctx context.Context, c *dagger.Client, _ *dagger.ModuleSource) (any, error) {
-	var plaintext string
-
-	switch v.secretSource {
-	case envSecretSource:
-		envPlaintext, ok := os.LookupEnv(v.sourceVal)
-		if !ok {
-			// Don't show the entire env var name, in case the user accidentally passed the value instead...
-			// This is important because users originally *did* have to pass the value, before we changed to
-			// passing by name instead.
-			key := v.sourceVal
-			if len(key) >= 4 {
-				key = key[:3] + "..."
-			}
-			return nil, fmt.Errorf("secret env var not found: %q", key)
-		}
-		plaintext = envPlaintext
-
-	case fileSecretSource:
-		sourceVal, err := expandHomeDir(v.sourceVal)
-		if err != nil {
-			return nil, err
-		}
-		filePlaintext, err := os.ReadFile(sourceVal)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read secret file %q: %w", v.sourceVal, err)
-		}
-		plaintext = string(filePlaintext)
-
-	case commandSecretSource:
-		// #nosec G204
-		stdoutBytes, err := exec.CommandContext(ctx, "sh", "-c", v.sourceVal).Output()
-		if err != nil {
-			return nil, fmt.Errorf("failed to run secret command %q: %w", v.sourceVal, err)
-		}
-		plaintext = string(stdoutBytes)
-
-	default:
-		return nil, fmt.Errorf("unsupported secret arg source: %q", v.secretSource)
+	plaintext, err := resolveSecret(ctx, v.secretSource, v.sourceVal)
+	if err != nil {
+		return nil, err
 	}
 
 	// NB: If we allow getting the name from the dagger.Secret instance,
 	// it can be vulnerable to brute force attacks.
-	hash := sha256.Sum256([]byte(plaintext))
+	hash := sha256.Sum256(plaintext)
 	secretName := hex.EncodeToString(hash[:])
-	return c.SetSecret(secretName, plaintext), nil
+	if secretTTL <= 0 {
+		return c.SetSecret(secretName, string(plaintext)), nil
+	}
+
+	// let the engine ask us for the current value once the ttl expires
+	cliSecrets.add(secretName, v.secretSource, v.sourceVal)
+	return c.SetSecret(secretName, string(plaintext), dagger.SetSecretOpts{
+		TTL: secretTTL.String(),
+	}), nil
 }
 
 // serviceValue is a pflag.Value that builds a dagger.Service from a host:port
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -28,6 +29,9 @@ var (
 
 	// outputPath is the parsed value of the `--output` flag.
 	outputPath string
+
+	// secretTTL is the parsed value of the `--secret-ttl` flag.
+	secretTTL time.Duration
 )
 
 const (
@@ -162,7 +166,11 @@ func (fc *FuncCommand) Command() *cobra.Command {
 
 			// Between PreRunE and RunE, flags are validated.
 			RunE: func(c *cobra.Command, a []string) error {
-				return withEngine(c.Context(), client.Params{}, func(ctx context.Context, engineClient *client.Client) (rerr error) {
+				params := client.Params{
+					// answers the engine when secret args set with a ttl expire
+					SecretStore: cliSecrets,
+				}
+				return withEngine(c.Context(), params, func(ctx context.Context, engineClient *client.Client) (rerr error) {
 					fc.c = engineClient
 					fc.q = querybuilder.Query().Client(engineClient.Dagger().GraphQLClient())
 
@@ -205,6 +213,8 @@ func (fc *FuncCommand) Command() *cobra.Command {
 		fc.cmd.PersistentFlags().StringVarP(&outputPath, "output", "o", "", "Save the result to a local file or directory")
 
 		fc.cmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Present result as JSON")
+
+		fc.cmd.PersistentFlags().DurationVar(&secretTTL, "secret-ttl", 0, "Resolve secret arguments again from their source once they are older than this (e.g. 15m)")
 	}
 	return fc.cmd
 }
@@ -283,10 +293,19 @@ func (fc *FuncCommand) execute(c *cobra.Command, a []string) (rerr error) {
 
 	// No args to the parent command
 	if cmd == c {
-		return fc.RunE(ctx, fc.mod.MainObject.AsObject.Constructor)(cmd, flags)
+		err = fc.RunE(ctx, fc.mod.MainObject.AsObject.Constructor)(cmd, flags)
+	} else {
+		err = cmd.RunE(cmd, flags)
+	}
+	if err != nil {
+		return err
 	}
 
-	return cmd.RunE(cmd, flags)
+	// pin what the module's functions resolved, for later calls
+	if fc.mod.LocalRoot != "" {
+		return syncModuleLock(ctx, fc.c.Dagger(), fc.mod.Module, fc.mod.LocalRoot)
+	}
+	return nil
 }
 
 // initializeModule loads the module's type definitions.
@@ -310,6 +329,10 @@ func initializeModule(ctx context.Context, dag *dagger.Client, loadModule bool)
 
 		def.Source = modConf.Source
 		mod := modConf.Source.AsModule().Initialize()
+		def.Module = mod
+		if modConf.SourceKind == dagger.LocalSource {
+			def.LocalRoot = modConf.LocalRootSourcePath
+		}
 
 		serveCtx, serveSpan := Tracer().Start(ctx, "installing module", telemetry.Encapsulate())
 		err = mod.Serve(serveCtx)
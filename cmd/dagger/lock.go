@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/spf13/cobra"
+
+	"dagger.io/dagger"
+	"github.com/dagger/dagger/core/modules"
+	"github.com/dagger/dagger/engine/client"
+)
+
+func init() {
+	lockCmd.AddCommand(
+		lockUpdateCmd,
+	)
+	rootCmd.AddCommand(lockCmd)
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Manage the module's lock file",
+	Long: `Manage the module's lock file.
+
+When a local module's functions are called, the images, git refs and http
+downloads they resolve are pinned in a dagger.lock file next to dagger.json,
+so that later calls resolve them the same way. Commit it along with the
+module.
+
+Local dependencies of the module get their own dagger.lock, next to their
+dagger.json. Git dependencies use the dagger.lock they were published with,
+and what they resolve beyond it isn't pinned.`,
+	GroupID: moduleGroup.ID,
+}
+
+var lockUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update everything pinned in the module's lock file",
+	Long: `Update everything pinned in the module's lock file.
+
+Every image, git ref and http download pinned in dagger.lock is resolved again
+and pinned to what it resolves to now.
+
+Http downloads made with headers or an authHeader are skipped, since those
+aren't recorded in dagger.lock. Remove their entries to pin them again on the
+next call.`,
+	Example: "dagger lock update -m ./path/to/module",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		ctx := cmd.Context()
+		return withEngine(ctx, client.Params{}, func(ctx context.Context, engineClient *client.Client) error {
+			dag := engineClient.Dagger()
+			modConf, err := getDefaultModuleConfiguration(ctx, dag, true, false)
+			if err != nil {
+				return fmt.Errorf("failed to get configured module: %w", err)
+			}
+			if modConf.SourceKind != dagger.LocalSource {
+				return fmt.Errorf("module must be local")
+			}
+			if !modConf.FullyInitialized() {
+				return fmt.Errorf("module at source dir %q doesn't exist or is invalid", modConf.LocalRootSourcePath)
+			}
+
+			lockPath := filepath.Join(modConf.LocalRootSourcePath, modules.LockFilename)
+			contents, err := os.ReadFile(lockPath)
+			if err != nil {
+				if errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("module has no %s yet, it's written when its functions are called", modules.LockFilename)
+				}
+				return fmt.Errorf("failed to read %s: %w", lockPath, err)
+			}
+			lock, err := modules.ParseModuleLock(contents)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", lockPath, err)
+			}
+
+			updated, skipped, err := updateModuleLock(ctx, dag, lock)
+			if err != nil {
+				return err
+			}
+			for _, url := range skipped {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: it was downloaded with headers, which aren't recorded in %s\n",
+					url, modules.LockFilename)
+			}
+			if err := writeModuleLock(lockPath, lock); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Updated %d of %d entries in %s\n",
+				updated, len(lock.Images)+len(lock.Git)+len(lock.HTTP), modules.LockFilename)
+			return nil
+		})
+	},
+}
+
+// updateModuleLock resolves everything pinned in the lock again, outside of
+// any module so that the lock itself isn't consulted, returning how many
+// entries changed and the URLs of http downloads it can't make again.
+func updateModuleLock(ctx context.Context, dag *dagger.Client, lock *modules.ModuleLock) (int, []string, error) {
+	var updated int
+	for _, image := range lock.Images {
+		ref, err := dag.Container(dagger.ContainerOpts{Platform: dagger.Platform(image.Platform)}).
+			From(image.Address).
+			ImageRef(ctx)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to resolve image %s: %w", image.Address, err)
+		}
+		_, dgst, ok := strings.Cut(ref, "@")
+		if !ok {
+			return 0, nil, fmt.Errorf("image %s resolved to %s, which isn't pinned to a digest", image.Address, ref)
+		}
+		if lock.SetImageDigest(image.Address, image.Platform, dgst) {
+			updated++
+		}
+	}
+
+	for _, gitRef := range lock.Git {
+		repo := dag.Git(gitRef.URL)
+		ref := repo.Head()
+		if gitRef.Ref != "HEAD" {
+			ref = repo.Ref(gitRef.Ref)
+		}
+		commit, err := ref.Commit(ctx)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to resolve %s of %s: %w", gitRef.Ref, gitRef.URL, err)
+		}
+		if lock.SetGitCommit(gitRef.URL, gitRef.Ref, commit) {
+			updated++
+		}
+	}
+
+	var skipped []string
+	for _, http := range lock.HTTP {
+		if http.Headers {
+			skipped = append(skipped, http.URL)
+			continue
+		}
+		dgst, err := httpDigest(ctx, dag, http.URL)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to download %s: %w", http.URL, err)
+		}
+		if lock.SetHTTPDigest(http.URL, dgst.String(), false) {
+			updated++
+		}
+	}
+
+	return updated, skipped, nil
+}
+
+// httpDigest downloads the URL, returning the digest of its contents.
+func httpDigest(ctx context.Context, dag *dagger.Client, url string) (digest.Digest, error) {
+	tmpDir, err := os.MkdirTemp("", "dagger-lock-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, "download")
+	if _, err := dag.HTTP(url).Export(ctx, path); err != nil {
+		return "", err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return digest.Canonical.FromReader(f)
+}
+
+// syncModuleLock writes what the module's functions resolved so far to the
+// dagger.lock file in its local root directory, and what the functions of its
+// local dependencies resolved to theirs, if anything changed.
+func syncModuleLock(ctx context.Context, dag *dagger.Client, mod *dagger.Module, rootPath string) error {
+	rootSubpath, err := mod.Source().SourceRootSubpath(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get module source root subpath: %w", err)
+	}
+	seen := map[string]bool{}
+	var sync func(mod *dagger.Module, subpath string) error
+	sync = func(mod *dagger.Module, subpath string) error {
+		if seen[subpath] {
+			return nil
+		}
+		seen[subpath] = true
+
+		relPath, err := filepath.Rel(rootSubpath, subpath)
+		if err != nil {
+			return err
+		}
+		if err := syncModuleLockFile(ctx, dag, mod, filepath.Join(rootPath, relPath, modules.LockFilename)); err != nil {
+			return err
+		}
+
+		deps, err := mod.Dependencies(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get module dependencies: %w", err)
+		}
+		for _, dep := range deps {
+			// git dependencies keep the lock they were published with
+			kind, err := dep.Source().Kind(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get dependency source kind: %w", err)
+			}
+			if kind != dagger.LocalSource {
+				continue
+			}
+			depSubpath, err := dep.Source().SourceRootSubpath(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get dependency source root subpath: %w", err)
+			}
+			if err := sync(&dep, depSubpath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return sync(mod, rootSubpath)
+}
+
+// syncModuleLockFile writes what the module's functions resolved so far to
+// the lock file, if anything changed.
+func syncModuleLockFile(ctx context.Context, dag *dagger.Client, mod *dagger.Module, lockPath string) error {
+	id, err := mod.ID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get module ID: %w", err)
+	}
+	var res struct {
+		LoadModuleFromID struct {
+			Lock string
+		}
+	}
+	err = dag.Do(ctx, &dagger.Request{
+		Query:     `query ModuleLock($id: ModuleID!) { loadModuleFromID(id: $id) { lock } }`,
+		Variables: map[string]any{"id": id},
+	}, &dagger.Response{Data: &res})
+	if err != nil {
+		return fmt.Errorf("failed to get module lock: %w", err)
+	}
+	contents := []byte(res.LoadModuleFromID.Lock)
+	if len(contents) == 0 {
+		return nil
+	}
+
+	existing, err := os.ReadFile(lockPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", lockPath, err)
+	}
+	if bytes.Equal(existing, contents) {
+		return nil
+	}
+	if err := os.WriteFile(lockPath, contents, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockPath, err)
+	}
+	return nil
+}
+
+func writeModuleLock(lockPath string, lock *modules.ModuleLock) error {
+	contents, err := lock.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(lockPath, contents, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", lockPath, err)
+	}
+	return nil
+}
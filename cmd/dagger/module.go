@@ -70,6 +70,7 @@ func init() {
 	listenCmd.PersistentFlags().AddFlagSet(moduleFlags)
 	queryCmd.PersistentFlags().AddFlagSet(moduleFlags)
 	configCmd.PersistentFlags().AddFlagSet(moduleFlags)
+	lockCmd.PersistentFlags().AddFlagSet(moduleFlags)
 
 	moduleInitCmd.Flags().StringVar(&sdk, "sdk", "", "Optionally install a Dagger SDK")
 	moduleInitCmd.Flags().StringVar(&moduleName, "name", "", "Name of the new module (defaults to parent directory name)")
@@ -740,6 +741,9 @@ func optionalModCmdWrapper(
 	return func(cmd *cobra.Command, cmdArgs []string) error {
 		return withEngine(cmd.Context(), client.Params{
 			SecretToken: presetSecretToken,
+			// answers the engine when secrets set with a ttl expire, e.g. by
+			// clients of dagger listen
+			SecretStore: cliSecrets,
 		}, func(ctx context.Context, engineClient *client.Client) (err error) {
 			_, explicitModRefSet := getExplicitModuleSourceRef()
 			modConf, err := getDefaultModuleConfiguration(ctx, engineClient.Dagger(), true, true)
@@ -775,6 +779,11 @@ type moduleDef struct {
 	// the ModuleSource definition for the module, needed by some arg types
 	// applying module-specific configs to the arg value.
 	Source *dagger.ModuleSource
+
+	// the served module, and its root directory on the host if it's local,
+	// where its dagger.lock is written.
+	Module    *dagger.Module
+	LocalRoot string
 }
 
 //go:embed typedefs.graphql
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/tidwall/gjson"
+	"gopkg.in/yaml.v3"
+)
+
+// secretProvider resolves the plaintext of a secret from the value following
+// its source prefix (e.g. "MY_TOKEN" in "env:MY_TOKEN").
+//
+// Providers are only ever called on the client, at the point the secret is
+// needed. Errors must never include the resolved plaintext.
+type secretProvider func(ctx context.Context, val string) ([]byte, error)
+
+const (
+	envSecretSource         = "env"
+	fileSecretSource        = "file"
+	commandSecretSource     = "cmd"
+	vaultSecretSource       = "vault"
+	onePasswordSecretSource = "op"
+	passSecretSource        = "pass"
+)
+
+var secretProviders = map[string]secretProvider{
+	envSecretSource:         envSecretProvider,
+	fileSecretSource:        fileSecretProvider,
+	commandSecretSource:     commandSecretProvider,
+	vaultSecretSource:       vaultSecretProvider,
+	onePasswordSecretSource: onePasswordSecretProvider,
+	passSecretSource:        passSecretProvider,
+}
+
+func resolveSecret(ctx context.Context, source string, val string) ([]byte, error) {
+	provider, ok := secretProviders[source]
+	if !ok {
+		return nil, fmt.Errorf("unsupported secret arg source: %q", source)
+	}
+	return provider(ctx, val)
+}
+
+// cliSecrets is the store of secret args that the engine may ask the CLI to
+// resolve again, once the value it was given expires.
+var cliSecrets = &cliSecretStore{sources: map[string]cliSecretSource{}}
+
+type cliSecretSource struct {
+	source string
+	val    string
+}
+
+// cliSecretStore answers requests from the engine for the current value of a
+// secret set by the CLI, by resolving it again from its provider. Secrets set
+// by SDKs through dagger session or dagger listen are requested by their
+// source instead, e.g. "env:GITHUB_TOKEN", which is resolved as is.
+type cliSecretStore struct {
+	sources map[string]cliSecretSource
+	mu      sync.Mutex
+}
+
+var _ secrets.SecretStore = (*cliSecretStore)(nil)
+
+func (store *cliSecretStore) add(name string, source string, val string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.sources[name] = cliSecretSource{source: source, val: val}
+}
+
+func (store *cliSecretStore) GetSecret(ctx context.Context, name string) ([]byte, error) {
+	store.mu.Lock()
+	src, ok := store.sources[name]
+	store.mu.Unlock()
+	if !ok {
+		source, val, isRef := strings.Cut(name, ":")
+		if _, known := secretProviders[source]; !isRef || !known {
+			return nil, fmt.Errorf("secret %q: %w", name, secrets.ErrNotFound)
+		}
+		src = cliSecretSource{source: source, val: val}
+	}
+	return resolveSecret(ctx, src.source, src.val)
+}
+
+// envSecretProvider reads a secret from a host environment variable, e.g.
+// "env:GITHUB_TOKEN".
+func envSecretProvider(_ context.Context, val string) ([]byte, error) {
+	plaintext, ok := os.LookupEnv(val)
+	if !ok {
+		// Don't show the entire env var name, in case the user accidentally passed the value instead...
+		// This is important because users originally *did* have to pass the value, before we changed to
+		// passing by name instead.
+		key := val
+		if len(key) >= 4 {
+			key = key[:3] + "..."
+		}
+		return nil, fmt.Errorf("secret env var not found: %q", key)
+	}
+	return []byte(plaintext), nil
+}
+
+// fileSecretProvider reads a secret from a host file, e.g.
+// "file:./token.txt".
+//
+// A key inside a structured file may be selected with a "#" suffix, e.g.
+// "file:./secrets.json#db.password". JSON, YAML and dotenv files are
+// supported, and are detected by their file extension. The suffix is only
+// treated as a key if the path before it is an existing structured file and
+// the full path is not, so paths that contain a "#" themselves are read as-is.
+func fileSecretProvider(_ context.Context, val string) ([]byte, error) {
+	filePath, key := val, ""
+	if i := strings.LastIndex(val, "#"); i >= 0 && !isSecretFile(val) &&
+		secretFileFormat(val[:i]) != "" && isSecretFile(val[:i]) {
+		filePath, key = val[:i], val[i+1:]
+	}
+
+	sourcePath, err := expandHomeDir(filePath)
+	if err != nil {
+		return nil, err
+	}
+	contents, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file %q: %w", filePath, err)
+	}
+	if key == "" {
+		return contents, nil
+	}
+
+	var plaintext []byte
+	switch secretFileFormat(sourcePath) {
+	case "json":
+		plaintext, err = lookupJSONSecret(contents, key)
+	case "yaml":
+		plaintext, err = lookupYAMLSecret(contents, key)
+	case "dotenv":
+		plaintext, err = lookupDotenvSecret(contents, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file %q: %w", filePath, err)
+	}
+	return plaintext, nil
+}
+
+// isSecretFile returns whether filePath names an existing regular file.
+func isSecretFile(filePath string) bool {
+	sourcePath, err := expandHomeDir(filePath)
+	if err != nil {
+		return false
+	}
+	fi, err := os.Stat(sourcePath)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+// secretFileFormat returns the structured format of a secret file based on
+// its name, or "" if keys can't be selected from it.
+func secretFileFormat(filePath string) string {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); {
+	case ext == ".json":
+		return "json"
+	case ext == ".yaml" || ext == ".yml":
+		return "yaml"
+	case ext == ".env" || filepath.Base(filePath) == ".env":
+		return "dotenv"
+	default:
+		return ""
+	}
+}
+
+// commandSecretProvider reads a secret from the stdout of a host command, e.g.
+// "cmd:gh auth token".
+func commandSecretProvider(ctx context.Context, val string) ([]byte, error) {
+	// #nosec G204
+	stdoutBytes, err := exec.CommandContext(ctx, "sh", "-c", val).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run secret command %q: %w", val, err)
+	}
+	return stdoutBytes, nil
+}
+
+// vaultSecretProvider reads a field of a HashiCorp Vault KV secret, e.g.
+// "vault://secret/data/ci#token".
+//
+// Both KV v1 and v2 engines are supported. The server address and token are
+// taken from VAULT_ADDR and VAULT_TOKEN (or ~/.vault-token), like the vault
+// CLI.
+func vaultSecretProvider(ctx context.Context, val string) ([]byte, error) {
+	secretPath, field, ok := strings.Cut(strings.TrimPrefix(val, "//"), "#")
+	if !ok || secretPath == "" || field == "" {
+		return nil, fmt.Errorf("invalid vault secret %q: expected vault://<path>#<field>", val)
+	}
+
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = "https://127.0.0.1:8200"
+	}
+	token, err := vaultToken()
+	if err != nil {
+		return nil, err
+	}
+
+	url := strings.TrimSuffix(addr, "/") + "/v1/" + strings.TrimPrefix(secretPath, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %q: %w", secretPath, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %q: %w", secretPath, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errResp struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(body, &errResp)
+		if len(errResp.Errors) > 0 {
+			return nil, fmt.Errorf("failed to read vault secret %q: %s: %s", secretPath, resp.Status, strings.Join(errResp.Errors, "; "))
+		}
+		return nil, fmt.Errorf("failed to read vault secret %q: %s", secretPath, resp.Status)
+	}
+
+	// KV v2 nests the secret's fields under data.data, KV v1 under data
+	result := gjson.GetBytes(body, "data.data")
+	if !result.IsObject() {
+		result = gjson.GetBytes(body, "data")
+	}
+	plaintext, err := lookupJSONSecret([]byte(result.Raw), field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vault secret %q: %w", secretPath, err)
+	}
+	return plaintext, nil
+}
+
+func vaultToken() (string, error) {
+	if token := os.Getenv("VAULT_TOKEN"); token != "" {
+		return token, nil
+	}
+	tokenPath, err := expandHomeDir("~/.vault-token")
+	if err != nil {
+		return "", err
+	}
+	token, err := os.ReadFile(tokenPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", errors.New("no vault token found: set VAULT_TOKEN or run `vault login`")
+		}
+		return "", fmt.Errorf("failed to read vault token: %w", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+// onePasswordSecretProvider reads a secret reference using the 1Password CLI,
+// e.g. "op://vault/item/field".
+func onePasswordSecretProvider(ctx context.Context, val string) ([]byte, error) {
+	if !strings.HasPrefix(val, "//") {
+		return nil, fmt.Errorf("invalid 1Password secret %q: expected op://<vault>/<item>/<field>", val)
+	}
+	ref := onePasswordSecretSource + ":" + val
+	// #nosec G204
+	stdoutBytes, err := exec.CommandContext(ctx, "op", "read", "--no-newline", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read 1Password secret %q: %w", ref, err)
+	}
+	return stdoutBytes, nil
+}
+
+// passSecretProvider reads a secret from the standard unix password manager,
+// e.g. "pass:ci/github-token".
+//
+// As is convention for pass, only the first line of the entry is used.
+func passSecretProvider(ctx context.Context, val string) ([]byte, error) {
+	// #nosec G204
+	stdoutBytes, err := exec.CommandContext(ctx, "pass", "show", val).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass secret %q: %w", val, err)
+	}
+	password, _, _ := bytes.Cut(stdoutBytes, []byte("\n"))
+	return password, nil
+}
+
+// lookupJSONSecret finds the value at a dotted key path in a JSON document.
+func lookupJSONSecret(contents []byte, key string) ([]byte, error) {
+	if !gjson.ValidBytes(contents) {
+		return nil, errors.New("invalid json")
+	}
+	result := gjson.GetBytes(contents, key)
+	if !result.Exists() {
+		return nil, fmt.Errorf("key %q not found", key)
+	}
+	if result.Type == gjson.String {
+		return []byte(result.Str), nil
+	}
+	return []byte(result.Raw), nil
+}
+
+// lookupYAMLSecret finds the value at a dotted key path in a YAML document.
+func lookupYAMLSecret(contents []byte, key string) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(contents, &doc); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	jsonContents, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	return lookupJSONSecret(jsonContents, key)
+}
+
+// lookupDotenvSecret finds the value of a variable in a dotenv file.
+func lookupDotenvSecret(contents []byte, key string) ([]byte, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) != key {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		return []byte(value), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid dotenv: %w", err)
+	}
+	return nil, fmt.Errorf("key %q not found", key)
+}
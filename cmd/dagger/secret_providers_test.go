@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSecretProvider(t *testing.T) {
+	dir := t.TempDir()
+	for name, contents := range map[string]string{
+		"token.txt": "plain-token\n",
+		"secrets.json": `{
+			"db": {"password": "json-password", "port": 5432}
+		}`,
+		"secrets.yaml":      "db:\n  password: yaml-password\n",
+		"secrets.env":       "# comment\nexport DB_PASSWORD=\"env-password\"\nOTHER=value\n",
+		"token#1.txt":       "hash-token\n",
+		"secrets.json#prod": `{"raw": true}`,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+	}
+
+	for _, tc := range []struct {
+		val     string
+		want    string
+		wantErr string
+	}{
+		{val: "token.txt", want: "plain-token\n"},
+		{val: "secrets.json#db.password", want: "json-password"},
+		{val: "secrets.json#db.port", want: "5432"},
+		{val: "secrets.json#db.user", wantErr: `key "db.user" not found`},
+		{val: "secrets.yaml#db.password", want: "yaml-password"},
+		{val: "secrets.env#DB_PASSWORD", want: "env-password"},
+		{val: "secrets.env#MISSING", wantErr: `key "MISSING" not found`},
+		{val: "token#1.txt", want: "hash-token\n"},
+		{val: "secrets.json#prod", want: `{"raw": true}`},
+		{val: "token.txt#key", wantErr: "failed to read secret file"},
+		{val: "missing.json#key", wantErr: "failed to read secret file"},
+		{val: "missing.txt", wantErr: "failed to read secret file"},
+	} {
+		t.Run(tc.val, func(t *testing.T) {
+			plaintext, err := fileSecretProvider(context.Background(), filepath.Join(dir, tc.val))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(plaintext))
+		})
+	}
+}
+
+func TestVaultSecretProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "dev-token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/ci":
+			w.Write([]byte(`{"data":{"data":{"token":"kv2-token"},"metadata":{"version":1}}}`))
+		case "/v1/kv/ci":
+			w.Write([]byte(`{"data":{"token":"kv1-token"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "dev-token")
+
+	for _, tc := range []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "vault://secret/data/ci#token", want: "kv2-token"},
+		{ref: "vault://kv/ci#token", want: "kv1-token"},
+		{ref: "vault://secret/data/ci#missing", wantErr: `key "missing" not found`},
+		{ref: "vault://secret/data/nope#token", wantErr: "404 Not Found"},
+		{ref: "vault://secret/data/ci", wantErr: "expected vault://<path>#<field>"},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			v := &secretValue{}
+			require.NoError(t, v.Set(tc.ref))
+			require.Equal(t, tc.ref, v.String())
+
+			plaintext, err := resolveSecret(context.Background(), v.secretSource, v.sourceVal)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(plaintext))
+		})
+	}
+
+	t.Run("bad token", func(t *testing.T) {
+		t.Setenv("VAULT_TOKEN", "wrong")
+		_, err := vaultSecretProvider(context.Background(), "//secret/data/ci#token")
+		require.ErrorContains(t, err, "permission denied")
+	})
+}
+
+func TestSecretValueSet(t *testing.T) {
+	v := &secretValue{}
+	require.NoError(t, v.Set("MY_TOKEN"))
+	require.Equal(t, "env:MY_TOKEN", v.String())
+
+	require.ErrorContains(t, v.Set("nope:MY_TOKEN"), `unsupported secret arg source: "nope"`)
+}
+
+func TestCLISecretStore(t *testing.T) {
+	store := &cliSecretStore{sources: map[string]cliSecretSource{}}
+
+	_, err := store.GetSecret(context.Background(), "missing")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+
+	t.Setenv("ROTATING_TOKEN", "first")
+	store.add("rotating", envSecretSource, "ROTATING_TOKEN")
+	plaintext, err := store.GetSecret(context.Background(), "rotating")
+	require.NoError(t, err)
+	require.Equal(t, "first", string(plaintext))
+
+	t.Setenv("ROTATING_TOKEN", "second")
+	plaintext, err = store.GetSecret(context.Background(), "rotating")
+	require.NoError(t, err)
+	require.Equal(t, "second", string(plaintext))
+
+	// secrets set by SDKs are requested by their source
+	plaintext, err = store.GetSecret(context.Background(), "env:ROTATING_TOKEN")
+	require.NoError(t, err)
+	require.Equal(t, "second", string(plaintext))
+
+	_, err = store.GetSecret(context.Background(), "nope:ROTATING_TOKEN")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+}
@@ -80,6 +80,8 @@ func EngineSession(cmd *cobra.Command, args []string) error {
 	return withEngine(ctx, client.Params{
 		SecretToken: sessionToken.String(),
 		UserAgent:   labelsFlag.Labels.WithCILabels().WithAnonymousGitLabels(workdir).UserAgent(),
+		// answers the engine when secrets set by the SDK with a ttl expire
+		SecretStore: cliSecrets,
 	}, func(ctx context.Context, sess *client.Client) error {
 		srv := http.Server{
 			Handler:           sess,
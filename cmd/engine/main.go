@@ -271,6 +271,10 @@ func main() { //nolint:gocyclo
 		if err != nil {
 			return err
 		}
+		engineCfg, err := server.LoadEngineConfigFile(c.GlobalString("config"))
+		if err != nil {
+			return err
+		}
 
 		bklog.G(ctx).Debug("setting up engine networking")
 		networkContext, cancelNetworking := context.WithCancel(context.Background())
@@ -377,6 +381,7 @@ func main() { //nolint:gocyclo
 		bklog.G(ctx).Debug("creating engine server")
 		srv, err := server.NewServer(ctx, &server.NewServerOpts{
 			Config:          &cfg,
+			EngineConfig:    &engineCfg,
 			Name:            engineName,
 			TelemetryPubSub: pubsub,
 		})
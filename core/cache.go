@@ -1,20 +1,32 @@
 package core
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
 	"strings"
 
 	"github.com/vektah/gqlparser/v2/ast"
 
+	"dagger.io/dagger/telemetry"
 	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/dagql/call"
 )
 
-// CacheVolume is a persistent volume with a globally scoped identifier.
+// CacheVolume is a persistent volume identified by its keys within a
+// namespace derived from its scope.
 type CacheVolume struct {
+	Query *Query
+
 	Keys []string `json:"keys"`
+
+	Scope     CacheVolumeScope `json:"scope,omitempty" field:"true" doc:"The scope the cache volume was created with."`
+	Namespace string           `json:"namespace,omitempty" field:"true" doc:"The namespace of the cache volume, derived from its scope. Empty for global cache volumes."`
 }
 
 func (*CacheVolume) Type() *ast.Type {
@@ -32,6 +44,16 @@ func NewCache(keys ...string) *CacheVolume {
 	return &CacheVolume{Keys: keys}
 }
 
+// NewScopedCache returns a cache volume for the given key within the given
+// namespace, as returned by CacheVolumeNamespace.
+func NewScopedCache(scope CacheVolumeScope, namespace string, key string) *CacheVolume {
+	return &CacheVolume{
+		Keys:      []string{key},
+		Scope:     scope,
+		Namespace: namespace,
+	}
+}
+
 func (cache *CacheVolume) Clone() *CacheVolume {
 	cp := *cache
 	cp.Keys = cloneSlice(cp.Keys)
@@ -44,10 +66,193 @@ func (cache *CacheVolume) Sum() string {
 	for _, tok := range cache.Keys {
 		_, _ = hash.Write([]byte(tok + "\x00"))
 	}
+	// the global namespace is empty, which keeps the checksums of global
+	// volumes the same as before they were scoped
+	if cache.Namespace != "" {
+		_, _ = hash.Write([]byte("namespace:" + cache.Namespace + "\x00"))
+	}
 
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
 }
 
+// Size returns the disk space used by the cache volume, in bytes.
+func (cache *CacheVolume) Size(ctx context.Context) (int64, error) {
+	bk, err := cache.Query.Buildkit(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+	return bk.CacheVolumeSize(ctx, cache.Sum())
+}
+
+// Snapshot copies the current contents of the cache volume into a Directory.
+func (cache *CacheVolume) Snapshot(ctx context.Context, srv *dagql.Server) (inst dagql.Instance[*Directory], rerr error) {
+	bk, err := cache.Query.Buildkit(ctx)
+	if err != nil {
+		return inst, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	ctx, span := Tracer().Start(ctx, "snapshot cache volume")
+	defer telemetry.End(span, func() error { return rerr })
+
+	_, desc, err := bk.CacheVolumeSnapshot(ctx, cache.Sum())
+	if err != nil {
+		return inst, fmt.Errorf("failed to snapshot cache volume: %w", err)
+	}
+	return LoadBlob(ctx, srv, desc)
+}
+
+// Seed replaces the contents of the cache volume with the contents of the
+// given directory.
+func (cache *CacheVolume) Seed(ctx context.Context, source *Directory) (rerr error) {
+	svcs, err := cache.Query.Services(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := cache.Query.Buildkit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	ctx, span := Tracer().Start(ctx, "seed cache volume")
+	defer telemetry.End(span, func() error { return rerr })
+
+	detach, _, err := svcs.StartBindings(ctx, source.Services)
+	if err != nil {
+		return err
+	}
+	defer detach()
+
+	return bk.SeedCacheVolume(ctx, cache.Sum(), source.LLB, source.Dir)
+}
+
+// Clear removes the contents of the cache volume. Execs currently using the
+// volume will see it emptied.
+func (cache *CacheVolume) Clear(ctx context.Context) error {
+	bk, err := cache.Query.Buildkit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+	return bk.ClearCacheVolume(ctx, cache.Sum())
+}
+
+type CacheVolumeScope string
+
+var CacheVolumeScopes = dagql.NewEnum[CacheVolumeScope]()
+
+var (
+	CacheVolumeScopeGlobal = CacheVolumeScopes.Register("GLOBAL_SCOPE",
+		"Shares the cache volume with every client and module using the same key")
+	CacheVolumeScopeModule = CacheVolumeScopes.Register("MODULE_SCOPE",
+		"Keeps a cache volume for the current module, regardless of who calls it")
+	CacheVolumeScopeCallerModule = CacheVolumeScopes.Register("CALLER_MODULE_SCOPE",
+		"Shares the cache volume with the caller of the current function, as if the caller had created it with its default scope")
+	CacheVolumeScopeClient = CacheVolumeScopes.Register("CLIENT_SCOPE",
+		"Keeps a cache volume for the client that started the session, e.g. the dagger CLI of a particular user")
+)
+
+func (scope CacheVolumeScope) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "CacheVolumeScope",
+		NonNull:   true,
+	}
+}
+
+func (scope CacheVolumeScope) TypeDescription() string {
+	return "Scope of a cache volume, which determines who it is shared with."
+}
+
+func (scope CacheVolumeScope) Decoder() dagql.InputDecoder {
+	return CacheVolumeScopes
+}
+
+func (scope CacheVolumeScope) ToLiteral() call.Literal {
+	return CacheVolumeScopes.Literal(scope)
+}
+
+// DefaultCacheVolumeScope returns the scope of cache volumes created without
+// one: CacheVolumeScopeModule within a module, CacheVolumeScopeGlobal otherwise.
+func DefaultCacheVolumeScope(ctx context.Context, q *Query) (CacheVolumeScope, error) {
+	_, err := q.CurrentModule(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNoCurrentModule) {
+			return CacheVolumeScopeGlobal, nil
+		}
+		return "", err
+	}
+	return CacheVolumeScopeModule, nil
+}
+
+// CacheVolumeNamespace returns the namespace of cache volumes with the given
+// scope created by the current client. The global namespace is empty.
+func CacheVolumeNamespace(ctx context.Context, q *Query, scope CacheVolumeScope) (string, error) {
+	switch scope {
+	case CacheVolumeScopeGlobal:
+		return "", nil
+	case CacheVolumeScopeModule:
+		mod, err := q.CurrentModule(ctx)
+		if err != nil {
+			if errors.Is(err, ErrNoCurrentModule) {
+				return "", fmt.Errorf("cache volume scope %s is only valid within a module", scope)
+			}
+			return "", err
+		}
+		return moduleCacheNamespace(mod)
+	case CacheVolumeScopeCallerModule:
+		if _, err := q.CurrentModule(ctx); err != nil {
+			if errors.Is(err, ErrNoCurrentModule) {
+				return "", fmt.Errorf("cache volume scope %s is only valid within a module", scope)
+			}
+			return "", err
+		}
+		mod, err := q.CallerModule(ctx)
+		if err != nil {
+			if errors.Is(err, ErrNoCurrentModule) {
+				// called directly by a client, whose default scope is global
+				return "", nil
+			}
+			return "", err
+		}
+		return moduleCacheNamespace(mod)
+	case CacheVolumeScopeClient:
+		stableID, err := q.MainClientStableID(ctx)
+		if err != nil {
+			return "", err
+		}
+		return "client:" + stableID, nil
+	default:
+		return "", fmt.Errorf("unknown cache volume scope %q", scope)
+	}
+}
+
+// moduleCacheNamespace returns the namespace of cache volumes scoped to the
+// given module. Modules are also identified by where their source lives, so
+// that unrelated modules with the same name don't share cache volumes: git
+// modules by their repository and subpath, and local modules by the identity
+// of their context directory and their subpath within it. Both are stable
+// across versions and edits.
+func moduleCacheNamespace(mod *Module) (string, error) {
+	namespace := "module:" + mod.Name()
+	src := mod.Source.Self
+	if src == nil {
+		return namespace, nil
+	}
+	symbolic, err := src.Symbolic()
+	if err != nil {
+		return "", err
+	}
+	if src.Kind == ModuleSourceKindLocal && src.AsLocalSource.Value.ContextIdentity != "" {
+		// the identity may be a remote URL with credentials, so only its
+		// digest is kept
+		sum := sha256.Sum256([]byte(src.AsLocalSource.Value.ContextIdentity))
+		contextID := hex.EncodeToString(sum[:8])
+		symbolic = path.Join(contextID, symbolic)
+	}
+	if symbolic != "" {
+		namespace += "@" + symbolic
+	}
+	return namespace, nil
+}
+
 type CacheSharingMode string
 
 var CacheSharingModes = dagql.NewEnum[CacheSharingMode]()
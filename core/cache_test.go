@@ -0,0 +1,200 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+)
+
+func TestScopedCacheSum(t *testing.T) {
+	// global cache volumes keep the same checksum as before they were scoped
+	require.Equal(t, NewCache("go-mod").Sum(), NewScopedCache(CacheVolumeScopeGlobal, "", "go-mod").Sum())
+
+	modA := NewScopedCache(CacheVolumeScopeModule, "module:a", "go-mod").Sum()
+	modB := NewScopedCache(CacheVolumeScopeModule, "module:b", "go-mod").Sum()
+	require.NotEqual(t, NewCache("go-mod").Sum(), modA)
+	require.NotEqual(t, modA, modB)
+
+	// the scope only determines the namespace, which is what isolates volumes
+	require.Equal(t, modA, NewScopedCache(CacheVolumeScopeCallerModule, "module:a", "go-mod").Sum())
+}
+
+type cacheScopeServer struct {
+	Server
+
+	currentModule *Module
+	callerModule  *Module
+	stableID      string
+}
+
+func (srv *cacheScopeServer) CurrentModule(context.Context) (*Module, error) {
+	if srv.currentModule == nil {
+		return nil, ErrNoCurrentModule
+	}
+	return srv.currentModule, nil
+}
+
+func (srv *cacheScopeServer) CallerModule(context.Context) (*Module, error) {
+	if srv.callerModule == nil {
+		return nil, ErrNoCurrentModule
+	}
+	return srv.callerModule, nil
+}
+
+func (srv *cacheScopeServer) MainClientStableID(context.Context) (string, error) {
+	return srv.stableID, nil
+}
+
+func TestDefaultCacheVolumeScope(t *testing.T) {
+	ctx := context.Background()
+
+	scope, err := DefaultCacheVolumeScope(ctx, NewRoot(&cacheScopeServer{}))
+	require.NoError(t, err)
+	require.Equal(t, CacheVolumeScopeGlobal, scope)
+
+	scope, err = DefaultCacheVolumeScope(ctx, NewRoot(&cacheScopeServer{currentModule: &Module{}}))
+	require.NoError(t, err)
+	require.Equal(t, CacheVolumeScopeModule, scope)
+}
+
+func TestCacheVolumeNamespace(t *testing.T) {
+	ctx := context.Background()
+
+	localModSource := func(refString, rootSubpath string, contextIdentity ...string) dagql.Instance[*ModuleSource] {
+		return dagql.Instance[*ModuleSource]{
+			Constructor: call.New().Append(
+				(&ModuleSource{}).Type(), "moduleSource", "", nil, false, 0,
+				call.NewArgument("refString", call.NewLiteralString(refString)),
+			),
+			Self: &ModuleSource{
+				Kind: ModuleSourceKindLocal,
+				AsLocalSource: dagql.NonNull(&LocalModuleSource{
+					RootSubpath:     rootSubpath,
+					ContextIdentity: strings.Join(contextIdentity, ""),
+				}),
+			},
+		}
+	}
+	localMod := &Module{
+		NameField: "local",
+		Source:    localModSource("./a", "a"),
+	}
+	otherLocalMod := &Module{
+		NameField: "local",
+		Source:    localModSource("./b", "b"),
+	}
+	// the same module loaded from another working directory, after edits
+	editedLocalMod := &Module{
+		NameField: "local",
+		Source:    localModSource("../a", "a"),
+	}
+	localNamespace := "module:local@a"
+	// same-named modules at the same subpath of unrelated repos
+	repoMod := &Module{
+		NameField: "ci",
+		Source:    localModSource("./ci", "ci", "git:https://github.com/dagger/dagger"),
+	}
+	otherRepoMod := &Module{
+		NameField: "ci",
+		Source:    localModSource("./ci", "ci", "path:/home/user/other"),
+	}
+	repoNamespace := "module:ci@49963dad84afe4a0/ci"
+	gitMod := &Module{
+		NameField: "go",
+		Source: dagql.Instance[*ModuleSource]{Self: &ModuleSource{
+			Kind: ModuleSourceKindGit,
+			AsGitSource: dagql.NonNull(&GitModuleSource{
+				CloneURL:    "https://github.com/dagger/dagger",
+				RootSubpath: "modules/go",
+			}),
+		}},
+	}
+
+	for _, tc := range []struct {
+		name      string
+		srv       *cacheScopeServer
+		scope     CacheVolumeScope
+		namespace string
+		err       string
+	}{
+		{
+			name:  "global",
+			srv:   &cacheScopeServer{currentModule: localMod},
+			scope: CacheVolumeScopeGlobal,
+		},
+		{
+			name:      "local module",
+			srv:       &cacheScopeServer{currentModule: localMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: localNamespace,
+		},
+		{
+			name:      "local module with the same name",
+			srv:       &cacheScopeServer{currentModule: otherLocalMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: "module:local@b",
+		},
+		{
+			name:      "edited local module",
+			srv:       &cacheScopeServer{currentModule: editedLocalMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: localNamespace,
+		},
+		{
+			name:      "local module in a repo",
+			srv:       &cacheScopeServer{currentModule: repoMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: repoNamespace,
+		},
+		{
+			name:      "local module with the same name in another repo",
+			srv:       &cacheScopeServer{currentModule: otherRepoMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: "module:ci@2716f54ee6123bff/ci",
+		},
+		{
+			name:      "git module",
+			srv:       &cacheScopeServer{currentModule: gitMod},
+			scope:     CacheVolumeScopeModule,
+			namespace: "module:go@https://github.com/dagger/dagger/modules/go",
+		},
+		{
+			name:  "module outside module",
+			srv:   &cacheScopeServer{},
+			scope: CacheVolumeScopeModule,
+			err:   "only valid within a module",
+		},
+		{
+			name:      "caller module",
+			srv:       &cacheScopeServer{currentModule: gitMod, callerModule: localMod},
+			scope:     CacheVolumeScopeCallerModule,
+			namespace: localNamespace,
+		},
+		{
+			name:  "caller is client",
+			srv:   &cacheScopeServer{currentModule: gitMod},
+			scope: CacheVolumeScopeCallerModule,
+		},
+		{
+			name:      "client",
+			srv:       &cacheScopeServer{currentModule: gitMod, stableID: "abc"},
+			scope:     CacheVolumeScopeClient,
+			namespace: "client:abc",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace, err := CacheVolumeNamespace(ctx, NewRoot(tc.srv), tc.scope)
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.namespace, namespace)
+		})
+	}
+}
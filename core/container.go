@@ -8,7 +8,6 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -94,6 +93,13 @@ type Container struct {
 	// (Internal-only for now) Environment variables from the engine container, prefixed
 	// with a special value, that will be inherited by this container if set.
 	SystemEnvNames []string `json:"system_envs,omitempty"`
+
+	// SPDX JSON document attached to the image when it's published or exported.
+	SBOM *File `json:"sbom,omitempty"`
+
+	// BuildKit SBOM scanner image to generate an SBOM with when the image is
+	// published or exported, if SBOM isn't set.
+	SBOMScanner string `json:"sbomScanner,omitempty"`
 }
 
 func (*Container) Type() *ast.Type {
@@ -130,6 +136,9 @@ func (container *Container) PBDefinitions(ctx context.Context) ([]*pb.Definition
 		}
 		defs = append(defs, ctrDefs...)
 	}
+	if container.SBOM != nil {
+		defs = append(defs, container.SBOM.LLB)
+	}
 	return defs, nil
 }
 
@@ -287,9 +296,15 @@ func (container *Container) From(ctx context.Context, addr string) (*Container,
 		return nil, err
 	}
 
+	if err := bk.ImagePolicy.Check(refName); err != nil {
+		return nil, err
+	}
+
+	// the image is resolved and pulled from where the engine's policy says,
+	// e.g. a mirror, but its ref is kept, so that it can be pinned regardless
 	ref := reference.TagNameOnly(refName).String()
 
-	_, digest, cfgBytes, err := bk.ResolveImageConfig(ctx, ref, sourceresolver.Opt{
+	_, dgst, cfgBytes, err := bk.ResolveImageConfig(ctx, ref, sourceresolver.Opt{
 		Platform: ptr(platform.Spec()),
 		ImageOpt: &sourceresolver.ResolveImageOpt{
 			ResolveMode: llb.ResolveModeDefault.String(),
@@ -299,7 +314,7 @@ func (container *Container) From(ctx context.Context, addr string) (*Container,
 		return nil, fmt.Errorf("failed to resolve image %s: %w", ref, err)
 	}
 
-	digested, err := reference.WithDigest(refName, digest)
+	digested, err := reference.WithDigest(refName, dgst)
 	if err != nil {
 		return nil, err
 	}
@@ -561,6 +576,8 @@ func (container *Container) WithMountedFile(ctx context.Context, target string,
 	return container.withMounted(ctx, target, file.LLB, file.File, file.Services, owner, readonly)
 }
 
+// SeenCacheKeys maps the checksum identifying the cache mount of each cache
+// volume mounted since the engine started to that *CacheVolume.
 var SeenCacheKeys = new(sync.Map)
 
 func (container *Container) WithMountedCache(ctx context.Context, target string, cache *CacheVolume, source *Directory, sharingMode CacheSharingMode, owner string) (*Container, error) {
@@ -602,7 +619,7 @@ func (container *Container) WithMountedCache(ctx context.Context, target string,
 	// set image ref to empty string
 	container.ImageRef = ""
 
-	SeenCacheKeys.Store(cache.Keys[0], struct{}{})
+	SeenCacheKeys.Store(cache.Sum(), cache)
 
 	return container, nil
 }
@@ -1062,59 +1079,185 @@ func (container Container) Evaluate(ctx context.Context) (*buildkit.Result, erro
 	})
 }
 
-func (container *Container) Publish(
+// ImageExportOpts configures the image Publish, Export and AsTarball produce.
+type ImageExportOpts struct {
+	PlatformVariants []*Container
+
+	// ForcedCompression recompresses every layer, at CompressionLevel if it's
+	// set.
+	ForcedCompression ImageLayerCompression
+	CompressionLevel  *int
+
+	MediaTypes ImageMediaTypes
+
+	// Annotations are set on the image's manifests, and IndexAnnotations on the
+	// index listing them. If AnnotateFromLabels is set, the
+	// org.opencontainers.image.* labels of each variant are set as annotations
+	// on its manifest too, unless Annotations overrides them.
+	Annotations        []ImageAnnotation
+	AnnotateFromLabels bool
+	IndexAnnotations   []ImageAnnotation
+
+	// Provenance, if not nil, holds the IDs of the container and of each of its
+	// platform variants, which the SLSA provenance attached to their images is
+	// derived from.
+	Provenance []*call.ID
+}
+
+// ImagePublishOpts configures the image Publish pushes.
+type ImagePublishOpts struct {
+	ImageExportOpts
+
+	// Tags are additional tags to push the image to, in the repository of the
+	// address it's published to.
+	Tags []string
+
+	// SigningKey signs the pushed image, unless it's nil.
+	SigningKey *ImageSigningKey
+}
+
+// ImageAnnotation is an OCI annotation to set on an image's manifests or
+// index.
+type ImageAnnotation struct {
+	Name  string `field:"true" doc:"The annotation name, e.g. org.opencontainers.image.source."`
+	Value string `field:"true" doc:"The annotation value."`
+}
+
+func (ImageAnnotation) TypeName() string {
+	return "ImageAnnotation"
+}
+
+func (ImageAnnotation) TypeDescription() string {
+	return "Key value object that represents an OCI image annotation."
+}
+
+// ociAnnotationPrefix is the prefix of the annotations the OCI image spec
+// predefines, which are also conventionally used as image labels.
+const ociAnnotationPrefix = "org.opencontainers.image."
+
+// imageExport collects the container and its platform variants into inputs
+// for the buildkit image exporter.
+func (container *Container) imageExport(
 	ctx context.Context,
-	ref string,
-	platformVariants []*Container,
-	forcedCompression ImageLayerCompression,
-	mediaTypes ImageMediaTypes,
-) (string, error) {
-	if mediaTypes == "" {
+	opts ImageExportOpts,
+) (map[string]buildkit.ContainerExport, ServiceBindings, buildkit.ImageExportOpts, error) {
+	if opts.MediaTypes == "" {
 		// Modern registry implementations support oci types and docker daemons
 		// have been capable of pulling them since 2018:
 		// https://github.com/moby/moby/pull/37359
 		// So they are a safe default.
-		mediaTypes = OCIMediaTypes
+		opts.MediaTypes = OCIMediaTypes
+	}
+	if opts.CompressionLevel != nil && opts.ForcedCompression == "" {
+		// layers with cached blobs would be exported as is, ignoring the level
+		return nil, nil, buildkit.ImageExportOpts{}, errors.New("a compression level requires a forced compression")
+	}
+
+	if opts.MediaTypes != OCIMediaTypes && (len(opts.Annotations) > 0 || len(opts.IndexAnnotations) > 0 || opts.AnnotateFromLabels) {
+		return nil, nil, buildkit.ImageExportOpts{}, errors.New("annotations require OCI media types")
+	}
+	overridden := map[string]bool{}
+	for _, annotation := range opts.Annotations {
+		overridden[annotation.Name] = true
 	}
 
 	inputByPlatform := map[string]buildkit.ContainerExport{}
 	services := ServiceBindings{}
-	for _, variant := range append([]*Container{container}, platformVariants...) {
+	for i, variant := range append([]*Container{container}, opts.PlatformVariants...) {
 		if variant.FS == nil {
 			continue
 		}
 		st, err := variant.FSState()
 		if err != nil {
-			return "", err
+			return nil, nil, buildkit.ImageExportOpts{}, err
 		}
 		def, err := st.Marshal(ctx, llb.Platform(variant.Platform.Spec()))
 		if err != nil {
-			return "", err
+			return nil, nil, buildkit.ImageExportOpts{}, err
 		}
 
 		platformString := variant.Platform.Format()
 		if _, ok := inputByPlatform[platformString]; ok {
-			return "", fmt.Errorf("duplicate platform %q", platformString)
+			return nil, nil, buildkit.ImageExportOpts{}, fmt.Errorf("duplicate platform %q", platformString)
+		}
+		var provenanceID *call.ID
+		if opts.Provenance != nil {
+			provenanceID = opts.Provenance[i]
+		}
+		attestations, err := variant.imageAttestations(ctx, provenanceID)
+		if err != nil {
+			return nil, nil, buildkit.ImageExportOpts{}, err
+		}
+		var annotations map[string]string
+		if opts.AnnotateFromLabels {
+			for k, v := range variant.Config.Labels {
+				if strings.HasPrefix(k, ociAnnotationPrefix) && !overridden[k] {
+					if annotations == nil {
+						annotations = map[string]string{}
+					}
+					annotations[k] = v
+				}
+			}
 		}
 		inputByPlatform[platformString] = buildkit.ContainerExport{
-			Definition: def.ToPB(),
-			Config:     variant.Config,
+			Definition:   def.ToPB(),
+			Config:       variant.Config,
+			Attestations: attestations,
+			Annotations:  annotations,
 		}
 		services.Merge(variant.Services)
 	}
 	if len(inputByPlatform) == 0 {
 		// Could also just ignore and do nothing, airing on side of error until proven otherwise.
-		return "", errors.New("no containers to export")
+		return nil, nil, buildkit.ImageExportOpts{}, errors.New("no containers to export")
 	}
 
-	opts := map[string]string{
-		string(exptypes.OptKeyName):     ref,
-		string(exptypes.OptKeyPush):     strconv.FormatBool(true),
-		string(exptypes.OptKeyOCITypes): strconv.FormatBool(mediaTypes == OCIMediaTypes),
+	exportOpts := buildkit.ImageExportOpts{
+		OCIMediaTypes:    opts.MediaTypes == OCIMediaTypes,
+		CompressionLevel: opts.CompressionLevel,
+	}
+	if opts.ForcedCompression != "" {
+		exportOpts.Compression = strings.ToLower(string(opts.ForcedCompression))
+	}
+	for _, annotation := range opts.Annotations {
+		if exportOpts.ManifestAnnotations == nil {
+			exportOpts.ManifestAnnotations = map[string]string{}
+		}
+		exportOpts.ManifestAnnotations[annotation.Name] = annotation.Value
+	}
+	for _, annotation := range opts.IndexAnnotations {
+		if exportOpts.IndexAnnotations == nil {
+			exportOpts.IndexAnnotations = map[string]string{}
+		}
+		exportOpts.IndexAnnotations[annotation.Name] = annotation.Value
+	}
+	return inputByPlatform, services, exportOpts, nil
+}
+
+// Publish pushes the container and its platform variants to a registry, at
+// ref and at each of opts.Tags in its repository, returning ref with the
+// digest of the pushed image.
+func (container *Container) Publish(
+	ctx context.Context,
+	ref string,
+	opts ImagePublishOpts,
+) (string, error) {
+	inputByPlatform, services, exportOpts, err := container.imageExport(ctx, opts.ImageExportOpts)
+	if err != nil {
+		return "", err
 	}
-	if forcedCompression != "" {
-		opts[string(exptypes.OptKeyLayerCompression)] = strings.ToLower(string(forcedCompression))
-		opts[string(exptypes.OptKeyForceCompression)] = strconv.FormatBool(true)
+
+	refName, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return "", err
+	}
+	exportOpts.Names = []string{ref}
+	for _, tag := range opts.Tags {
+		tagged, err := reference.WithTag(reference.TrimNamed(refName), tag)
+		if err != nil {
+			return "", fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+		exportOpts.Names = append(exportOpts.Names, tagged.String())
 	}
 
 	svcs, err := container.Query.Services(ctx)
@@ -1132,23 +1275,41 @@ func (container *Container) Publish(
 	}
 	defer detach()
 
-	resp, err := bk.PublishContainerImage(ctx, inputByPlatform, opts)
-	if err != nil {
-		return "", err
+	var signingOpts buildkit.ImageSigningOpts
+	if opts.SigningKey != nil {
+		// check the key first, to not push an image that can't be signed
+		signingOpts, err = opts.SigningKey.signingOpts(ctx, container.Query)
+		if err != nil {
+			return "", err
+		}
+		if err := signingOpts.Validate(); err != nil {
+			return "", err
+		}
 	}
 
-	refName, err := reference.ParseNormalizedNamed(ref)
+	resp, err := bk.PublishContainerImage(ctx, inputByPlatform, exportOpts)
 	if err != nil {
 		return "", err
 	}
 
 	imageDigest, found := resp[exptypes.ExporterImageDigestKey]
+	if !found && opts.SigningKey != nil {
+		return "", fmt.Errorf("cannot sign %s: the registry did not return its digest", ref)
+	}
 	if found {
 		dig, err := digest.Parse(imageDigest)
 		if err != nil {
 			return "", fmt.Errorf("parse digest: %w", err)
 		}
 
+		// signatures are stored by digest in the repository, so one covers
+		// every tag
+		if opts.SigningKey != nil {
+			if err := bk.SignContainerImage(ctx, ref, dig, signingOpts); err != nil {
+				return "", fmt.Errorf("failed to sign %s: %w", ref, err)
+			}
+		}
+
 		withDig, err := reference.WithDigest(refName, dig)
 		if err != nil {
 			return "", fmt.Errorf("with digest: %w", err)
@@ -1163,9 +1324,7 @@ func (container *Container) Publish(
 func (container *Container) Export(
 	ctx context.Context,
 	dest string,
-	platformVariants []*Container,
-	forcedCompression ImageLayerCompression,
-	mediaTypes ImageMediaTypes,
+	opts ImageExportOpts,
 ) error {
 	svcs, err := container.Query.Services(ctx)
 	if err != nil {
@@ -1176,52 +1335,9 @@ func (container *Container) Export(
 		return fmt.Errorf("failed to get buildkit client: %w", err)
 	}
 
-	if mediaTypes == "" {
-		// Modern registry implementations support oci types and docker daemons
-		// have been capable of pulling them since 2018:
-		// https://github.com/moby/moby/pull/37359
-		// So they are a safe default.
-		mediaTypes = OCIMediaTypes
-	}
-
-	inputByPlatform := map[string]buildkit.ContainerExport{}
-	services := ServiceBindings{}
-	for _, variant := range append([]*Container{container}, platformVariants...) {
-		if variant.FS == nil {
-			continue
-		}
-		st, err := variant.FSState()
-		if err != nil {
-			return err
-		}
-
-		def, err := st.Marshal(ctx, llb.Platform(variant.Platform.Spec()))
-		if err != nil {
-			return err
-		}
-
-		platformString := variant.Platform.Format()
-		if _, ok := inputByPlatform[platformString]; ok {
-			return fmt.Errorf("duplicate platform %q", platformString)
-		}
-		inputByPlatform[platformString] = buildkit.ContainerExport{
-			Definition: def.ToPB(),
-			Config:     variant.Config,
-		}
-		services.Merge(variant.Services)
-	}
-	if len(inputByPlatform) == 0 {
-		// Could also just ignore and do nothing, airing on side of error until proven otherwise.
-		return errors.New("no containers to export")
-	}
-
-	opts := map[string]string{
-		"tar":                           strconv.FormatBool(true),
-		string(exptypes.OptKeyOCITypes): strconv.FormatBool(mediaTypes == OCIMediaTypes),
-	}
-	if forcedCompression != "" {
-		opts[string(exptypes.OptKeyLayerCompression)] = strings.ToLower(string(forcedCompression))
-		opts[string(exptypes.OptKeyForceCompression)] = strconv.FormatBool(true)
+	inputByPlatform, services, exportOpts, err := container.imageExport(ctx, opts)
+	if err != nil {
+		return err
 	}
 
 	detach, _, err := svcs.StartBindings(ctx, services)
@@ -1230,15 +1346,13 @@ func (container *Container) Export(
 	}
 	defer detach()
 
-	_, err = bk.ExportContainerImage(ctx, inputByPlatform, dest, opts)
+	_, err = bk.ExportContainerImage(ctx, inputByPlatform, dest, exportOpts)
 	return err
 }
 
 func (container *Container) AsTarball(
 	ctx context.Context,
-	platformVariants []*Container,
-	forcedCompression ImageLayerCompression,
-	mediaTypes ImageMediaTypes,
+	opts ImageExportOpts,
 ) (*File, error) {
 	bk, err := container.Query.Buildkit(ctx)
 	if err != nil {
@@ -1250,47 +1364,9 @@ func (container *Container) AsTarball(
 	}
 	engineHostPlatform := container.Query.Platform()
 
-	if mediaTypes == "" {
-		mediaTypes = OCIMediaTypes
-	}
-
-	inputByPlatform := map[string]buildkit.ContainerExport{}
-	services := ServiceBindings{}
-	for _, variant := range append([]*Container{container}, platformVariants...) {
-		if variant.FS == nil {
-			continue
-		}
-		st, err := variant.FSState()
-		if err != nil {
-			return nil, err
-		}
-
-		def, err := st.Marshal(ctx, llb.Platform(variant.Platform.Spec()))
-		if err != nil {
-			return nil, err
-		}
-
-		platformString := platforms.Format(variant.Platform.Spec())
-		if _, ok := inputByPlatform[platformString]; ok {
-			return nil, fmt.Errorf("duplicate platform %q", platformString)
-		}
-		inputByPlatform[platformString] = buildkit.ContainerExport{
-			Definition: def.ToPB(),
-			Config:     variant.Config,
-		}
-		services.Merge(variant.Services)
-	}
-	if len(inputByPlatform) == 0 {
-		return nil, errors.New("no containers to export")
-	}
-
-	opts := map[string]string{
-		"tar":                           strconv.FormatBool(true),
-		string(exptypes.OptKeyOCITypes): strconv.FormatBool(mediaTypes == OCIMediaTypes),
-	}
-	if forcedCompression != "" {
-		opts[string(exptypes.OptKeyLayerCompression)] = strings.ToLower(string(forcedCompression))
-		opts[string(exptypes.OptKeyForceCompression)] = strconv.FormatBool(true)
+	inputByPlatform, services, exportOpts, err := container.imageExport(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	detach, _, err := svcs.StartBindings(ctx, services)
@@ -1300,7 +1376,7 @@ func (container *Container) AsTarball(
 	defer detach()
 
 	fileName := identity.NewID() + ".tar"
-	pbDef, err := bk.ContainerImageToTarball(ctx, engineHostPlatform.Spec(), fileName, inputByPlatform, opts)
+	pbDef, err := bk.ContainerImageToTarball(ctx, engineHostPlatform.Spec(), fileName, inputByPlatform, exportOpts)
 	if err != nil {
 		return nil, fmt.Errorf("container image to tarball file conversion failed: %w", err)
 	}
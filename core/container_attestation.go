@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine/buildkit"
+)
+
+// Paths the BuildKit SBOM scanner protocol mounts the scanned filesystem and
+// the output directory at.
+const (
+	sbomScanSource      = "/run/src/core/sbom"
+	sbomScanDestination = "/run/out"
+)
+
+// WithSBOM attaches an SPDX JSON document to the container's image when it's
+// published or exported.
+func (container *Container) WithSBOM(ctx context.Context, sbom *File) (*Container, error) {
+	content, err := sbom.Contents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil || doc.SPDXVersion == "" {
+		return nil, fmt.Errorf("%s is not an SPDX JSON document", path.Base(sbom.File))
+	}
+
+	container = container.Clone()
+	container.SBOM = sbom
+	container.SBOMScanner = ""
+	return container, nil
+}
+
+// WithGeneratedSBOM generates an SBOM with a BuildKit SBOM scanner image when
+// the container's image is published or exported, so that it describes the
+// container's final root filesystem.
+func (container *Container) WithGeneratedSBOM(scanner string) *Container {
+	container = container.Clone()
+	container.SBOM = nil
+	container.SBOMScanner = scanner
+	return container
+}
+
+func (container *Container) WithoutSBOM() *Container {
+	container = container.Clone()
+	container.SBOM = nil
+	container.SBOMScanner = ""
+	return container
+}
+
+// imageAttestations returns the attestations to attach to the container's
+// image: its SBOM, if any, and provenance derived from id, unless it's nil.
+func (container *Container) imageAttestations(ctx context.Context, id *call.ID) ([]buildkit.ContainerAttestation, error) {
+	var atts []buildkit.ContainerAttestation
+	switch {
+	case container.SBOM != nil:
+		atts = append(atts, buildkit.ContainerAttestation{
+			PredicateType: intoto.PredicateSPDX,
+			Definition:    container.SBOM.LLB,
+			Path:          container.SBOM.File,
+		})
+	case container.SBOMScanner != "":
+		out, err := container.scanSBOM(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate SBOM: %w", err)
+		}
+		atts = append(atts, buildkit.ContainerAttestation{
+			PredicateType: intoto.PredicateSPDX,
+			Definition:    out.LLB,
+			Path:          out.Dir,
+			Bundle:        true,
+		})
+	}
+	if id != nil {
+		provenance, err := ImageProvenance(id, container.Platform)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate provenance: %w", err)
+		}
+		atts = append(atts, buildkit.ContainerAttestation{
+			PredicateType: slsa02.PredicateSLSAProvenance,
+			Path:          "provenance.json",
+			Content:       provenance,
+		})
+	}
+	return atts, nil
+}
+
+// scanSBOM runs the container's SBOM scanner over its root filesystem,
+// returning the directory of in-toto statements it writes.
+func (container *Container) scanSBOM(ctx context.Context) (*Directory, error) {
+	rootfs, err := container.RootFS(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := container.Query.Platform()
+	scanner, err := NewContainer(container.Query, platform)
+	if err != nil {
+		return nil, err
+	}
+	scanner, err = scanner.From(ctx, container.SBOMScanner)
+	if err != nil {
+		return nil, err
+	}
+	scanner, err = scanner.UpdateImageConfig(ctx, func(cfg specs.ImageConfig) specs.ImageConfig {
+		cfg.Env = append(cfg.Env,
+			"BUILDKIT_SCAN_SOURCE="+sbomScanSource,
+			"BUILDKIT_SCAN_DESTINATION="+sbomScanDestination,
+		)
+		return cfg
+	})
+	if err != nil {
+		return nil, err
+	}
+	scanner, err = scanner.WithMountedDirectory(ctx, sbomScanSource, rootfs, "", true)
+	if err != nil {
+		return nil, err
+	}
+	scanner, err = scanner.WithMountedDirectory(ctx, sbomScanDestination, NewScratchDirectory(container.Query, platform), "", false)
+	if err != nil {
+		return nil, err
+	}
+	scanner, err = scanner.WithExec(ctx, ContainerExecOpts{
+		UseEntrypoint: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scanner.Directory(ctx, sbomScanDestination)
+}
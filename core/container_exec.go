@@ -6,9 +6,15 @@ import (
 	"os"
 	"path"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/vektah/gqlparser/v2/ast"
 
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/buildkit"
 	"github.com/moby/buildkit/client/llb"
@@ -44,6 +50,21 @@ type ContainerExecOpts struct {
 	// Grant the process all root capabilities
 	InsecureRootCapabilities bool `default:"false"`
 
+	// Exit codes this exec is allowed to exit with
+	Expect ReturnTypes `default:"SUCCESS"`
+
+	// Maximum duration the command may run for before it is killed
+	Timeout string `default:""`
+
+	// Maximum amount of memory the command may use
+	MemoryLimit string `default:""`
+
+	// Maximum number of CPUs the command may use
+	CPUQuota string `name:"cpuQuota" default:""`
+
+	// Maximum number of processes the command may create
+	PidsLimit int `default:"0"`
+
 	// (Internal-only) If this is a nested exec, exec metadata to use for it
 	NestedExecMetadata *buildkit.ExecutionMetadata `name:"-"`
 }
@@ -89,6 +110,15 @@ func (container *Container) WithExec(ctx context.Context, opts ContainerExecOpts
 	execMD.RedirectStderrPath = opts.RedirectStderr
 	execMD.SystemEnvNames = container.SystemEnvNames
 	execMD.EnabledGPUs = container.EnabledGPUs
+	execMD.ValidExitCodes = opts.Expect.ReturnCodes()
+	if err := opts.setResourceLimits(&execMD); err != nil {
+		return nil, err
+	}
+	if limits := resourceLimitsKey(execMD); limits != "" {
+		// ensure the resource limits are in the cache key, since a command that
+		// hits one of them (e.g. with expect: ANY) produces a different result
+		runOpts = append(runOpts, llb.AddEnv(buildkit.DaggerResourceLimitsEnv, limits))
+	}
 
 	// if GPU parameters are set for this container pass them over:
 	if len(execMD.EnabledGPUs) > 0 {
@@ -138,6 +168,11 @@ func (container *Container) WithExec(ctx context.Context, opts ContainerExecOpts
 		runOpts = append(runOpts, llb.AddEnv(buildkit.DaggerRedirectStderrEnv, opts.RedirectStderr))
 	}
 
+	if opts.Expect != ReturnSuccess && opts.Expect != "" {
+		// ensure the expected exit codes are in the cache key
+		runOpts = append(runOpts, llb.AddEnv(buildkit.DaggerExpectEnv, string(opts.Expect)))
+	}
+
 	var aliasStrs []string
 	for _, bnd := range container.Services {
 		for _, alias := range bnd.Aliases {
@@ -338,6 +373,83 @@ func (container *Container) MetaFileContents(ctx context.Context, filePath strin
 	return string(content), nil
 }
 
+// setResourceLimits parses any resource limits set on the exec into the
+// execution metadata, where the executor applies them to the container.
+func (opts ContainerExecOpts) setResourceLimits(execMD *buildkit.ExecutionMetadata) error {
+	if opts.Timeout != "" {
+		timeout, err := time.ParseDuration(opts.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", opts.Timeout, err)
+		}
+		if timeout <= 0 {
+			return fmt.Errorf("invalid timeout %q: must be positive", opts.Timeout)
+		}
+		execMD.Timeout = timeout
+	}
+
+	if opts.MemoryLimit != "" {
+		memoryLimit, err := units.RAMInBytes(opts.MemoryLimit)
+		if err != nil {
+			return fmt.Errorf("invalid memory limit %q: %w", opts.MemoryLimit, err)
+		}
+		if memoryLimit <= 0 {
+			return fmt.Errorf("invalid memory limit %q: must be positive", opts.MemoryLimit)
+		}
+		execMD.MemoryLimit = memoryLimit
+	}
+
+	if opts.CPUQuota != "" {
+		cpus, err := strconv.ParseFloat(opts.CPUQuota, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cpu quota %q: %w", opts.CPUQuota, err)
+		}
+		if cpus <= 0 {
+			return fmt.Errorf("invalid cpu quota %q: must be positive", opts.CPUQuota)
+		}
+		execMD.CPUQuota = int64(cpus * float64(buildkit.CPUPeriod))
+	}
+
+	if opts.PidsLimit < 0 {
+		return fmt.Errorf("invalid pids limit %d: must not be negative", opts.PidsLimit)
+	}
+	execMD.PidsLimit = int64(opts.PidsLimit)
+
+	return nil
+}
+
+// resourceLimitsKey returns a stable representation of the resource limits
+// set in the execution metadata, or "" if there are none.
+func resourceLimitsKey(execMD buildkit.ExecutionMetadata) string {
+	var limits []string
+	if execMD.Timeout > 0 {
+		limits = append(limits, "timeout="+execMD.Timeout.String())
+	}
+	if execMD.MemoryLimit > 0 {
+		limits = append(limits, "memory="+strconv.FormatInt(execMD.MemoryLimit, 10))
+	}
+	if execMD.CPUQuota > 0 {
+		limits = append(limits, "cpu="+strconv.FormatInt(execMD.CPUQuota, 10))
+	}
+	if execMD.PidsLimit > 0 {
+		limits = append(limits, "pids="+strconv.FormatInt(execMD.PidsLimit, 10))
+	}
+	return strings.Join(limits, ",")
+}
+
+func (container *Container) ExitCode(ctx context.Context) (int, error) {
+	contents, err := container.MetaFileContents(ctx, buildkit.MetaMountExitCodePath)
+	if err != nil {
+		return 0, err
+	}
+	contents = strings.TrimSpace(contents)
+
+	code, err := strconv.ParseInt(contents, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse exit code %q: %w", contents, err)
+	}
+	return int(code), nil
+}
+
 func metaMount(stdin string) (llb.State, string) {
 	meta := llb.Mkdir(buildkit.MetaMountDestPath, 0o777)
 	if stdin != "" {
@@ -350,3 +462,58 @@ func metaMount(stdin string) (llb.State, string) {
 		),
 		buildkit.MetaMountDestPath
 }
+
+type ReturnTypes string
+
+var ReturnTypesEnum = dagql.NewEnum[ReturnTypes]()
+
+var (
+	ReturnSuccess = ReturnTypesEnum.Register("SUCCESS",
+		"A successful execution (exit code 0)")
+	ReturnFailure = ReturnTypesEnum.Register("FAILURE",
+		"A failed execution (exit codes 1-127)")
+	ReturnAny = ReturnTypesEnum.Register("ANY",
+		"Any execution (exit codes 0-127)")
+)
+
+func (expect ReturnTypes) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ReturnType",
+		NonNull:   true,
+	}
+}
+
+func (expect ReturnTypes) TypeDescription() string {
+	return "Expected return type of an execution"
+}
+
+func (expect ReturnTypes) Decoder() dagql.InputDecoder {
+	return ReturnTypesEnum
+}
+
+func (expect ReturnTypes) ToLiteral() call.Literal {
+	return ReturnTypesEnum.Literal(expect)
+}
+
+// ReturnCodes gets the valid exit codes allowed for a specific return type
+//
+// NOTE: exit codes above 127 are reserved for signals, and so are never
+// considered valid.
+func (expect ReturnTypes) ReturnCodes() []int {
+	switch expect {
+	case ReturnFailure:
+		codes := make([]int, 0, 127)
+		for i := 1; i <= 127; i++ {
+			codes = append(codes, i)
+		}
+		return codes
+	case ReturnAny:
+		codes := make([]int, 0, 128)
+		for i := 0; i <= 127; i++ {
+			codes = append(codes, i)
+		}
+		return codes
+	default:
+		return []int{0}
+	}
+}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+
+	"github.com/dagger/dagger/engine/buildkit"
+)
+
+// ImageSigningKey is a private key to sign images with when they're
+// published, in a format cosign can verify.
+type ImageSigningKey struct {
+	// Key is a PEM encoded private key, which may be a cosign key encrypted
+	// with Password.
+	Key      *Secret
+	Password *Secret
+
+	// Certificate is an optional PEM encoded certificate for Key, followed by
+	// its chain.
+	Certificate string
+}
+
+func (key *ImageSigningKey) signingOpts(ctx context.Context, query *Query) (buildkit.ImageSigningOpts, error) {
+	secretStore, err := query.Secrets(ctx)
+	if err != nil {
+		return buildkit.ImageSigningOpts{}, fmt.Errorf("failed to get secret store: %w", err)
+	}
+	opts := buildkit.ImageSigningOpts{
+		Certificate: []byte(key.Certificate),
+	}
+	opts.Key, err = secretStore.GetSecretPlaintext(ctx, key.Key.IDDigest)
+	if err != nil {
+		return buildkit.ImageSigningOpts{}, err
+	}
+	if key.Password != nil {
+		opts.Password, err = secretStore.GetSecretPlaintext(ctx, key.Password.IDDigest)
+		if err != nil {
+			return buildkit.ImageSigningOpts{}, err
+		}
+	}
+	return opts, nil
+}
+
+// VerifySignature checks that the image the container was pulled from is
+// signed, in a format cosign produces, with the private key of a PEM encoded
+// public key or certificate.
+func (container *Container) VerifySignature(ctx context.Context, publicKey string) error {
+	if container.ImageRef == "" {
+		return fmt.Errorf("container was not pulled from an image")
+	}
+	ref, err := reference.ParseNormalizedNamed(container.ImageRef)
+	if err != nil {
+		return err
+	}
+	digested, ok := ref.(reference.Digested)
+	if !ok {
+		return fmt.Errorf("image reference %s has no digest", container.ImageRef)
+	}
+
+	bk, err := container.Query.Buildkit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+	// signatures are next to the image wherever it was pulled from
+	pullName, err := bk.ImagePolicy.Rewrite(ref)
+	if err != nil {
+		return err
+	}
+	if err := bk.VerifyContainerImage(ctx, pullName.String(), digested.Digest(), []byte(publicKey)); err != nil {
+		return fmt.Errorf("failed to verify image %s: %w", reference.FamiliarString(ref), err)
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml"
+	"github.com/vektah/gqlparser/v2/ast"
+	"gopkg.in/yaml.v3"
+
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
+)
+
+type DataFormat string
+
+var DataFormats = dagql.NewEnum[DataFormat]()
+
+var (
+	DataFormatJSON = DataFormats.Register("JSON_FORMAT",
+		"JSON, e.g. package.json")
+	DataFormatYAML = DataFormats.Register("YAML_FORMAT",
+		"YAML, e.g. Chart.yaml. Only the first document of a stream is read.")
+	DataFormatTOML = DataFormats.Register("TOML_FORMAT",
+		"TOML, e.g. Cargo.toml")
+)
+
+func (format DataFormat) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "DataFormat",
+		NonNull:   true,
+	}
+}
+
+func (format DataFormat) TypeDescription() string {
+	return "Format of a file containing structured data."
+}
+
+func (format DataFormat) Decoder() dagql.InputDecoder {
+	return DataFormats
+}
+
+func (format DataFormat) ToLiteral() call.Literal {
+	return DataFormats.Literal(format)
+}
+
+// DataFormatOf infers the format of a file from its extension.
+func DataFormatOf(filename string) (DataFormat, error) {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".json":
+		return DataFormatJSON, nil
+	case ".yaml", ".yml":
+		return DataFormatYAML, nil
+	case ".toml":
+		return DataFormatTOML, nil
+	default:
+		return "", fmt.Errorf("cannot infer the format of %s, please specify it", path.Base(filename))
+	}
+}
+
+// ParseData parses structured data into the values encoding/json decodes
+// JSON to, with numbers kept as json.Number.
+func ParseData(content []byte, format DataFormat) (any, error) {
+	var jsonContent []byte
+	switch format {
+	case DataFormatJSON:
+		jsonContent = content
+	case DataFormatYAML:
+		var val any
+		if err := yaml.Unmarshal(content, &val); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		var err error
+		jsonContent, err = json.Marshal(stringKeys(val))
+		if err != nil {
+			return nil, err
+		}
+	case DataFormatTOML:
+		tree, err := toml.LoadBytes(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TOML: %w", err)
+		}
+		jsonContent, err = json.Marshal(tree.ToMap())
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonContent))
+	dec.UseNumber()
+	var val any
+	if err := dec.Decode(&val); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return val, nil
+}
+
+// stringKeys converts the YAML maps with non-string keys in val to maps with
+// string keys, so that they can be encoded to JSON.
+func stringKeys(val any) any {
+	switch x := val.(type) {
+	case map[string]any:
+		for k, v := range x {
+			x[k] = stringKeys(v)
+		}
+		return x
+	case map[any]any:
+		m := make(map[string]any, len(x))
+		for k, v := range x {
+			m[fmt.Sprint(k)] = stringKeys(v)
+		}
+		return m
+	case []any:
+		for i, v := range x {
+			x[i] = stringKeys(v)
+		}
+		return x
+	default:
+		return val
+	}
+}
+
+// QueryData returns the value at a path in data parsed by ParseData.
+//
+// The path is a subset of JSONPath: an optional "$" for the root, followed by
+// ".key" or "[\"key\"]" to select a key of an object and "[n]" to select an
+// element of an array, counting from the end if negative. The leading "." may
+// be omitted, e.g. "dependencies.react" or "$.workspaces[0]".
+func QueryData(data any, expr string) (any, error) {
+	steps, err := parseDataPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	val := data
+	for i, step := range steps {
+		var ok bool
+		switch step := step.(type) {
+		case string:
+			var obj map[string]any
+			if obj, ok = val.(map[string]any); ok {
+				val, ok = obj[step]
+			}
+		case int:
+			var arr []any
+			if arr, ok = val.([]any); ok {
+				if step < 0 {
+					step += len(arr)
+				}
+				if ok = step >= 0 && step < len(arr); ok {
+					val = arr[step]
+				}
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("no value at %s", formatDataPath(steps[:i+1]))
+		}
+	}
+	return val, nil
+}
+
+func parseDataPath(expr string) ([]any, error) {
+	invalid := func(msg string) error {
+		return fmt.Errorf("invalid path %q: %s", expr, msg)
+	}
+
+	var steps []any
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, invalid("empty key")
+			}
+			if strings.ContainsRune(key, ']') {
+				return nil, invalid("unexpected ]")
+			}
+			steps = append(steps, key)
+			rest = rest[end+1:]
+		case '[':
+			if len(rest) > 1 && (rest[1] == '"' || rest[1] == '\'') {
+				key, n, ok := parseQuotedKey(rest[1:])
+				if !ok || 1+n >= len(rest) || rest[1+n] != ']' {
+					return nil, invalid("unterminated quoted key")
+				}
+				steps = append(steps, key)
+				rest = rest[1+n+1:]
+				continue
+			}
+			end := strings.IndexByte(rest, ']')
+			if end == -1 {
+				return nil, invalid("missing ]")
+			}
+			inner := rest[1:end]
+			idx, err := strconv.Atoi(strings.TrimSpace(inner))
+			if err != nil {
+				return nil, invalid(fmt.Sprintf("%q is not an index", inner))
+			}
+			steps = append(steps, idx)
+			rest = rest[end+1:]
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest[0]))
+		}
+	}
+	return steps, nil
+}
+
+// parseQuotedKey parses a key quoted with its first character, in which
+// backslashes escape the next character, returning the number of bytes read.
+func parseQuotedKey(s string) (string, int, bool) {
+	quote := s[0]
+	var sb strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				sb.WriteByte(s[i])
+			}
+		case quote:
+			return sb.String(), i + 1, true
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return "", 0, false
+}
+
+func formatDataPath(steps []any) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, step := range steps {
+		switch step := step.(type) {
+		case string:
+			if strings.ContainsAny(step, ".[]'\" ") {
+				fmt.Fprintf(&sb, "[%q]", step)
+			} else {
+				sb.WriteString("." + step)
+			}
+		case int:
+			fmt.Fprintf(&sb, "[%d]", step)
+		}
+	}
+	return sb.String()
+}
+
+// Data parses the file as structured data in the given format, or the one
+// inferred from its extension if empty.
+func (file *File) Data(ctx context.Context, format DataFormat) (any, error) {
+	if format == "" {
+		var err error
+		format, err = DataFormatOf(file.File)
+		if err != nil {
+			return nil, err
+		}
+	}
+	content, err := file.Contents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ParseData(content, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path.Base(file.File), err)
+	}
+	return data, nil
+}
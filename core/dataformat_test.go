@@ -0,0 +1,66 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryData(t *testing.T) {
+	for _, tc := range []struct {
+		format  DataFormat
+		content string
+	}{
+		{DataFormatJSON, `{"name": "app", "version": "1.2.3", "deps": {"@types/node": "20", "a.b": 1}, "files": ["a", "b"], "big": 12345678901234567890}`},
+		{DataFormatYAML, "name: app\nversion: 1.2.3\ndeps:\n  '@types/node': '20'\n  a.b: 1\nfiles: [a, b]\nbig: 12345678901234567890\n"},
+		{DataFormatTOML, "name = \"app\"\nversion = \"1.2.3\"\nfiles = [\"a\", \"b\"]\nbig = 1234567890123456789\n[deps]\n\"@types/node\" = \"20\"\n\"a.b\" = 1\n"},
+	} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			data, err := ParseData([]byte(tc.content), tc.format)
+			require.NoError(t, err)
+
+			query := func(expr string) string {
+				t.Helper()
+				val, err := QueryData(data, expr)
+				require.NoError(t, err)
+				out, err := json.Marshal(val)
+				require.NoError(t, err)
+				return string(out)
+			}
+			require.Equal(t, `"1.2.3"`, query("version"))
+			require.Equal(t, `"1.2.3"`, query("$.version"))
+			require.Equal(t, `"20"`, query(`deps["@types/node"]`))
+			require.Equal(t, `1`, query(`$.deps['a.b']`))
+			require.Equal(t, `"b"`, query("files[1]"))
+			require.Equal(t, `"b"`, query("$.files[-1]"))
+			require.Equal(t, `["a","b"]`, query("files"))
+			if tc.format != DataFormatTOML {
+				// TOML integers are limited to 64 bits
+				require.Equal(t, `12345678901234567890`, query("big"))
+			}
+
+			_, err = QueryData(data, "deps.missing.x")
+			require.EqualError(t, err, "no value at $.deps.missing")
+			_, err = QueryData(data, "files[2]")
+			require.EqualError(t, err, "no value at $.files[2]")
+			_, err = QueryData(data, "name[0]")
+			require.EqualError(t, err, "no value at $.name[0]")
+		})
+	}
+}
+
+func TestParseDataPath(t *testing.T) {
+	steps, err := parseDataPath(`$`)
+	require.NoError(t, err)
+	require.Empty(t, steps)
+
+	steps, err = parseDataPath(`a.b[0]["c\"]"].d`)
+	require.NoError(t, err)
+	require.Equal(t, []any{"a", "b", 0, `c"]`, "d"}, steps)
+
+	for _, expr := range []string{`a..b`, `a[`, `a["b]`, `a[x]`, `a]`} {
+		_, err := parseDataPath(expr)
+		require.ErrorContains(t, err, "invalid path", expr)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	cfs "github.com/containerd/continuity/fs"
 	"github.com/moby/buildkit/client/llb"
 	bkgw "github.com/moby/buildkit/frontend/gateway/client"
 	"github.com/moby/buildkit/solver/pb"
@@ -20,6 +21,7 @@ import (
 
 	"dagger.io/dagger/telemetry"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine/buildkit"
 )
 
@@ -335,6 +337,40 @@ func (dir *Directory) Glob(ctx context.Context, pattern string) ([]string, error
 	return paths, nil
 }
 
+// Search returns the lines of the files in the directory matching the given
+// options.
+func (dir *Directory) Search(ctx context.Context, opts buildkit.SearchOpts) ([]*SearchMatch, error) {
+	svcs, err := dir.Query.Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	detach, _, err := svcs.StartBindings(ctx, dir.Services)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	bkMatches, err := bk.SearchDirectory(ctx, dir.LLB, dir.Dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*SearchMatch, 0, len(bkMatches))
+	for _, bkMatch := range bkMatches {
+		matches = append(matches, &SearchMatch{
+			Path:       bkMatch.Path,
+			LineNumber: bkMatch.LineNumber,
+			Line:       bkMatch.Line,
+		})
+	}
+	return matches, nil
+}
+
 func (dir *Directory) WithNewFile(ctx context.Context, dest string, content []byte, permissions fs.FileMode, ownership *Ownership) (*Directory, error) {
 	dir = dir.Clone()
 
@@ -627,6 +663,148 @@ func (dir *Directory) WithTimestamps(ctx context.Context, unix int) (*Directory,
 	return dir, nil
 }
 
+// WithOwner returns the directory with the ownership of all of its contents
+// set to owner, a user and optional group which may be names from the
+// directory's own etc/passwd and etc/group files.
+func (dir *Directory) WithOwner(ctx context.Context, owner string) (*Directory, error) {
+	dir = dir.Clone()
+
+	st, err := dir.State()
+	if err != nil {
+		return nil, err
+	}
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	rooted := llb.Scratch().File(llb.Copy(st, dir.Dir, ".", &llb.CopyInfo{
+		CopyDirContentsOnly: true,
+	}))
+	ownership, err := resolveUIDGID(ctx, rooted, bk, dir.Platform, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	st = llb.Scratch().File(
+		llb.Copy(st, dir.Dir, ".", &llb.CopyInfo{
+			CopyDirContentsOnly: true,
+		}, ownership.Opt()),
+	)
+
+	err = dir.SetState(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	dir.Dir = ""
+
+	return dir, nil
+}
+
+// WithSymlink returns the directory with a symlink at linkName pointing to
+// target, which may be relative to the symlink and doesn't need to exist.
+func (dir *Directory) WithSymlink(ctx context.Context, target, linkName string) (*Directory, error) {
+	dir = dir.Clone()
+
+	linkName = path.Clean(linkName)
+	if linkName == "." || linkName == "/" || strings.HasPrefix(linkName, "../") {
+		return nil, fmt.Errorf("invalid symlink path: %s", linkName)
+	}
+	if target == "" {
+		return nil, fmt.Errorf("symlink target must not be empty")
+	}
+
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+	linkDef, _, err := bk.Symlink(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	linkSt, err := defToState(linkDef)
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := dir.State()
+	if err != nil {
+		return nil, err
+	}
+	st = st.File(llb.Copy(linkSt, "link", path.Join(dir.Dir, linkName), &llb.CopyInfo{
+		CreateDestPath: true,
+	}))
+
+	err = dir.SetState(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	return dir, nil
+}
+
+// WithPatch returns the directory with a patch in the unified diff format
+// produced by git diff applied to it.
+func (dir *Directory) WithPatch(ctx context.Context, patch string) (*Directory, error) {
+	dir = dir.Clone()
+
+	svcs, err := dir.Query.Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	detach, _, err := svcs.StartBindings(ctx, dir.Services)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	patchedDef, _, deleted, err := bk.ApplyPatch(ctx, dir.LLB, dir.Dir, []byte(patch))
+	if err != nil {
+		return nil, err
+	}
+
+	st, err := dir.State()
+	if err != nil {
+		return nil, err
+	}
+	if len(deleted) > 0 {
+		var rm *llb.FileAction
+		for _, p := range deleted {
+			// deleted directories include their contents, which may already be gone
+			rmOpt := llb.WithAllowNotFound(true)
+			if rm == nil {
+				rm = llb.Rm(path.Join(dir.Dir, p), rmOpt)
+			} else {
+				rm = rm.Rm(path.Join(dir.Dir, p), rmOpt)
+			}
+		}
+		st = st.File(rm)
+	}
+	if patchedDef != nil {
+		patchedSt, err := defToState(patchedDef)
+		if err != nil {
+			return nil, err
+		}
+		st = st.File(llb.Copy(patchedSt, "/", path.Join(dir.Dir, "."), &llb.CopyInfo{
+			CopyDirContentsOnly: true,
+			CreateDestPath:      true,
+		}))
+	}
+
+	err = dir.SetState(ctx, st)
+	if err != nil {
+		return nil, err
+	}
+
+	return dir, nil
+}
+
 func (dir *Directory) WithNewDirectory(ctx context.Context, dest string, permissions fs.FileMode) (*Directory, error) {
 	dir = dir.Clone()
 
@@ -691,6 +869,64 @@ func (dir *Directory) Diff(ctx context.Context, other *Directory) (*Directory, e
 	return dir, nil
 }
 
+// Changes returns the paths added, modified or deleted in other compared to
+// this directory.
+func (dir *Directory) Changes(ctx context.Context, other *Directory) ([]*DirectoryChange, error) {
+	svcs, err := dir.Query.Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	services := ServiceBindings{}
+	services.Merge(dir.Services)
+	services.Merge(other.Services)
+	detach, _, err := svcs.StartBindings(ctx, services)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	bkChanges, err := bk.DirectoryChanges(ctx, dir.LLB, dir.Dir, other.LLB, other.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*DirectoryChange, 0, len(bkChanges))
+	for _, bkChange := range bkChanges {
+		change := &DirectoryChange{
+			Path: bkChange.Path,
+		}
+		switch bkChange.Kind {
+		case cfs.ChangeKindAdd:
+			change.Kind = DirectoryChangeKindAdded
+		case cfs.ChangeKindModify:
+			change.Kind = DirectoryChangeKindModified
+		case cfs.ChangeKindDelete:
+			change.Kind = DirectoryChangeKindDeleted
+		default:
+			return nil, fmt.Errorf("unexpected change kind %q for %s", bkChange.Kind, bkChange.Path)
+		}
+		if info := bkChange.Upper; info != nil {
+			change.IsDirectory = info.IsDir()
+			change.Mode = int(info.Mode().Perm())
+			change.Size = int(info.Size())
+		}
+		if info := bkChange.Lower; info != nil {
+			if bkChange.Upper == nil {
+				change.IsDirectory = info.IsDir()
+			}
+			change.PreviousMode = int(info.Mode().Perm())
+			change.PreviousSize = int(info.Size())
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
 func (dir *Directory) Without(ctx context.Context, path string) (*Directory, error) {
 	dir = dir.Clone()
 
@@ -793,6 +1029,76 @@ func (dir *Directory) AsBlob(
 	return inst, nil
 }
 
+type SearchMatch struct {
+	Path       string `field:"true" doc:"The path of the matching file, relative to the searched directory."`
+	LineNumber int    `field:"true" doc:"The number of the matching line, starting at 1."`
+	Line       string `field:"true" doc:"The text of the matching line, without its line ending."`
+}
+
+func (*SearchMatch) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "SearchMatch",
+		NonNull:   true,
+	}
+}
+
+func (*SearchMatch) TypeDescription() string {
+	return "A line matching a search in a directory."
+}
+
+type DirectoryChange struct {
+	Path         string              `field:"true" doc:"The path that changed, relative to the directory."`
+	Kind         DirectoryChangeKind `field:"true" doc:"How the path changed."`
+	IsDirectory  bool                `field:"true" doc:"Whether the path is a directory."`
+	Mode         int                 `field:"true" doc:"The permissions of the path, unless it was deleted."`
+	PreviousMode int                 `field:"true" doc:"The previous permissions of the path, unless it was added."`
+	Size         int                 `field:"true" doc:"The size of the path in bytes, unless it was deleted."`
+	PreviousSize int                 `field:"true" doc:"The previous size of the path in bytes, unless it was added."`
+}
+
+func (*DirectoryChange) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "DirectoryChange",
+		NonNull:   true,
+	}
+}
+
+func (*DirectoryChange) TypeDescription() string {
+	return "A path that was added, modified or deleted between two directories."
+}
+
+type DirectoryChangeKind string
+
+var DirectoryChangeKinds = dagql.NewEnum[DirectoryChangeKind]()
+
+var (
+	DirectoryChangeKindAdded = DirectoryChangeKinds.Register("ADDED",
+		"The path only exists in the other directory")
+	DirectoryChangeKindModified = DirectoryChangeKinds.Register("MODIFIED",
+		"The path exists in both directories with a different content, type or permissions")
+	DirectoryChangeKindDeleted = DirectoryChangeKinds.Register("DELETED",
+		"The path only exists in this directory")
+)
+
+func (kind DirectoryChangeKind) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "DirectoryChangeKind",
+		NonNull:   true,
+	}
+}
+
+func (kind DirectoryChangeKind) TypeDescription() string {
+	return "The kind of a change between two directories."
+}
+
+func (kind DirectoryChangeKind) Decoder() dagql.InputDecoder {
+	return DirectoryChangeKinds
+}
+
+func (kind DirectoryChangeKind) ToLiteral() call.Literal {
+	return DirectoryChangeKinds.Literal(kind)
+}
+
 func validateFileName(file string) error {
 	baseFileName := filepath.Base(file)
 	if len(baseFileName) > 255 {
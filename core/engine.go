@@ -1,8 +1,17 @@
 package core
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine/buildkit"
 	"github.com/vektah/gqlparser/v2/ast"
 )
@@ -61,12 +70,158 @@ func (*EngineCacheEntrySet) Evaluate(context.Context) (*buildkit.Result, error)
 	return nil, nil
 }
 
+// EngineCacheEntriesOpts narrows down and orders the entries in a cache entry
+// set. The zero value selects every entry, in no particular order.
+type EngineCacheEntriesOpts struct {
+	// Only select entries whose description matches this glob, where "*"
+	// matches any sequence of characters and "?" any single character.
+	Description string
+
+	// Only select entries of this type.
+	Type EngineCacheEntryType
+
+	// Only select entries that are (or aren't) actively used, if set.
+	InUse *bool
+
+	// Only select entries using at least this many bytes.
+	MinSize int
+
+	// Only select entries that were last used before this time.
+	LastUsedBefore time.Time
+
+	// The order to return entries in.
+	SortBy EngineCacheEntrySortBy
+
+	// Return at most this many entries, if non-zero.
+	Limit int
+}
+
+// Filter returns the entries of the set selected by the given options, in a
+// new set.
+func (set *EngineCacheEntrySet) Filter(opts EngineCacheEntriesOpts) *EngineCacheEntrySet {
+	var descRe *regexp.Regexp
+	if opts.Description != "" {
+		descRe = globRegexp(opts.Description)
+	}
+	filtered := &EngineCacheEntrySet{}
+	for _, ent := range set.EntriesList {
+		if descRe != nil && !descRe.MatchString(ent.Description) {
+			continue
+		}
+		if opts.Type != "" && ent.RecordType != opts.Type {
+			continue
+		}
+		if opts.InUse != nil && ent.ActivelyUsed != *opts.InUse {
+			continue
+		}
+		if ent.DiskSpaceBytes < opts.MinSize {
+			continue
+		}
+		if !opts.LastUsedBefore.IsZero() && !ent.lastUsed().Before(opts.LastUsedBefore) {
+			continue
+		}
+		filtered.EntriesList = append(filtered.EntriesList, ent)
+	}
+
+	switch opts.SortBy {
+	case EngineCacheEntrySortBySize:
+		slices.SortStableFunc(filtered.EntriesList, func(a, b *EngineCacheEntry) int {
+			return cmp.Compare(b.DiskSpaceBytes, a.DiskSpaceBytes)
+		})
+	case EngineCacheEntrySortByLastUsed:
+		slices.SortStableFunc(filtered.EntriesList, func(a, b *EngineCacheEntry) int {
+			return a.lastUsed().Compare(b.lastUsed())
+		})
+	case EngineCacheEntrySortByCreated:
+		slices.SortStableFunc(filtered.EntriesList, func(a, b *EngineCacheEntry) int {
+			return cmp.Compare(a.CreatedTimeUnixNano, b.CreatedTimeUnixNano)
+		})
+	}
+
+	if opts.Limit > 0 && len(filtered.EntriesList) > opts.Limit {
+		filtered.EntriesList = filtered.EntriesList[:opts.Limit]
+	}
+	for _, ent := range filtered.EntriesList {
+		filtered.DiskSpaceBytes += ent.DiskSpaceBytes
+	}
+	filtered.EntryCount = len(filtered.EntriesList)
+	return filtered
+}
+
+// Page returns at most limit entries of the set (or all of them, if limit is
+// zero), skipping the first offset entries.
+func (set *EngineCacheEntrySet) Page(offset, limit int) []*EngineCacheEntry {
+	if offset >= len(set.EntriesList) {
+		return nil
+	}
+	ents := set.EntriesList[offset:]
+	if limit > 0 && len(ents) > limit {
+		ents = ents[:limit]
+	}
+	return ents
+}
+
+// globRegexp converts a glob to an anchored regular expression. Unlike
+// path.Match, "*" also matches "/", since descriptions often contain paths
+// and image references.
+func globRegexp(glob string) *regexp.Regexp {
+	var re strings.Builder
+	re.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			re.WriteString(".*")
+		case '?':
+			re.WriteString(".")
+		default:
+			re.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	re.WriteString("$")
+	return regexp.MustCompile(re.String())
+}
+
+type EngineCacheEntrySortBy string
+
+var EngineCacheEntrySortByEnum = dagql.NewEnum[EngineCacheEntrySortBy]()
+
+var (
+	EngineCacheEntrySortBySize = EngineCacheEntrySortByEnum.Register("SIZE",
+		"Largest entries first")
+	EngineCacheEntrySortByLastUsed = EngineCacheEntrySortByEnum.Register("LAST_USED",
+		"Least recently used entries first")
+	EngineCacheEntrySortByCreated = EngineCacheEntrySortByEnum.Register("CREATED",
+		"Oldest entries first")
+)
+
+func (sortBy EngineCacheEntrySortBy) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "DaggerEngineCacheEntrySortBy",
+		NonNull:   true,
+	}
+}
+
+func (sortBy EngineCacheEntrySortBy) TypeDescription() string {
+	return "The order to list cache entries in."
+}
+
+func (sortBy EngineCacheEntrySortBy) Decoder() dagql.InputDecoder {
+	return EngineCacheEntrySortByEnum
+}
+
+func (sortBy EngineCacheEntrySortBy) ToLiteral() call.Literal {
+	return EngineCacheEntrySortByEnum.Literal(sortBy)
+}
+
 type EngineCacheEntry struct {
-	Description               string `field:"true" doc:"The description of the cache entry."`
-	DiskSpaceBytes            int    `field:"true" doc:"The disk space used by the cache entry."`
-	CreatedTimeUnixNano       int    `field:"true" doc:"The time the cache entry was created, in Unix nanoseconds."`
-	MostRecentUseTimeUnixNano int    `field:"true" doc:"The most recent time the cache entry was used, in Unix nanoseconds."`
-	ActivelyUsed              bool   `field:"true" doc:"Whether the cache entry is actively being used."`
+	Description               string               `field:"true" doc:"The description of the cache entry."`
+	DiskSpaceBytes            int                  `field:"true" doc:"The disk space used by the cache entry."`
+	CreatedTimeUnixNano       int                  `field:"true" doc:"The time the cache entry was created, in Unix nanoseconds."`
+	MostRecentUseTimeUnixNano int                  `field:"true" doc:"The most recent time the cache entry was used, in Unix nanoseconds."`
+	ActivelyUsed              bool                 `field:"true" doc:"Whether the cache entry is actively being used."`
+	RecordType                EngineCacheEntryType `field:"true" doc:"The type of data held by the cache entry."`
+	CacheMountKey             string               `field:"true" doc:"The key of the cache volume owning the cache entry, if it is a cache mount. Only known for cache volumes mounted since the engine started."`
+	CacheMountNamespace       string               `field:"true" doc:"The namespace of the cache volume owning the cache entry, derived from its scope. Empty for global cache volumes. Only known for cache volumes mounted since the engine started."`
 }
 
 func (*EngineCacheEntry) Type() *ast.Type {
@@ -79,3 +234,97 @@ func (*EngineCacheEntry) Type() *ast.Type {
 func (*EngineCacheEntry) TypeDescription() string {
 	return "An individual cache entry in a cache entry set"
 }
+
+// lastUsed returns the time the entry was last used, or created if it has
+// never been used.
+func (ent *EngineCacheEntry) lastUsed() time.Time {
+	if ent.MostRecentUseTimeUnixNano != 0 {
+		return time.Unix(0, int64(ent.MostRecentUseTimeUnixNano))
+	}
+	return time.Unix(0, int64(ent.CreatedTimeUnixNano))
+}
+
+type EngineCacheEntryType string
+
+var EngineCacheEntryTypeEnum = dagql.NewEnum[EngineCacheEntryType]()
+
+var (
+	EngineCacheEntryCacheMount = EngineCacheEntryTypeEnum.Register("CACHE_MOUNT",
+		"The contents of a cache volume mounted into an exec")
+	EngineCacheEntryImageLayer = EngineCacheEntryTypeEnum.Register("IMAGE_LAYER",
+		"A layer of a pulled container image")
+	EngineCacheEntryExec = EngineCacheEntryTypeEnum.Register("EXEC",
+		"A filesystem snapshot produced by an exec")
+	EngineCacheEntryLocalImport = EngineCacheEntryTypeEnum.Register("LOCAL_IMPORT",
+		"Files loaded from a client's host")
+	EngineCacheEntryGitCheckout = EngineCacheEntryTypeEnum.Register("GIT_CHECKOUT",
+		"A checkout of a git repository")
+	EngineCacheEntryInternal = EngineCacheEntryTypeEnum.Register("INTERNAL",
+		"Internal state of the engine")
+	EngineCacheEntryOther = EngineCacheEntryTypeEnum.Register("OTHER",
+		"Any other data, such as files created through the API")
+)
+
+func (typ EngineCacheEntryType) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "DaggerEngineCacheEntryType",
+		NonNull:   true,
+	}
+}
+
+func (typ EngineCacheEntryType) TypeDescription() string {
+	return "The kind of data held by a cache entry."
+}
+
+func (typ EngineCacheEntryType) Decoder() dagql.InputDecoder {
+	return EngineCacheEntryTypeEnum
+}
+
+func (typ EngineCacheEntryType) ToLiteral() call.Literal {
+	return EngineCacheEntryTypeEnum.Literal(typ)
+}
+
+// ParseEngineCacheEntryType looks up a cache entry type by name, accepting
+// the lowercase dashed form used in config files (e.g. "cache-mount") as well
+// as the enum value (e.g. "CACHE_MOUNT").
+func ParseEngineCacheEntryType(name string) (EngineCacheEntryType, error) {
+	return EngineCacheEntryTypeEnum.Lookup(strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+}
+
+// ParseEngineCacheDuration parses a duration for cache policies. In addition
+// to the usual Go durations (e.g. "24h"), a number of days (e.g. "7d") is
+// accepted, since cache retention is usually thought about in days.
+func ParseEngineCacheDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("invalid duration %q: must not be negative", s)
+	}
+	return d, nil
+}
+
+// EngineCachePruneOpts narrows down which entries are pruned from a cache. The
+// zero value prunes everything that is releasable.
+type EngineCachePruneOpts struct {
+	// Only prune entries that have not been used for at least this long.
+	OlderThan time.Duration
+
+	// Only prune entries of this type.
+	Type EngineCacheEntryType
+
+	// Only prune entries using at least this many bytes.
+	MinSize int64
+
+	// Only prune while the whole cache uses more than this many bytes, oldest
+	// entries first.
+	KeepBytes int64
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineCacheEntrySetFilter(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) int {
+		return int(now.Add(-d).UnixNano())
+	}
+	set := &EngineCacheEntrySet{
+		EntriesList: []*EngineCacheEntry{
+			{
+				Description:               "pulled from docker.io/library/alpine:latest",
+				DiskSpaceBytes:            100,
+				CreatedTimeUnixNano:       ago(72 * time.Hour),
+				MostRecentUseTimeUnixNano: ago(time.Hour),
+				RecordType:                EngineCacheEntryImageLayer,
+			},
+			{
+				Description:         "cached mount /root/.cache/go-build from exec",
+				DiskSpaceBytes:      1000,
+				CreatedTimeUnixNano: ago(48 * time.Hour),
+				ActivelyUsed:        true,
+				RecordType:          EngineCacheEntryCacheMount,
+				CacheMountKey:       "go-build",
+			},
+			{
+				Description:               "mount / from exec go build ./...",
+				DiskSpaceBytes:            10,
+				CreatedTimeUnixNano:       ago(24 * time.Hour),
+				MostRecentUseTimeUnixNano: ago(12 * time.Hour),
+				RecordType:                EngineCacheEntryExec,
+			},
+		},
+	}
+	descriptions := func(set *EngineCacheEntrySet) []string {
+		var descs []string
+		for _, ent := range set.EntriesList {
+			descs = append(descs, ent.Description)
+		}
+		return descs
+	}
+	inUse := true
+
+	for _, tc := range []struct {
+		name  string
+		opts  EngineCacheEntriesOpts
+		want  []string
+		bytes int
+	}{
+		{
+			name:  "all",
+			want:  descriptions(set),
+			bytes: 1110,
+		},
+		{
+			name:  "description glob",
+			opts:  EngineCacheEntriesOpts{Description: "pulled from *alpine*"},
+			want:  []string{"pulled from docker.io/library/alpine:latest"},
+			bytes: 100,
+		},
+		{
+			name:  "type",
+			opts:  EngineCacheEntriesOpts{Type: EngineCacheEntryExec},
+			want:  []string{"mount / from exec go build ./..."},
+			bytes: 10,
+		},
+		{
+			name:  "in use",
+			opts:  EngineCacheEntriesOpts{InUse: &inUse},
+			want:  []string{"cached mount /root/.cache/go-build from exec"},
+			bytes: 1000,
+		},
+		{
+			name:  "min size",
+			opts:  EngineCacheEntriesOpts{MinSize: 100},
+			want:  []string{"pulled from docker.io/library/alpine:latest", "cached mount /root/.cache/go-build from exec"},
+			bytes: 1100,
+		},
+		{
+			name:  "last used before",
+			opts:  EngineCacheEntriesOpts{LastUsedBefore: now.Add(-6 * time.Hour)},
+			want:  []string{"cached mount /root/.cache/go-build from exec", "mount / from exec go build ./..."},
+			bytes: 1010,
+		},
+		{
+			name:  "sort by size with limit",
+			opts:  EngineCacheEntriesOpts{SortBy: EngineCacheEntrySortBySize, Limit: 2},
+			want:  []string{"cached mount /root/.cache/go-build from exec", "pulled from docker.io/library/alpine:latest"},
+			bytes: 1100,
+		},
+		{
+			name: "sort by last used",
+			opts: EngineCacheEntriesOpts{SortBy: EngineCacheEntrySortByLastUsed},
+			want: []string{
+				"cached mount /root/.cache/go-build from exec",
+				"mount / from exec go build ./...",
+				"pulled from docker.io/library/alpine:latest",
+			},
+			bytes: 1110,
+		},
+		{
+			name: "sort by created",
+			opts: EngineCacheEntriesOpts{SortBy: EngineCacheEntrySortByCreated},
+			want: []string{
+				"pulled from docker.io/library/alpine:latest",
+				"cached mount /root/.cache/go-build from exec",
+				"mount / from exec go build ./...",
+			},
+			bytes: 1110,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			filtered := set.Filter(tc.opts)
+			require.Equal(t, tc.want, descriptions(filtered))
+			require.Equal(t, len(tc.want), filtered.EntryCount)
+			require.Equal(t, tc.bytes, filtered.DiskSpaceBytes)
+		})
+	}
+}
+
+func TestEngineCacheEntrySetPage(t *testing.T) {
+	set := &EngineCacheEntrySet{
+		EntriesList: []*EngineCacheEntry{
+			{Description: "a"},
+			{Description: "b"},
+			{Description: "c"},
+		},
+	}
+	descriptions := func(ents []*EngineCacheEntry) []string {
+		var descs []string
+		for _, ent := range ents {
+			descs = append(descs, ent.Description)
+		}
+		return descs
+	}
+
+	for _, tc := range []struct {
+		name   string
+		offset int
+		limit  int
+		want   []string
+	}{
+		{name: "all", want: []string{"a", "b", "c"}},
+		{name: "limit", limit: 2, want: []string{"a", "b"}},
+		{name: "offset", offset: 1, want: []string{"b", "c"}},
+		{name: "offset and limit", offset: 1, limit: 1, want: []string{"b"}},
+		{name: "limit past end", offset: 2, limit: 5, want: []string{"c"}},
+		{name: "offset past end", offset: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, descriptions(set.Page(tc.offset, tc.limit)))
+		})
+	}
+}
+
+func TestParseEngineCacheDuration(t *testing.T) {
+	d, err := ParseEngineCacheDuration("7d")
+	require.NoError(t, err)
+	require.Equal(t, 7*24*time.Hour, d)
+
+	d, err = ParseEngineCacheDuration("1.5h")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Minute, d)
+
+	_, err = ParseEngineCacheDuration("-1d")
+	require.Error(t, err)
+	_, err = ParseEngineCacheDuration("soon")
+	require.Error(t, err)
+}
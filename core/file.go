@@ -134,6 +134,23 @@ func (file *File) Evaluate(ctx context.Context) (*buildkit.Result, error) {
 	})
 }
 
+// HTTPDigest returns the digest of the content downloaded by the http source
+// the file comes from, as pinned by the source when it was downloaded.
+func (file *File) HTTPDigest(ctx context.Context) (digest.Digest, error) {
+	res, err := file.Evaluate(ctx)
+	if err != nil {
+		return "", err
+	}
+	if res.Ref == nil {
+		return "", fmt.Errorf("no result was resolved")
+	}
+	p := res.Ref.Provenance()
+	if p == nil || len(p.Sources.HTTP) != 1 {
+		return "", fmt.Errorf("file doesn't come from a single http source")
+	}
+	return p.Sources.HTTP[0].Digest, nil
+}
+
 // Contents handles file content retrieval
 func (file *File) Contents(ctx context.Context) ([]byte, error) {
 	svcs, err := file.Query.Services(ctx)
@@ -297,6 +314,58 @@ func (file *File) WithTimestamps(ctx context.Context, unix int) (*File, error) {
 	return file, nil
 }
 
+// WithReplaced returns the file with the first or all occurrences of search
+// replaced, failing if there are none.
+func (file *File) WithReplaced(ctx context.Context, search, replace string, all bool) (*File, error) {
+	file = file.Clone()
+
+	svcs, err := file.Query.Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := file.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	detach, _, err := svcs.StartBindings(ctx, file.Services)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	def, _, err := bk.ReplaceInFile(ctx, file.LLB, file.File, search, replace, all)
+	if err != nil {
+		return nil, err
+	}
+	file.LLB = def
+	file.File = path.Base(file.File)
+
+	return file, nil
+}
+
+func (file *File) WithPermissions(ctx context.Context, permissions fs.FileMode) (*File, error) {
+	file = file.Clone()
+
+	st, err := file.State()
+	if err != nil {
+		return nil, err
+	}
+
+	chmodded := llb.Scratch().File(llb.Copy(st, file.File, path.Base(file.File), &llb.CopyInfo{
+		Mode: &permissions,
+	}))
+
+	def, err := chmodded.Marshal(ctx, llb.Platform(file.Platform.Spec()))
+	if err != nil {
+		return nil, err
+	}
+	file.LLB = def.ToPB()
+	file.File = path.Base(file.File)
+
+	return file, nil
+}
+
 func (file *File) Open(ctx context.Context) (io.ReadCloser, error) {
 	bk, err := file.Query.Buildkit(ctx)
 	if err != nil {
@@ -3,11 +3,16 @@ package core
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/moby/buildkit/client/llb"
+	"github.com/moby/buildkit/util/urlutil"
 	"github.com/pkg/errors"
 	"github.com/vektah/gqlparser/v2/ast"
 
+	"dagger.io/dagger/telemetry"
+	"github.com/dagger/dagger/dagql"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/sources/gitdns"
 )
@@ -19,6 +24,10 @@ type GitRepository struct {
 
 	KeepGitDir bool `json:"keepGitDir"`
 
+	// Depth is the depth of history to fetch, or 0 to fetch all of it.
+	Depth      int  `json:"depth"`
+	Submodules bool `json:"submodules"`
+
 	SSHKnownHosts string  `json:"sshKnownHosts"`
 	SSHAuthSocket *Socket `json:"sshAuthSocket"`
 
@@ -27,6 +36,10 @@ type GitRepository struct {
 
 	AuthToken  *Secret `json:"authToken"`
 	AuthHeader *Secret `json:"authHeader"`
+
+	// Local is a checkout of a repository created in the engine, including
+	// its .git directory, which is used instead of fetching from URL.
+	Local *Directory `json:"local,omitempty"`
 }
 
 func (*GitRepository) Type() *ast.Type {
@@ -58,8 +71,19 @@ func (*GitRef) TypeDescription() string {
 	return "A git ref (tag, branch, or commit)."
 }
 
-func (ref *GitRef) Tree(ctx context.Context) (*Directory, error) {
-	st, err := ref.getState(ctx)
+// Tree returns the filesystem tree at the ref. If sparsePaths is set, only
+// those paths are checked out.
+func (ref *GitRef) Tree(ctx context.Context, sparsePaths []string) (*Directory, error) {
+	if ref.Repo.Local != nil {
+		if len(sparsePaths) > 0 {
+			return nil, errors.Errorf("sparse checkout is not supported for local commits")
+		}
+		if ref.Repo.KeepGitDir {
+			return ref.Repo.Local, nil
+		}
+		return ref.Repo.Local.Without(ctx, ".git")
+	}
+	st, err := ref.getState(ctx, sparsePaths)
 	if err != nil {
 		return nil, err
 	}
@@ -67,11 +91,14 @@ func (ref *GitRef) Tree(ctx context.Context) (*Directory, error) {
 }
 
 func (ref *GitRef) Commit(ctx context.Context) (string, error) {
+	if ref.Repo.Local != nil {
+		return ref.Ref, nil
+	}
 	bk, err := ref.Query.Buildkit(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get buildkit client: %w", err)
 	}
-	st, err := ref.getState(ctx)
+	st, err := ref.getState(ctx, nil)
 	if err != nil {
 		return "", err
 	}
@@ -85,7 +112,240 @@ func (ref *GitRef) Commit(ctx context.Context) (string, error) {
 	return p.Sources.Git[0].Commit, nil
 }
 
-func (ref *GitRef) getState(ctx context.Context) (llb.State, error) {
+// CommitInfo reads the metadata of the commit at the ref.
+func (ref *GitRef) CommitInfo(ctx context.Context) (*GitCommit, error) {
+	svcs, err := ref.Query.Services(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := ref.Query.Buildkit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	dir, err := ref.checkoutWithGitDir(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	detach, _, err := svcs.StartBindings(ctx, dir.Services)
+	if err != nil {
+		return nil, err
+	}
+	defer detach()
+
+	commit, err := bk.ReadGitCommit(ctx, dir.LLB, dir.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &GitCommit{
+		SHA: commit.Hash.String(),
+		Author: &GitActor{
+			Name:  commit.Author.Name,
+			Email: commit.Author.Email,
+		},
+		Committer: &GitActor{
+			Name:  commit.Committer.Name,
+			Email: commit.Committer.Email,
+		},
+		Message:   commit.Message,
+		Timestamp: int(commit.Committer.When.Unix()),
+		Parents:   []string{},
+	}
+	for _, parent := range commit.ParentHashes {
+		info.Parents = append(info.Parents, parent.String())
+	}
+	return info, nil
+}
+
+type GitPushOpts struct {
+	Remote string
+	Ref    string
+	Force  bool
+
+	SSHKnownHosts string
+	SSHAuthSocket *Socket
+	AuthToken     *Secret
+	AuthHeader    *Secret
+}
+
+// Push pushes the commit at the ref to a remote. If no credentials are given
+// and the remote is the repository's own, its credentials are used.
+func (ref *GitRef) Push(ctx context.Context, opts GitPushOpts) (rerr error) {
+	svcs, err := ref.Query.Services(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := ref.Query.Buildkit(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	noAuth := opts.SSHAuthSocket == nil && opts.AuthToken == nil && opts.AuthHeader == nil
+	if noAuth && opts.Remote == ref.Repo.URL {
+		opts.SSHAuthSocket = ref.Repo.SSHAuthSocket
+		opts.AuthToken = ref.Repo.AuthToken
+		opts.AuthHeader = ref.Repo.AuthHeader
+		if opts.SSHKnownHosts == "" {
+			opts.SSHKnownHosts = ref.Repo.SSHKnownHosts
+		}
+	}
+
+	pushOpts := gitdns.PushOpts{
+		Remote:        opts.Remote,
+		Ref:           opts.Ref,
+		Force:         opts.Force,
+		KnownSSHHosts: opts.SSHKnownHosts,
+	}
+	if !strings.HasPrefix(pushOpts.Ref, "refs/") {
+		pushOpts.Ref = "refs/heads/" + pushOpts.Ref
+	}
+	if opts.SSHAuthSocket != nil {
+		pushOpts.MountSSHSock = opts.SSHAuthSocket.LLBID()
+	}
+	if opts.AuthToken != nil {
+		pushOpts.AuthTokenSecret = opts.AuthToken.LLBID()
+	}
+	if opts.AuthHeader != nil {
+		pushOpts.AuthHeaderSecret = opts.AuthHeader.LLBID()
+	}
+
+	// remotes may not have any of the commit's history, and don't accept
+	// shallow pushes by default
+	dir, err := ref.checkoutWithGitDir(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := Tracer().Start(ctx, fmt.Sprintf("git push %s %s", urlutil.RedactCredentials(opts.Remote), pushOpts.Ref))
+	defer telemetry.End(span, func() error { return rerr })
+
+	detach, _, err := svcs.StartBindings(ctx, dir.Services)
+	if err != nil {
+		return err
+	}
+	defer detach()
+
+	return bk.GitPush(ctx, dir.LLB, dir.Dir, pushOpts)
+}
+
+type GitCommitOpts struct {
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+	// Parent is the commit to build on, if any.
+	Parent *GitRef
+}
+
+// AsGitCommit commits the contents of the directory, excluding any .git
+// directory, as a new commit. It returns a checkout of the commit including
+// its .git directory, along with the commit id.
+func (dir *Directory) AsGitCommit(ctx context.Context, srv *dagql.Server, opts GitCommitOpts) (_ dagql.Instance[*Directory], _ string, rerr error) {
+	var inst dagql.Instance[*Directory]
+	svcs, err := dir.Query.Services(ctx)
+	if err != nil {
+		return inst, "", fmt.Errorf("failed to get services: %w", err)
+	}
+	bk, err := dir.Query.Buildkit(ctx)
+	if err != nil {
+		return inst, "", fmt.Errorf("failed to get buildkit client: %w", err)
+	}
+
+	ctx, span := Tracer().Start(ctx, "git commit")
+	defer telemetry.End(span, func() error { return rerr })
+
+	services := ServiceBindings(cloneSlice(dir.Services))
+
+	st := llb.Scratch()
+	if opts.Parent != nil {
+		// the full history of the parent, so the commit can be pushed anywhere
+		parentDir, err := opts.Parent.checkoutWithGitDir(ctx, 0)
+		if err != nil {
+			return inst, "", err
+		}
+		parentSt, err := parentDir.State()
+		if err != nil {
+			return inst, "", err
+		}
+		st = st.File(llb.Copy(parentSt, path.Join(parentDir.Dir, ".git"), ".git", &llb.CopyInfo{
+			CopyDirContentsOnly: true,
+			CreateDestPath:      true,
+		}))
+		services.Merge(parentDir.Services)
+	}
+	dirSt, err := dir.State()
+	if err != nil {
+		return inst, "", err
+	}
+	st = st.File(llb.Copy(dirSt, dir.Dir, ".", &llb.CopyInfo{
+		CopyDirContentsOnly: true,
+		ExcludePatterns:     []string{".git"},
+	}))
+	def, err := st.Marshal(ctx, llb.Platform(dir.Platform.Spec()))
+	if err != nil {
+		return inst, "", err
+	}
+
+	detach, _, err := svcs.StartBindings(ctx, services)
+	if err != nil {
+		return inst, "", err
+	}
+	defer detach()
+
+	_, commit, desc, err := bk.GitCommit(ctx, def.ToPB(), gitdns.CommitOpts{
+		Message:     opts.Message,
+		AuthorName:  opts.AuthorName,
+		AuthorEmail: opts.AuthorEmail,
+	})
+	if err != nil {
+		return inst, "", err
+	}
+	inst, err = LoadBlob(ctx, srv, desc)
+	if err != nil {
+		return inst, "", err
+	}
+	return inst, commit, nil
+}
+
+// NewLocalGitRef returns a ref to a commit in the given checkout, which must
+// include its .git directory.
+func NewLocalGitRef(checkout *Directory, commit string, keepGitDir bool) *GitRef {
+	return &GitRef{
+		Query: checkout.Query,
+		Ref:   commit,
+		Repo: &GitRepository{
+			Query:      checkout.Query,
+			KeepGitDir: keepGitDir,
+			Depth:      1,
+			Submodules: true,
+			Platform:   checkout.Platform,
+			Local:      checkout,
+		},
+	}
+}
+
+// checkoutWithGitDir returns a checkout of the ref including its .git
+// directory, with as little else as possible. Its history is fetched to the
+// given depth, or fully if it's 0.
+func (ref *GitRef) checkoutWithGitDir(ctx context.Context, depth int) (*Directory, error) {
+	if ref.Repo.Local != nil {
+		return ref.Repo.Local, nil
+	}
+	repo := *ref.Repo
+	repo.KeepGitDir = true
+	repo.Depth = depth
+	repo.Submodules = false
+	checkout := *ref
+	checkout.Repo = &repo
+	st, err := checkout.getState(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return NewDirectorySt(ctx, ref.Query, st, "/", repo.Platform, repo.Services)
+}
+
+func (ref *GitRef) getState(ctx context.Context, sparsePaths []string) (llb.State, error) {
 	opts := []llb.GitOption{}
 
 	if ref.Repo.KeepGitDir {
@@ -109,5 +369,46 @@ func (ref *GitRef) getState(ctx context.Context) (llb.State, error) {
 		return llb.State{}, err
 	}
 
-	return gitdns.Git(ref.Repo.URL, ref.Ref, clientMetadata.SessionID, opts...), nil
+	checkout := gitdns.CheckoutOpts{
+		Depth:          ref.Repo.Depth,
+		SkipSubmodules: !ref.Repo.Submodules,
+		SparsePaths:    sparsePaths,
+	}
+	return gitdns.Git(ref.Repo.URL, ref.Ref, clientMetadata.SessionID, checkout, opts...), nil
+}
+
+type GitCommit struct {
+	SHA       string    `field:"true" name:"sha" doc:"The SHA of the commit."`
+	Author    *GitActor `field:"true" doc:"The author of the commit."`
+	Committer *GitActor `field:"true" doc:"The committer of the commit."`
+	Message   string    `field:"true" doc:"The full commit message."`
+	Timestamp int       `field:"true" doc:"The time the commit was made, in Unix seconds."`
+	Parents   []string  `field:"true" doc:"The SHAs of the parent commits."`
+}
+
+func (*GitCommit) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "GitCommit",
+		NonNull:   true,
+	}
+}
+
+func (*GitCommit) TypeDescription() string {
+	return "The metadata of a git commit."
+}
+
+type GitActor struct {
+	Name  string `field:"true" doc:"The name of the author or committer."`
+	Email string `field:"true" doc:"The email of the author or committer."`
+}
+
+func (*GitActor) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "GitActor",
+		NonNull:   true,
+	}
+}
+
+func (*GitActor) TypeDescription() string {
+	return "The author or committer of a git commit."
 }
@@ -1,13 +1,18 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	bkgw "github.com/moby/buildkit/frontend/gateway/client"
 
 	"dagger.io/dagger/telemetry"
 	"github.com/dagger/dagger/engine/buildkit"
@@ -86,3 +91,183 @@ func (d *portHealthChecker) Check(ctx context.Context) (rerr error) {
 
 	return nil
 }
+
+// ServiceHealthcheck is a readiness probe that must pass, after all of a
+// service's ports are reachable, before the service is considered started.
+type ServiceHealthcheck struct {
+	// HTTP, if set, sends a GET request to the service.
+	HTTP *HTTPHealthcheck `json:"http,omitempty"`
+
+	// Exec, if set, runs a command inside the service container.
+	Exec *ExecHealthcheck `json:"exec,omitempty"`
+
+	// How long to wait between attempts.
+	Interval time.Duration `json:"interval"`
+
+	// How long a single attempt may take before it is considered failed.
+	Timeout time.Duration `json:"timeout"`
+
+	// How many times to retry a failed attempt before giving up.
+	Retries int `json:"retries"`
+}
+
+type HTTPHealthcheck struct {
+	Port           int    `json:"port"`
+	Path           string `json:"path"`
+	ExpectedStatus int    `json:"expectedStatus"`
+}
+
+type ExecHealthcheck struct {
+	Args []string `json:"args"`
+}
+
+func (check ServiceHealthcheck) String() string {
+	switch {
+	case check.HTTP != nil:
+		return fmt.Sprintf("GET :%d%s", check.HTTP.Port, check.HTTP.Path)
+	case check.Exec != nil:
+		return strings.Join(check.Exec.Args, " ")
+	default:
+		return "unknown healthcheck"
+	}
+}
+
+type probeHealthChecker struct {
+	bk     *buildkit.Client
+	ctr    *buildkit.Container
+	host   string
+	req    bkgw.StartRequest
+	checks []ServiceHealthcheck
+}
+
+// newProbeHealth returns a checker for the given readiness probes. Exec probes
+// are started in ctr using req as a template for the environment, working
+// directory, user, etc.
+func newProbeHealth(bk *buildkit.Client, ctr *buildkit.Container, host string, req bkgw.StartRequest, checks []ServiceHealthcheck) *probeHealthChecker {
+	return &probeHealthChecker{
+		bk:     bk,
+		ctr:    ctr,
+		host:   host,
+		req:    req,
+		checks: checks,
+	}
+}
+
+func (d *probeHealthChecker) Check(ctx context.Context) error {
+	for _, check := range d.checks {
+		if err := d.check(ctx, check); err != nil {
+			return fmt.Errorf("checking %s: %w", check, err)
+		}
+	}
+	return nil
+}
+
+func (d *probeHealthChecker) check(ctx context.Context, check ServiceHealthcheck) (rerr error) {
+	// always show health checks
+	ctx, span := Tracer().Start(ctx, check.String())
+	defer telemetry.End(span, func() error { return rerr })
+
+	slog := slog.SpanLogger(ctx, InstrumentationLibrary)
+
+	for attempt := 1; ; attempt++ {
+		err := d.probe(ctx, check)
+		if err == nil {
+			slog.Info("service is healthy", "attempt", attempt)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		slog.Warn("healthcheck failed", "error", err, "attempt", attempt)
+		if attempt > check.Retries {
+			return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(check.Interval):
+		}
+	}
+}
+
+func (d *probeHealthChecker) probe(ctx context.Context, check ServiceHealthcheck) error {
+	ctx, cancel := context.WithTimeoutCause(ctx, check.Timeout, fmt.Errorf("timed out after %s", check.Timeout))
+	defer cancel()
+
+	switch {
+	case check.HTTP != nil:
+		return d.probeHTTP(ctx, check.HTTP)
+	case check.Exec != nil:
+		return d.probeExec(ctx, check.Exec)
+	default:
+		return fmt.Errorf("unknown healthcheck")
+	}
+}
+
+func (d *probeHealthChecker) probeHTTP(ctx context.Context, check *HTTPHealthcheck) error {
+	dialer := net.Dialer{}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return buildkit.RunInNetNS(ctx, d.bk, d.ctr, func() (net.Conn, error) {
+					return dialer.DialContext(ctx, network, addr)
+				})
+			},
+			DisableKeepAlives: true,
+		},
+		// don't follow redirects; the expected status may itself be a redirect
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	u := "http://" + net.JoinHostPort(d.host, strconv.Itoa(check.Port)) + check.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != check.ExpectedStatus {
+		return fmt.Errorf("expected status %d, got %s", check.ExpectedStatus, resp.Status)
+	}
+	return nil
+}
+
+func (d *probeHealthChecker) probeExec(ctx context.Context, check *ExecHealthcheck) error {
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	if err := execInService(ctx, d.ctr, d.req, check.Args, stdout, stderr); err != nil {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		if out := strings.TrimSpace(stdout.String() + "\n" + stderr.String()); out != "" {
+			return fmt.Errorf("%w: %s", err, lastLines(out, 5))
+		}
+		return err
+	}
+	return nil
+}
+
+// lastLines returns up to the last n lines of s.
+func lastLines(s string, n int) string {
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/containerd/containerd/labels"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/vektah/gqlparser/v2/ast"
 
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine"
 )
 
 type Host struct {
@@ -120,7 +122,7 @@ func (host *Host) File(ctx context.Context, srv *dagql.Server, filePath string)
 	return i, nil
 }
 
-func (host *Host) SetSecretFile(ctx context.Context, srv *dagql.Server, secretName string, path string) (i dagql.Instance[*Secret], err error) {
+func (host *Host) SetSecretFile(ctx context.Context, srv *dagql.Server, secretName string, path string, ttl time.Duration) (i dagql.Instance[*Secret], err error) {
 	secretStore, err := host.Query.Secrets(ctx)
 	if err != nil {
 		return i, fmt.Errorf("failed to get secrets: %w", err)
@@ -158,7 +160,20 @@ func (host *Host) SetSecretFile(ctx context.Context, srv *dagql.Server, secretNa
 		return i, fmt.Errorf("failed to select secret: %w", err)
 	}
 
-	if err := secretStore.AddSecret(i.Self, secretName, secretFileContent); err != nil {
+	var refetch SecretRefetchFunc
+	if ttl > 0 {
+		clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+		if err != nil {
+			return i, fmt.Errorf("failed to get client metadata: %w", err)
+		}
+		refetch = func(ctx context.Context) ([]byte, error) {
+			// re-read the file from the host of the client that set the secret
+			ctx = engine.ContextWithClientMetadata(ctx, clientMetadata)
+			return bk.ReadCallerHostFile(ctx, path)
+		}
+	}
+
+	if err := secretStore.AddExpiringSecret(i.Self, secretName, secretFileContent, ttl, refetch); err != nil {
 		return i, fmt.Errorf("failed to add secret: %w", err)
 	}
 
@@ -0,0 +1,14 @@
+package core
+
+type HTTPHeader struct {
+	Name  string `field:"true" doc:"The header name."`
+	Value string `field:"true" doc:"The header value."`
+}
+
+func (HTTPHeader) TypeName() string {
+	return "HTTPHeader"
+}
+
+func (HTTPHeader) TypeDescription() string {
+	return "Key value object that represents an HTTP header."
+}
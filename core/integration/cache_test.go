@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"dagger.io/dagger"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/testctx"
 )
 
@@ -39,6 +41,207 @@ func (CacheSuite) TestVolume(ctx context.Context, t *testctx.T) {
 	require.NotEqual(t, volID1, volID3)
 }
 
+func (CacheSuite) TestVolumeContents(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	key := identity.NewID()
+	ls := func() string {
+		t.Helper()
+		out, err := c.Container().From(alpineImage).
+			WithMountedCache("/cache", c.CacheVolume(key)).
+			WithEnvVariable("BUST", identity.NewID()).
+			WithExec([]string{"ls", "/cache"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		return strings.TrimSpace(out)
+	}
+	query := func(selection string, vars map[string]any, res any) {
+		t.Helper()
+		params := "$key: String!"
+		if _, ok := vars["source"]; ok {
+			params += ", $source: DirectoryID!"
+		}
+		vars["key"] = key
+		err := c.Do(ctx, &dagger.Request{
+			Query:     fmt.Sprintf(`query(%s){cacheVolume(key: $key){%s}}`, params, selection),
+			Variables: vars,
+		}, &dagger.Response{Data: res})
+		require.NoError(t, err)
+	}
+	type volume struct {
+		CacheVolume struct {
+			Size     int
+			Snapshot struct {
+				Entries []string
+			}
+		}
+	}
+
+	// volumes that were never used are empty
+	var res volume
+	query("size", map[string]any{}, &res)
+	require.Zero(t, res.CacheVolume.Size)
+
+	_, err := c.Container().From(alpineImage).
+		WithMountedCache("/cache", c.CacheVolume(key)).
+		WithExec([]string{"sh", "-c", "echo hello > /cache/a && mkdir /cache/b && echo world > /cache/b/c"}).
+		Sync(ctx)
+	require.NoError(t, err)
+
+	t.Run("size", func(ctx context.Context, t *testctx.T) {
+		var res volume
+		query("size", map[string]any{}, &res)
+		require.Positive(t, res.CacheVolume.Size)
+	})
+
+	t.Run("snapshot", func(ctx context.Context, t *testctx.T) {
+		var res volume
+		query("snapshot{entries}", map[string]any{}, &res)
+		require.ElementsMatch(t, []string{"a", "b"}, res.CacheVolume.Snapshot.Entries)
+	})
+
+	t.Run("seed", func(ctx context.Context, t *testctx.T) {
+		srcID, err := c.Directory().
+			WithNewFile("sub/seeded", "hi").
+			Directory("sub").
+			ID(ctx)
+		require.NoError(t, err)
+		query("seed(source: $source)", map[string]any{"source": srcID}, &volume{})
+		require.Equal(t, "seeded", ls())
+	})
+
+	t.Run("clear", func(ctx context.Context, t *testctx.T) {
+		query("clear", map[string]any{}, &volume{})
+		require.Empty(t, ls())
+
+		var res volume
+		query("size", map[string]any{}, &res)
+		require.Zero(t, res.CacheVolume.Size)
+	})
+}
+
+func (CacheSuite) TestVolumeScope(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	t.Run("pinned in ID", func(ctx context.Context, t *testctx.T) {
+		var res struct {
+			CacheVolume struct {
+				ID        string
+				Scope     string
+				Namespace string
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `{cacheVolume(key: "scoped"){id scope namespace}}`,
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		require.Equal(t, "GLOBAL_SCOPE", res.CacheVolume.Scope)
+		require.Empty(t, res.CacheVolume.Namespace)
+
+		var id call.ID
+		require.NoError(t, id.Decode(res.CacheVolume.ID))
+		require.Equal(t,
+			`__internalCacheVolume(key: "scoped", scope: GLOBAL_SCOPE, namespace: ""): CacheVolume!`,
+			id.Display())
+	})
+
+	t.Run("module scope outside module", func(ctx context.Context, t *testctx.T) {
+		err := c.Do(ctx, &dagger.Request{
+			Query: `{cacheVolume(key: "scoped", scope: MODULE_SCOPE){id}}`,
+		}, &dagger.Response{})
+		require.ErrorContains(t, err, "only valid within a module")
+	})
+
+	t.Run("modules are isolated by default", func(ctx context.Context, t *testctx.T) {
+		key := identity.NewID()
+		modSrc := func(name string) string {
+			return fmt.Sprintf(`package main
+
+import (
+	"context"
+
+	"dagger/%[1]s/internal/dagger"
+)
+
+type %[2]s struct{}
+
+func (m *%[2]s) Append(ctx context.Context, value string, global bool) (string, error) {
+	opts := dagger.CacheVolumeOpts{}
+	if global {
+		opts.Scope = dagger.GlobalScope
+	}
+	return dag.Container().From("%[3]s").
+		WithMountedCache("/cache", dag.CacheVolume(%[4]q, opts)).
+		WithExec([]string{"sh", "-c", "echo " + value + " >> /cache/log && cat /cache/log"}).
+		Stdout(ctx)
+}
+`, name, strings.ToUpper(name), alpineImage, key)
+		}
+
+		ctr := goGitBase(t, c).
+			WithMountedFile(testCLIBinPath, daggerCliFile(t, c)).
+			With(withModInitAt("./a", "go", modSrc("a"))).
+			With(withModInitAt("./b", "go", modSrc("b")))
+
+		out, err := ctr.With(daggerCallAt("a", "append", "--value=a1")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "a1", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("b", "append", "--value=b1")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "b1", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("a", "append", "--value=a2")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "a1\na2", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("a", "append", "--value=a3", "--global")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "a3", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("b", "append", "--value=b2", "--global")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "a3\nb2", strings.TrimSpace(out))
+	})
+
+	t.Run("same-named modules in different repos are isolated", func(ctx context.Context, t *testctx.T) {
+		key := identity.NewID()
+		modSrc := fmt.Sprintf(`package main
+
+import "context"
+
+type C struct{}
+
+func (m *C) Append(ctx context.Context, value string) (string, error) {
+	return dag.Container().From("%s").
+		WithMountedCache("/cache", dag.CacheVolume(%q)).
+		WithExec([]string{"sh", "-c", "echo " + value + " >> /cache/log && cat /cache/log"}).
+		Stdout(ctx)
+}
+`, alpineImage, key)
+
+		// both repos have a local module named c at ./c
+		ctr := goGitBase(t, c).
+			WithMountedFile(testCLIBinPath, daggerCliFile(t, c)).
+			WithExec([]string{"git", "init", "/work/one"}).
+			WithExec([]string{"git", "init", "/work/two"}).
+			With(withModInitAt("./one/c", "go", modSrc)).
+			With(withModInitAt("./two/c", "go", modSrc))
+
+		out, err := ctr.With(daggerCallAt("one/c", "append", "--value=one1")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "one1", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("two/c", "append", "--value=two1")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "two1", strings.TrimSpace(out))
+
+		out, err = ctr.With(daggerCallAt("one/c", "append", "--value=one2")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "one1\none2", strings.TrimSpace(out))
+	})
+}
+
 func (CacheSuite) TestVolumeWithSubmount(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
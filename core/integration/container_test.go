@@ -3,10 +3,17 @@ package core
 import (
 	"bytes"
 	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -19,7 +26,11 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/moby/buildkit/identity"
+	"github.com/opencontainers/go-digest"
 	ocispecs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
@@ -538,6 +549,248 @@ func (ContainerSuite) TestExecRedirectStdoutStderr(ctx context.Context, t *testc
 	require.Equal(t, "goodbye\n", stderr)
 }
 
+func (ContainerSuite) TestExecExpect(ctx context.Context, t *testctx.T) {
+	type result struct {
+		Container struct {
+			From struct {
+				WithExec struct {
+					ExitCode int
+					Stdout   string
+					Stderr   string
+				}
+			}
+		}
+	}
+
+	t.Run("success", func(ctx context.Context, t *testctx.T) {
+		var res result
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["true"]) {
+							exitCode
+						}
+					}
+				}
+			}`, &res, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Container.From.WithExec.ExitCode)
+	})
+
+	t.Run("expected failure", func(ctx context.Context, t *testctx.T) {
+		var res result
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sh", "-c", "echo hello; echo goodbye >/dev/stderr; exit 5"], expect: FAILURE) {
+							exitCode
+							stdout
+							stderr
+						}
+					}
+				}
+			}`, &res, nil)
+		require.NoError(t, err)
+		require.Equal(t, 5, res.Container.From.WithExec.ExitCode)
+		require.Equal(t, "hello\n", res.Container.From.WithExec.Stdout)
+		require.Equal(t, "goodbye\n", res.Container.From.WithExec.Stderr)
+	})
+
+	t.Run("unexpected success", func(ctx context.Context, t *testctx.T) {
+		var res result
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["true"], expect: FAILURE) {
+							exitCode
+						}
+					}
+				}
+			}`, &res, nil)
+		require.ErrorContains(t, err, "exit code 0 was not expected")
+	})
+
+	t.Run("any", func(ctx context.Context, t *testctx.T) {
+		for _, code := range []int{0, 1, 127} {
+			var res result
+			err := testutil.Query(t,
+				fmt.Sprintf(`{
+					container {
+						from(address: "`+alpineImage+`") {
+							withExec(args: ["sh", "-c", "exit %d"], expect: ANY) {
+								exitCode
+							}
+						}
+					}
+				}`, code), &res, nil)
+			require.NoError(t, err)
+			require.Equal(t, code, res.Container.From.WithExec.ExitCode)
+		}
+	})
+
+	t.Run("signals are never expected", func(ctx context.Context, t *testctx.T) {
+		var res result
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sh", "-c", "kill -9 $$"], expect: ANY) {
+							exitCode
+						}
+					}
+				}
+			}`, &res, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("exit code without exec", func(ctx context.Context, t *testctx.T) {
+		var res struct{}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						exitCode
+					}
+				}
+			}`, &res, nil)
+		require.ErrorContains(t, err, "no command has been set")
+	})
+}
+
+func (ContainerSuite) TestExecResourceLimits(ctx context.Context, t *testctx.T) {
+	t.Run("timeout", func(ctx context.Context, t *testctx.T) {
+		var res struct{}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sleep", "60"], timeout: "2s") {
+							sync
+						}
+					}
+				}
+			}`, &res, nil)
+		require.ErrorContains(t, err, "timed out after 2s")
+	})
+
+	t.Run("timeout not reached", func(ctx context.Context, t *testctx.T) {
+		var res struct {
+			Container struct {
+				From struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["echo", "hello"], timeout: "1m") {
+							stdout
+						}
+					}
+				}
+			}`, &res, nil)
+		require.NoError(t, err)
+		require.Equal(t, "hello\n", res.Container.From.WithExec.Stdout)
+	})
+
+	t.Run("invalid timeout", func(ctx context.Context, t *testctx.T) {
+		var res struct{}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["true"], timeout: "forever") {
+							sync
+						}
+					}
+				}
+			}`, &res, nil)
+		require.ErrorContains(t, err, "invalid timeout")
+	})
+
+	t.Run("cgroup limits", func(ctx context.Context, t *testctx.T) {
+		var res struct {
+			Container struct {
+				From struct {
+					WithExec struct {
+						Stdout string
+					}
+				}
+			}
+		}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(
+							args: ["sh", "-c", "cat /sys/fs/cgroup/memory.max /sys/fs/cgroup/cpu.max /sys/fs/cgroup/pids.max"],
+							memoryLimit: "64m",
+							cpuQuota: "0.5",
+							pidsLimit: 32
+						) {
+							stdout
+						}
+					}
+				}
+			}`, &res, nil)
+		require.NoError(t, err)
+		require.Equal(t, "67108864\n50000 100000\n32\n", res.Container.From.WithExec.Stdout)
+	})
+
+	t.Run("limits in cache key", func(ctx context.Context, t *testctx.T) {
+		var res struct {
+			Container struct {
+				From struct {
+					Small struct {
+						Stdout string
+					}
+					Large struct {
+						Stdout string
+					}
+				}
+			}
+		}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						small: withExec(args: ["cat", "/sys/fs/cgroup/memory.max"], memoryLimit: "64m") {
+							stdout
+						}
+						large: withExec(args: ["cat", "/sys/fs/cgroup/memory.max"], memoryLimit: "128m") {
+							stdout
+						}
+					}
+				}
+			}`, &res, nil)
+		require.NoError(t, err)
+		require.Equal(t, "67108864\n", res.Container.From.Small.Stdout)
+		require.Equal(t, "134217728\n", res.Container.From.Large.Stdout)
+	})
+
+	t.Run("pids limit enforced", func(ctx context.Context, t *testctx.T) {
+		var res struct{}
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sh", "-c", "for i in $(seq 20); do sleep 5 & done; wait"], pidsLimit: 5) {
+							sync
+						}
+					}
+				}
+			}`, &res, nil)
+		require.Error(t, err)
+	})
+}
+
 func (ContainerSuite) TestExecWithWorkdir(ctx context.Context, t *testctx.T) {
 	res := struct {
 		Container struct {
@@ -2839,6 +3092,286 @@ func (ContainerSuite) TestPublish(ctx context.Context, t *testctx.T) {
 	require.Equal(t, "im-a-default-arg\n", output)
 }
 
+func (ContainerSuite) TestPublishSigned(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	newKey := func(t *testctx.T) (privatePEM, publicPEM string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+		require.NoError(t, err)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	}
+	privateKey, publicKey := newKey(t)
+	_, otherPublicKey := newKey(t)
+
+	keyID, err := c.SetSecret("signing-key", privateKey).ID(ctx)
+	require.NoError(t, err)
+
+	var res struct {
+		Container struct {
+			From struct {
+				Publish string
+			}
+		}
+	}
+	err = c.Do(ctx, &dagger.Request{
+		Query: `query Test($image: String!, $ref: String!, $key: SecretID!) {
+			container {
+				from(address: $image) {
+					publish(address: $ref, signingKey: $key)
+				}
+			}
+		}`,
+		Variables: map[string]any{
+			"image": alpineImage,
+			"ref":   registryRef("container-publish-signed"),
+			"key":   keyID,
+		},
+	}, &dagger.Response{Data: &res})
+	require.NoError(t, err)
+	pushedRef := res.Container.From.Publish
+	require.Contains(t, pushedRef, "@sha256:")
+
+	// the signature is where cosign looks for it
+	repo, dgst, ok := strings.Cut(pushedRef, "@")
+	require.True(t, ok)
+	repo = repo[:strings.LastIndex(repo, ":")]
+	sigRef, err := name.ParseReference(repo+":"+strings.Replace(dgst, ":", "-", 1)+".sig", name.Insecure)
+	require.NoError(t, err)
+	sigDesc, err := remote.Get(sigRef, remote.WithTransport(http.DefaultTransport))
+	require.NoError(t, err)
+	sigImg, err := sigDesc.Image()
+	require.NoError(t, err)
+	sigManifest, err := sigImg.Manifest()
+	require.NoError(t, err)
+	require.Len(t, sigManifest.Layers, 1)
+	require.EqualValues(t, "application/vnd.dev.cosign.simplesigning.v1+json", sigManifest.Layers[0].MediaType)
+	require.NotEmpty(t, sigManifest.Layers[0].Annotations["dev.cosignproject.cosign/signature"])
+
+	// sigstore verifies the signature like cosign verify --key does, which also
+	// requires --insecure-ignore-tlog since it has no transparency log entry
+	require.NotContains(t, sigManifest.Layers[0].Annotations, "dev.sigstore.cosign/bundle")
+	sigLayer, err := sigImg.LayerByDigest(sigManifest.Layers[0].Digest)
+	require.NoError(t, err)
+	rc, err := sigLayer.Compressed()
+	require.NoError(t, err)
+	sigPayload, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	rawSig, err := base64.StdEncoding.DecodeString(sigManifest.Layers[0].Annotations["dev.cosignproject.cosign/signature"])
+	require.NoError(t, err)
+	pub, err := cryptoutils.UnmarshalPEMToPublicKey([]byte(publicKey))
+	require.NoError(t, err)
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	require.NoError(t, err)
+	require.NoError(t, verifier.VerifySignature(bytes.NewReader(rawSig), bytes.NewReader(sigPayload)))
+	var simple payload.SimpleContainerImage
+	require.NoError(t, json.Unmarshal(sigPayload, &simple))
+	require.Equal(t, payload.CosignSignatureType, simple.Critical.Type)
+	require.Equal(t, dgst, simple.Critical.Image.DockerManifestDigest)
+
+	verify := func(ref, publicKey string) error {
+		return c.Do(ctx, &dagger.Request{
+			Query: `query Test($ref: String!, $key: String!) {
+				container {
+					from(address: $ref, verify: $key) {
+						id
+					}
+				}
+			}`,
+			Variables: map[string]any{"ref": ref, "key": publicKey},
+		}, &dagger.Response{})
+	}
+
+	t.Run("valid signature", func(ctx context.Context, t *testctx.T) {
+		require.NoError(t, verify(pushedRef, publicKey))
+	})
+
+	t.Run("other key", func(ctx context.Context, t *testctx.T) {
+		require.ErrorContains(t, verify(pushedRef, otherPublicKey), "matches the public key")
+	})
+
+	t.Run("unsigned image", func(ctx context.Context, t *testctx.T) {
+		unsignedRef, err := c.Container().
+			From(alpineImage).
+			WithEnvVariable("UNSIGNED", "1").
+			Publish(ctx, registryRef("container-publish-unsigned"))
+		require.NoError(t, err)
+		require.ErrorContains(t, verify(unsignedRef, publicKey), "no signatures found")
+	})
+}
+
+func (ContainerSuite) TestPublishOptions(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	ctrID, err := c.Container().
+		From(alpineImage).
+		WithLabel("org.opencontainers.image.source", "https://github.com/dagger/dagger").
+		WithLabel("org.opencontainers.image.title", "from label").
+		WithNewFile("/publish-options", "hello").
+		ID(ctx)
+	require.NoError(t, err)
+
+	publish := func(ctx context.Context, args string) (string, error) {
+		var res struct {
+			LoadContainerFromID struct {
+				Publish string
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query Test($ctr: ContainerID!, $ref: String!) {
+				loadContainerFromID(id: $ctr) {
+					publish(address: $ref, ` + args + `)
+				}
+			}`,
+			Variables: map[string]any{
+				"ctr": ctrID,
+				"ref": registryRef("container-publish-options:v1"),
+			},
+		}, &dagger.Response{Data: &res})
+		return res.LoadContainerFromID.Publish, err
+	}
+
+	t.Run("annotations, tags and compression level", func(ctx context.Context, t *testctx.T) {
+		pushedRef, err := publish(ctx, `
+			forcedCompression: Zstd,
+			compressionLevel: 19,
+			annotateFromLabels: true,
+			annotations: [
+				{name: "org.opencontainers.image.title", value: "from annotation"},
+				{name: "com.example.team", value: "core"},
+			],
+			tags: ["v1.2.3", "latest"],
+		`)
+		require.NoError(t, err)
+		_, dgst, ok := strings.Cut(pushedRef, "@")
+		require.True(t, ok)
+
+		for _, tag := range []string{"v1", "v1.2.3", "latest"} {
+			parsedRef, err := name.ParseReference(registryRef("container-publish-options:"+tag), name.Insecure)
+			require.NoError(t, err)
+			imgDesc, err := remote.Get(parsedRef, remote.WithTransport(http.DefaultTransport))
+			require.NoError(t, err)
+			require.Equal(t, dgst, imgDesc.Digest.String())
+
+			img, err := imgDesc.Image()
+			require.NoError(t, err)
+			manifest, err := img.Manifest()
+			require.NoError(t, err)
+			require.Equal(t, map[string]string{
+				"org.opencontainers.image.source": "https://github.com/dagger/dagger",
+				"org.opencontainers.image.title":  "from annotation",
+				"com.example.team":                "core",
+			}, manifest.Annotations)
+			for _, layer := range manifest.Layers {
+				require.EqualValues(t, "application/vnd.oci.image.layer.v1.tar+zstd", layer.MediaType)
+			}
+		}
+	})
+
+	t.Run("labels aren't annotations by default", func(ctx context.Context, t *testctx.T) {
+		pushedRef, err := publish(ctx, `annotations: [{name: "com.example.team", value: "core"}]`)
+		require.NoError(t, err)
+		parsedRef, err := name.ParseReference(pushedRef, name.Insecure)
+		require.NoError(t, err)
+		img, err := remote.Image(parsedRef, remote.WithTransport(http.DefaultTransport))
+		require.NoError(t, err)
+		manifest, err := img.Manifest()
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"com.example.team": "core",
+		}, manifest.Annotations)
+	})
+
+	t.Run("labels of each platform variant", func(ctx context.Context, t *testctx.T) {
+		variants := map[dagger.Platform]string{
+			"linux/amd64": "amd64 image",
+			"linux/arm64": "arm64 image",
+		}
+		var ctrs []*dagger.Container
+		for platform, title := range variants {
+			ctrs = append(ctrs, c.Container(dagger.ContainerOpts{Platform: platform}).
+				From(alpineImage).
+				WithLabel("org.opencontainers.image.title", title))
+		}
+		pushedRef, err := ctrs[0].Publish(ctx, registryRef("container-publish-options-variants"), dagger.ContainerPublishOpts{
+			PlatformVariants:   ctrs[1:],
+			AnnotateFromLabels: true,
+		})
+		require.NoError(t, err)
+
+		parsedRef, err := name.ParseReference(pushedRef, name.Insecure)
+		require.NoError(t, err)
+		idx, err := remote.Index(parsedRef, remote.WithTransport(http.DefaultTransport))
+		require.NoError(t, err)
+		idxManifest, err := idx.IndexManifest()
+		require.NoError(t, err)
+		titles := map[dagger.Platform]string{}
+		for _, desc := range idxManifest.Manifests {
+			if desc.Platform == nil || desc.Platform.OS == "unknown" {
+				continue
+			}
+			img, err := idx.Image(desc.Digest)
+			require.NoError(t, err)
+			manifest, err := img.Manifest()
+			require.NoError(t, err)
+			titles[dagger.Platform(desc.Platform.OS+"/"+desc.Platform.Architecture)] = manifest.Annotations["org.opencontainers.image.title"]
+		}
+		require.Equal(t, variants, titles)
+	})
+
+	t.Run("compression level without forced compression", func(ctx context.Context, t *testctx.T) {
+		_, err := publish(ctx, `compressionLevel: 3`)
+		require.ErrorContains(t, err, "requires a forced compression")
+	})
+
+	t.Run("annotations with docker media types", func(ctx context.Context, t *testctx.T) {
+		_, err := publish(ctx, `mediaTypes: DockerMediaTypes, annotations: [{name: "a", value: "b"}]`)
+		require.ErrorContains(t, err, "annotations require OCI media types")
+	})
+}
+
+func (ContainerSuite) TestFromRequireDigest(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	from := func(ctx context.Context, address string) (string, error) {
+		var res struct {
+			Container struct {
+				From struct {
+					ImageRef string
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query Test($address: String!) {
+				container {
+					from(address: $address, requireDigest: true) {
+						imageRef
+					}
+				}
+			}`,
+			Variables: map[string]any{"address": address},
+		}, &dagger.Response{Data: &res})
+		return res.Container.From.ImageRef, err
+	}
+
+	_, err := from(ctx, alpineImage)
+	require.ErrorContains(t, err, "is not pinned to a digest")
+
+	// imageRef pins the address to the digest it resolved to
+	pinned, err := c.Container().From(alpineImage).ImageRef(ctx)
+	require.NoError(t, err)
+	require.Regexp(t, `^docker\.io/library/alpine:[^@]+@sha256:[0-9a-f]{64}$`, pinned)
+
+	ref, err := from(ctx, pinned)
+	require.NoError(t, err)
+	require.Equal(t, pinned, ref)
+}
+
 func (ContainerSuite) TestExecFromScratch(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -2984,6 +3517,137 @@ func (ContainerSuite) TestAsTarball(ctx context.Context, t *testctx.T) {
 	require.Equal(t, "/foo.tar: POSIX tar archive\n", output)
 }
 
+func (ContainerSuite) TestAttestations(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	// attestations returns the in-toto statements attached to the single image
+	// in a tarball, by predicate type
+	attestations := func(t *testctx.T, query string, vars map[string]any) map[string]json.RawMessage {
+		var res struct {
+			Container struct {
+				From struct {
+					WithSBOM struct {
+						AsTarball struct {
+							ID dagger.FileID
+						}
+					}
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{Query: query, Variables: vars}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+
+		tarPath := filepath.Join(t.TempDir(), "image.tar")
+		_, err = c.LoadFileFromID(res.Container.From.WithSBOM.AsTarball.ID).Export(ctx, tarPath)
+		require.NoError(t, err)
+
+		readBlob := func(dgst digest.Digest, v any) {
+			require.NoError(t, json.Unmarshal(readTarFile(t, tarPath, "blobs/sha256/"+dgst.Encoded()), v))
+		}
+		var layout ocispecs.Index
+		require.NoError(t, json.Unmarshal(readTarFile(t, tarPath, "index.json"), &layout))
+		require.Len(t, layout.Manifests, 1)
+		var index ocispecs.Index
+		readBlob(layout.Manifests[0].Digest, &index)
+
+		statements := map[string]json.RawMessage{}
+		for _, desc := range index.Manifests {
+			if desc.Annotations["vnd.docker.reference.type"] != "attestation-manifest" {
+				continue
+			}
+			var manifest ocispecs.Manifest
+			readBlob(desc.Digest, &manifest)
+			for _, layer := range manifest.Layers {
+				var statement struct {
+					PredicateType string          `json:"predicateType"`
+					Predicate     json.RawMessage `json:"predicate"`
+				}
+				readBlob(layer.Digest, &statement)
+				statements[statement.PredicateType] = statement.Predicate
+			}
+		}
+		return statements
+	}
+
+	sbomID, err := c.Directory().
+		WithNewFile("sbom.spdx.json", `{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "name": "test"}`).
+		File("sbom.spdx.json").
+		ID(ctx)
+	require.NoError(t, err)
+
+	t.Run("SBOM and provenance", func(ctx context.Context, t *testctx.T) {
+		statements := attestations(t, `query Test($image: String!, $sbom: FileID!) {
+			container {
+				from(address: $image) {
+					withSBOM(sbom: $sbom) {
+						asTarball(provenance: true) {
+							id
+						}
+					}
+				}
+			}
+		}`, map[string]any{"image": alpineImage, "sbom": sbomID})
+		require.Len(t, statements, 2)
+		require.JSONEq(t,
+			`{"spdxVersion": "SPDX-2.3", "SPDXID": "SPDXRef-DOCUMENT", "name": "test"}`,
+			string(statements["https://spdx.dev/Document"]))
+
+		var provenance struct {
+			BuildType  string `json:"buildType"`
+			Invocation struct {
+				ConfigSource struct {
+					EntryPoint string `json:"entryPoint"`
+				} `json:"configSource"`
+			} `json:"invocation"`
+			Materials []struct {
+				URI string `json:"uri"`
+			} `json:"materials"`
+		}
+		require.NoError(t, json.Unmarshal(statements["https://slsa.dev/provenance/v0.2"], &provenance))
+		require.Equal(t, core.ProvenanceBuildType, provenance.BuildType)
+		require.Contains(t, provenance.Invocation.ConfigSource.EntryPoint, "withSBOM")
+		require.Len(t, provenance.Materials, 1)
+		require.Contains(t, provenance.Materials[0].URI, "pkg:docker/")
+	})
+
+	t.Run("generated SBOM", func(ctx context.Context, t *testctx.T) {
+		statements := attestations(t, `query Test($image: String!) {
+			container {
+				from(address: $image) {
+					withSBOM {
+						asTarball {
+							id
+						}
+					}
+				}
+			}
+		}`, map[string]any{"image": alpineImage})
+		require.Len(t, statements, 1)
+		require.Contains(t, string(statements["https://spdx.dev/Document"]), "alpine-baselayout")
+	})
+
+	t.Run("invalid SBOM", func(ctx context.Context, t *testctx.T) {
+		notSBOM, err := c.Directory().
+			WithNewFile("sbom.json", `{"bomFormat": "CycloneDX"}`).
+			File("sbom.json").
+			ID(ctx)
+		require.NoError(t, err)
+		err = c.Do(ctx, &dagger.Request{
+			Query: `query Test($image: String!, $sbom: FileID!) {
+				container {
+					from(address: $image) {
+						withSBOM(sbom: $sbom) {
+							id
+						}
+					}
+				}
+			}`,
+			Variables: map[string]any{"image": alpineImage, "sbom": notSBOM},
+		}, &dagger.Response{})
+		require.ErrorContains(t, err, "sbom.json is not an SPDX JSON document")
+	})
+}
+
 func (ContainerSuite) TestImport(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"regexp"
 	"strings"
@@ -645,6 +646,289 @@ func (DirectorySuite) TestDiff(ctx context.Context, t *testctx.T) {
 	*/
 }
 
+func (DirectorySuite) TestSearch(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	dirID, err := c.Directory().
+		WithNewFile("main.go", "package main\n\n// TODO: fix\nfunc main() {}\n").
+		WithNewFile("lib/lib.go", "package lib\n// TODO(someone): later\n").
+		WithNewFile("README.md", "no todos here\nTODO").
+		WithNewFile("vendor/dep/dep.go", "// TODO: not ours\n").
+		ID(ctx)
+	require.NoError(t, err)
+
+	type match struct {
+		Path       string
+		LineNumber int
+		Line       string
+	}
+	search := func(ctx context.Context, args string) ([]match, error) {
+		var res struct {
+			LoadDirectoryFromID struct {
+				Search []match
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test($id: DirectoryID!) {
+				loadDirectoryFromID(id: $id) {
+					search(%s) { path lineNumber line }
+				}
+			}`, args),
+			Variables: map[string]any{"id": dirID},
+		}, &dagger.Response{Data: &res})
+		return res.LoadDirectoryFromID.Search, err
+	}
+
+	t.Run("literal", func(ctx context.Context, t *testctx.T) {
+		res, err := search(ctx, `pattern: "TODO"`)
+		require.NoError(t, err)
+		require.Equal(t, []match{
+			{Path: "README.md", LineNumber: 2, Line: "TODO"},
+			{Path: "lib/lib.go", LineNumber: 2, Line: "// TODO(someone): later"},
+			{Path: "main.go", LineNumber: 3, Line: "// TODO: fix"},
+			{Path: "vendor/dep/dep.go", LineNumber: 1, Line: "// TODO: not ours"},
+		}, res)
+	})
+
+	t.Run("regex and globs", func(ctx context.Context, t *testctx.T) {
+		res, err := search(ctx, `pattern: "TODO\\(\\w+\\)", regex: true, globs: ["**/*.go", "!vendor"]`)
+		require.NoError(t, err)
+		require.Equal(t, []match{
+			{Path: "lib/lib.go", LineNumber: 2, Line: "// TODO(someone): later"},
+		}, res)
+	})
+
+	t.Run("max results", func(ctx context.Context, t *testctx.T) {
+		res, err := search(ctx, `pattern: "TODO", maxResults: 2`)
+		require.NoError(t, err)
+		require.Len(t, res, 2)
+	})
+
+	t.Run("invalid regex", func(ctx context.Context, t *testctx.T) {
+		_, err := search(ctx, `pattern: "(", regex: true`)
+		require.ErrorContains(t, err, "invalid regular expression")
+	})
+}
+
+func (DirectorySuite) TestWithSymlinkAndOwner(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	dirID, err := c.Directory().
+		WithNewFile("lib/libfoo.so.1", "foo").
+		WithNewFile("etc/passwd", "root:x:0:0:root:/root:/bin/sh\nfoo:x:1234:1234::/home/foo:/bin/sh\n").
+		WithNewFile("etc/group", "root:x:0:\nbar:x:5678:\n").
+		ID(ctx)
+	require.NoError(t, err)
+
+	var res struct {
+		LoadDirectoryFromID struct {
+			WithSymlink struct {
+				WithOwner struct {
+					ID dagger.DirectoryID
+				}
+			}
+		}
+	}
+	err = c.Do(ctx, &dagger.Request{
+		Query: `query Test($id: DirectoryID!) {
+			loadDirectoryFromID(id: $id) {
+				withSymlink(target: "libfoo.so.1", linkName: "lib/libfoo.so") {
+					withOwner(owner: "foo:bar") { id }
+				}
+			}
+		}`,
+		Variables: map[string]any{"id": dirID},
+	}, &dagger.Response{Data: &res})
+	require.NoError(t, err)
+
+	out, err := c.Container().From(alpineImage).
+		WithMountedDirectory("/mnt", c.LoadDirectoryFromID(res.LoadDirectoryFromID.WithSymlink.WithOwner.ID)).
+		WithWorkdir("/mnt").
+		WithExec([]string{"sh", "-c", "readlink lib/libfoo.so && cat lib/libfoo.so && stat -c '%u:%g' lib/libfoo.so.1 etc"}).
+		Stdout(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "libfoo.so.1\nfoo1234:5678\n1234:5678\n", out)
+}
+
+func (DirectorySuite) TestWithPatch(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	dirID, err := c.Directory().
+		WithNewFile("src/main.go", "package main\n").
+		WithNewFile("src/old.go", "package main\n").
+		WithNewFile("README.md", "hello\n").
+		Directory("src").
+		ID(ctx)
+	require.NoError(t, err)
+
+	patch := func(ctx context.Context, patch string) (*dagger.Directory, error) {
+		var res struct {
+			LoadDirectoryFromID struct {
+				WithPatch struct {
+					ID dagger.DirectoryID
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query Test($id: DirectoryID!, $patch: String!) {
+				loadDirectoryFromID(id: $id) {
+					withPatch(patch: $patch) { id }
+				}
+			}`,
+			Variables: map[string]any{"id": dirID, "patch": patch},
+		}, &dagger.Response{Data: &res})
+		if err != nil {
+			return nil, err
+		}
+		return c.LoadDirectoryFromID(res.LoadDirectoryFromID.WithPatch.ID), nil
+	}
+
+	t.Run("applies", func(ctx context.Context, t *testctx.T) {
+		dir, err := patch(ctx, `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1 +1,3 @@
+ package main
++
++func main() {}
+diff --git a/old.go b/old.go
+deleted file mode 100644
+--- a/old.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+diff --git a/cmd/run.sh b/cmd/run.sh
+new file mode 100755
+--- /dev/null
++++ b/cmd/run.sh
+@@ -0,0 +1 @@
++go run .
+`)
+		require.NoError(t, err)
+
+		entries, err := dir.Glob(ctx, "**/*")
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"cmd", "cmd/run.sh", "main.go"}, entries)
+
+		contents, err := dir.File("main.go").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "package main\n\nfunc main() {}\n", contents)
+
+		out, err := c.Container().From(alpineImage).
+			WithMountedDirectory("/mnt", dir).
+			WithExec([]string{"stat", "-c", "%a", "/mnt/cmd/run.sh"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "755\n", out)
+	})
+
+	t.Run("does not apply", func(ctx context.Context, t *testctx.T) {
+		_, err := patch(ctx, `--- a/README.md
++++ b/README.md
+@@ -1 +1 @@
+-hello
++bye
+`)
+		require.ErrorContains(t, err, "failed to apply patch")
+	})
+}
+
+func (DirectorySuite) TestChanges(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	base := c.Directory().
+		WithNewFile("same.txt", "same").
+		WithNewFile("sub/modified.txt", "old").
+		WithNewFile("deleted.txt", "bye").
+		WithNewFile("script.sh", "echo hi", dagger.DirectoryWithNewFileOpts{Permissions: 0o644})
+	baseID, err := base.ID(ctx)
+	require.NoError(t, err)
+
+	type change struct {
+		Path         string
+		Kind         string
+		IsDirectory  bool
+		Mode         int
+		PreviousMode int
+		Size         int
+		PreviousSize int
+	}
+	changes := func(ctx context.Context, other *dagger.Directory) ([]change, error) {
+		otherID, err := other.ID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var res struct {
+			LoadDirectoryFromID struct {
+				Changes []change
+			}
+		}
+		err = c.Do(ctx, &dagger.Request{
+			Query: `query Test($id: DirectoryID!, $other: DirectoryID!) {
+				loadDirectoryFromID(id: $id) {
+					changes(other: $other) { path kind isDirectory mode previousMode size previousSize }
+				}
+			}`,
+			Variables: map[string]any{
+				"id":    baseID,
+				"other": otherID,
+			},
+		}, &dagger.Response{Data: &res})
+		return res.LoadDirectoryFromID.Changes, err
+	}
+
+	t.Run("unchanged", func(ctx context.Context, t *testctx.T) {
+		// regenerating the same content doesn't count as a change
+		res, err := changes(ctx, c.Directory().
+			WithNewFile("same.txt", "same").
+			WithNewFile("sub/modified.txt", "old").
+			WithNewFile("deleted.txt", "bye").
+			WithNewFile("script.sh", "echo hi", dagger.DirectoryWithNewFileOpts{Permissions: 0o644}))
+		require.NoError(t, err)
+		require.Empty(t, res)
+	})
+
+	t.Run("changed", func(ctx context.Context, t *testctx.T) {
+		res, err := changes(ctx, base.
+			WithNewFile("sub/modified.txt", "newer").
+			WithoutFile("deleted.txt").
+			WithNewFile("added.txt", "hi").
+			WithNewFile("script.sh", "echo hi", dagger.DirectoryWithNewFileOpts{Permissions: 0o755}))
+		require.NoError(t, err)
+		require.ElementsMatch(t, []change{
+			{Path: "added.txt", Kind: "ADDED", Mode: 0o644, Size: 2},
+			{Path: "deleted.txt", Kind: "DELETED", PreviousMode: 0o644, PreviousSize: 3},
+			{Path: "script.sh", Kind: "MODIFIED", Mode: 0o755, PreviousMode: 0o644, Size: 7, PreviousSize: 7},
+			{Path: "sub/modified.txt", Kind: "MODIFIED", Mode: 0o644, PreviousMode: 0o644, Size: 5, PreviousSize: 3},
+		}, res)
+	})
+
+	t.Run("file replaced by directory", func(ctx context.Context, t *testctx.T) {
+		res, err := changes(ctx, base.
+			WithoutFile("deleted.txt").
+			WithNewFile("deleted.txt/inner.txt", "hi"))
+		require.NoError(t, err)
+		kinds := map[string]bool{}
+		for _, change := range res {
+			kinds[change.Path] = change.IsDirectory
+		}
+		require.Equal(t, map[string]bool{
+			"deleted.txt":           true,
+			"deleted.txt/inner.txt": false,
+		}, kinds)
+	})
+
+	t.Run("subdirectories", func(ctx context.Context, t *testctx.T) {
+		res, err := changes(ctx, c.Directory().
+			WithDirectory("generated", base.WithoutFile("deleted.txt")).
+			Directory("generated"))
+		require.NoError(t, err)
+		require.Equal(t, []change{
+			{Path: "deleted.txt", Kind: "DELETED", PreviousMode: 0o644, PreviousSize: 3},
+		}, res)
+	})
+}
+
 func (DirectorySuite) TestExport(ctx context.Context, t *testctx.T) {
 	wd := t.TempDir()
 	dest := t.TempDir()
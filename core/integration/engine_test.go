@@ -81,6 +81,17 @@ func engineWithConfig(ctx context.Context, t *testctx.T, cfgFns ...func(context.
 	}
 }
 
+// engineWithExtraConfig appends Dagger-specific settings, which the buildkit
+// config ignores, to the engine config.
+func engineWithExtraConfig(ctx context.Context, t *testctx.T, extra string) func(*dagger.Container) *dagger.Container {
+	return func(ctr *dagger.Container) *dagger.Container {
+		t.Helper()
+		existingCfgStr, err := ctr.File("/etc/dagger/engine.toml").Contents(ctx)
+		require.NoError(t, err)
+		return ctr.WithNewFile("/etc/dagger/engine.toml", existingCfgStr+"\n"+extra)
+	}
+}
+
 func engineClientContainer(ctx context.Context, t *testctx.T, c *dagger.Client, devEngine *dagger.Service) (*dagger.Container, error) {
 	daggerCli := daggerCliFile(t, c)
 
@@ -598,3 +609,63 @@ func (EngineSuite) TestModuleVersionCompat(ctx context.Context, t *testctx.T) {
 		})
 	}
 }
+
+func (EngineSuite) TestImagePolicyConfig(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	imageRef, err := c.Container().From(alpineImage).ImageRef(ctx)
+	require.NoError(t, err)
+	_, dgst, ok := strings.Cut(imageRef, "@")
+	require.True(t, ok)
+
+	engineSvc, err := c.Host().Tunnel(devEngineContainer(c, engineWithExtraConfig(ctx, t, `
+[image]
+  requireDigests = true
+
+[[image.rewrite]]
+  from = "mirror.invalid/library"
+  to = "docker.io/library"
+`)).AsService()).Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+	endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+	require.NoError(t, err)
+
+	c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	t.Cleanup(func() { c2.Close() })
+
+	t.Run("unpinned image", func(ctx context.Context, t *testctx.T) {
+		_, err := c2.Container().From(alpineImage).Sync(ctx)
+		require.ErrorContains(t, err, "is not pinned to a digest")
+	})
+
+	t.Run("rewritten image", func(ctx context.Context, t *testctx.T) {
+		// only pullable through the rewrite, which isn't visible in its ref
+		pinned := "mirror.invalid/library/" + alpineImage + "@" + dgst
+		ctr := c2.Container().From(pinned)
+		out, err := ctr.WithExec([]string{"cat", "/etc/alpine-release"}).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, distconsts.AlpineVersion, strings.TrimSpace(out))
+
+		ref, err := ctr.ImageRef(ctx)
+		require.NoError(t, err)
+		require.Equal(t, pinned, ref)
+	})
+
+	t.Run("dockerfile base images", func(ctx context.Context, t *testctx.T) {
+		dockerBuild := func(from string) *dagger.Container {
+			return c2.Directory().
+				WithNewFile("Dockerfile", "FROM "+from+"\nRUN cat /etc/alpine-release > /release\n").
+				DockerBuild()
+		}
+
+		_, err := dockerBuild(alpineImage).Sync(ctx)
+		require.ErrorContains(t, err, "denied by policy")
+
+		out, err := dockerBuild("mirror.invalid/library/" + alpineImage + "@" + dgst).File("/release").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, distconsts.AlpineVersion, strings.TrimSpace(out))
+	})
+}
@@ -460,3 +460,162 @@ func (FileSuite) TestSync(ctx context.Context, t *testctx.T) {
 		require.Equal(t, "bar", contents)
 	})
 }
+
+func (FileSuite) TestWithReplaced(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	fileID, err := c.Directory().
+		WithNewFile("config.txt", "version=1\nname=foo\nalias=foo\n", dagger.DirectoryWithNewFileOpts{Permissions: 0o600}).
+		File("config.txt").
+		ID(ctx)
+	require.NoError(t, err)
+
+	replace := func(ctx context.Context, args string) (*dagger.File, error) {
+		var res struct {
+			LoadFileFromID struct {
+				WithReplaced struct {
+					ID dagger.FileID
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test($id: FileID!) {
+				loadFileFromID(id: $id) {
+					withReplaced(%s) { id }
+				}
+			}`, args),
+			Variables: map[string]any{"id": fileID},
+		}, &dagger.Response{Data: &res})
+		if err != nil {
+			return nil, err
+		}
+		return c.LoadFileFromID(res.LoadFileFromID.WithReplaced.ID), nil
+	}
+
+	t.Run("first", func(ctx context.Context, t *testctx.T) {
+		file, err := replace(ctx, `search: "foo", replace: "bar"`)
+		require.NoError(t, err)
+		contents, err := file.Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "version=1\nname=bar\nalias=foo\n", contents)
+
+		// the name and permissions are kept
+		out, err := c.Container().From(alpineImage).
+			WithMountedFile("/mnt/config.txt", file).
+			WithExec([]string{"stat", "-c", "%a", "/mnt/config.txt"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "600\n", out)
+		name, err := file.Name(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "config.txt", name)
+	})
+
+	t.Run("all", func(ctx context.Context, t *testctx.T) {
+		file, err := replace(ctx, `search: "foo", replace: "bar", all: true`)
+		require.NoError(t, err)
+		contents, err := file.Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "version=1\nname=bar\nalias=bar\n", contents)
+	})
+
+	t.Run("not found", func(ctx context.Context, t *testctx.T) {
+		_, err := replace(ctx, `search: "nope", replace: "bar"`)
+		require.ErrorContains(t, err, `"nope" not found in`)
+	})
+}
+
+func (FileSuite) TestWithPermissions(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	fileID, err := c.Directory().
+		WithNewFile("script.sh", "echo hi").
+		File("script.sh").
+		ID(ctx)
+	require.NoError(t, err)
+
+	var res struct {
+		LoadFileFromID struct {
+			WithPermissions struct {
+				ID dagger.FileID
+			}
+		}
+	}
+	err = c.Do(ctx, &dagger.Request{
+		Query: `query Test($id: FileID!) {
+			loadFileFromID(id: $id) {
+				withPermissions(permissions: 493) { id }
+			}
+		}`,
+		Variables: map[string]any{"id": fileID},
+	}, &dagger.Response{Data: &res})
+	require.NoError(t, err)
+
+	out, err := c.Container().From(alpineImage).
+		WithMountedFile("/mnt/script.sh", c.LoadFileFromID(res.LoadFileFromID.WithPermissions.ID)).
+		WithExec([]string{"stat", "-c", "%a", "/mnt/script.sh"}).
+		Stdout(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "755\n", out)
+}
+
+func (FileSuite) TestStructuredData(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	dir := c.Directory().
+		WithNewFile("package.json", `{"name": "app", "version": "1.2.3", "dependencies": {"@types/node": "20.1.0"}}`).
+		WithNewFile("Chart.yaml", "apiVersion: v2\nname: app\nversion: 0.4.0\nkeywords: [web, api]\n").
+		WithNewFile("Cargo.toml", "[package]\nname = \"app\"\nversion = \"0.1.0\"\n\n[dependencies]\nserde = \"1\"\n").
+		WithNewFile("config", "enabled: true\n")
+	dirID, err := dir.ID(ctx)
+	require.NoError(t, err)
+
+	query := func(ctx context.Context, file, selection string) (string, error) {
+		var res struct {
+			LoadDirectoryFromID struct {
+				File struct {
+					Value string
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test($id: DirectoryID!, $file: String!) {
+				loadDirectoryFromID(id: $id) {
+					file(path: $file) { value: %s }
+				}
+			}`, selection),
+			Variables: map[string]any{"id": dirID, "file": file},
+		}, &dagger.Response{Data: &res})
+		return res.LoadDirectoryFromID.File.Value, err
+	}
+
+	for _, tc := range []struct {
+		file      string
+		selection string
+		expected  string
+	}{
+		{"package.json", `query(path: "version")`, `"1.2.3"`},
+		{"package.json", `query(path: "$.dependencies['@types/node']")`, `"20.1.0"`},
+		{"Chart.yaml", `query(path: "keywords[-1]")`, `"api"`},
+		{"Chart.yaml", `query(path: "keywords")`, `["web","api"]`},
+		{"Cargo.toml", `query(path: "package.version")`, `"0.1.0"`},
+		{"Cargo.toml", `asJSON`, `{"dependencies":{"serde":"1"},"package":{"name":"app","version":"0.1.0"}}`},
+		{"config", `query(path: "enabled", format: YAML_FORMAT)`, `true`},
+	} {
+		t.Run(tc.file+" "+tc.selection, func(ctx context.Context, t *testctx.T) {
+			value, err := query(ctx, tc.file, tc.selection)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, value)
+		})
+	}
+
+	t.Run("missing value", func(ctx context.Context, t *testctx.T) {
+		_, err := query(ctx, "package.json", `query(path: "dependencies.react")`)
+		require.ErrorContains(t, err, "no value at $.dependencies.react")
+	})
+
+	t.Run("unknown format", func(ctx context.Context, t *testctx.T) {
+		_, err := query(ctx, "config", `asJSON`)
+		require.ErrorContains(t, err, "cannot infer the format of config")
+	})
+}
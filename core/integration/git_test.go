@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"crypto/sha1"
 	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -346,3 +348,409 @@ func (GitSuite) TestGitTags(ctx context.Context, t *testctx.T) {
 		require.Contains(t, tags, "sdk/go/v0.9.3")
 	})
 }
+
+func (GitSuite) TestGitBranchesAndRefs(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	lsRemote := func(ctx context.Context, t *testctx.T, field string, patterns []string) []string {
+		var res struct {
+			Git map[string][]string
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test($patterns: [String!]) {
+				git(url: "https://github.com/dagger/dagger") {
+					%s(patterns: $patterns)
+				}
+			}`, field),
+			Variables: map[string]any{
+				"patterns": patterns,
+			},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		return res.Git[field]
+	}
+
+	t.Run("all branches", func(ctx context.Context, t *testctx.T) {
+		branches := lsRemote(ctx, t, "branches", nil)
+		require.Contains(t, branches, "main")
+		require.NotContains(t, branches, "v0.9.3")
+	})
+
+	t.Run("branch pattern", func(ctx context.Context, t *testctx.T) {
+		branches := lsRemote(ctx, t, "branches", []string{"refs/heads/ma*"})
+		require.Equal(t, []string{"main"}, branches)
+	})
+
+	t.Run("ref pattern", func(ctx context.Context, t *testctx.T) {
+		refs := lsRemote(ctx, t, "refs", []string{"refs/heads/main", "refs/tags/v0.9.*"})
+		require.Contains(t, refs, "refs/heads/main")
+		require.Contains(t, refs, "refs/tags/v0.9.3")
+		require.NotContains(t, refs, "refs/tags/sdk/go/v0.9.3")
+	})
+}
+
+func (GitSuite) TestCheckoutOptions(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	svc, repoURL := gitServiceWithHistory(ctx, t, c)
+
+	tree := func(ctx context.Context, t *testctx.T, gitArgs string, treeArgs string) *dagger.Directory {
+		var res struct {
+			Git struct {
+				Branch struct {
+					Tree struct {
+						ID dagger.DirectoryID
+					}
+				}
+			}
+		}
+		if treeArgs != "" {
+			treeArgs = "(" + treeArgs + ")"
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test($url: String!, $svc: ServiceID!) {
+				git(url: $url, experimentalServiceHost: $svc%s) {
+					branch(name: "main") {
+						tree%s { id }
+					}
+				}
+			}`, gitArgs, treeArgs),
+			Variables: map[string]any{
+				"url": repoURL,
+				"svc": svc,
+			},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		return c.LoadDirectoryFromID(res.Git.Branch.Tree.ID)
+	}
+
+	commitCount := func(ctx context.Context, t *testctx.T, dir *dagger.Directory) string {
+		out, err := c.Container().
+			From(alpineImage).
+			WithExec([]string{"apk", "add", "git"}).
+			WithMountedDirectory("/repo", dir).
+			WithWorkdir("/repo").
+			WithExec([]string{"git", "rev-list", "--count", "HEAD"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		return strings.TrimSpace(out)
+	}
+
+	t.Run("defaults", func(ctx context.Context, t *testctx.T) {
+		dir := tree(ctx, t, ", keepGitDir: true", "")
+
+		ents, err := dir.Entries(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{".git", ".gitmodules", "docs", "src", "sub"}, ents)
+
+		dt, err := dir.File("sub/sub.txt").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "submodule", dt)
+
+		require.Equal(t, "1", commitCount(ctx, t, dir))
+	})
+
+	t.Run("depth", func(ctx context.Context, t *testctx.T) {
+		require.Equal(t, "2", commitCount(ctx, t, tree(ctx, t, ", keepGitDir: true, depth: 2", "")))
+		require.Equal(t, "3", commitCount(ctx, t, tree(ctx, t, ", keepGitDir: true, depth: 0", "")))
+	})
+
+	t.Run("without submodules", func(ctx context.Context, t *testctx.T) {
+		ents, err := tree(ctx, t, ", withSubmodules: false", "").Directory("sub").Entries(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ents)
+	})
+
+	t.Run("sparse", func(ctx context.Context, t *testctx.T) {
+		ents, err := tree(ctx, t, "", `sparse: ["docs"]`).Entries(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"docs"}, ents)
+	})
+
+	t.Run("sparse with git dir", func(ctx context.Context, t *testctx.T) {
+		dir := tree(ctx, t, ", keepGitDir: true", `sparse: ["src"]`)
+		ents, err := dir.Entries(ctx)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{".git", "src"}, ents)
+
+		// only the blobs of the sparse paths are fetched
+		out, err := c.Container().
+			From(alpineImage).
+			WithExec([]string{"apk", "add", "git"}).
+			WithMountedDirectory("/repo", dir).
+			WithWorkdir("/repo").
+			WithExec([]string{"sh", "-c", "git rev-list --objects --missing=print HEAD | sed -n 's/^?//p'"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		readme := sha1.Sum([]byte("blob 4\x00one\n"))
+		require.Equal(t, []string{hex.EncodeToString(readme[:])}, strings.Fields(out))
+	})
+}
+
+func (GitSuite) TestCommitInfo(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	svc, repoURL := gitServiceWithHistory(ctx, t, c)
+
+	commitInfo := func(ctx context.Context, t *testctx.T, ref string) map[string]any {
+		var res struct {
+			Git struct {
+				Ref struct {
+					CommitInfo map[string]any
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query Test($url: String!, $svc: ServiceID!, $ref: String!) {
+				git(url: $url, experimentalServiceHost: $svc) {
+					ref(name: $ref) {
+						commitInfo {
+							sha
+							author { name email }
+							committer { name email }
+							message
+							timestamp
+							parents
+						}
+					}
+				}
+			}`,
+			Variables: map[string]any{
+				"url": repoURL,
+				"svc": svc,
+				"ref": ref,
+			},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		return res.Git.Ref.CommitInfo
+	}
+
+	head := commitInfo(ctx, t, "main")
+	require.Len(t, head["sha"], 40)
+	require.Equal(t, map[string]any{"name": "Test User", "email": "root@localhost"}, head["author"])
+	require.Equal(t, map[string]any{"name": "Test User", "email": "root@localhost"}, head["committer"])
+	require.Equal(t, "three\n", head["message"])
+	require.NotZero(t, head["timestamp"])
+	require.Len(t, head["parents"], 1)
+
+	parentID := head["parents"].([]any)[0].(string)
+	parent := commitInfo(ctx, t, parentID)
+	require.Equal(t, parentID, parent["sha"])
+	require.Equal(t, "two\n", parent["message"])
+}
+
+func (GitSuite) TestCommitAndPush(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	svc, repoURL := gitServiceWithHistory(ctx, t, c)
+	// keep the remote up for pushing, which doesn't bind services itself
+	svc, err := svc.Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { svc.Stop(context.Background()) })
+
+	main := c.Git(repoURL, dagger.GitOpts{ExperimentalServiceHost: svc}).Branch("main")
+	mainID, err := main.ID(ctx)
+	require.NoError(t, err)
+	mainCommit, err := main.Commit(ctx)
+	require.NoError(t, err)
+
+	type commitInfo struct {
+		SHA     string
+		Message string
+		Author  struct {
+			Name  string
+			Email string
+		}
+		Parents []string
+	}
+
+	t.Run("commit on parent and push", func(ctx context.Context, t *testctx.T) {
+		dirID, err := main.Tree().
+			// the submodule is checked out without its .git, so it would be
+			// committed as plain files
+			WithoutDirectory("sub").
+			WithNewFile("VERSION", "2.0.0").
+			ID(ctx)
+		require.NoError(t, err)
+
+		var res struct {
+			LoadDirectoryFromID struct {
+				AsGitCommit struct {
+					Commit     string
+					CommitInfo commitInfo
+					Tree       struct {
+						Entries []string
+					}
+					Push *string
+				}
+			}
+		}
+		err = c.Do(ctx, &dagger.Request{
+			Query: `query Test($dir: DirectoryID!, $parent: GitRefID!, $url: String!) {
+				loadDirectoryFromID(id: $dir) {
+					asGitCommit(message: "Bump version", author: "Release Bot <bot@example.com>", parent: $parent) {
+						commit
+						commitInfo { sha message author { name email } parents }
+						tree { entries }
+						push(remote: $url, ref: "release")
+					}
+				}
+			}`,
+			Variables: map[string]any{
+				"dir":    dirID,
+				"parent": mainID,
+				"url":    repoURL,
+			},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+
+		commit := res.LoadDirectoryFromID.AsGitCommit
+		require.Len(t, commit.Commit, 40)
+		require.Equal(t, commit.Commit, commit.CommitInfo.SHA)
+		require.Equal(t, "Bump version\n", commit.CommitInfo.Message)
+		require.Equal(t, "Release Bot", commit.CommitInfo.Author.Name)
+		require.Equal(t, "bot@example.com", commit.CommitInfo.Author.Email)
+		require.Equal(t, []string{mainCommit}, commit.CommitInfo.Parents)
+		require.ElementsMatch(t, []string{".gitmodules", "VERSION", "docs", "src"}, commit.Tree.Entries)
+
+		pushed := c.Git(repoURL, dagger.GitOpts{ExperimentalServiceHost: svc}).Branch("release")
+		pushedCommit, err := pushed.Commit(ctx)
+		require.NoError(t, err)
+		require.Equal(t, commit.Commit, pushedCommit)
+		version, err := pushed.Tree().File("VERSION").Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "2.0.0", version)
+	})
+
+	t.Run("push to an empty remote", func(ctx context.Context, t *testctx.T) {
+		// the remote has none of the history, which must be pushed along
+		emptyURL := strings.TrimSuffix(repoURL, "repo.git") + "empty.git"
+
+		err := main.Push(ctx, emptyURL, "main")
+		require.NoError(t, err)
+
+		commit := main.Tree().
+			WithoutDirectory("sub").
+			WithNewFile("VERSION", "3.0.0").
+			AsGitCommit("Bump version", "Release Bot <bot@example.com>", dagger.DirectoryAsGitCommitOpts{
+				Parent: main,
+			})
+		commitSHA, err := commit.Commit(ctx)
+		require.NoError(t, err)
+		err = commit.Push(ctx, emptyURL, "release")
+		require.NoError(t, err)
+
+		empty := c.Git(emptyURL, dagger.GitOpts{ExperimentalServiceHost: svc})
+		pushedMain, err := empty.Branch("main").Commit(ctx)
+		require.NoError(t, err)
+		require.Equal(t, mainCommit, pushedMain)
+		pushedRelease, err := empty.Branch("release").Commit(ctx)
+		require.NoError(t, err)
+		require.Equal(t, commitSHA, pushedRelease)
+	})
+
+	t.Run("commit without parent", func(ctx context.Context, t *testctx.T) {
+		dirID, err := c.Directory().WithNewFile("README.md", "new").ID(ctx)
+		require.NoError(t, err)
+
+		var res struct {
+			LoadDirectoryFromID struct {
+				AsGitCommit struct {
+					CommitInfo commitInfo
+					Tree       struct {
+						Entries []string
+					}
+				}
+			}
+		}
+		err = c.Do(ctx, &dagger.Request{
+			Query: `query Test($dir: DirectoryID!) {
+				loadDirectoryFromID(id: $dir) {
+					asGitCommit(message: "init", author: "Release Bot <bot@example.com>") {
+						commitInfo { sha message author { name email } parents }
+						tree { entries }
+					}
+				}
+			}`,
+			Variables: map[string]any{
+				"dir": dirID,
+			},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		require.Empty(t, res.LoadDirectoryFromID.AsGitCommit.CommitInfo.Parents)
+		require.Equal(t, []string{"README.md"}, res.LoadDirectoryFromID.AsGitCommit.Tree.Entries)
+	})
+
+	t.Run("invalid author", func(ctx context.Context, t *testctx.T) {
+		err := c.Do(ctx, &dagger.Request{
+			Query: `{directory{asGitCommit(message: "init", author: "nobody"){commit}}}`,
+		}, &dagger.Response{})
+		require.ErrorContains(t, err, "invalid author")
+	})
+}
+
+// gitServiceWithHistory serves a repo with a few commits on main and a
+// submodule at ./sub, and an empty repo at ./empty.git next to it. Anyone can
+// push to them.
+func gitServiceWithHistory(ctx context.Context, t *testctx.T, c *dagger.Client) (*dagger.Service, string) {
+	t.Helper()
+
+	srv := c.Container().
+		From(alpineImage).
+		WithExec([]string{"apk", "add", "git"}).
+		WithNewFile("/root/create.sh", `#!/bin/sh
+
+set -e -u -x
+
+cd /root
+
+git config --global user.email "root@localhost"
+git config --global user.name "Test User"
+git config --global init.defaultBranch main
+git config --global protocol.file.allow always
+
+git init --bare -b main srv/sub.git
+git init --bare -b main srv/repo.git
+git -C srv/repo.git config uploadpack.allowFilter true
+git init --bare -b main srv/empty.git
+
+git clone srv/sub.git sub
+cd sub
+	echo -n submodule > sub.txt
+	git add sub.txt
+	git commit -m "sub"
+	git push origin main
+cd ..
+
+# clone from the served repo, so the submodule url is relative to it
+git clone srv/repo.git repo
+cd repo
+	mkdir docs src
+	echo one > docs/README.md
+	git add .
+	git commit -m "one"
+	echo two > src/main.go
+	git add .
+	git commit -m "two"
+	git submodule add ../sub.git sub
+	git commit -m "three"
+	git push origin main
+cd ..
+`).
+		WithExec([]string{"sh", "/root/create.sh"}).
+		Directory("/root/srv")
+
+	const gitPort = 9418
+	gitDaemon := c.Container().
+		From(alpineImage).
+		WithExec([]string{"apk", "add", "git", "git-daemon"}).
+		WithDirectory("/root/srv", srv).
+		WithExposedPort(gitPort).
+		WithExec([]string{"sh", "-c", "git daemon --verbose --export-all --enable=receive-pack --base-path=/root/srv"}).
+		AsService()
+
+	gitHost, err := gitDaemon.Hostname(ctx)
+	require.NoError(t, err)
+
+	return gitDaemon, fmt.Sprintf("git://%s/repo.git", gitHost)
+}
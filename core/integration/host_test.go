@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/dagger/dagger/testctx"
 	"github.com/moby/buildkit/identity"
@@ -218,6 +219,65 @@ func (HostSuite) TestSetSecretFile(ctx context.Context, t *testctx.T) {
 
 		require.Equal(t, hashStr, hashStrCmd)
 	})
+
+	t.Run("expired secret is re-read from host", func(ctx context.Context, t *testctx.T) {
+		secretPath := filepath.Join(dir, "rotating-file")
+		require.NoError(t, os.WriteFile(secretPath, []byte("first-token"), 0o600))
+
+		var res struct {
+			Host struct {
+				SetSecretFile struct {
+					ID dagger.SecretID
+				}
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query($path: String!) {
+				host {
+					setSecretFile(name: "rotating", path: $path, ttl: "1s") {
+						id
+					}
+				}
+			}`,
+			Variables: map[string]any{"path": secretPath},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		secret := c.LoadSecretFromID(res.Host.SetSecretFile.ID)
+
+		plaintext, err := secret.Plaintext(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "first-token", plaintext)
+
+		require.NoError(t, os.WriteFile(secretPath, []byte("second-token"), 0o600))
+		time.Sleep(2 * time.Second)
+
+		plaintext, err = secret.Plaintext(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "second-token", plaintext)
+
+		// execs started after the refetch see the new value, and it's scrubbed
+		output, err := c.Container().From(alpineImage).
+			WithEnvVariable("CACHEBUST", identity.NewID()).
+			WithMountedSecret("/rotating", secret).
+			WithExec([]string{"sh", "-c", "cat /rotating; echo; cat /rotating | wc -c"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "***\n12\n", output)
+	})
+
+	t.Run("invalid ttl", func(ctx context.Context, t *testctx.T) {
+		err := c.Do(ctx, &dagger.Request{
+			Query: `query($path: String!) {
+				host {
+					setSecretFile(name: "bad-ttl", path: $path, ttl: "soon") {
+						id
+					}
+				}
+			}`,
+			Variables: map[string]any{"path": filepath.Join(dir, "some-file")},
+		}, &dagger.Response{})
+		require.ErrorContains(t, err, `invalid secret ttl "soon"`)
+	})
 }
 
 func (HostSuite) TestDirectoryAbsolute(ctx context.Context, t *testctx.T) {
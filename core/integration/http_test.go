@@ -2,10 +2,12 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/dagger/dagger/testctx"
 	"github.com/moby/buildkit/identity"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 
 	"dagger.io/dagger"
@@ -64,3 +66,90 @@ func (HTTPSuite) TestHTTPServiceStableDigest(ctx context.Context, t *testctx.T)
 	c2 := connect(ctx, t)
 	require.Equal(t, hostname(c1), hostname(c2))
 }
+
+func (HTTPSuite) TestHTTPOptions(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	// echoes back the request headers we care about
+	svc := c.Container().
+		From("python").
+		WithNewFile("/srv/server.py", `from http.server import BaseHTTPRequestHandler, HTTPServer
+
+class Handler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        body = "auth=%s custom=%s" % (self.headers.get("Authorization"), self.headers.get("X-Custom"))
+        self.send_response(200)
+        self.end_headers()
+        self.wfile.write(body.encode())
+
+HTTPServer(("", 8000), Handler).serve_forever()
+`).
+		WithExposedPort(8000).
+		WithExec([]string{"python", "/srv/server.py"}).
+		AsService()
+	url, err := svc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "http"})
+	require.NoError(t, err)
+
+	secretID, err := c.SetSecret("http-auth", "Bearer hunter2").ID(ctx)
+	require.NoError(t, err)
+
+	fetch := func(ctx context.Context, args string, vars map[string]any, fields string) (map[string]any, error) {
+		var res struct {
+			HTTP map[string]any
+		}
+		vars["url"] = url
+		vars["svc"] = svc
+		decls := "$url: String!, $svc: ServiceID!"
+		if _, ok := vars["secret"]; ok {
+			decls += ", $secret: SecretID!"
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`query Test(%s) {
+				http(url: $url, experimentalServiceHost: $svc%s) { %s }
+			}`, decls, args, fields),
+			Variables: vars,
+		}, &dagger.Response{Data: &res})
+		return res.HTTP, err
+	}
+
+	t.Run("headers", func(ctx context.Context, t *testctx.T) {
+		res, err := fetch(ctx,
+			`, authHeader: $secret, headers: [{name: "X-Custom", value: "yes"}]`,
+			map[string]any{"secret": secretID},
+			"contents")
+		require.NoError(t, err)
+		require.Equal(t, "auth=Bearer hunter2 custom=yes", res["contents"])
+	})
+
+	t.Run("name and permissions", func(ctx context.Context, t *testctx.T) {
+		res, err := fetch(ctx, `, name: "hello.txt", permissions: 493`, map[string]any{}, "name id")
+		require.NoError(t, err)
+		require.Equal(t, "hello.txt", res["name"])
+
+		out, err := c.Container().
+			From(alpineImage).
+			WithFile("/out/", c.LoadFileFromID(dagger.FileID(res["id"].(string)))).
+			WithExec([]string{"stat", "-c", "%n %a", "/out/hello.txt"}).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "/out/hello.txt 755\n", out)
+	})
+
+	t.Run("invalid name", func(ctx context.Context, t *testctx.T) {
+		_, err := fetch(ctx, `, name: "../hello.txt"`, map[string]any{}, "name")
+		require.ErrorContains(t, err, "invalid file name")
+	})
+
+	t.Run("checksum", func(ctx context.Context, t *testctx.T) {
+		content := "auth=None custom=None"
+		res, err := fetch(ctx, fmt.Sprintf(`, checksum: %q`, digest.FromString(content)), map[string]any{}, "contents")
+		require.NoError(t, err)
+		require.Equal(t, content, res["contents"])
+
+		_, err = fetch(ctx, fmt.Sprintf(`, checksum: %q`, digest.FromString("something else")), map[string]any{}, "contents")
+		require.ErrorContains(t, err, "checksum mismatch")
+
+		_, err = fetch(ctx, `, checksum: "nope"`, map[string]any{}, "contents")
+		require.ErrorContains(t, err, "invalid checksum")
+	})
+}
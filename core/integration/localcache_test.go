@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -226,6 +227,304 @@ func (EngineSuite) TestLocalCacheManualGC(ctx context.Context, t *testctx.T) {
 	require.Len(t, newEnts, 1) // 1 because there are cache entries created internally when each session starts (the file containing the core schema)
 }
 
+func (EngineSuite) TestLocalCacheFilteredPrune(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	engineSvc, err := c.Host().Tunnel(devEngineContainer(c).AsService()).Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+	endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+	require.NoError(t, err)
+
+	c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	t.Cleanup(func() { c2.Close() })
+
+	c3, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	_, err = c3.Container().From(alpineImage).
+		WithMountedCache("/cache", c3.CacheVolume("filtered-prune")).
+		WithExec([]string{"sh", "-c", "echo hey > /cache/hey && touch /foo"}).
+		Sync(ctx)
+	require.NoError(t, err)
+	require.NoError(t, c3.Close())
+
+	prune := func(args string) {
+		t.Helper()
+		err := c2.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`{daggerEngine{localCache{prune(%s)}}}`, args),
+		}, &dagger.Response{})
+		require.NoError(t, err)
+	}
+	hasEntry := func(prefix string) bool {
+		t.Helper()
+		ents, err := c2.DaggerEngine().LocalCache().EntrySet().Entries(ctx)
+		require.NoError(t, err)
+		for _, ent := range ents {
+			desc, err := ent.Description(ctx)
+			require.NoError(t, err)
+			if strings.HasPrefix(desc, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	const cacheMountPrefix = "cached mount /cache"
+	const execPrefix = "mount / from exec sh -c echo hey"
+
+	require.True(t, hasEntry(cacheMountPrefix))
+	require.True(t, hasEntry(execPrefix))
+
+	// everything was just used, so nothing is old enough to be pruned
+	prune(`olderThan: "1h"`)
+	require.True(t, hasEntry(cacheMountPrefix))
+	require.True(t, hasEntry(execPrefix))
+
+	// the cache mount is far smaller than the minimum size
+	prune(`type: CACHE_MOUNT, minSize: 1073741824`)
+	require.True(t, hasEntry(cacheMountPrefix))
+
+	// session cleanup releases refs asynchronously, so retry
+	for i := 0; hasEntry(cacheMountPrefix); i++ {
+		if i == 10 {
+			t.Fatal("expected cache mount entry to be pruned")
+		}
+		prune(`type: CACHE_MOUNT`)
+		time.Sleep(time.Second)
+	}
+	require.True(t, hasEntry(execPrefix))
+
+	err = c2.Do(ctx, &dagger.Request{
+		Query: `{daggerEngine{localCache{prune(olderThan: "a week")}}}`,
+	}, &dagger.Response{})
+	require.ErrorContains(t, err, `invalid olderThan "a week"`)
+}
+
+func (EngineSuite) TestLocalCacheEntrySetFilters(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	engineSvc, err := c.Host().Tunnel(devEngineContainer(c).AsService()).Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+	endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+	require.NoError(t, err)
+
+	c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	t.Cleanup(func() { c2.Close() })
+
+	_, err = c2.Container().From(alpineImage).
+		WithMountedCache("/cache", c2.CacheVolume("entry-set-filters")).
+		WithExec([]string{"sh", "-c", "dd if=/dev/zero of=/cache/big bs=1M count=10 && touch /foo"}).
+		Sync(ctx)
+	require.NoError(t, err)
+
+	type entry struct {
+		Description    string
+		DiskSpaceBytes int
+		RecordType     string
+		CacheMountKey  string
+	}
+	entrySet := func(args string) (int, []entry) {
+		t.Helper()
+		var res struct {
+			DaggerEngine struct {
+				LocalCache struct {
+					EntrySet struct {
+						EntryCount int
+						Entries    []entry
+					}
+				}
+			}
+		}
+		err := c2.Do(ctx, &dagger.Request{
+			Query: fmt.Sprintf(`{daggerEngine{localCache{entrySet(%s){entryCount entries{description diskSpaceBytes recordType cacheMountKey}}}}}`, args),
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		set := res.DaggerEngine.LocalCache.EntrySet
+		require.Len(t, set.Entries, set.EntryCount)
+		return set.EntryCount, set.Entries
+	}
+
+	count, ents := entrySet(`type: CACHE_MOUNT`)
+	require.Equal(t, 1, count)
+	require.Equal(t, "CACHE_MOUNT", ents[0].RecordType)
+	require.Equal(t, "entry-set-filters", ents[0].CacheMountKey)
+	require.GreaterOrEqual(t, ents[0].DiskSpaceBytes, 10*1024*1024)
+
+	_, ents = entrySet(`description: "pulled from *alpine*"`)
+	require.NotEmpty(t, ents)
+	for _, ent := range ents {
+		require.Equal(t, "IMAGE_LAYER", ent.RecordType)
+		require.Empty(t, ent.CacheMountKey)
+	}
+
+	_, ents = entrySet(`description: "mount / from exec sh -c dd*"`)
+	require.Len(t, ents, 1)
+	require.Equal(t, "EXEC", ents[0].RecordType)
+
+	_, ents = entrySet(`minSize: 10485760`)
+	for _, ent := range ents {
+		require.GreaterOrEqual(t, ent.DiskSpaceBytes, 10*1024*1024)
+	}
+
+	_, ents = entrySet(`sortBy: SIZE, limit: 2`)
+	require.Len(t, ents, 2)
+	require.GreaterOrEqual(t, ents[0].DiskSpaceBytes, ents[1].DiskSpaceBytes)
+
+	count, _ = entrySet(`lastUsedBefore: "1h"`)
+	require.Zero(t, count)
+
+	var paged struct {
+		DaggerEngine struct {
+			LocalCache struct {
+				EntrySet struct {
+					EntryCount int
+					All        []entry
+					Page       []entry
+				}
+			}
+		}
+	}
+	err = c2.Do(ctx, &dagger.Request{
+		Query: `{daggerEngine{localCache{entrySet(sortBy: SIZE){entryCount all: entries{description} page: entries(offset: 1, limit: 2){description}}}}}`,
+	}, &dagger.Response{Data: &paged})
+	require.NoError(t, err)
+	pagedSet := paged.DaggerEngine.LocalCache.EntrySet
+	require.Greater(t, pagedSet.EntryCount, 2)
+	require.Len(t, pagedSet.All, pagedSet.EntryCount)
+	require.Equal(t, pagedSet.All[1:3], pagedSet.Page)
+
+	err = c2.Do(ctx, &dagger.Request{
+		Query: `{daggerEngine{localCache{entrySet(lastUsedBefore: "yesterday"){entryCount}}}}`,
+	}, &dagger.Response{})
+	require.ErrorContains(t, err, `invalid lastUsedBefore "yesterday"`)
+}
+
+func (EngineSuite) TestCacheCLI(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	devEngine := devEngineContainer(c).AsService()
+	clientCtr, err := engineClientContainer(ctx, t, c, devEngine)
+	require.NoError(t, err)
+
+	clientCtr = clientCtr.
+		WithEnvVariable("NO_COLOR", "1").
+		WithExec([]string{"dagger", "query"}, dagger.ContainerWithExecOpts{
+			Stdin: `{container{from(address:"` + alpineImage + `"){withMountedCache(path:"/cache", cache:"cache-cli"){withExec(args:["touch","/cache/foo"]){sync}}}}}`,
+		})
+
+	t.Run("du", func(ctx context.Context, t *testctx.T) {
+		out, err := clientCtr.WithExec([]string{"dagger", "cache", "du", "--json"}).Stdout(ctx)
+		require.NoError(t, err)
+		var usage struct {
+			EntryCount     int
+			DiskSpaceBytes int
+			Types          []struct {
+				Type       string
+				EntryCount int
+			}
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &usage))
+		require.NotZero(t, usage.EntryCount)
+		require.NotZero(t, usage.DiskSpaceBytes)
+		var types []string
+		for _, typ := range usage.Types {
+			types = append(types, typ.Type)
+		}
+		require.Contains(t, types, "CACHE_MOUNT")
+		require.Contains(t, types, "IMAGE_LAYER")
+
+		out, err = clientCtr.WithExec([]string{"dagger", "cache", "du"}).Stdout(ctx)
+		require.NoError(t, err)
+		require.Regexp(t, `(?m)^TYPE\s+ENTRIES\s+SIZE$`, out)
+		require.Regexp(t, `(?m)^cache-mount\s+1\s`, out)
+	})
+
+	t.Run("ls", func(ctx context.Context, t *testctx.T) {
+		out, err := clientCtr.WithExec([]string{"dagger", "cache", "ls", "--filter", "type=cache-mount", "--json"}).Stdout(ctx)
+		require.NoError(t, err)
+		var ents []struct {
+			RecordType    string
+			CacheMountKey string
+		}
+		require.NoError(t, json.Unmarshal([]byte(out), &ents))
+		require.Len(t, ents, 1)
+		require.Equal(t, "CACHE_MOUNT", ents[0].RecordType)
+		require.Equal(t, "cache-cli", ents[0].CacheMountKey)
+
+		out, err = clientCtr.WithExec([]string{"dagger", "cache", "ls", "--sort", "size", "--limit", "1"}).Stdout(ctx)
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(out), "\n")
+		require.Len(t, lines, 2)
+		require.Contains(t, lines[0], "DESCRIPTION")
+	})
+
+	t.Run("prune", func(ctx context.Context, t *testctx.T) {
+		_, err := clientCtr.WithExec([]string{"dagger", "cache", "prune", "--filter", "description=*"}).Sync(ctx)
+		require.ErrorContains(t, err, "only type filters are supported when pruning")
+
+		out, err := clientCtr.WithExec([]string{"dagger", "cache", "prune", "--filter", "type=image-layer", "--older-than", "24h", "--json"}).Stdout(ctx)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"entriesPruned": 0, "bytesFreed": 0}`, out)
+	})
+}
+
+func (EngineSuite) TestLocalCacheGCPolicyConfig(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	engineSvc, err := c.Host().Tunnel(devEngineContainer(c, engineWithExtraConfig(ctx, t, `
+[[gc.policy]]
+  types = ["exec"]
+  keepDuration = "1s"
+`)).AsService()).Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { engineSvc.Stop(ctx) })
+
+	endpoint, err := engineSvc.Endpoint(ctx, dagger.ServiceEndpointOpts{Scheme: "tcp"})
+	require.NoError(t, err)
+
+	c2, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	t.Cleanup(func() { c2.Close() })
+
+	c3, err := dagger.Connect(ctx, dagger.WithRunnerHost(endpoint), dagger.WithLogOutput(testutil.NewTWriter(t)))
+	require.NoError(t, err)
+	_, err = c3.Container().From(alpineImage).
+		WithExec([]string{"touch", "/foo"}).
+		Sync(ctx)
+	require.NoError(t, err)
+	require.NoError(t, c3.Close())
+
+	// automatic gc is time based (currently kicks in 1sec after a session ends but throttled to run at most once a min)
+	const tryCount = 150
+	for i := range tryCount {
+		ents, err := c2.DaggerEngine().LocalCache().EntrySet().Entries(ctx)
+		require.NoError(t, err)
+		var alpineImageEnt, touchFooEnt *cacheEntryVals
+		for _, ent := range ents {
+			entVal := getCacheEntryVals(ctx, t, ent)
+			switch {
+			case strings.HasPrefix(entVal.Description, "pulled from docker.io/library/alpine"):
+				alpineImageEnt = entVal
+			case strings.HasPrefix(entVal.Description, "mount / from exec touch /foo"):
+				touchFooEnt = entVal
+			}
+		}
+		// the policy only applies to exec snapshots
+		require.NotNil(t, alpineImageEnt)
+		if touchFooEnt == nil {
+			break
+		}
+		if i == tryCount-1 {
+			t.Fatal("expected exec snapshot to be garbage collected")
+		}
+		time.Sleep(time.Second)
+	}
+}
+
 func engineConfigWithKeepBytes(keepStorageSetting string) func(context.Context, *testctx.T, bkconfig.Config) bkconfig.Config {
 	return func(ctx context.Context, t *testctx.T, cfg bkconfig.Config) bkconfig.Config {
 		t.Helper()
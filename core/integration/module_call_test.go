@@ -546,6 +546,43 @@ func (m *Test) Insecure(ctx context.Context, token *dagger.Secret) (string, erro
 			_, err := modGen.With(daggerCall("insecure", "--token", "wtf:HUH")).Stdout(ctx)
 			require.ErrorContains(t, err, `unsupported secret arg source: "wtf"`)
 		})
+
+		t.Run("ttl", func(ctx context.Context, t *testctx.T) {
+			modGen := modGen.WithNewFile("rotating.go", `package main
+
+import (
+	"context"
+	"time"
+
+	"dagger/test/internal/dagger"
+)
+
+func (m *Test) Rotated(ctx context.Context, token *dagger.Secret) (bool, error) {
+	first, err := token.Plaintext(ctx)
+	if err != nil {
+		return false, err
+	}
+	time.Sleep(2 * time.Second)
+	second, err := token.Plaintext(ctx)
+	if err != nil {
+		return false, err
+	}
+	return first != second, nil
+}
+`,
+			)
+
+			// every run of the command prints a new value
+			token := "cmd:echo -n $(date +%s%N)"
+
+			out, err := modGen.With(daggerCall("--secret-ttl=1s", "rotated", "--token", token)).Stdout(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "true", out)
+
+			out, err = modGen.With(daggerCall("rotated", "--token", token)).Stdout(ctx)
+			require.NoError(t, err)
+			require.Equal(t, "false", out)
+		})
 	})
 
 	t.Run("cache volume args", func(ctx context.Context, t *testctx.T) {
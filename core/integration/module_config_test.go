@@ -1776,3 +1776,174 @@ func (m *Test) Fn(dir *dagger.Directory) *dagger.Directory {
 	require.NoError(t, err)
 	require.Equal(t, "", strings.TrimSpace(out))
 }
+
+func (ModuleSuite) TestModuleLock(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	const gitURL = "https://github.com/dagger/dagger.git"
+
+	ctr := modInit(t, c, "go", `package main
+
+import "context"
+
+type Test struct{}
+
+func (m *Test) ImageRef(ctx context.Context) (string, error) {
+	return dag.Container().From("`+alpineImage+`").ImageRef(ctx)
+}
+
+func (m *Test) Commit(ctx context.Context) (string, error) {
+	return dag.Git("`+gitURL+`").Tag("v0.9.5").Commit(ctx)
+}
+`)
+	imageAddress := "docker.io/library/" + alpineImage
+
+	ctr = ctr.
+		With(daggerCall("image-ref")).
+		With(daggerCall("commit"))
+
+	lockContents, err := ctr.File(modules.LockFilename).Contents(ctx)
+	require.NoError(t, err)
+	lock, err := modules.ParseModuleLock([]byte(lockContents))
+	require.NoError(t, err)
+
+	require.Len(t, lock.Images, 1)
+	require.Equal(t, imageAddress, lock.Images[0].Address)
+	imageDigest := lock.Images[0].Digest
+	require.NotEmpty(t, imageDigest)
+
+	commit, ok := lock.GitCommit(gitURL, "v0.9.5")
+	require.True(t, ok)
+	require.NotEmpty(t, commit)
+
+	// pin the tag to another commit, which calls should resolve to instead
+	otherCommit, err := c.Git(gitURL).Tag("v0.9.4").Commit(ctx)
+	require.NoError(t, err)
+
+	t.Run("resolves as pinned", func(ctx context.Context, t *testctx.T) {
+		out, err := ctr.With(daggerCall("image-ref")).Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, imageAddress+"@"+imageDigest, strings.TrimSpace(out))
+
+		pinned := *lock
+		pinned.Images = nil
+		pinned.Git = []*modules.ModuleLockGitRef{{URL: gitURL, Ref: "v0.9.5", Commit: otherCommit}}
+		pinnedContents, err := pinned.Marshal()
+		require.NoError(t, err)
+		out, err = ctr.
+			WithNewFile(modules.LockFilename, string(pinnedContents)).
+			With(daggerCall("commit")).
+			Stdout(ctx)
+		require.NoError(t, err)
+		require.Equal(t, otherCommit, strings.TrimSpace(out))
+	})
+
+	t.Run("update", func(ctx context.Context, t *testctx.T) {
+		stale := *lock
+		stale.Git = []*modules.ModuleLockGitRef{{URL: gitURL, Ref: "v0.9.5", Commit: otherCommit}}
+		// downloads made with headers can't be made again without them
+		const authURL = "https://example.invalid/private"
+		stale.HTTP = []*modules.ModuleLockHTTP{{URL: authURL, Digest: imageDigest, Headers: true}}
+		staleContents, err := stale.Marshal()
+		require.NoError(t, err)
+
+		updated := ctr.
+			WithNewFile(modules.LockFilename, string(staleContents)).
+			With(daggerExec("lock", "update"))
+		out, err := updated.Stdout(ctx)
+		require.NoError(t, err)
+		require.Contains(t, out, "Updated 1 of 3 entries")
+		stderr, err := updated.Stderr(ctx)
+		require.NoError(t, err)
+		require.Contains(t, stderr, "Skipped "+authURL)
+
+		lockContents, err := updated.File(modules.LockFilename).Contents(ctx)
+		require.NoError(t, err)
+		updatedLock, err := modules.ParseModuleLock([]byte(lockContents))
+		require.NoError(t, err)
+		updatedCommit, _ := updatedLock.GitCommit(gitURL, "v0.9.5")
+		require.Equal(t, commit, updatedCommit)
+	})
+}
+
+func (ModuleSuite) TestModuleLockDependencies(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	const gitURL = "https://github.com/dagger/dagger.git"
+
+	commit, err := c.Git(gitURL).Tag("v0.9.5").Commit(ctx)
+	require.NoError(t, err)
+	otherCommit, err := c.Git(gitURL).Tag("v0.9.4").Commit(ctx)
+	require.NoError(t, err)
+
+	depSrc := func(name string) string {
+		return fmt.Sprintf(`package main
+
+import "context"
+
+type %[1]s struct{}
+
+func (m *%[1]s) Commit(ctx context.Context) (string, error) {
+	return dag.Git(%[2]q).Tag("v0.9.5").Commit(ctx)
+}
+`, name, gitURL)
+	}
+
+	// dep-a pins the tag to another commit in its own lock, while dep-b
+	// resolves it, so each must get its own commit for the same call
+	pinned := &modules.ModuleLock{
+		Git: []*modules.ModuleLockGitRef{{URL: gitURL, Ref: "v0.9.5", Commit: otherCommit}},
+	}
+	pinnedContents, err := pinned.Marshal()
+	require.NoError(t, err)
+
+	ctr := goGitBase(t, c).
+		WithWorkdir("/work/dep-a").
+		With(daggerExec("init", "--source=.", "--name=dep-a", "--sdk=go")).
+		WithNewFile("/work/dep-a/main.go", depSrc("DepA")).
+		WithNewFile("/work/dep-a/"+modules.LockFilename, string(pinnedContents)).
+		WithWorkdir("/work/dep-b").
+		With(daggerExec("init", "--source=.", "--name=dep-b", "--sdk=go")).
+		WithNewFile("/work/dep-b/main.go", depSrc("DepB")).
+		WithWorkdir("/work/main").
+		With(daggerExec("init", "--source=.", "--name=test", "--sdk=go")).
+		With(daggerExec("install", "../dep-a")).
+		With(daggerExec("install", "../dep-b")).
+		WithNewFile("/work/main/main.go", `package main
+
+import "context"
+
+type Test struct{}
+
+func (m *Test) Commits(ctx context.Context) (string, error) {
+	a, err := dag.DepA().Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+	b, err := dag.DepB().Commit(ctx)
+	if err != nil {
+		return "", err
+	}
+	return a + " " + b, nil
+}
+`).
+		With(daggerCall("commits"))
+
+	out, err := ctr.Stdout(ctx)
+	require.NoError(t, err)
+	require.Equal(t, otherCommit+" "+commit, strings.TrimSpace(out))
+
+	t.Run("writes dependency locks", func(ctx context.Context, t *testctx.T) {
+		lockContents, err := ctr.File("/work/dep-b/" + modules.LockFilename).Contents(ctx)
+		require.NoError(t, err)
+		lock, err := modules.ParseModuleLock([]byte(lockContents))
+		require.NoError(t, err)
+		depCommit, ok := lock.GitCommit(gitURL, "v0.9.5")
+		require.True(t, ok)
+		require.Equal(t, commit, depCommit)
+
+		lockContents, err = ctr.File("/work/dep-a/" + modules.LockFilename).Contents(ctx)
+		require.NoError(t, err)
+		require.Equal(t, string(pinnedContents), lockContents)
+	})
+}
@@ -5,10 +5,14 @@ import (
 	"context"
 	_ "embed"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"dagger.io/dagger"
 	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/internal/testutil"
 	"github.com/dagger/dagger/testctx"
@@ -135,6 +139,34 @@ func (SecretSuite) TestSet(ctx context.Context, t *testctx.T) {
 	require.Equal(t, secretName, name)
 }
 
+func (SecretSuite) TestSetWithTTL(ctx context.Context, t *testctx.T) {
+	// the SDK connects through dagger session, which resolves the secret's
+	// source again once it expires
+	c := connect(ctx, t)
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("first"), 0o600))
+
+	id, err := c.SetSecret("rotating", "first", dagger.SetSecretOpts{
+		TTL:    "1s",
+		Source: "file:" + tokenPath,
+	}).ID(ctx)
+	require.NoError(t, err)
+	// load it by ID, so reading it doesn't set it again
+	s := c.LoadSecretFromID(id)
+
+	plaintext, err := s.Plaintext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "first", plaintext)
+
+	require.NoError(t, os.WriteFile(tokenPath, []byte("second"), 0o600))
+	time.Sleep(2 * time.Second)
+
+	plaintext, err = s.Plaintext(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "second", plaintext)
+}
+
 func (SecretSuite) TestUnsetVariable(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
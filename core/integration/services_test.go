@@ -32,6 +32,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/moby/buildkit/identity"
 	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 	"golang.org/x/sync/errgroup"
 
 	"dagger.io/dagger"
@@ -246,6 +247,286 @@ func (ServiceSuite) TestPortsSkipHealthCheck(ctx context.Context, t *testctx.T)
 	})
 }
 
+func (ServiceSuite) TestHealthchecks(ctx context.Context, t *testctx.T) {
+	t.Run("http healthcheck waits for expected status", func(ctx context.Context, t *testctx.T) {
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "python") {
+						withExposedPort(port: 8000) {
+							withExec(args: ["sh", "-c", "python -m http.server 8000 & sleep 3; mkdir ready; wait"]) {
+								asService {
+									withHTTPHealthcheck(port: 8000, path: "/ready/", interval: "500ms") {
+										start
+									}
+								}
+							}
+						}
+					}
+				}
+			}`, nil, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("http healthcheck fails after retries", func(ctx context.Context, t *testctx.T) {
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "python") {
+						withExposedPort(port: 8000) {
+							withExec(args: ["python", "-m", "http.server", "8000"]) {
+								asService {
+									withHTTPHealthcheck(port: 8000, path: "/never", interval: "100ms", retries: 2) {
+										start
+									}
+								}
+							}
+						}
+					}
+				}
+			}`, nil, nil)
+		require.ErrorContains(t, err, "failed after 3 attempts")
+		require.ErrorContains(t, err, "expected status 200, got 404 Not Found")
+	})
+
+	t.Run("exec healthcheck waits for success", func(ctx context.Context, t *testctx.T) {
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sh", "-c", "sleep 3; touch /tmp/ready; sleep infinity"]) {
+							asService {
+								withExecHealthcheck(args: ["test", "-f", "/tmp/ready"], interval: "500ms") {
+									start
+								}
+							}
+						}
+					}
+				}
+			}`, nil, nil)
+		require.NoError(t, err)
+	})
+
+	t.Run("exec healthcheck reports output", func(ctx context.Context, t *testctx.T) {
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sleep", "infinity"]) {
+							asService {
+								withExecHealthcheck(args: ["sh", "-c", "echo db not ready >&2; exit 1"], interval: "100ms", retries: 1) {
+									start
+								}
+							}
+						}
+					}
+				}
+			}`, nil, nil)
+		require.ErrorContains(t, err, "failed after 2 attempts")
+		require.ErrorContains(t, err, "db not ready")
+	})
+
+	t.Run("exec healthcheck times out", func(ctx context.Context, t *testctx.T) {
+		err := testutil.Query(t,
+			`{
+				container {
+					from(address: "`+alpineImage+`") {
+						withExec(args: ["sleep", "infinity"]) {
+							asService {
+								withExecHealthcheck(args: ["sleep", "10"], timeout: "500ms", retries: 0) {
+									start
+								}
+							}
+						}
+					}
+				}
+			}`, nil, nil)
+		require.ErrorContains(t, err, "timed out after 500ms")
+	})
+}
+
+func (ServiceSuite) TestRestartPolicy(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	startAndWatch := func(ctx context.Context, t *testctx.T, svc string, want string) {
+		var started struct {
+			LoadServiceFromID struct {
+				Start string
+			}
+		}
+		err := c.Do(ctx, &dagger.Request{
+			Query:     `query($svc: ServiceID!) { loadServiceFromID(id: $svc) { start } }`,
+			Variables: map[string]any{"svc": svc},
+		}, &dagger.Response{Data: &started})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			var res struct {
+				LoadServiceFromID struct {
+					Status string
+				}
+			}
+			err := c.Do(ctx, &dagger.Request{
+				Query:     `query($svc: ServiceID!) { loadServiceFromID(id: $svc) { status } }`,
+				Variables: map[string]any{"svc": svc},
+			}, &dagger.Response{Data: &res})
+			require.NoError(t, err)
+			return res.LoadServiceFromID.Status == want
+		}, time.Minute, 100*time.Millisecond)
+	}
+
+	serviceID := func(ctx context.Context, t *testctx.T, ctr *dagger.Container, args string) string {
+		ctrID, err := ctr.ID(ctx)
+		require.NoError(t, err)
+		var res struct {
+			LoadContainerFromID struct {
+				AsService struct {
+					ID string
+				}
+			}
+		}
+		err = c.Do(ctx, &dagger.Request{
+			Query:     `query($ctr: ContainerID!) { loadContainerFromID(id: $ctr) { asService(` + args + `) { id } } }`,
+			Variables: map[string]any{"ctr": ctrID},
+		}, &dagger.Response{Data: &res})
+		require.NoError(t, err)
+		return res.LoadContainerFromID.AsService.ID
+	}
+
+	t.Run("never", func(ctx context.Context, t *testctx.T) {
+		ctr := c.Container().From(alpineImage).
+			WithEnvVariable("BUST", identity.NewID()).
+			WithExec([]string{"sh", "-c", "sleep 1; echo db died >&2; exit 3"})
+		svc := serviceID(ctx, t, ctr, "restartPolicy: NEVER")
+		startAndWatch(ctx, t, svc, "CRASHED")
+	})
+
+	t.Run("on failure", func(ctx context.Context, t *testctx.T) {
+		cache := c.CacheVolume("restart-count-" + identity.NewID())
+		ctr := c.Container().From(alpineImage).
+			WithMountedCache("/count", cache).
+			WithExec([]string{"sh", "-c", "echo x >> /count/starts; [ $(wc -l < /count/starts) -ge 3 ] && sleep infinity; exit 1"})
+		svc := serviceID(ctx, t, ctr, "restartPolicy: ON_FAILURE")
+		startAndWatch(ctx, t, svc, "RUNNING")
+
+		require.Eventually(t, func() bool {
+			out, err := c.Container().From(alpineImage).
+				WithEnvVariable("BUST", identity.NewID()).
+				WithMountedCache("/count", cache).
+				WithExec([]string{"sh", "-c", "wc -l < /count/starts"}).
+				Stdout(ctx)
+			require.NoError(t, err)
+			return strings.TrimSpace(out) == "3"
+		}, time.Minute, time.Second)
+	})
+
+	t.Run("on failure with max retries", func(ctx context.Context, t *testctx.T) {
+		ctr := c.Container().From(alpineImage).
+			WithEnvVariable("BUST", identity.NewID()).
+			WithExec([]string{"sh", "-c", "sleep 1; exit 1"})
+		svc := serviceID(ctx, t, ctr, "restartPolicy: ON_FAILURE, maxRetries: 2")
+		startAndWatch(ctx, t, svc, "CRASHED")
+	})
+
+	t.Run("max retries requires on failure", func(ctx context.Context, t *testctx.T) {
+		ctrID, err := c.Container().From(alpineImage).WithExec([]string{"true"}).ID(ctx)
+		require.NoError(t, err)
+		err = c.Do(ctx, &dagger.Request{
+			Query:     `query($ctr: ContainerID!) { loadContainerFromID(id: $ctr) { asService(restartPolicy: ALWAYS, maxRetries: 2) { id } } }`,
+			Variables: map[string]any{"ctr": ctrID},
+		}, &dagger.Response{})
+		require.ErrorContains(t, err, "max retries is only supported with the ON_FAILURE restart policy")
+	})
+}
+
+func (ServiceSuite) TestCrashedServiceReportsExit(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	// the service exits before it's healthy, so starting it fails with its
+	// exit code and last lines of output
+	_, err := c.Container().From(alpineImage).
+		WithEnvVariable("BUST", identity.NewID()).
+		WithExposedPort(5432).
+		WithExec([]string{"sh", "-c", "echo starting; echo FATAL: db died >&2; exit 3"}).
+		AsService().
+		Start(ctx)
+	require.ErrorContains(t, err, "service exited with code 3")
+	require.ErrorContains(t, err, "FATAL: db died")
+}
+
+func (ServiceSuite) TestLogsAndExec(ctx context.Context, t *testctx.T) {
+	c := connect(ctx, t)
+
+	srv := c.Container().From(alpineImage).
+		WithEnvVariable("BUST", identity.NewID()).
+		WithEnvVariable("GREETING", "hello from the service").
+		WithExec([]string{"sh", "-c", "echo booted; echo warming up >&2; touch /tmp/ready; sleep infinity"}).
+		AsService()
+
+	srvID, err := srv.ID(ctx)
+	require.NoError(t, err)
+
+	query := func(ctx context.Context, fields string, res any) error {
+		return c.Do(ctx, &dagger.Request{
+			Query:     `query($svc: ServiceID!) { loadServiceFromID(id: $svc) { ` + fields + ` } }`,
+			Variables: map[string]any{"svc": srvID},
+		}, &dagger.Response{Data: res})
+	}
+
+	t.Run("not running", func(ctx context.Context, t *testctx.T) {
+		err := query(ctx, `logs`, nil)
+		require.ErrorContains(t, err, "is not running")
+		err = query(ctx, `exec(args: ["true"])`, nil)
+		require.ErrorContains(t, err, "is not running")
+	})
+
+	_, err = srv.Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { srv.Stop(context.Background()) })
+
+	t.Run("logs", func(ctx context.Context, t *testctx.T) {
+		require.Eventually(t, func() bool {
+			var res struct {
+				LoadServiceFromID struct {
+					Logs string
+				}
+			}
+			require.NoError(t, query(ctx, `logs`, &res))
+			logs := res.LoadServiceFromID.Logs
+			return strings.Contains(logs, "booted\n") && strings.Contains(logs, "warming up\n")
+		}, time.Minute, 100*time.Millisecond)
+
+		var res struct {
+			LoadServiceFromID struct {
+				Logs string
+			}
+		}
+		require.NoError(t, query(ctx, `logs(since: "`+time.Now().Add(time.Hour).Format(time.RFC3339)+`")`, &res))
+		require.Empty(t, res.LoadServiceFromID.Logs)
+
+		err := query(ctx, `logs(since: "yesterday")`, nil)
+		require.ErrorContains(t, err, `invalid since "yesterday"`)
+	})
+
+	t.Run("exec", func(ctx context.Context, t *testctx.T) {
+		var res struct {
+			LoadServiceFromID struct {
+				Exec string
+			}
+		}
+		err := query(ctx, `exec(args: ["sh", "-c", "test -f /tmp/ready && echo $GREETING"])`, &res)
+		require.NoError(t, err)
+		require.Equal(t, "hello from the service\n", res.LoadServiceFromID.Exec)
+
+		err = query(ctx, `exec(args: ["sh", "-c", "echo nope >&2; exit 4"])`, nil)
+		var gqlErr *gqlerror.Error
+		require.ErrorAs(t, err, &gqlErr)
+		require.Equal(t, "EXEC_ERROR", gqlErr.Extensions["_type"])
+		require.EqualValues(t, 4, gqlErr.Extensions["exitCode"])
+		require.Equal(t, "nope", gqlErr.Extensions["stderr"])
+	})
+}
+
 func (ContainerSuite) TestPortLifecycle(ctx context.Context, t *testctx.T) {
 	c := connect(ctx, t)
 
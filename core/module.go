@@ -63,6 +63,9 @@ type Module struct {
 
 	// InstanceID is the ID of the initialized module.
 	InstanceID *call.ID
+
+	// Lock pins what the module's code resolves, and is shared by its clones.
+	Lock *ModuleLock
 }
 
 func (*Module) Type() *ast.Type {
@@ -0,0 +1,157 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/dagger/dagger/core/modules"
+)
+
+// ModuleLock pins what a module's code resolves to what it resolved on earlier
+// runs, as recorded in the module's dagger.lock file, and records what it
+// resolves for the first time.
+//
+// The lock is consulted by Container.from, git refs and http within the
+// module, which select what it pins explicitly, e.g. commit(id) for a git
+// tag, so that what they return is cached by the pinned value rather than by
+// the calling module.
+type ModuleLock struct {
+	mu   sync.Mutex
+	lock *modules.ModuleLock
+}
+
+func NewModuleLock(lock *modules.ModuleLock) *ModuleLock {
+	if lock == nil {
+		lock = &modules.ModuleLock{}
+	}
+	return &ModuleLock{lock: lock}
+}
+
+func (lock *ModuleLock) ImageDigest(address, platform string) (string, bool) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	return lock.lock.ImageDigest(address, platform)
+}
+
+func (lock *ModuleLock) SetImageDigest(address, platform, digest string) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	lock.lock.SetImageDigest(address, platform, digest)
+}
+
+func (lock *ModuleLock) GitCommit(url, ref string) (string, bool) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	return lock.lock.GitCommit(url, ref)
+}
+
+func (lock *ModuleLock) SetGitCommit(url, ref, commit string) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	lock.lock.SetGitCommit(url, ref, commit)
+}
+
+func (lock *ModuleLock) HTTPDigest(url string) (string, bool) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	return lock.lock.HTTPDigest(url)
+}
+
+func (lock *ModuleLock) SetHTTPDigest(url, digest string, headers bool) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	lock.lock.SetHTTPDigest(url, digest, headers)
+}
+
+// Contents returns the contents of the dagger.lock file recording everything
+// pinned so far, or nil if nothing is.
+func (lock *ModuleLock) Contents() ([]byte, error) {
+	lock.mu.Lock()
+	defer lock.mu.Unlock()
+	if lock.lock.IsEmpty() {
+		return nil, nil
+	}
+	return lock.lock.Marshal()
+}
+
+// LockedGitCommit returns the commit the named ref of the repository is pinned
+// to within a module: the one recorded in the module's lock, or else the one
+// it resolves to now, which is recorded. An empty name is HEAD.
+//
+// It returns an empty string outside of modules, and for refs that aren't
+// pinned, i.e. commits and refs of local repositories.
+func LockedGitCommit(ctx context.Context, repo *GitRepository, name string) (string, error) {
+	if repo.Local != nil || gitCommitRe.MatchString(name) {
+		return "", nil
+	}
+	lock, err := CurrentModuleLock(ctx, repo.Query)
+	if err != nil || lock == nil {
+		return "", err
+	}
+	lockName := name
+	if lockName == "" {
+		lockName = "HEAD"
+	}
+	if commit, ok := lock.GitCommit(repo.URL, lockName); ok {
+		return commit, nil
+	}
+	ref := &GitRef{
+		Query: repo.Query,
+		Ref:   name,
+		Repo:  repo,
+	}
+	commit, err := ref.Commit(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s of %s: %w", lockName, repo.URL, err)
+	}
+	lock.SetGitCommit(repo.URL, lockName, commit)
+	return commit, nil
+}
+
+// CurrentModuleLock returns the lock of the module whose code the current
+// client runs, or nil outside of modules.
+func CurrentModuleLock(ctx context.Context, q *Query) (*ModuleLock, error) {
+	mod, err := q.CurrentModule(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNoCurrentModule) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mod.Lock, nil
+}
+
+// ModuleLock loads the module's dagger.lock file, returning false if it has
+// none.
+func (src *ModuleSource) ModuleLock(ctx context.Context) (*modules.ModuleLock, bool, error) {
+	contextDir, err := src.ContextDirectory()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get context directory: %w", err)
+	}
+	if contextDir.Self == nil {
+		return nil, false, nil
+	}
+
+	rootSubpath, err := src.SourceRootSubpath()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get source root subpath: %w", err)
+	}
+
+	lockFile, err := contextDir.Self.File(ctx, filepath.Join(rootSubpath, modules.LockFilename))
+	if err != nil {
+		// the module hasn't pinned anything yet
+		return nil, false, nil
+	}
+	lockBytes, err := lockFile.Contents(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read module lock file: %w", err)
+	}
+	lock, err := modules.ParseModuleLock(lockBytes)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode module lock: %w", err)
+	}
+	return lock, true, nil
+}
@@ -0,0 +1,167 @@
+package modules
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// LockFilename is the name of the module lock file, next to its config file.
+const LockFilename = "dagger.lock"
+
+// ModuleLock pins what a module's code resolved on earlier runs, so that it
+// resolves the same on later runs, as loaded from a dagger.lock file.
+type ModuleLock struct {
+	// Image addresses passed to Container.from, pinned to digests.
+	Images []*ModuleLockImage `json:"images,omitempty"`
+
+	// Git refs, pinned to commits.
+	Git []*ModuleLockGitRef `json:"git,omitempty"`
+
+	// URLs passed to http, pinned to the digests of their contents.
+	HTTP []*ModuleLockHTTP `json:"http,omitempty"`
+}
+
+type ModuleLockImage struct {
+	// The normalized image address, e.g. docker.io/library/alpine:latest.
+	Address string `json:"address"`
+
+	// The platform the image was resolved for, e.g. linux/amd64.
+	Platform string `json:"platform"`
+
+	Digest string `json:"digest"`
+}
+
+type ModuleLockGitRef struct {
+	URL string `json:"url"`
+
+	// The ref, e.g. a branch or tag, or HEAD.
+	Ref string `json:"ref"`
+
+	Commit string `json:"commit"`
+}
+
+type ModuleLockHTTP struct {
+	URL string `json:"url"`
+
+	Digest string `json:"digest"`
+
+	// Whether the download sent headers or an authHeader, which aren't
+	// recorded since they may be secret.
+	Headers bool `json:"headers,omitempty"`
+}
+
+// ParseModuleLock parses the contents of a dagger.lock file.
+func ParseModuleLock(data []byte) (*ModuleLock, error) {
+	var lock ModuleLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("unmarshal module lock: %w", err)
+	}
+	return &lock, nil
+}
+
+// Marshal encodes the lock as the contents of a dagger.lock file, with entries
+// sorted so that it's stable.
+func (lock *ModuleLock) Marshal() ([]byte, error) {
+	sort.Slice(lock.Images, func(i, j int) bool {
+		if lock.Images[i].Address != lock.Images[j].Address {
+			return lock.Images[i].Address < lock.Images[j].Address
+		}
+		return lock.Images[i].Platform < lock.Images[j].Platform
+	})
+	sort.Slice(lock.Git, func(i, j int) bool {
+		if lock.Git[i].URL != lock.Git[j].URL {
+			return lock.Git[i].URL < lock.Git[j].URL
+		}
+		return lock.Git[i].Ref < lock.Git[j].Ref
+	})
+	sort.Slice(lock.HTTP, func(i, j int) bool {
+		return lock.HTTP[i].URL < lock.HTTP[j].URL
+	})
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal module lock: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// IsEmpty returns true if nothing is pinned.
+func (lock *ModuleLock) IsEmpty() bool {
+	return len(lock.Images) == 0 && len(lock.Git) == 0 && len(lock.HTTP) == 0
+}
+
+func (lock *ModuleLock) ImageDigest(address, platform string) (string, bool) {
+	for _, image := range lock.Images {
+		if image.Address == address && image.Platform == platform {
+			return image.Digest, true
+		}
+	}
+	return "", false
+}
+
+// SetImageDigest pins the image address on the platform to the digest,
+// returning true if the lock changed.
+func (lock *ModuleLock) SetImageDigest(address, platform, digest string) bool {
+	for _, image := range lock.Images {
+		if image.Address == address && image.Platform == platform {
+			if image.Digest == digest {
+				return false
+			}
+			image.Digest = digest
+			return true
+		}
+	}
+	lock.Images = append(lock.Images, &ModuleLockImage{Address: address, Platform: platform, Digest: digest})
+	return true
+}
+
+func (lock *ModuleLock) GitCommit(url, ref string) (string, bool) {
+	for _, gitRef := range lock.Git {
+		if gitRef.URL == url && gitRef.Ref == ref {
+			return gitRef.Commit, true
+		}
+	}
+	return "", false
+}
+
+// SetGitCommit pins the git ref to the commit, returning true if the lock
+// changed.
+func (lock *ModuleLock) SetGitCommit(url, ref, commit string) bool {
+	for _, gitRef := range lock.Git {
+		if gitRef.URL == url && gitRef.Ref == ref {
+			if gitRef.Commit == commit {
+				return false
+			}
+			gitRef.Commit = commit
+			return true
+		}
+	}
+	lock.Git = append(lock.Git, &ModuleLockGitRef{URL: url, Ref: ref, Commit: commit})
+	return true
+}
+
+func (lock *ModuleLock) HTTPDigest(url string) (string, bool) {
+	for _, http := range lock.HTTP {
+		if http.URL == url {
+			return http.Digest, true
+		}
+	}
+	return "", false
+}
+
+// SetHTTPDigest pins the URL, downloaded with or without headers, to the
+// digest of its contents, returning true if the lock changed.
+func (lock *ModuleLock) SetHTTPDigest(url, digest string, headers bool) bool {
+	for _, http := range lock.HTTP {
+		if http.URL == url {
+			if http.Digest == digest && http.Headers == headers {
+				return false
+			}
+			http.Digest = digest
+			http.Headers = headers
+			return true
+		}
+	}
+	lock.HTTP = append(lock.HTTP, &ModuleLockHTTP{URL: url, Digest: digest, Headers: headers})
+	return true
+}
@@ -366,6 +366,11 @@ type LocalModuleSource struct {
 	RootSubpath string `field:"true" doc:"The path to the root of the module source under the context directory. This directory contains its configuration file. It also contains its source code (possibly as a subdirectory)."`
 
 	ContextDirectory dagql.Nullable[dagql.Instance[*Directory]] `field:"true" doc:"The directory containing everything needed to load load and use the module."`
+
+	// ContextIdentity identifies the context directory across versions and
+	// edits when it was loaded from the caller's host, e.g. by its git remote,
+	// or is empty otherwise.
+	ContextIdentity string
 }
 
 func (src *LocalModuleSource) Type() *ast.Type {
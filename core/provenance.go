@@ -0,0 +1,181 @@
+package core
+
+import (
+	"encoding/json"
+	"regexp"
+
+	"github.com/distribution/reference"
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/moby/buildkit/util/purl"
+
+	"github.com/dagger/dagger/dagql/call"
+	"github.com/dagger/dagger/engine"
+)
+
+const (
+	// ProvenanceBuilderID identifies the Dagger Engine as the builder in SLSA
+	// provenance.
+	ProvenanceBuilderID = "https://dagger.io/engine"
+
+	// ProvenanceBuildType identifies SLSA provenance whose invocation is a
+	// dagql call.
+	ProvenanceBuildType = "https://dagger.io/dagql/call@v1"
+)
+
+// ImageProvenance returns a SLSA provenance predicate for an image built by
+// the dagql call with the given ID.
+//
+// The encoded ID is recorded as the invocation's parameters, so that the call
+// can be inspected and replayed, and the images, git repositories and HTTP
+// resources it loads are recorded as materials. Materials loaded in other ways,
+// e.g. by modules' functions, aren't.
+func ImageProvenance(id *call.ID, platform Platform) ([]byte, error) {
+	encoded, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(slsa02.ProvenancePredicate{
+		Builder: common.ProvenanceBuilder{
+			ID: ProvenanceBuilderID,
+		},
+		BuildType: ProvenanceBuildType,
+		Invocation: slsa02.ProvenanceInvocation{
+			ConfigSource: slsa02.ConfigSource{
+				EntryPoint: id.Path(),
+			},
+			Parameters: map[string]string{
+				"id":     encoded,
+				"digest": id.Digest().String(),
+			},
+			Environment: map[string]string{
+				"engineVersion": engine.Version,
+				"platform":      platform.Format(),
+			},
+		},
+		Metadata: &slsa02.ProvenanceMetadata{
+			BuildInvocationID: id.Digest().String(),
+			Completeness: slsa02.ProvenanceComplete{
+				Parameters: true,
+			},
+		},
+		Materials: provenanceMaterials(id),
+	})
+}
+
+var gitCommitRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// provenanceMaterials returns the images, git repositories and HTTP resources
+// loaded by the calls in an ID, including those in its arguments.
+func provenanceMaterials(id *call.ID) []common.ProvenanceMaterial {
+	materials := []common.ProvenanceMaterial{}
+	seenIDs := map[string]bool{}
+	seenURIs := map[string]bool{}
+	add := func(material common.ProvenanceMaterial) {
+		if !seenURIs[material.URI] {
+			seenURIs[material.URI] = true
+			materials = append(materials, material)
+		}
+	}
+
+	var walkID func(*call.ID)
+	var walkLiteral func(call.Literal)
+	walkID = func(id *call.ID) {
+		for ; id != nil; id = id.Receiver() {
+			if seenIDs[id.Digest().String()] {
+				return
+			}
+			seenIDs[id.Digest().String()] = true
+
+			switch id.Field() {
+			case "from", "__internalFrom":
+				if addr, ok := stringArg(id, "address"); ok {
+					if material, ok := imageMaterial(addr); ok {
+						add(material)
+					}
+				}
+			case "git", "http", "__internalHTTP":
+				if url, ok := stringArg(id, "url"); ok {
+					add(common.ProvenanceMaterial{URI: url})
+				}
+			case "commit", "branch", "tag", "ref", "__internalRef":
+				if id.Receiver().Field() != "git" {
+					break
+				}
+				url, ok := stringArg(id.Receiver(), "url")
+				if !ok {
+					break
+				}
+				name, ok := stringArg(id, "id")
+				if !ok {
+					name, ok = stringArg(id, "name")
+				}
+				if !ok || name == "" {
+					break
+				}
+				material := common.ProvenanceMaterial{URI: url + "#" + name}
+				if gitCommitRe.MatchString(name) {
+					material.Digest = common.DigestSet{"sha1": name}
+				}
+				add(material)
+			}
+
+			for _, arg := range id.Args() {
+				walkLiteral(arg.Value())
+			}
+			if mod := id.Module(); mod != nil {
+				walkID(mod.ID())
+			}
+		}
+	}
+	walkLiteral = func(lit call.Literal) {
+		switch lit := lit.(type) {
+		case *call.LiteralID:
+			walkID(lit.Value())
+		case *call.LiteralList:
+			lit.Range(func(_ int, v call.Literal) error {
+				walkLiteral(v)
+				return nil
+			})
+		case *call.LiteralObject:
+			lit.Range(func(_ int, _ string, v call.Literal) error {
+				walkLiteral(v)
+				return nil
+			})
+		}
+	}
+	walkID(id)
+	return materials
+}
+
+func stringArg(id *call.ID, name string) (string, bool) {
+	for _, arg := range id.Args() {
+		if arg.Name() != name {
+			continue
+		}
+		lit, ok := arg.Value().(*call.LiteralString)
+		if !ok {
+			return "", false
+		}
+		return lit.Value(), true
+	}
+	return "", false
+}
+
+// imageMaterial returns a material for an image reference, with its digest
+// if it's pinned to one.
+func imageMaterial(addr string) (common.ProvenanceMaterial, bool) {
+	uri, err := purl.RefToPURL("docker", addr, nil)
+	if err != nil {
+		return common.ProvenanceMaterial{}, false
+	}
+	material := common.ProvenanceMaterial{URI: uri}
+	if ref, err := reference.ParseNormalizedNamed(addr); err == nil {
+		if digested, ok := ref.(reference.Digested); ok {
+			material.Digest = common.DigestSet{
+				digested.Digest().Algorithm().String(): digested.Digest().Encoded(),
+			}
+		}
+	}
+	return material, true
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/common"
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	"github.com/stretchr/testify/require"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/dagger/dagger/dagql/call"
+)
+
+func TestImageProvenance(t *testing.T) {
+	typ := func(name string) *ast.Type {
+		return &ast.Type{NamedType: name, NonNull: true}
+	}
+	str := func(name, value string) *call.Argument {
+		return call.NewArgument(name, call.NewLiteralString(value))
+	}
+	const alpineDigest = "sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5"
+	const commit = "c80ac2c13df7d573a069938e01ca13f7a81f0345"
+
+	src := call.New().
+		Append(typ("GitRepository"), "git", "", nil, false, 0, str("url", "https://github.com/dagger/dagger")).
+		Append(typ("GitRef"), "commit", "", nil, false, 0, str("id", commit)).
+		Append(typ("Directory"), "tree", "", nil, false, 0)
+	base := call.New().
+		Append(typ("Container"), "container", "", nil, false, 0).
+		Append(typ("Container"), "__internalFrom", "", nil, false, 0, str("address", "alpine@"+alpineDigest))
+	id := base.
+		Append(typ("Container"), "withDirectory", "", nil, false, 0,
+			str("path", "/src"),
+			call.NewArgument("directory", call.NewLiteralID(src))).
+		Append(typ("Container"), "withDirectory", "", nil, false, 0,
+			str("path", "/src2"),
+			call.NewArgument("directory", call.NewLiteralID(src)))
+
+	content, err := ImageProvenance(id, Platform{OS: "linux", Architecture: "amd64"})
+	require.NoError(t, err)
+	var pred slsa02.ProvenancePredicate
+	require.NoError(t, json.Unmarshal(content, &pred))
+
+	require.Equal(t, ProvenanceBuilderID, pred.Builder.ID)
+	require.Equal(t, ProvenanceBuildType, pred.BuildType)
+	require.Equal(t, id.Path(), pred.Invocation.ConfigSource.EntryPoint)
+
+	params, ok := pred.Invocation.Parameters.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, id.Digest().String(), params["digest"])
+	var decoded call.ID
+	require.NoError(t, decoded.Decode(params["id"].(string)))
+	require.Equal(t, id.Digest(), decoded.Digest())
+
+	require.Equal(t, []common.ProvenanceMaterial{
+		{
+			URI:    "https://github.com/dagger/dagger#" + commit,
+			Digest: common.DigestSet{"sha1": commit},
+		},
+		{URI: "https://github.com/dagger/dagger"},
+		{
+			URI:    "pkg:docker/alpine?digest=" + alpineDigest,
+			Digest: common.DigestSet{"sha256": alpineDigest[len("sha256:"):]},
+		},
+	}, pred.Materials)
+}
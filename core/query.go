@@ -36,6 +36,10 @@ type Server interface {
 	// If the current client is coming from a function, return the function call metadata
 	CurrentFunctionCall(context.Context) (*FunctionCall, error)
 
+	// If the current client is coming from a function, return the module of the client that called
+	// that function
+	CallerModule(context.Context) (*Module, error)
+
 	// Return the list of deps being served to the current client
 	CurrentServedDeps(context.Context) (*ModDeps, error)
 
@@ -43,6 +47,9 @@ type Server interface {
 	// invoked by the user)
 	MainClientCallerID(context.Context) (string, error)
 
+	// The stable ID of the main client caller, which persists across its sessions
+	MainClientStableID(context.Context) (string, error)
+
 	// The default deps of every user module (currently just core)
 	DefaultDeps(context.Context) (*ModDeps, error)
 
@@ -81,11 +88,11 @@ type Server interface {
 	// The lease manager for the engine as a whole
 	LeaseManager() *leaseutil.Manager
 
-	// Return all the cache entries in the local cache. No support for filtering yet.
+	// Return all the cache entries in the local cache.
 	EngineLocalCacheEntries(context.Context) (*EngineCacheEntrySet, error)
 
-	// Prune everything that is releasable in the local cache. No support for filtering yet.
-	PruneEngineLocalCacheEntries(context.Context) (*EngineCacheEntrySet, error)
+	// Prune the releasable entries in the local cache that match the given options.
+	PruneEngineLocalCacheEntries(context.Context, EngineCachePruneOpts) (*EngineCacheEntrySet, error)
 
 	// The KeepBytes setting to use for automatic local cache GC.
 	EngineLocalCacheKeepBytes() int64
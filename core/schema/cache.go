@@ -2,6 +2,7 @@ package schema
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
@@ -20,11 +21,40 @@ func (s *cacheSchema) Name() string {
 func (s *cacheSchema) Install() {
 	dagql.Fields[*core.Query]{
 		dagql.Func("cacheVolume", s.cacheVolume).
+			Impure("The namespace of a scoped cache volume depends on the calling module.",
+				`Despite being impure, this field returns a pure CacheVolume object,
+				with the namespace of its scope pinned in its ID.`).
 			Doc("Constructs a cache volume for a given cache key.").
-			ArgDoc("key", `A string identifier to target this cache volume (e.g., "modules-cache").`),
+			ArgDoc("key", `A string identifier to target this cache volume (e.g., "modules-cache").`).
+			ArgDoc("scope",
+				`Who the cache volume is shared with. Defaults to MODULE_SCOPE within a`,
+				`module, and GLOBAL_SCOPE otherwise.`),
+
+		dagql.Func("__internalCacheVolume", s.internalCacheVolume).
+			Doc(`(Internal-only) Constructs a cache volume for a given cache key within the given namespace.`),
 	}.Install(s.srv)
 
-	dagql.Fields[*core.CacheVolume]{}.Install(s.srv)
+	dagql.Fields[*core.CacheVolume]{
+		dagql.Func("size", s.size).
+			Impure("Cache volume contents change as they are used.").
+			Doc(`The disk space used by the cache volume, in bytes.`),
+
+		dagql.Func("snapshot", s.snapshot).
+			Impure("Cache volume contents change as they are used.").
+			Doc(`Returns a copy of the current contents of the cache volume.`,
+				`Changes to the cache volume after the snapshot is taken are not reflected in the returned directory.`),
+
+		dagql.Func("seed", s.seed).
+			Impure("Mutates the cache volume.").
+			Doc(`Replaces the contents of the cache volume with the contents of the given directory.`,
+				`Containers currently using the cache volume will see the new contents.`).
+			ArgDoc("source", `Directory to copy into the cache volume.`),
+
+		dagql.Func("clear", s.clear).
+			Impure("Mutates the cache volume.").
+			Doc(`Removes the contents of the cache volume.`,
+				`Containers currently using the cache volume will see it emptied.`),
+	}.Install(s.srv)
 }
 
 func (s *cacheSchema) Dependencies() []SchemaResolvers {
@@ -32,13 +62,88 @@ func (s *cacheSchema) Dependencies() []SchemaResolvers {
 }
 
 type cacheArgs struct {
-	Key string
+	Key   string
+	Scope dagql.Optional[core.CacheVolumeScope]
+}
+
+func (s *cacheSchema) cacheVolume(ctx context.Context, parent *core.Query, args cacheArgs) (inst dagql.Instance[*core.CacheVolume], _ error) {
+	scope := args.Scope.Value
+	if !args.Scope.Valid {
+		var err error
+		scope, err = core.DefaultCacheVolumeScope(ctx, parent)
+		if err != nil {
+			return inst, err
+		}
+	}
+	namespace, err := core.CacheVolumeNamespace(ctx, parent, scope)
+	if err != nil {
+		return inst, err
+	}
+
+	// pin the namespace in the ID, so the cache volume stays the same when
+	// the ID is loaded by another module
+	if err := s.srv.Select(ctx, s.srv.Root(), &inst, dagql.Selector{
+		Field: "__internalCacheVolume",
+		Args: []dagql.NamedInput{
+			{
+				Name:  "key",
+				Value: dagql.NewString(args.Key),
+			},
+			{
+				Name:  "scope",
+				Value: scope,
+			},
+			{
+				Name:  "namespace",
+				Value: dagql.NewString(namespace),
+			},
+		},
+	}); err != nil {
+		return inst, fmt.Errorf("failed to select internal cache volume: %w", err)
+	}
+	return inst, nil
+}
+
+type internalCacheArgs struct {
+	Key   string
+	Scope core.CacheVolumeScope
+
+	// Namespace is derived from the scope by the client that created the cache
+	// volume. It's what isolates cache volumes with the same key.
+	Namespace string
+}
+
+func (s *cacheSchema) internalCacheVolume(ctx context.Context, parent *core.Query, args internalCacheArgs) (*core.CacheVolume, error) {
+	cache := core.NewScopedCache(args.Scope, args.Namespace, args.Key)
+	cache.Query = parent
+	return cache, nil
+}
+
+func (s *cacheSchema) size(ctx context.Context, parent *core.CacheVolume, args struct{}) (dagql.Int, error) {
+	size, err := parent.Size(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return dagql.NewInt(size), nil
+}
+
+func (s *cacheSchema) snapshot(ctx context.Context, parent *core.CacheVolume, args struct{}) (dagql.Instance[*core.Directory], error) {
+	return parent.Snapshot(ctx, s.srv)
+}
+
+type cacheSeedArgs struct {
+	Source core.DirectoryID
+}
+
+func (s *cacheSchema) seed(ctx context.Context, parent *core.CacheVolume, args cacheSeedArgs) (dagql.Nullable[core.Void], error) {
+	void := dagql.Null[core.Void]()
+	dir, err := args.Source.Load(ctx, s.srv)
+	if err != nil {
+		return void, err
+	}
+	return void, parent.Seed(ctx, dir.Self)
 }
 
-func (s *cacheSchema) cacheVolume(ctx context.Context, parent *core.Query, args cacheArgs) (*core.CacheVolume, error) {
-	// TODO(vito): inject some sort of scope/session/project/user derived value
-	// here instead of a static value
-	//
-	// we have to inject something so we can tell it's a valid ID
-	return core.NewCache(args.Key), nil
+func (s *cacheSchema) clear(ctx context.Context, parent *core.CacheVolume, args struct{}) (dagql.Nullable[core.Void], error) {
+	return dagql.Null[core.Void](), parent.Clear(ctx)
 }
@@ -11,12 +11,15 @@ import (
 	"strings"
 	"time"
 
+	"github.com/distribution/reference"
 	"github.com/moby/buildkit/frontend/dockerfile/shell"
+	"github.com/opencontainers/go-digest"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/vektah/gqlparser/v2/ast"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine/buildkit"
 	"github.com/dagger/dagger/engine/slog"
 )
@@ -49,11 +52,26 @@ func (s *containerSchema) Install() {
 			ArgDoc("description", "Description of the sub-pipeline.").
 			ArgDoc("labels", "Labels to apply to the sub-pipeline."),
 
-		dagql.Func("from", s.from).
+		dagql.NodeFunc("from", s.from).
+			Impure("Within a module, addresses without a digest are pinned by the module's lock.",
+				`Despite being impure, this field returns a pure Container object,
+				with the digest the lock pins the address to in its ID.`).
 			Doc(`Initializes this container from a pulled base image.`).
 			ArgDoc("address",
 				`Image's address from its registry.`,
-				`Formatted as [host]/[user]/[repo]:[tag] (e.g., "docker.io/dagger/dagger:main").`),
+				`Formatted as [host]/[user]/[repo]:[tag] (e.g., "docker.io/dagger/dagger:main").`).
+			ArgDoc("verify",
+				`A PEM encoded public key or certificate to verify the image's
+				signature with, as pushed by cosign or Container.publish.`,
+				`The image must have a valid signature made with the matching private key.`).
+			ArgDoc("requireDigest",
+				`Require the address to be pinned to a digest (e.g.,
+				"alpine@sha256:...").`,
+				`Unpinned images can be pinned to the digest they resolve to with
+				imageRef.`),
+
+		dagql.Func("__internalFrom", s.internalFrom).
+			Doc(`(Internal-only) Initializes this container from a pulled base image, as pinned by from.`),
 
 		dagql.Func("build", s.build).
 			Doc(`Initializes this container from a Dockerfile build.`).
@@ -327,6 +345,18 @@ func (s *containerSchema) Install() {
 			ArgDoc("redirectStderr",
 				`Redirect the command's standard error to a file in the container (e.g.,
 			"/tmp/stderr").`).
+			ArgDoc("expect",
+				`Exit codes this command is allowed to exit with without error`).
+			ArgDoc("timeout",
+				`Maximum duration the command may run for before it is killed and the
+				exec fails (e.g., "30s", "5m").`).
+			ArgDoc("memoryLimit",
+				`Maximum amount of memory the command may use (e.g., "512m", "2g").`).
+			ArgDoc("cpuQuota",
+				`Maximum number of CPUs the command may use (e.g., "0.5", "2").`).
+			ArgDoc("pidsLimit",
+				`Maximum number of processes the command may create.`,
+				`If zero, no limit is applied.`).
 			ArgDoc("experimentalPrivilegedNesting",
 				`Provides Dagger access to the executed command.`,
 				`Do not use this option unless you trust the command being executed;
@@ -378,6 +408,10 @@ func (s *containerSchema) Install() {
 			Doc(`The error stream of the last executed command.`,
 				`Will execute default command if none is set, or error if there's no default.`),
 
+		dagql.Func("exitCode", s.exitCode).
+			Doc(`The exit code of the last executed command.`,
+				`Returns an error if no command was set.`),
+
 		dagql.Func("stdout", s.stdoutLegacy).
 			View(BeforeVersion("v0.12.0")).
 			Doc(`The output stream of the last executed command.`,
@@ -407,11 +441,40 @@ func (s *containerSchema) Install() {
 				compression algorithms for different layers). If this is unset and a
 				layer has no compressed blob in the engine's cache, then it will be
 				compressed using Gzip.`).
+			ArgDoc("compressionLevel",
+				`The level to compress layers at with forcedCompression, e.g. 1-22 for
+				Zstd. Requires forcedCompression.`).
 			ArgDoc("mediaTypes",
 				`Use the specified media types for the published image's layers.`,
 				`Defaults to OCI, which is largely compatible with most recent
 				registries, but Docker may be needed for older registries without OCI
-				support.`),
+				support.`).
+			ArgDoc("provenance",
+				`Attach SLSA provenance derived from the calls that produced the
+				container and its platform variants to the image.`).
+			ArgDoc("annotations",
+				`OCI annotations to set on the image's manifests.`,
+				`Annotations require OCI media types.`).
+			ArgDoc("annotateFromLabels",
+				`Also set the org.opencontainers.image.* labels of the container and of
+				each platform variant as annotations on their own manifest, unless
+				overridden by annotations. Requires OCI media types.`).
+			ArgDoc("indexAnnotations",
+				`OCI annotations to set on the image index, which only exists for
+				multi-platform images or images with attestations.`).
+			ArgDoc("tags",
+				`Additional tags to push the image to, in the repository of address
+				(e.g. ["v1.2.3", "latest"]).`).
+			ArgDoc("signingKey",
+				`Sign the published image with this PEM encoded private key, pushing
+				the signature next to it in a format cosign can verify.`,
+				`Keys encrypted by cosign generate-key-pair are supported.`,
+				`The signature isn't recorded in a transparency log, so verifying it
+				with cosign verify --key requires --insecure-ignore-tlog.`).
+			ArgDoc("signingKeyPassword", `The password of an encrypted signingKey.`).
+			ArgDoc("signingCertificate",
+				`A PEM encoded certificate for signingKey, followed by its chain, to
+				attach to the signature.`),
 
 		dagql.Func("platform", s.platform).
 			Doc(`The platform this container executes and publishes as.`),
@@ -434,11 +497,27 @@ func (s *containerSchema) Install() {
 				compression algorithms for different layers). If this is unset and a
 				layer has no compressed blob in the engine's cache, then it will be
 				compressed using Gzip.`).
+			ArgDoc("compressionLevel",
+				`The level to compress layers at with forcedCompression, e.g. 1-22 for
+				Zstd. Requires forcedCompression.`).
 			ArgDoc("mediaTypes",
 				`Use the specified media types for the exported image's layers.`,
 				`Defaults to OCI, which is largely compatible with most recent
 				container runtimes, but Docker may be needed for older runtimes without
-				OCI support.`),
+				OCI support.`).
+			ArgDoc("provenance",
+				`Attach SLSA provenance derived from the calls that produced the
+				container and its platform variants to the image.`).
+			ArgDoc("annotations",
+				`OCI annotations to set on the image's manifests.`,
+				`Annotations require OCI media types.`).
+			ArgDoc("annotateFromLabels",
+				`Also set the org.opencontainers.image.* labels of the container and of
+				each platform variant as annotations on their own manifest, unless
+				overridden by annotations. Requires OCI media types.`).
+			ArgDoc("indexAnnotations",
+				`OCI annotations to set on the image index, which only exists for
+				multi-platform images or images with attestations.`),
 		dagql.Func("export", s.exportLegacy).
 			View(BeforeVersion("v0.12.0")).
 			Extend(),
@@ -455,10 +534,26 @@ func (s *containerSchema) Install() {
 				compression algorithms for different layers). If this is unset and a
 				layer has no compressed blob in the engine's cache, then it will be
 				compressed using Gzip.`).
+			ArgDoc("compressionLevel",
+				`The level to compress layers at with forcedCompression, e.g. 1-22 for
+				Zstd. Requires forcedCompression.`).
 			ArgDoc("mediaTypes", `Use the specified media types for the image's layers.`,
 				`Defaults to OCI, which is largely compatible with most recent
 				container runtimes, but Docker may be needed for older runtimes without
-				OCI support.`),
+				OCI support.`).
+			ArgDoc("provenance",
+				`Attach SLSA provenance derived from the calls that produced the
+				container and its platform variants to the image.`).
+			ArgDoc("annotations",
+				`OCI annotations to set on the image's manifests.`,
+				`Annotations require OCI media types.`).
+			ArgDoc("annotateFromLabels",
+				`Also set the org.opencontainers.image.* labels of the container and of
+				each platform variant as annotations on their own manifest, unless
+				overridden by annotations. Requires OCI media types.`).
+			ArgDoc("indexAnnotations",
+				`OCI annotations to set on the image index, which only exists for
+				multi-platform images or images with attestations.`),
 
 		dagql.Func("import", s.import_).
 			Doc(`Reads the container from an OCI tarball.`).
@@ -478,8 +573,22 @@ func (s *containerSchema) Install() {
 			ArgDoc("address", `Registry's address to remove the authentication from.`,
 				`Formatted as [host]/[user]/[repo]:[tag] (e.g. docker.io/dagger/dagger:main).`),
 
+		dagql.Func("withSBOM", s.withSBOM).
+			Doc(`Retrieves this container with an SPDX SBOM attached to its image as an
+			attestation when it's published or exported.`,
+				`If no SBOM is given, one is generated by scanning the container's root
+				filesystem when its image is published or exported, like `+"`docker buildx build --sbom`"+` does.`).
+			ArgDoc("sbom", `An SPDX JSON document describing the container's contents.`).
+			ArgDoc("scanner",
+				`A BuildKit SBOM scanner image to generate the SBOM with, if none is given.`),
+
+		dagql.Func("withoutSBOM", s.withoutSBOM).
+			Doc(`Retrieves this container without an SBOM attached to its image.`),
+
 		dagql.Func("imageRef", s.imageRef).
-			Doc(`The unique image reference which can only be retrieved immediately after the 'Container.From' call.`),
+			Doc(`The unique image reference which can only be retrieved immediately after the 'Container.From' call.`,
+				`It's the address the container was initialized from, pinned to the
+				digest it resolved to, even if the engine pulled it from a mirror.`),
 
 		dagql.Func("withExposedPort", s.withExposedPort).
 			Doc(`Expose a network port.`,
@@ -603,11 +712,91 @@ func (s *containerSchema) container(ctx context.Context, parent *core.Query, arg
 }
 
 type containerFromArgs struct {
+	Address       string
+	Verify        dagql.Optional[dagql.String]
+	RequireDigest bool `default:"false"`
+}
+
+func (s *containerSchema) from(ctx context.Context, parent dagql.Instance[*core.Container], args containerFromArgs) (inst dagql.Instance[*core.Container], _ error) {
+	refName, err := reference.ParseNormalizedNamed(args.Address)
+	if err != nil {
+		return inst, err
+	}
+	_, digested := refName.(reference.Digested)
+	if args.RequireDigest && !digested {
+		return inst, fmt.Errorf("image address %s is not pinned to a digest", args.Address)
+	}
+
+	// within a module, unpinned addresses are pinned by its lock
+	address := args.Address
+	platform := parent.Self.Platform.Format()
+	var lock *core.ModuleLock
+	var lockAddress string
+	if !digested {
+		lock, err = core.CurrentModuleLock(ctx, parent.Self.Query)
+		if err != nil {
+			return inst, err
+		}
+		if lock != nil {
+			lockAddress = reference.TagNameOnly(refName).String()
+			if locked, ok := lock.ImageDigest(lockAddress, platform); ok {
+				dgst, err := digest.Parse(locked)
+				if err != nil {
+					return inst, fmt.Errorf("invalid digest of %s in dagger.lock: %w", lockAddress, err)
+				}
+				pinned, err := reference.WithDigest(reference.TagNameOnly(refName), dgst)
+				if err != nil {
+					return inst, err
+				}
+				address = pinned.String()
+				lock = nil
+			}
+		}
+	}
+
+	// pin the digest in the ID, so the container is cached by what it's
+	// pinned to rather than by the module that pulled it
+	sel := dagql.Selector{
+		Field: "__internalFrom",
+		Args: []dagql.NamedInput{
+			{Name: "address", Value: dagql.NewString(address)},
+		},
+	}
+	if args.Verify.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "verify", Value: args.Verify.Value})
+	}
+	if err := s.srv.Select(ctx, parent, &inst, sel); err != nil {
+		return inst, fmt.Errorf("failed to select internal from: %w", err)
+	}
+
+	if lock != nil {
+		ref, err := reference.ParseNormalizedNamed(inst.Self.ImageRef)
+		if err != nil {
+			return inst, err
+		}
+		if digested, ok := ref.(reference.Digested); ok {
+			lock.SetImageDigest(lockAddress, platform, digested.Digest().String())
+		}
+	}
+	return inst, nil
+}
+
+type containerInternalFromArgs struct {
 	Address string
+	Verify  dagql.Optional[dagql.String]
 }
 
-func (s *containerSchema) from(ctx context.Context, parent *core.Container, args containerFromArgs) (*core.Container, error) {
-	return parent.From(ctx, args.Address)
+func (s *containerSchema) internalFrom(ctx context.Context, parent *core.Container, args containerInternalFromArgs) (*core.Container, error) {
+	ctr, err := parent.From(ctx, args.Address)
+	if err != nil {
+		return nil, err
+	}
+	if args.Verify.Valid {
+		if err := ctr.VerifySignature(ctx, args.Verify.Value.String()); err != nil {
+			return nil, err
+		}
+	}
+	return ctr, nil
 }
 
 type containerBuildArgs struct {
@@ -732,6 +921,14 @@ func (s *containerSchema) stderr(ctx context.Context, parent *core.Container, _
 	return parent.MetaFileContents(ctx, buildkit.MetaMountStderrPath)
 }
 
+func (s *containerSchema) exitCode(ctx context.Context, parent *core.Container, _ struct{}) (dagql.Int, error) {
+	code, err := parent.ExitCode(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return dagql.NewInt(code), nil
+}
+
 func (s *containerSchema) stdoutLegacy(ctx context.Context, parent *core.Container, _ struct{}) (string, error) {
 	out, err := parent.MetaFileContents(ctx, buildkit.MetaMountStdoutPath)
 	if errors.Is(err, core.ErrNoCommand) {
@@ -1074,30 +1271,99 @@ func (s *containerSchema) withMountedDirectory(ctx context.Context, parent *core
 }
 
 type containerPublishArgs struct {
-	Address           dagql.String
-	PlatformVariants  []core.ContainerID `default:"[]"`
-	ForcedCompression dagql.Optional[core.ImageLayerCompression]
-	MediaTypes        core.ImageMediaTypes `default:"OCIMediaTypes"`
+	Address            dagql.String
+	PlatformVariants   []core.ContainerID `default:"[]"`
+	ForcedCompression  dagql.Optional[core.ImageLayerCompression]
+	CompressionLevel   dagql.Optional[dagql.Int]
+	MediaTypes         core.ImageMediaTypes                      `default:"OCIMediaTypes"`
+	Provenance         bool                                      `default:"false"`
+	Annotations        []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
+	AnnotateFromLabels bool                                      `default:"false"`
+	IndexAnnotations   []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
+	Tags               []string                                  `default:"[]"`
+
+	SigningKey         dagql.Optional[core.SecretID]
+	SigningKeyPassword dagql.Optional[core.SecretID]
+	SigningCertificate string `default:""`
 }
 
 func (s *containerSchema) publish(ctx context.Context, parent *core.Container, args containerPublishArgs) (dagql.String, error) {
-	variants, err := dagql.LoadIDs(ctx, s.srv, args.PlatformVariants)
+	opts, err := s.imageExportOpts(ctx, args.PlatformVariants, args.Provenance)
 	if err != nil {
 		return "", err
 	}
-	ref, err := parent.Publish(
-		ctx,
-		args.Address.String(),
-		variants,
-		args.ForcedCompression.Value,
-		args.MediaTypes,
-	)
+	opts.ForcedCompression = args.ForcedCompression.Value
+	opts.CompressionLevel = optionalInt(args.CompressionLevel)
+	opts.MediaTypes = args.MediaTypes
+	opts.Annotations = collectInputsSlice(args.Annotations)
+	opts.AnnotateFromLabels = args.AnnotateFromLabels
+	opts.IndexAnnotations = collectInputsSlice(args.IndexAnnotations)
+	signingKey, err := s.signingKey(ctx, args)
+	if err != nil {
+		return "", err
+	}
+	ref, err := parent.Publish(ctx, args.Address.String(), core.ImagePublishOpts{
+		ImageExportOpts: opts,
+		Tags:            args.Tags,
+		SigningKey:      signingKey,
+	})
 	if err != nil {
 		return "", err
 	}
 	return dagql.NewString(ref), nil
 }
 
+func (s *containerSchema) signingKey(ctx context.Context, args containerPublishArgs) (*core.ImageSigningKey, error) {
+	if !args.SigningKey.Valid {
+		if args.SigningKeyPassword.Valid || args.SigningCertificate != "" {
+			return nil, fmt.Errorf("signingKeyPassword and signingCertificate require a signingKey")
+		}
+		return nil, nil
+	}
+	key, err := args.SigningKey.Value.Load(ctx, s.srv)
+	if err != nil {
+		return nil, err
+	}
+	signingKey := &core.ImageSigningKey{
+		Key:         key.Self,
+		Certificate: args.SigningCertificate,
+	}
+	if args.SigningKeyPassword.Valid {
+		password, err := args.SigningKeyPassword.Value.Load(ctx, s.srv)
+		if err != nil {
+			return nil, err
+		}
+		signingKey.Password = password.Self
+	}
+	return signingKey, nil
+}
+
+// imageExportOpts loads the platform variants of a publish or export call and,
+// if provenance is enabled, the IDs of the container it's selected on and of
+// its variants.
+func (s *containerSchema) imageExportOpts(ctx context.Context, variantIDs []core.ContainerID, provenance bool) (core.ImageExportOpts, error) {
+	variants, err := dagql.LoadIDs(ctx, s.srv, variantIDs)
+	if err != nil {
+		return core.ImageExportOpts{}, err
+	}
+	opts := core.ImageExportOpts{PlatformVariants: variants}
+	if provenance {
+		opts.Provenance = []*call.ID{dagql.CurrentID(ctx).Receiver()}
+		for _, variant := range variantIDs {
+			opts.Provenance = append(opts.Provenance, variant.ID())
+		}
+	}
+	return opts, nil
+}
+
+func optionalInt(v dagql.Optional[dagql.Int]) *int {
+	if !v.Valid {
+		return nil
+	}
+	i := v.Value.Int()
+	return &i
+}
+
 type containerWithMountedFileArgs struct {
 	Path   string
 	Source core.FileID
@@ -1368,24 +1634,29 @@ func (s *containerSchema) platform(ctx context.Context, parent *core.Container,
 }
 
 type containerExportArgs struct {
-	Path              string
-	PlatformVariants  []core.ContainerID `default:"[]"`
-	ForcedCompression dagql.Optional[core.ImageLayerCompression]
-	MediaTypes        core.ImageMediaTypes `default:"OCIMediaTypes"`
+	Path               string
+	PlatformVariants   []core.ContainerID `default:"[]"`
+	ForcedCompression  dagql.Optional[core.ImageLayerCompression]
+	CompressionLevel   dagql.Optional[dagql.Int]
+	MediaTypes         core.ImageMediaTypes                      `default:"OCIMediaTypes"`
+	Provenance         bool                                      `default:"false"`
+	Annotations        []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
+	AnnotateFromLabels bool                                      `default:"false"`
+	IndexAnnotations   []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
 }
 
 func (s *containerSchema) export(ctx context.Context, parent *core.Container, args containerExportArgs) (dagql.String, error) {
-	variants, err := dagql.LoadIDs(ctx, s.srv, args.PlatformVariants)
+	opts, err := s.imageExportOpts(ctx, args.PlatformVariants, args.Provenance)
 	if err != nil {
 		return "", err
 	}
-	err = parent.Export(
-		ctx,
-		args.Path,
-		variants,
-		args.ForcedCompression.Value,
-		args.MediaTypes,
-	)
+	opts.ForcedCompression = args.ForcedCompression.Value
+	opts.CompressionLevel = optionalInt(args.CompressionLevel)
+	opts.MediaTypes = args.MediaTypes
+	opts.Annotations = collectInputsSlice(args.Annotations)
+	opts.AnnotateFromLabels = args.AnnotateFromLabels
+	opts.IndexAnnotations = collectInputsSlice(args.IndexAnnotations)
+	err = parent.Export(ctx, args.Path, opts)
 	if err != nil {
 		return "", err
 	}
@@ -1409,17 +1680,28 @@ func (s *containerSchema) exportLegacy(ctx context.Context, parent *core.Contain
 }
 
 type containerAsTarballArgs struct {
-	PlatformVariants  []core.ContainerID `default:"[]"`
-	ForcedCompression dagql.Optional[core.ImageLayerCompression]
-	MediaTypes        core.ImageMediaTypes `default:"OCIMediaTypes"`
+	PlatformVariants   []core.ContainerID `default:"[]"`
+	ForcedCompression  dagql.Optional[core.ImageLayerCompression]
+	CompressionLevel   dagql.Optional[dagql.Int]
+	MediaTypes         core.ImageMediaTypes                      `default:"OCIMediaTypes"`
+	Provenance         bool                                      `default:"false"`
+	Annotations        []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
+	AnnotateFromLabels bool                                      `default:"false"`
+	IndexAnnotations   []dagql.InputObject[core.ImageAnnotation] `default:"[]"`
 }
 
 func (s *containerSchema) asTarball(ctx context.Context, parent *core.Container, args containerAsTarballArgs) (*core.File, error) {
-	variants, err := dagql.LoadIDs(ctx, s.srv, args.PlatformVariants)
+	opts, err := s.imageExportOpts(ctx, args.PlatformVariants, args.Provenance)
 	if err != nil {
 		return nil, err
 	}
-	return parent.AsTarball(ctx, variants, args.ForcedCompression.Value, args.MediaTypes)
+	opts.ForcedCompression = args.ForcedCompression.Value
+	opts.CompressionLevel = optionalInt(args.CompressionLevel)
+	opts.MediaTypes = args.MediaTypes
+	opts.Annotations = collectInputsSlice(args.Annotations)
+	opts.AnnotateFromLabels = args.AnnotateFromLabels
+	opts.IndexAnnotations = collectInputsSlice(args.IndexAnnotations)
+	return parent.AsTarball(ctx, opts)
 }
 
 type containerImportArgs struct {
@@ -1444,6 +1726,26 @@ func (s *containerSchema) import_(ctx context.Context, parent *core.Container, a
 	)
 }
 
+type containerWithSBOMArgs struct {
+	SBOM    dagql.Optional[core.FileID] `name:"sbom"`
+	Scanner string                      `default:"docker/buildkit-syft-scanner:stable-1"`
+}
+
+func (s *containerSchema) withSBOM(ctx context.Context, parent *core.Container, args containerWithSBOMArgs) (*core.Container, error) {
+	if !args.SBOM.Valid {
+		return parent.WithGeneratedSBOM(args.Scanner), nil
+	}
+	sbom, err := args.SBOM.Value.Load(ctx, s.srv)
+	if err != nil {
+		return nil, err
+	}
+	return parent.WithSBOM(ctx, sbom.Self)
+}
+
+func (s *containerSchema) withoutSBOM(ctx context.Context, parent *core.Container, args struct{}) (*core.Container, error) {
+	return parent.WithoutSBOM(), nil
+}
+
 type containerWithRegistryAuthArgs struct {
 	Address  string
 	Username string
@@ -1460,9 +1762,9 @@ func (s *containerSchema) withRegistryAuth(ctx context.Context, parent *core.Con
 	if err != nil {
 		return nil, err
 	}
-	secretBytes, ok := secretStore.GetSecretPlaintext(secret.Self.IDDigest)
-	if !ok {
-		return nil, fmt.Errorf("secret %s not found", secret.Self.IDDigest)
+	secretBytes, err := secretStore.GetSecretPlaintext(ctx, secret.Self.IDDigest)
+	if err != nil {
+		return nil, err
 	}
 
 	auth, err := parent.Query.Auth(ctx)
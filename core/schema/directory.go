@@ -7,6 +7,7 @@ import (
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine/buildkit"
 )
 
 type directorySchema struct {
@@ -36,6 +37,14 @@ func (s *directorySchema) Install() {
 		dagql.Func("glob", s.glob).
 			Doc(`Returns a list of files and directories that matche the given pattern.`).
 			ArgDoc("pattern", `Pattern to match (e.g., "*.md").`),
+		dagql.Func("search", s.search).
+			Doc(`Searches the contents of the files in this directory, returning the matching lines.`,
+				`Binary files are skipped and symlinks are not followed.`).
+			ArgDoc("pattern", `Text to search for, or a regular expression if regex is true.`).
+			ArgDoc("regex", `Interpret the pattern as a regular expression, using RE2 syntax (e.g., "TODO\(\w+\)").`).
+			ArgDoc("globs", `Only search files matching these patterns (e.g., ["**/*.go", "!vendor"]).`,
+				`If every pattern is an exclusion, all other files are searched.`).
+			ArgDoc("maxResults", `Stop after this many matches. Zero means no limit.`),
 		dagql.Func("file", s.file).
 			Doc(`Retrieves a file at the given path.`).
 			ArgDoc("path", `Location of the file to retrieve (e.g., "README.md").`),
@@ -76,6 +85,10 @@ func (s *directorySchema) Install() {
 		dagql.Func("diff", s.diff).
 			Doc(`Gets the difference between this directory and an another directory.`).
 			ArgDoc("other", `Identifier of the directory to compare.`),
+		dagql.Func("changes", s.changes).
+			Doc(`Lists the paths that were added, modified or deleted in another directory compared to this one.`,
+				`Paths whose metadata changed but whose content and permissions are the same, like files with new timestamps, are not listed.`).
+			ArgDoc("other", `Identifier of the directory to compare.`),
 		dagql.Func("export", s.export).
 			View(AllVersion).
 			Impure("Writes to the local host.").
@@ -97,6 +110,20 @@ func (s *directorySchema) Install() {
 			Doc(`Retrieves this directory with all file/dir timestamps set to the given time.`).
 			ArgDoc("timestamp", `Timestamp to set dir/files in.`,
 				`Formatted in seconds following Unix epoch (e.g., 1672531199).`),
+		dagql.Func("withOwner", s.withOwner).
+			Doc(`Retrieves this directory with the owner of all of its files and directories set to the given user and group.`).
+			ArgDoc("owner",
+				`A user:group to set for the directory and its contents.`,
+				`The user and group can either be an ID (1000:1000) or a name (foo:bar)
+				looked up in the directory's own etc/passwd and etc/group files.`,
+				`If the group is omitted, it defaults to the same as the user.`),
+		dagql.Func("withSymlink", s.withSymlink).
+			Doc(`Retrieves this directory plus a symlink at the given path.`).
+			ArgDoc("target", `Location the symlink points to (e.g., "../lib/libfoo.so.1"), which doesn't need to exist.`).
+			ArgDoc("linkName", `Location of the symlink to create (e.g., "lib/libfoo.so").`),
+		dagql.Func("withPatch", s.withPatch).
+			Doc(`Retrieves this directory with the given patch applied.`).
+			ArgDoc("patch", `Patch in the unified diff format produced by "git diff", with paths relative to this directory.`),
 		dagql.NodeFunc("terminal", s.terminal).
 			View(AfterVersion("v0.12.0")).
 			Impure("Nondeterministic.").
@@ -115,6 +142,9 @@ func (s *directorySchema) Install() {
 			guarantees when using this option. It should only be used when
 			absolutely necessary and only with trusted commands.`),
 	}.Install(s.srv)
+
+	dagql.Fields[*core.DirectoryChange]{}.Install(s.srv)
+	dagql.Fields[*core.SearchMatch]{}.Install(s.srv)
 }
 
 type directoryPipelineArgs struct {
@@ -172,6 +202,31 @@ func (s *directorySchema) withTimestamps(ctx context.Context, parent *core.Direc
 	return parent.WithTimestamps(ctx, args.Timestamp)
 }
 
+type dirWithOwnerArgs struct {
+	Owner string
+}
+
+func (s *directorySchema) withOwner(ctx context.Context, parent *core.Directory, args dirWithOwnerArgs) (*core.Directory, error) {
+	return parent.WithOwner(ctx, args.Owner)
+}
+
+type dirWithSymlinkArgs struct {
+	Target   string
+	LinkName string
+}
+
+func (s *directorySchema) withSymlink(ctx context.Context, parent *core.Directory, args dirWithSymlinkArgs) (*core.Directory, error) {
+	return parent.WithSymlink(ctx, args.Target, args.LinkName)
+}
+
+type dirWithPatchArgs struct {
+	Patch string
+}
+
+func (s *directorySchema) withPatch(ctx context.Context, parent *core.Directory, args dirWithPatchArgs) (*core.Directory, error) {
+	return parent.WithPatch(ctx, args.Patch)
+}
+
 type entriesArgs struct {
 	Path dagql.Optional[dagql.String]
 }
@@ -192,6 +247,25 @@ func (s *directorySchema) glob(ctx context.Context, parent *core.Directory, args
 	return parent.Glob(ctx, args.Pattern)
 }
 
+type searchArgs struct {
+	Pattern    string
+	Regex      bool     `default:"false"`
+	Globs      []string `default:"[]"`
+	MaxResults int      `default:"0"`
+}
+
+func (s *directorySchema) search(ctx context.Context, parent *core.Directory, args searchArgs) (dagql.Array[*core.SearchMatch], error) {
+	if args.MaxResults < 0 {
+		return nil, fmt.Errorf("maxResults must not be negative")
+	}
+	return parent.Search(ctx, buildkit.SearchOpts{
+		Pattern:    args.Pattern,
+		Regex:      args.Regex,
+		Globs:      args.Globs,
+		MaxResults: args.MaxResults,
+	})
+}
+
 type dirFileArgs struct {
 	Path string
 }
@@ -270,6 +344,14 @@ func (s *directorySchema) diff(ctx context.Context, parent *core.Directory, args
 	return parent.Diff(ctx, dir.Self)
 }
 
+func (s *directorySchema) changes(ctx context.Context, parent *core.Directory, args diffArgs) (dagql.Array[*core.DirectoryChange], error) {
+	dir, err := args.Other.Load(ctx, s.srv)
+	if err != nil {
+		return nil, err
+	}
+	return parent.Changes(ctx, dir.Self)
+}
+
 type dirExportArgs struct {
 	Path string
 	Wipe bool `default:"false"`
@@ -3,6 +3,7 @@ package schema
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
@@ -38,15 +39,36 @@ func (s *engineSchema) Install() {
 	dagql.Fields[*core.EngineCache]{
 		dagql.Func("entrySet", s.cacheEntrySet).
 			Impure("Cache is changing asynchronously in the background").
-			Doc("The current set of entries in the cache"),
+			Doc("The current set of entries in the cache").
+			ArgDoc("description",
+				`Only include entries whose description matches this glob, where "*"`,
+				`matches any sequence of characters (e.g. "pulled from *alpine*").`).
+			ArgDoc("type", `Only include entries of this type.`).
+			ArgDoc("inUse", `Only include entries that are (or aren't) actively being used.`).
+			ArgDoc("minSize", `Only include entries using at least this many bytes.`).
+			ArgDoc("lastUsedBefore",
+				`Only include entries last used before this time, given as an RFC 3339`,
+				`timestamp or a duration ago (e.g. "24h" or "7d").`).
+			ArgDoc("sortBy", `The order to list entries in.`).
+			ArgDoc("limit", `The maximum number of entries to include.`),
 		dagql.Func("prune", s.cachePrune).
 			Impure("Mutates mutable state").
-			Doc("Prune the cache of releaseable entries"),
+			Doc("Prune the cache of releaseable entries").
+			ArgDoc("olderThan",
+				`Only prune entries that have not been used for at least this long,`,
+				`e.g. "24h" or "7d".`).
+			ArgDoc("type", `Only prune entries of this type.`).
+			ArgDoc("minSize", `Only prune entries using at least this many bytes.`).
+			ArgDoc("keepBytes",
+				`Only prune while the cache uses more than this many bytes, starting`,
+				`with the least recently used entries.`),
 	}.Install(s.srv)
 
 	dagql.Fields[*core.EngineCacheEntrySet]{
 		dagql.Func("entries", s.cacheEntrySetEntries).
-			Doc("The list of individual cache entries in the set"),
+			Doc("The list of individual cache entries in the set").
+			ArgDoc("offset", `The number of entries to skip.`).
+			ArgDoc("limit", `The maximum number of entries to include.`),
 	}.Install(s.srv)
 
 	dagql.Fields[*core.EngineCacheEntry]{}.Install(s.srv)
@@ -66,11 +88,51 @@ func (s *engineSchema) localCache(ctx context.Context, parent *core.Engine, args
 	}, nil
 }
 
-func (s *engineSchema) cacheEntrySet(ctx context.Context, parent *core.EngineCache, args struct{}) (inst dagql.Instance[*core.EngineCacheEntrySet], _ error) {
+type cacheEntrySetArgs struct {
+	Description    string `default:""`
+	Type           dagql.Optional[core.EngineCacheEntryType]
+	InUse          dagql.Optional[dagql.Boolean]
+	MinSize        int    `default:"0"`
+	LastUsedBefore string `default:""`
+	SortBy         dagql.Optional[core.EngineCacheEntrySortBy]
+	Limit          int `default:"0"`
+}
+
+func (args cacheEntrySetArgs) opts() (core.EngineCacheEntriesOpts, error) {
+	opts := core.EngineCacheEntriesOpts{
+		Description: args.Description,
+		Type:        args.Type.Value,
+		MinSize:     args.MinSize,
+		SortBy:      args.SortBy.Value,
+		Limit:       args.Limit,
+	}
+	if args.InUse.Valid {
+		inUse := args.InUse.Value.Bool()
+		opts.InUse = &inUse
+	}
+	if args.LastUsedBefore != "" {
+		if d, err := core.ParseEngineCacheDuration(args.LastUsedBefore); err == nil {
+			opts.LastUsedBefore = time.Now().Add(-d)
+		} else if opts.LastUsedBefore, err = time.Parse(time.RFC3339, args.LastUsedBefore); err != nil {
+			return opts, fmt.Errorf("invalid lastUsedBefore %q: expected a duration or RFC 3339 timestamp", args.LastUsedBefore)
+		}
+	}
+	if args.Limit < 0 {
+		return opts, fmt.Errorf("invalid limit %d: must not be negative", args.Limit)
+	}
+	return opts, nil
+}
+
+func (s *engineSchema) cacheEntrySet(ctx context.Context, parent *core.EngineCache, args cacheEntrySetArgs) (inst dagql.Instance[*core.EngineCacheEntrySet], _ error) {
 	if err := parent.Query.RequireMainClient(ctx); err != nil {
 		return inst, err
 	}
 
+	opts, err := args.opts()
+	if err != nil {
+		return inst, err
+	}
+
 	entrySetMap, err := parent.Query.EngineCacheEntrySetMap(ctx)
 	if err != nil {
 		return inst, fmt.Errorf("failed to load cache entry set map: %w", err)
@@ -80,6 +142,7 @@ func (s *engineSchema) cacheEntrySet(ctx context.Context, parent *core.EngineCac
 	if err != nil {
 		return inst, fmt.Errorf("failed to load cache entries: %w", err)
 	}
+	entrySet = entrySet.Filter(opts)
 
 	//
 	id := identity.NewID()
@@ -101,13 +164,39 @@ func (s *engineSchema) cacheEntrySet(ctx context.Context, parent *core.EngineCac
 	return inst, nil
 }
 
-func (s *engineSchema) cachePrune(ctx context.Context, parent *core.EngineCache, args struct{}) (dagql.Nullable[core.Void], error) {
+type cachePruneArgs struct {
+	OlderThan string `default:""`
+	Type      dagql.Optional[core.EngineCacheEntryType]
+	MinSize   int `default:"0"`
+	KeepBytes int `default:"0"`
+}
+
+func (s *engineSchema) cachePrune(ctx context.Context, parent *core.EngineCache, args cachePruneArgs) (dagql.Nullable[core.Void], error) {
 	void := dagql.Null[core.Void]()
 	if err := parent.Query.RequireMainClient(ctx); err != nil {
 		return void, err
 	}
 
-	_, err := parent.Query.PruneEngineLocalCacheEntries(ctx)
+	opts := core.EngineCachePruneOpts{
+		Type:      args.Type.Value,
+		MinSize:   int64(args.MinSize),
+		KeepBytes: int64(args.KeepBytes),
+	}
+	if args.OlderThan != "" {
+		olderThan, err := core.ParseEngineCacheDuration(args.OlderThan)
+		if err != nil {
+			return void, fmt.Errorf("invalid olderThan %q: %w", args.OlderThan, err)
+		}
+		opts.OlderThan = olderThan
+	}
+	if args.MinSize < 0 {
+		return void, fmt.Errorf("invalid minSize %d: must not be negative", args.MinSize)
+	}
+	if args.KeepBytes < 0 {
+		return void, fmt.Errorf("invalid keepBytes %d: must not be negative", args.KeepBytes)
+	}
+
+	_, err := parent.Query.PruneEngineLocalCacheEntries(ctx, opts)
 	if err != nil {
 		return void, fmt.Errorf("failed to prune cache entries: %w", err)
 	}
@@ -138,6 +227,17 @@ func (s *engineSchema) internalCacheEntrySet(ctx context.Context, parent *core.Q
 	return entrySet, nil
 }
 
-func (s *engineSchema) cacheEntrySetEntries(ctx context.Context, parent *core.EngineCacheEntrySet, args struct{}) ([]*core.EngineCacheEntry, error) {
-	return parent.EntriesList, nil
+type cacheEntrySetEntriesArgs struct {
+	Offset int `default:"0"`
+	Limit  int `default:"0"`
+}
+
+func (s *engineSchema) cacheEntrySetEntries(ctx context.Context, parent *core.EngineCacheEntrySet, args cacheEntrySetEntriesArgs) ([]*core.EngineCacheEntry, error) {
+	if args.Offset < 0 {
+		return nil, fmt.Errorf("invalid offset %d: must not be negative", args.Offset)
+	}
+	if args.Limit < 0 {
+		return nil, fmt.Errorf("invalid limit %d: must not be negative", args.Limit)
+	}
+	return parent.Page(args.Offset, args.Limit), nil
 }
@@ -2,7 +2,9 @@ package schema
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/fs"
 	"path/filepath"
 
 	"github.com/dagger/dagger/core"
@@ -50,6 +52,25 @@ func (s *fileSchema) Install() {
 			Doc(`Retrieves this file with its created/modified timestamps set to the given time.`).
 			ArgDoc("timestamp", `Timestamp to set dir/files in.`,
 				`Formatted in seconds following Unix epoch (e.g., 1672531199).`),
+		dagql.Func("asJSON", s.asJSON).
+			Doc(`Parses the file as structured data and returns it as JSON.`).
+			ArgDoc("format", `Format of the file. Inferred from its extension if not set.`),
+		dagql.Func("query", s.query).
+			Doc(`Parses the file as structured data and returns the JSON value at the given path.`,
+				`Returns an error if there is no value at the path.`).
+			ArgDoc("path",
+				`Path of the value to return, in a subset of JSONPath (e.g., "version", "$.dependencies['@types/node']" or "workspaces[0]").`,
+				`Keys are selected with ".key" or "['key']", and array elements with "[n]", counting from the end if negative.`).
+			ArgDoc("format", `Format of the file. Inferred from its extension if not set.`),
+		dagql.Func("withReplaced", s.withReplaced).
+			Doc(`Retrieves this file with the first or all occurrences of the given text replaced.`,
+				`Fails if the text does not occur in the file.`).
+			ArgDoc("search", `Text to replace.`).
+			ArgDoc("replace", `Text to replace it with.`).
+			ArgDoc("all", `Replace all occurrences instead of only the first one.`),
+		dagql.Func("withPermissions", s.withPermissions).
+			Doc(`Retrieves this file with its permissions set to the given value.`).
+			ArgDoc("permissions", `Permissions to set on the file (e.g., 0755).`),
 	}.Install(s.srv)
 }
 
@@ -132,3 +153,50 @@ type fileWithTimestampsArgs struct {
 func (s *fileSchema) withTimestamps(ctx context.Context, parent *core.File, args fileWithTimestampsArgs) (*core.File, error) {
 	return parent.WithTimestamps(ctx, args.Timestamp)
 }
+
+type fileWithReplacedArgs struct {
+	Search  string
+	Replace string
+	All     bool `default:"false"`
+}
+
+func (s *fileSchema) withReplaced(ctx context.Context, parent *core.File, args fileWithReplacedArgs) (*core.File, error) {
+	return parent.WithReplaced(ctx, args.Search, args.Replace, args.All)
+}
+
+type fileWithPermissionsArgs struct {
+	Permissions int
+}
+
+func (s *fileSchema) withPermissions(ctx context.Context, parent *core.File, args fileWithPermissionsArgs) (*core.File, error) {
+	return parent.WithPermissions(ctx, fs.FileMode(args.Permissions))
+}
+
+type fileAsJSONArgs struct {
+	Format dagql.Optional[core.DataFormat]
+}
+
+func (s *fileSchema) asJSON(ctx context.Context, parent *core.File, args fileAsJSONArgs) (core.JSON, error) {
+	data, err := parent.Data(ctx, args.Format.Value)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+type fileQueryArgs struct {
+	Path   string
+	Format dagql.Optional[core.DataFormat]
+}
+
+func (s *fileSchema) query(ctx context.Context, parent *core.File, args fileQueryArgs) (core.JSON, error) {
+	data, err := parent.Data(ctx, args.Format.Value)
+	if err != nil {
+		return nil, err
+	}
+	val, err := core.QueryData(data, args.Path)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(val)
+}
@@ -28,26 +28,48 @@ func (s *gitSchema) Install() {
 				"Can be formatted as `https://{host}/{owner}/{repo}`, `git@{host}:{owner}/{repo}`.",
 				`Suffix ".git" is optional.`).
 			ArgDoc("keepGitDir", `Set to true to keep .git directory.`).
+			ArgDoc("depth",
+				`Depth of history to fetch.`,
+				`Set to 0 to fetch the full history.`).
+			ArgDoc("withSubmodules", `Set to false to skip checking out submodules.`).
 			ArgDoc("sshKnownHosts", `Set SSH known hosts`).
 			ArgDoc("sshAuthSocket", `Set SSH auth socket`).
 			ArgDoc("experimentalServiceHost", `A service which must be started before the repo is fetched.`),
 	}.Install(s.srv)
 
 	dagql.Fields[*core.GitRepository]{
-		dagql.Func("head", s.head).
+		dagql.NodeFunc("head", s.head).
+			Impure("Within a module, the ref is pinned to a commit by the module's lock.",
+				`Despite being impure, this field returns a pure GitRef object,
+				with the commit the lock pins it to in its ID.`).
 			Doc(`Returns details for HEAD.`),
-		dagql.Func("ref", s.ref).
+		dagql.NodeFunc("ref", s.ref).
+			Impure("Within a module, the ref is pinned to a commit by the module's lock.",
+				`Despite being impure, this field returns a pure GitRef object,
+				with the commit the lock pins it to in its ID.`).
 			Doc(`Returns details of a ref.`).
 			ArgDoc("name", `Ref's name (can be a commit identifier, a tag name, a branch name, or a fully-qualified ref).`),
-		dagql.Func("branch", s.branch).
+		dagql.NodeFunc("branch", s.branch).
+			Impure("Within a module, the ref is pinned to a commit by the module's lock.",
+				`Despite being impure, this field returns a pure GitRef object,
+				with the commit the lock pins it to in its ID.`).
 			Doc(`Returns details of a branch.`).
 			ArgDoc("name", `Branch's name (e.g., "main").`),
-		dagql.Func("tag", s.tag).
+		dagql.NodeFunc("tag", s.tag).
+			Impure("Within a module, the ref is pinned to a commit by the module's lock.",
+				`Despite being impure, this field returns a pure GitRef object,
+				with the commit the lock pins it to in its ID.`).
 			Doc(`Returns details of a tag.`).
 			ArgDoc("name", `Tag's name (e.g., "v0.3.9").`),
 		dagql.Func("tags", s.tags).
 			Doc(`tags that match any of the given glob patterns.`).
 			ArgDoc("patterns", `Glob patterns (e.g., "refs/tags/v*").`),
+		dagql.Func("branches", s.branches).
+			Doc(`branches that match any of the given glob patterns.`).
+			ArgDoc("patterns", `Glob patterns (e.g., "refs/heads/release/*").`),
+		dagql.Func("refs", s.refs).
+			Doc(`Fully-qualified refs that match any of the given glob patterns.`).
+			ArgDoc("patterns", `Glob patterns (e.g., "refs/pull/*").`),
 		dagql.Func("commit", s.commit).
 			Doc(`Returns details of a commit.`).
 			// TODO: id is normally a reserved word; we should probably rename this
@@ -58,12 +80,18 @@ func (s *gitSchema) Install() {
 		dagql.Func("withAuthHeader", s.withAuthHeader).
 			Doc(`Header to authenticate the remote with.`).
 			ArgDoc("header", `Secret used to populate the Authorization HTTP header`),
+
+		dagql.Func("__internalRef", s.internalRef).
+			Doc(`(Internal-only) Returns details of a ref, as pinned by head, ref, branch and tag.`),
 	}.Install(s.srv)
 
 	dagql.Fields[*core.GitRef]{
 		dagql.Func("tree", s.tree).
 			View(AllVersion).
-			Doc(`The filesystem tree at this ref.`),
+			Doc(`The filesystem tree at this ref.`).
+			ArgDoc("sparse",
+				`Only check out these paths (e.g., ["docs", "go.mod"]).`,
+				`Paths are relative to the root of the repository.`),
 		dagql.Func("tree", s.treeLegacy).
 			View(BeforeVersion("v0.12.0")).
 			Doc(`The filesystem tree at this ref.`).
@@ -71,12 +99,44 @@ func (s *gitSchema) Install() {
 			ArgDeprecated("sshAuthSocket", "This option should be passed to `git` instead."),
 		dagql.Func("commit", s.fetchCommit).
 			Doc(`The resolved commit id at this ref.`),
+		dagql.Func("commitInfo", s.commitInfo).
+			Doc(`The metadata of the resolved commit at this ref.`),
+		dagql.Func("push", s.push).
+			Impure("Updates a remote repository.").
+			Doc(`Pushes the commit at this ref to a remote repository.`).
+			ArgDoc("remote", `URL of the repository to push to.`).
+			ArgDoc("ref",
+				`Ref to update in the remote (e.g., "main" or "refs/tags/v1.0.0").`,
+				`Names that are not fully-qualified are treated as branches.`).
+			ArgDoc("force", `Update the ref even if the commit does not descend from its current commit.`).
+			ArgDoc("sshKnownHosts", `Set SSH known hosts`).
+			ArgDoc("sshAuthSocket", `Set SSH auth socket`).
+			ArgDoc("authToken", `Secret used to populate the password during basic HTTP Authorization`).
+			ArgDoc("authHeader", `Secret used to populate the Authorization HTTP header`),
 	}.Install(s.srv)
+
+	dagql.Fields[*core.Directory]{
+		dagql.Func("asGitCommit", s.asGitCommit).
+			Impure("Commits are timestamped with the current time.").
+			Doc(`Commits the contents of this directory to a new git commit.`,
+				`Any .git directory in it is ignored.`).
+			ArgDoc("message", `The commit message.`).
+			ArgDoc("author", `The author and committer of the commit (e.g., "Jane Doe <jane@example.com>").`).
+			ArgDoc("parent", `The commit to build on. If not set, a new repository is created.`),
+
+		dagql.Func("__internalAsGitRef", s.internalAsGitRef).
+			Doc(`(Internal-only) Returns a ref to a commit in this checkout, which includes its .git directory.`),
+	}.Install(s.srv)
+
+	dagql.Fields[*core.GitCommit]{}.Install(s.srv)
+	dagql.Fields[*core.GitActor]{}.Install(s.srv)
 }
 
 type gitArgs struct {
 	URL                     string
 	KeepGitDir              bool `default:"false"`
+	Depth                   int  `default:"1"`
+	WithSubmodules          bool `default:"true"`
 	ExperimentalServiceHost dagql.Optional[core.ServiceID]
 
 	SSHKnownHosts string                        `name:"sshKnownHosts" default:""`
@@ -100,6 +160,9 @@ func (s *gitSchema) git(ctx context.Context, parent *core.Query, args gitArgs) (
 			Hostname: host,
 		})
 	}
+	if args.Depth < 0 {
+		return nil, fmt.Errorf("invalid depth %d: must not be negative", args.Depth)
+	}
 	var authSock *core.Socket
 	if args.SSHAuthSocket.Valid {
 		sock, err := args.SSHAuthSocket.Value.Load(ctx, s.srv)
@@ -112,6 +175,8 @@ func (s *gitSchema) git(ctx context.Context, parent *core.Query, args gitArgs) (
 		Query:         parent,
 		URL:           args.URL,
 		KeepGitDir:    args.KeepGitDir,
+		Depth:         args.Depth,
+		Submodules:    args.WithSubmodules,
 		SSHKnownHosts: args.SSHKnownHosts,
 		SSHAuthSocket: authSock,
 		Services:      svcs,
@@ -119,23 +184,16 @@ func (s *gitSchema) git(ctx context.Context, parent *core.Query, args gitArgs) (
 	}, nil
 }
 
-func (s *gitSchema) head(ctx context.Context, parent *core.GitRepository, args struct{}) (*core.GitRef, error) {
-	return &core.GitRef{
-		Query: parent.Query,
-		Repo:  parent,
-	}, nil
+func (s *gitSchema) head(ctx context.Context, parent dagql.Instance[*core.GitRepository], args struct{}) (dagql.Instance[*core.GitRef], error) {
+	return s.lockedRef(ctx, parent, "")
 }
 
 type refArgs struct {
 	Name string
 }
 
-func (s *gitSchema) ref(ctx context.Context, parent *core.GitRepository, args refArgs) (*core.GitRef, error) {
-	return &core.GitRef{
-		Query: parent.Query,
-		Ref:   args.Name,
-		Repo:  parent,
-	}, nil
+func (s *gitSchema) ref(ctx context.Context, parent dagql.Instance[*core.GitRepository], args refArgs) (dagql.Instance[*core.GitRef], error) {
+	return s.lockedRef(ctx, parent, args.Name)
 }
 
 type commitArgs struct {
@@ -154,19 +212,47 @@ type branchArgs struct {
 	Name string
 }
 
-func (s *gitSchema) branch(ctx context.Context, parent *core.GitRepository, args branchArgs) (*core.GitRef, error) {
-	return &core.GitRef{
-		Query: parent.Query,
-		Ref:   args.Name,
-		Repo:  parent,
-	}, nil
+func (s *gitSchema) branch(ctx context.Context, parent dagql.Instance[*core.GitRepository], args branchArgs) (dagql.Instance[*core.GitRef], error) {
+	return s.lockedRef(ctx, parent, args.Name)
 }
 
 type tagArgs struct {
 	Name string
 }
 
-func (s *gitSchema) tag(ctx context.Context, parent *core.GitRepository, args tagArgs) (*core.GitRef, error) {
+func (s *gitSchema) tag(ctx context.Context, parent dagql.Instance[*core.GitRepository], args tagArgs) (dagql.Instance[*core.GitRef], error) {
+	return s.lockedRef(ctx, parent, args.Name)
+}
+
+// lockedRef selects the named ref of the repository, or the commit the
+// current module's lock pins it to, so the ref is cached by its commit rather
+// than by the module that resolved it.
+func (s *gitSchema) lockedRef(ctx context.Context, parent dagql.Instance[*core.GitRepository], name string) (inst dagql.Instance[*core.GitRef], _ error) {
+	commit, err := core.LockedGitCommit(ctx, parent.Self, name)
+	if err != nil {
+		return inst, err
+	}
+	sel := dagql.Selector{
+		Field: "__internalRef",
+		Args: []dagql.NamedInput{
+			{Name: "name", Value: dagql.NewString(name)},
+		},
+	}
+	if commit != "" {
+		sel = dagql.Selector{
+			Field: "commit",
+			Args: []dagql.NamedInput{
+				{Name: "id", Value: dagql.NewString(commit)},
+			},
+		}
+	}
+	if err := s.srv.Select(ctx, parent, &inst, sel); err != nil {
+		return inst, fmt.Errorf("failed to select ref: %w", err)
+	}
+	return inst, nil
+}
+
+func (s *gitSchema) internalRef(ctx context.Context, parent *core.GitRepository, args refArgs) (*core.GitRef, error) {
 	return &core.GitRef{
 		Query: parent.Query,
 		Ref:   args.Name,
@@ -179,15 +265,54 @@ type tagsArgs struct {
 }
 
 func (s *gitSchema) tags(ctx context.Context, parent *core.GitRepository, args tagsArgs) ([]string, error) {
-	queryArgs := []string{
-		"ls-remote",
-		"--tags", // we only want tags
-		"--refs", // we don't want to include ^{} entries for annotated tags
-		parent.URL,
+	refs, err := lsRemote(ctx, parent.URL, []string{"--tags"}, args.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, len(refs))
+	for i, ref := range refs {
+		// this API is to fetch tags, not refs, so we can drop the `refs/tags/`
+		// prefix
+		tags[i] = strings.TrimPrefix(ref, "refs/tags/")
+	}
+	return tags, nil
+}
+
+type branchesArgs struct {
+	Patterns dagql.Optional[dagql.ArrayInput[dagql.String]] `name:"patterns"`
+}
+
+func (s *gitSchema) branches(ctx context.Context, parent *core.GitRepository, args branchesArgs) ([]string, error) {
+	refs, err := lsRemote(ctx, parent.URL, []string{"--heads"}, args.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	branches := make([]string, len(refs))
+	for i, ref := range refs {
+		branches[i] = strings.TrimPrefix(ref, "refs/heads/")
 	}
+	return branches, nil
+}
+
+type refsArgs struct {
+	Patterns dagql.Optional[dagql.ArrayInput[dagql.String]] `name:"patterns"`
+}
 
-	if args.Patterns.Valid {
-		val := args.Patterns.Value.ToArray()
+func (s *gitSchema) refs(ctx context.Context, parent *core.GitRepository, args refsArgs) ([]string, error) {
+	return lsRemote(ctx, parent.URL, nil, args.Patterns)
+}
+
+// lsRemote lists the refs of the remote matching any of the patterns.
+func lsRemote(ctx context.Context, url string, flags []string, patterns dagql.Optional[dagql.ArrayInput[dagql.String]]) ([]string, error) {
+	queryArgs := []string{"ls-remote"}
+	queryArgs = append(queryArgs, flags...)
+	queryArgs = append(queryArgs,
+		"--refs", // we don't want to include ^{} entries for annotated tags
+		url,
+	)
+
+	if patterns.Valid {
+		val := patterns.Value.ToArray()
 
 		for _, p := range val {
 			queryArgs = append(queryArgs, p.String())
@@ -201,21 +326,16 @@ func (s *gitSchema) tags(ctx context.Context, parent *core.GitRepository, args t
 
 	scanner := bufio.NewScanner(bytes.NewBuffer(output))
 
-	tags := []string{}
+	refs := []string{}
 	for scanner.Scan() {
 		fields := strings.Fields(scanner.Text())
 		if len(fields) < 2 {
 			continue
 		}
-
-		// this API is to fetch tags, not refs, so we can drop the `refs/tags/`
-		// prefix
-		tag := strings.TrimPrefix(fields[1], "refs/tags/")
-
-		tags = append(tags, tag)
+		refs = append(refs, fields[1])
 	}
 
-	return tags, nil
+	return refs, nil
 }
 
 type withAuthTokenArgs struct {
@@ -246,8 +366,18 @@ func (s *gitSchema) withAuthHeader(ctx context.Context, parent *core.GitReposito
 	return &repo, nil
 }
 
-func (s *gitSchema) tree(ctx context.Context, parent *core.GitRef, _ struct{}) (*core.Directory, error) {
-	return parent.Tree(ctx)
+type treeArgs struct {
+	Sparse dagql.Optional[dagql.ArrayInput[dagql.String]]
+}
+
+func (s *gitSchema) tree(ctx context.Context, parent *core.GitRef, args treeArgs) (*core.Directory, error) {
+	var sparse []string
+	if args.Sparse.Valid {
+		for _, p := range args.Sparse.Value.ToArray() {
+			sparse = append(sparse, p.String())
+		}
+	}
+	return parent.Tree(ctx, sparse)
 }
 
 type treeArgsLegacy struct {
@@ -271,7 +401,7 @@ func (s *gitSchema) treeLegacy(ctx context.Context, parent *core.GitRef, args tr
 		cp.SSHAuthSocket = authSock
 		res.Repo = &cp
 	}
-	return res.Tree(ctx)
+	return res.Tree(ctx, nil)
 }
 
 func (s *gitSchema) fetchCommit(ctx context.Context, parent *core.GitRef, _ struct{}) (dagql.String, error) {
@@ -282,6 +412,106 @@ func (s *gitSchema) fetchCommit(ctx context.Context, parent *core.GitRef, _ stru
 	return dagql.NewString(str), nil
 }
 
+func (s *gitSchema) commitInfo(ctx context.Context, parent *core.GitRef, _ struct{}) (*core.GitCommit, error) {
+	return parent.CommitInfo(ctx)
+}
+
+type gitPushArgs struct {
+	Remote string
+	Ref    string
+	Force  bool `default:"false"`
+
+	SSHKnownHosts string                        `name:"sshKnownHosts" default:""`
+	SSHAuthSocket dagql.Optional[core.SocketID] `name:"sshAuthSocket"`
+	AuthToken     dagql.Optional[core.SecretID]
+	AuthHeader    dagql.Optional[core.SecretID]
+}
+
+func (s *gitSchema) push(ctx context.Context, parent *core.GitRef, args gitPushArgs) (dagql.Nullable[core.Void], error) {
+	void := dagql.Null[core.Void]()
+	opts := core.GitPushOpts{
+		Remote:        args.Remote,
+		Ref:           args.Ref,
+		Force:         args.Force,
+		SSHKnownHosts: args.SSHKnownHosts,
+	}
+	if args.SSHAuthSocket.Valid {
+		sock, err := args.SSHAuthSocket.Value.Load(ctx, s.srv)
+		if err != nil {
+			return void, err
+		}
+		opts.SSHAuthSocket = sock.Self
+	}
+	if args.AuthToken.Valid {
+		token, err := args.AuthToken.Value.Load(ctx, s.srv)
+		if err != nil {
+			return void, err
+		}
+		opts.AuthToken = token.Self
+	}
+	if args.AuthHeader.Valid {
+		header, err := args.AuthHeader.Value.Load(ctx, s.srv)
+		if err != nil {
+			return void, err
+		}
+		opts.AuthHeader = header.Self
+	}
+	return void, parent.Push(ctx, opts)
+}
+
+type asGitCommitArgs struct {
+	Message string
+	Author  string
+	Parent  dagql.Optional[core.GitRefID]
+}
+
+var gitAuthorRe = regexp.MustCompile(`^\s*(.+?)\s*<([^<>]+)>\s*$`)
+
+func (s *gitSchema) asGitCommit(ctx context.Context, parent *core.Directory, args asGitCommitArgs) (inst dagql.Instance[*core.GitRef], _ error) {
+	author := gitAuthorRe.FindStringSubmatch(args.Author)
+	if author == nil {
+		return inst, fmt.Errorf("invalid author %q: must be formatted as \"Name <email>\"", args.Author)
+	}
+	opts := core.GitCommitOpts{
+		Message:     args.Message,
+		AuthorName:  author[1],
+		AuthorEmail: author[2],
+	}
+	var keepGitDir bool
+	if args.Parent.Valid {
+		ref, err := args.Parent.Value.Load(ctx, s.srv)
+		if err != nil {
+			return inst, err
+		}
+		opts.Parent = ref.Self
+		keepGitDir = ref.Self.Repo.KeepGitDir
+	}
+	checkout, commit, err := parent.AsGitCommit(ctx, s.srv, opts)
+	if err != nil {
+		return inst, err
+	}
+
+	// select the ref from the checkout blob, so that its ID is pure and
+	// refers to this exact commit
+	err = s.srv.Select(ctx, checkout, &inst, dagql.Selector{
+		Field: "__internalAsGitRef",
+		Args: []dagql.NamedInput{
+			{Name: "commit", Value: dagql.NewString(commit)},
+			{Name: "keepGitDir", Value: dagql.NewBoolean(keepGitDir)},
+		},
+	})
+	return inst, err
+}
+
+type internalAsGitRefArgs struct {
+	Commit     string
+	KeepGitDir bool
+}
+
+func (s *gitSchema) internalAsGitRef(ctx context.Context, parent *core.Directory, args internalAsGitRefArgs) (*core.GitRef, error) {
+	return core.NewLocalGitRef(parent, args.Commit, args.KeepGitDir), nil
+}
+
 // TODO: make this part of the actual git api, just using as util for moduleRef right now
 func defaultBranch(ctx context.Context, repoURL string) (string, error) {
 	stdoutBytes, err := exec.CommandContext(ctx, "git", "ls-remote", "--symref", repoURL, "HEAD").Output()
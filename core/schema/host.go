@@ -184,17 +184,25 @@ func (s *hostSchema) Install() {
 				and returns the secret.`,
 				`The file is limited to a size of 512000 bytes.`).
 			ArgDoc("name", `The user defined name for this secret.`).
-			ArgDoc("path", `Location of the file to set as a secret.`),
+			ArgDoc("path", `Location of the file to set as a secret.`).
+			ArgDoc("ttl",
+				`How long the file's contents are valid for (e.g., "15m").`,
+				`Once expired, the file is read again from the host.`),
 	}.Install(s.srv)
 }
 
 type setSecretFileArgs struct {
 	Name string
 	Path string
+	TTL  string `name:"ttl" default:""`
 }
 
-func (s *hostSchema) setSecretFile(ctx context.Context, host *core.Host, args setSecretFileArgs) (dagql.Instance[*core.Secret], error) {
-	return host.SetSecretFile(ctx, s.srv, args.Name, args.Path)
+func (s *hostSchema) setSecretFile(ctx context.Context, host *core.Host, args setSecretFileArgs) (inst dagql.Instance[*core.Secret], _ error) {
+	ttl, err := parseSecretTTL(args.TTL)
+	if err != nil {
+		return inst, err
+	}
+	return host.SetSecretFile(ctx, s.srv, args.Name, args.Path, ttl)
 }
 
 type hostDirectoryArgs struct {
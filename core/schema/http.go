@@ -2,6 +2,10 @@ package schema
 
 import (
 	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
 
 	"github.com/moby/buildkit/client/llb"
 	"github.com/opencontainers/go-digest"
@@ -21,23 +25,103 @@ type httpSchema struct {
 func (s *httpSchema) Install() {
 	dagql.Fields[*core.Query]{
 		dagql.Func("http", s.http).
+			Impure("Within a module, downloads without a checksum are pinned by the module's lock.",
+				`Despite being impure, this field returns a pure File object, with
+				the checksum the lock pins the download to in its ID.`).
 			Doc(`Returns a file containing an http remote url content.`).
 			ArgDoc("url", `HTTP url to get the content from (e.g., "https://docs.dagger.io").`).
+			ArgDoc("checksum",
+				`Expected digest of the content (e.g., "sha256:...").`,
+				`The download fails if the content doesn't match, and is skipped if`,
+				`content with the digest is already cached.`).
+			ArgDoc("authHeader", `Secret used to populate the Authorization HTTP header`).
+			ArgDoc("headers", `Additional HTTP headers to send with the request.`).
+			ArgDoc("name", `File name of the downloaded file.`).
+			ArgDoc("permissions", `Permission given to the downloaded file (e.g., 0600).`).
 			ArgDoc("experimentalServiceHost", `A service which must be started before the URL is fetched.`),
+
+		dagql.Func("__internalHTTP", s.internalHTTP).
+			Doc(`(Internal-only) Returns a file containing an http remote url content, as pinned by http.`),
 	}.Install(s.srv)
 }
 
 type httpArgs struct {
 	URL                     string
+	Checksum                dagql.Optional[dagql.String]
+	AuthHeader              dagql.Optional[core.SecretID]
+	Headers                 []dagql.InputObject[core.HTTPHeader] `default:"[]"`
+	Name                    dagql.Optional[dagql.String]
+	Permissions             dagql.Optional[dagql.Int]
 	ExperimentalServiceHost dagql.Optional[core.ServiceID]
 }
 
-func (s *httpSchema) http(ctx context.Context, parent *core.Query, args httpArgs) (*core.File, error) {
+func (s *httpSchema) http(ctx context.Context, parent *core.Query, args httpArgs) (inst dagql.Instance[*core.File], _ error) {
+	// within a module, downloads without a checksum are pinned by its lock
+	var lock *core.ModuleLock
+	if !args.Checksum.Valid {
+		var err error
+		lock, err = core.CurrentModuleLock(ctx, parent)
+		if err != nil {
+			return inst, err
+		}
+		if lock != nil {
+			if dgst, ok := lock.HTTPDigest(args.URL); ok {
+				args.Checksum = dagql.Opt(dagql.NewString(dgst))
+				lock = nil
+			}
+		}
+	}
+
+	// pin the checksum in the ID, so the file is cached by what it's pinned
+	// to rather than by the module that downloaded it
+	sel := dagql.Selector{
+		Field: "__internalHTTP",
+		Args: []dagql.NamedInput{
+			{Name: "url", Value: dagql.NewString(args.URL)},
+			{Name: "headers", Value: dagql.ArrayInput[dagql.InputObject[core.HTTPHeader]](args.Headers)},
+		},
+	}
+	if args.Checksum.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "checksum", Value: args.Checksum.Value})
+	}
+	if args.AuthHeader.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "authHeader", Value: args.AuthHeader.Value})
+	}
+	if args.Name.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "name", Value: args.Name.Value})
+	}
+	if args.Permissions.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "permissions", Value: args.Permissions.Value})
+	}
+	if args.ExperimentalServiceHost.Valid {
+		sel.Args = append(sel.Args, dagql.NamedInput{Name: "experimentalServiceHost", Value: args.ExperimentalServiceHost.Value})
+	}
+	if err := s.srv.Select(ctx, s.srv.Root(), &inst, sel); err != nil {
+		return inst, fmt.Errorf("failed to select internal http: %w", err)
+	}
+
+	if lock != nil {
+		dgst, err := inst.Self.HTTPDigest(ctx)
+		if err != nil {
+			return inst, fmt.Errorf("failed to pin %s: %w", args.URL, err)
+		}
+		lock.SetHTTPDigest(args.URL, dgst.String(), len(args.Headers) > 0 || args.AuthHeader.Valid)
+	}
+	return inst, nil
+}
+
+func (s *httpSchema) internalHTTP(ctx context.Context, parent *core.Query, args httpArgs) (*core.File, error) {
 	// Use a filename that is set to the URL. Buildkit internally stores some cache metadata of etags
 	// and http checksums using an id based on this name, so setting it to the URL maximizes our chances
 	// of following more optimized cache codepaths.
 	// Do a hash encode to prevent conflicts with use of `/` in the URL while also not hitting max filename limits
 	filename := digest.FromString(args.URL).Encoded()
+	if args.Name.Valid {
+		filename = args.Name.Value.String()
+		if filename == "" || filename == "." || filename == ".." || strings.ContainsRune(filename, '/') {
+			return nil, fmt.Errorf("invalid file name %q", filename)
+		}
+	}
 
 	svcs := core.ServiceBindings{}
 	if args.ExperimentalServiceHost.Valid {
@@ -59,12 +143,37 @@ func (s *httpSchema) http(ctx context.Context, parent *core.Query, args httpArgs
 	opts := []llb.HTTPOption{
 		llb.Filename(filename),
 	}
+	if args.Checksum.Valid {
+		dgst, err := digest.Parse(args.Checksum.Value.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid checksum %q: %w", args.Checksum.Value, err)
+		}
+		opts = append(opts, llb.Checksum(dgst))
+	}
+	if args.Permissions.Valid {
+		opts = append(opts, llb.Chmod(fs.FileMode(args.Permissions.Value.Int())))
+	}
+
+	var reqOpts httpdns.RequestOpts
+	if len(args.Headers) > 0 {
+		reqOpts.Headers = http.Header{}
+		for _, header := range args.Headers {
+			reqOpts.Headers.Add(header.Value.Name, header.Value.Value)
+		}
+	}
+	if args.AuthHeader.Valid {
+		secret, err := args.AuthHeader.Value.Load(ctx, s.srv)
+		if err != nil {
+			return nil, err
+		}
+		reqOpts.AuthHeaderSecret = secret.Self.LLBID()
+	}
 
 	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	st := httpdns.HTTP(args.URL, clientMetadata.SessionID, opts...)
+	st := httpdns.HTTP(args.URL, clientMetadata.SessionID, reqOpts, opts...)
 	return core.NewFileSt(ctx, parent, st, filename, parent.Platform(), svcs)
 }
@@ -90,6 +90,9 @@ func (s *moduleSchema) Install() {
 			Doc(`Update the module source with a new context directory. Only valid for local sources.`).
 			ArgDoc("dir", `The directory to set as the context directory.`),
 
+		dagql.Func("__withContextIdentity", s.moduleSourceWithContextIdentity).
+			Doc(`(Internal-only) Identifies the context directory of a local source across versions and edits, e.g. by its git remote.`),
+
 		dagql.Func("directory", s.moduleSourceDirectory).
 			Doc(`The directory containing the module configuration and source code (source code may be in a subdir).`).
 			ArgDoc(`path`, `The path from the source directory to select.`),
@@ -191,6 +194,10 @@ func (s *moduleSchema) Install() {
 		dagql.Func("generatedContextDiff", s.moduleGeneratedContextDiff).
 			Doc(`The generated files and directories made on top of the module source's context directory.`),
 
+		dagql.Func("lock", s.moduleLock).
+			Impure(`Changes as the module's functions resolve images, git refs and http downloads.`).
+			Doc(`The contents of the module's dagger.lock file, pinning what its functions resolved so far in this session, or an empty string if nothing is pinned.`),
+
 		dagql.NodeFunc("initialize", s.moduleInitialize).
 			Doc(`Retrieves the module with the objects loaded via its SDK.`),
 
@@ -737,6 +744,12 @@ func (s *moduleSchema) moduleWithSource(ctx context.Context, mod *core.Module, a
 		return nil, fmt.Errorf("failed to get module SDK: %w", err)
 	}
 
+	lock, _, err := src.Self.ModuleLock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dagger.lock: %w", err)
+	}
+	mod.Lock = core.NewModuleLock(lock)
+
 	modCfg, modCfgPath, err := s.updateDaggerConfig(ctx, string(args.EngineVersion.Value), mod, src)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update dagger.json: %w", err)
@@ -763,6 +776,17 @@ func (s *moduleSchema) moduleWithSource(ctx context.Context, mod *core.Module, a
 	return mod, nil
 }
 
+func (s *moduleSchema) moduleLock(ctx context.Context, mod *core.Module, args struct{}) (dagql.String, error) {
+	if mod.Lock == nil {
+		return "", nil
+	}
+	contents, err := mod.Lock.Contents()
+	if err != nil {
+		return "", err
+	}
+	return dagql.String(contents), nil
+}
+
 func (s *moduleSchema) moduleGeneratedContextDiff(
 	ctx context.Context,
 	mod *core.Module,
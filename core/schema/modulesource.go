@@ -1,6 +1,7 @@
 package schema
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -9,6 +10,7 @@ import (
 	"sort"
 	"strings"
 
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -602,21 +604,31 @@ func (s *moduleSchema) moduleSourceResolveDependency(
 		return newDepSrc, nil
 
 	case core.ModuleSourceKindLocal:
-		var newDepSrc dagql.Instance[*core.ModuleSource]
-		err = s.dag.Select(ctx, s.dag.Root(), &newDepSrc,
-			dagql.Selector{
+		sels := []dagql.Selector{
+			{
 				Field: "moduleSource",
 				Args: []dagql.NamedInput{
 					{Name: "refString", Value: dagql.String(depSubpath)},
 				},
 			},
-			dagql.Selector{
+			{
 				Field: "withContextDirectory",
 				Args: []dagql.NamedInput{
 					{Name: "dir", Value: dagql.NewID[*core.Directory](contextDir.ID())},
 				},
 			},
-		)
+		}
+		// the dep shares the context directory, and so its identity
+		if src.Kind == core.ModuleSourceKindLocal && src.AsLocalSource.Value.ContextIdentity != "" {
+			sels = append(sels, dagql.Selector{
+				Field: "__withContextIdentity",
+				Args: []dagql.NamedInput{
+					{Name: "identity", Value: dagql.String(src.AsLocalSource.Value.ContextIdentity)},
+				},
+			})
+		}
+		var newDepSrc dagql.Instance[*core.ModuleSource]
+		err = s.dag.Select(ctx, s.dag.Root(), &newDepSrc, sels...)
 		if err != nil {
 			return inst, fmt.Errorf("failed to load local dep: %w", err)
 		}
@@ -653,6 +665,24 @@ func (s *moduleSchema) moduleSourceWithContextDirectory(
 	}
 	src.AsLocalSource.Value.ContextDirectory.Value = dir
 	src.AsLocalSource.Value.ContextDirectory.Valid = true
+	// the identity was of the previous context directory
+	src.AsLocalSource.Value.ContextIdentity = ""
+	return src, nil
+}
+
+func (s *moduleSchema) moduleSourceWithContextIdentity(
+	ctx context.Context,
+	src *core.ModuleSource,
+	args struct {
+		Identity string
+	},
+) (*core.ModuleSource, error) {
+	if src.Kind != core.ModuleSourceKindLocal {
+		return nil, fmt.Errorf("cannot set context identity for non-local module source")
+	}
+
+	src = src.Clone()
+	src.AsLocalSource.Value.ContextIdentity = args.Identity
 	return src, nil
 }
 
@@ -833,6 +863,13 @@ func (s *moduleSchema) moduleSourceResolveFromCaller(
 		}
 		includeSet[configRelPath] = struct{}{}
 
+		// and the lock file, if any
+		lockRelPath, err := filepath.Rel(contextAbsPath, filepath.Join(rootPath, modules.LockFilename))
+		if err != nil {
+			return inst, fmt.Errorf("failed to get relative path: %w", err)
+		}
+		includeSet[lockRelPath] = struct{}{}
+
 		// always include the source dir
 		source := localDep.modCfg.Source
 		if source == "" {
@@ -889,8 +926,9 @@ func (s *moduleSchema) moduleSourceResolveFromCaller(
 	if err != nil {
 		return inst, fmt.Errorf("failed to load local module source: %w", err)
 	}
+	contextIdentity := callerHostContextIdentity(ctx, bk, contextAbsPath)
 
-	return s.normalizeCallerLoadedSource(ctx, src, sourceRootRelPath, loadedDir)
+	return s.normalizeCallerLoadedSource(ctx, src, sourceRootRelPath, loadedDir, contextIdentity)
 }
 
 // get an instance of ModuleSource with the context resolved from the caller that doesn't
@@ -901,6 +939,7 @@ func (s *moduleSchema) normalizeCallerLoadedSource(
 	src *core.ModuleSource,
 	sourceRootRelPath string,
 	loadedDir dagql.Instance[*core.Directory],
+	contextIdentity string,
 ) (inst dagql.Instance[*core.ModuleSource], err error) {
 	err = s.dag.Select(ctx, s.dag.Root(), &inst,
 		dagql.Selector{
@@ -915,6 +954,12 @@ func (s *moduleSchema) normalizeCallerLoadedSource(
 				{Name: "dir", Value: dagql.NewID[*core.Directory](loadedDir.ID())},
 			},
 		},
+		dagql.Selector{
+			Field: "__withContextIdentity",
+			Args: []dagql.NamedInput{
+				{Name: "identity", Value: dagql.String(contextIdentity)},
+			},
+		},
 	)
 	if err != nil {
 		return inst, fmt.Errorf("failed to load the context directory: %w", err)
@@ -1197,6 +1242,27 @@ func callerHostFindUpContext(
 	return callerHostFindUpContext(ctx, bk, nextDirPath)
 }
 
+// callerHostContextIdentity identifies the context directory on the caller's
+// host across versions and edits: by the URL of its git remote if it has one,
+// or else by its path.
+func callerHostContextIdentity(
+	ctx context.Context,
+	bk *buildkit.Client,
+	contextAbsPath string,
+) string {
+	// .git may also be missing, or be a file in worktrees and submodules
+	gitConfig, err := bk.ReadCallerHostFile(ctx, filepath.Join(contextAbsPath, ".git", "config"))
+	if err == nil {
+		cfg, err := gitconfig.ReadConfig(bytes.NewReader(gitConfig))
+		if err == nil {
+			if remote, ok := cfg.Remotes["origin"]; ok && len(remote.URLs) > 0 {
+				return "git:" + remote.URLs[0]
+			}
+		}
+	}
+	return "path:" + contextAbsPath
+}
+
 func (s *moduleSchema) moduleSourceResolveDirectoryFromCaller(
 	ctx context.Context,
 	src *core.ModuleSource,
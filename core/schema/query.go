@@ -25,12 +25,22 @@ func (s *querySchema) Install() {
 	core.ImageLayerCompressions.Install(s.srv)
 	core.ImageMediaTypesEnum.Install(s.srv)
 	core.CacheSharingModes.Install(s.srv)
+	core.CacheVolumeScopes.Install(s.srv)
+	core.DirectoryChangeKinds.Install(s.srv)
+	core.DataFormats.Install(s.srv)
 	core.TypeDefKinds.Install(s.srv)
 	core.ModuleSourceKindEnum.Install(s.srv)
+	core.ReturnTypesEnum.Install(s.srv)
+	core.ServiceRestartPolicyEnum.Install(s.srv)
+	core.ServiceStatusEnum.Install(s.srv)
+	core.EngineCacheEntryTypeEnum.Install(s.srv)
+	core.EngineCacheEntrySortByEnum.Install(s.srv)
 
 	dagql.MustInputSpec(PipelineLabel{}).Install(s.srv)
 	dagql.MustInputSpec(core.PortForward{}).Install(s.srv)
 	dagql.MustInputSpec(core.BuildArg{}).Install(s.srv)
+	dagql.MustInputSpec(core.ImageAnnotation{}).Install(s.srv)
+	dagql.MustInputSpec(core.HTTPHeader{}).Install(s.srv)
 
 	dagql.Fields[EnvVariable]{}.Install(s.srv)
 
@@ -544,6 +544,10 @@ func (sdk *goSDK) base(ctx context.Context) (dagql.Instance[*core.Container], er
 				Name:  "key",
 				Value: dagql.String("modgomodcache"),
 			},
+			{
+				Name:  "scope",
+				Value: core.CacheVolumeScopeGlobal,
+			},
 		},
 	}); err != nil {
 		return inst, fmt.Errorf("failed to get mod cache from go module sdk tarball: %w", err)
@@ -556,6 +560,10 @@ func (sdk *goSDK) base(ctx context.Context) (dagql.Instance[*core.Container], er
 				Name:  "key",
 				Value: dagql.String("modgobuildcache"),
 			},
+			{
+				Name:  "scope",
+				Value: core.CacheVolumeScopeGlobal,
+			},
 		},
 	}); err != nil {
 		return inst, fmt.Errorf("failed to get build cache from go module sdk tarball: %w", err)
@@ -3,9 +3,11 @@ package schema
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
+	"github.com/dagger/dagger/engine"
 )
 
 type secretSchema struct {
@@ -22,7 +24,16 @@ func (s *secretSchema) Install() {
 				`The plaintext value is limited to a size of 128000 bytes.`).
 			ArgDoc("name", `The user defined name for this secret`).
 			ArgDoc("plaintext", `The plaintext of the secret`).
-			ArgSensitive("plaintext"),
+			ArgSensitive("plaintext").
+			ArgDoc("ttl",
+				`How long the plaintext is valid for (e.g., "15m").`,
+				`Once expired, the secret's value is fetched again from the client
+				that set it, by asking its session for the secret's source, or for
+				the secret with this name if it has none. Clients connected through
+				the dagger CLI support this; Dagger Functions get an error.`).
+			ArgDoc("source",
+				`Where the client resolves the secret's value from once its ttl
+				expires (e.g., "env:GITHUB_TOKEN", "file:./token.txt" or "cmd:gh auth token").`),
 
 		dagql.Func("secret", s.secret).
 			Doc(`Reference a secret by name.`),
@@ -56,6 +67,8 @@ func (s *secretSchema) secret(ctx context.Context, parent *core.Query, args secr
 type setSecretArgs struct {
 	Name      string
 	Plaintext string `sensitive:"true"` // NB: redundant with ArgSensitive above
+	TTL       string `name:"ttl" default:""`
+	Source    string `default:""`
 }
 
 func (s *secretSchema) setSecret(ctx context.Context, parent *core.Query, args setSecretArgs) (i dagql.Instance[*core.Secret], err error) {
@@ -87,13 +100,60 @@ func (s *secretSchema) setSecret(ctx context.Context, parent *core.Query, args s
 		return i, fmt.Errorf("failed to select secret: %w", err)
 	}
 
-	if err := secretStore.AddSecret(i.Self, args.Name, []byte(args.Plaintext)); err != nil {
+	ttl, err := parseSecretTTL(args.TTL)
+	if err != nil {
+		return i, err
+	}
+	var refetch core.SecretRefetchFunc
+	if ttl > 0 {
+		bk, err := parent.Buildkit(ctx)
+		if err != nil {
+			return i, fmt.Errorf("failed to get buildkit client: %w", err)
+		}
+		ok, err := bk.CallerServesSecrets(ctx)
+		if err != nil {
+			return i, err
+		}
+		if !ok {
+			// without it, the secret would fail for good once expired
+			return i, fmt.Errorf("secret ttl requires a client that can provide the secret's value again, such as one connected through the dagger CLI")
+		}
+		clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+		if err != nil {
+			return i, fmt.Errorf("failed to get client metadata: %w", err)
+		}
+		id := args.Name
+		if args.Source != "" {
+			id = args.Source
+		}
+		refetch = func(ctx context.Context) ([]byte, error) {
+			// ask the client that originally set the secret for its new value
+			ctx = engine.ContextWithClientMetadata(ctx, clientMetadata)
+			return bk.ReadCallerSecret(ctx, id)
+		}
+	}
+
+	if err := secretStore.AddExpiringSecret(i.Self, args.Name, []byte(args.Plaintext), ttl, refetch); err != nil {
 		return i, fmt.Errorf("failed to add secret: %w", err)
 	}
 
 	return i, nil
 }
 
+func parseSecretTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid secret ttl %q: %w", ttl, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid secret ttl %q: must be positive", ttl)
+	}
+	return d, nil
+}
+
 func (s *secretSchema) name(ctx context.Context, secret *core.Secret, args struct{}) (dagql.String, error) {
 	secretStore, err := secret.Query.Secrets(ctx)
 	if err != nil {
@@ -112,9 +172,9 @@ func (s *secretSchema) plaintext(ctx context.Context, secret *core.Secret, args
 	if err != nil {
 		return "", fmt.Errorf("failed to get secret store: %w", err)
 	}
-	plaintext, ok := secretStore.GetSecretPlaintext(secret.IDDigest)
-	if !ok {
-		return "", fmt.Errorf("secret not found: %s", secret.IDDigest)
+	plaintext, err := secretStore.GetSecretPlaintext(ctx, secret.IDDigest)
+	if err != nil {
+		return "", err
 	}
 
 	return dagql.NewString(string(plaintext)), nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime/debug"
+	"time"
 
 	"github.com/dagger/dagger/core"
 	"github.com/dagger/dagger/dagql"
@@ -20,7 +21,10 @@ func (s *serviceSchema) Install() {
 	dagql.Fields[*core.Container]{
 		dagql.Func("asService", s.containerAsService).
 			Doc(`Turn the container into a Service.`,
-				`Be sure to set any exposed ports before this conversion.`),
+				`Be sure to set any exposed ports before this conversion.`).
+			ArgDoc("restartPolicy", `When to restart the service if it exits without being stopped.`).
+			ArgDoc("maxRetries", `The maximum number of times to restart the service with the ON_FAILURE restart policy.`,
+				`Zero means no limit.`),
 	}.Install(s.srv)
 
 	dagql.Fields[*core.Service]{
@@ -39,6 +43,48 @@ func (s *serviceSchema) Install() {
 			ArgDoc("port", `The exposed port number for the endpoint`).
 			ArgDoc("scheme", `Return a URL with the given scheme, eg. http for http://`),
 
+		dagql.Func("withHTTPHealthcheck", s.withHTTPHealthcheck).
+			Doc(`Adds an HTTP readiness probe to the service.`,
+				`The service is only considered started once a GET request to the
+				given port and path responds with the expected status, after all of
+				its exposed ports are reachable.`).
+			ArgDoc("port", `The port to send the request to.`).
+			ArgDoc("path", `The path to request.`).
+			ArgDoc("expectedStatus", `The HTTP status code that indicates the service is ready.`).
+			ArgDoc("interval", `How long to wait between attempts (e.g., "1s").`).
+			ArgDoc("timeout", `How long a single attempt may take before it fails (e.g., "10s").`).
+			ArgDoc("retries", `How many times to retry a failed attempt before giving up.`),
+
+		dagql.Func("withExecHealthcheck", s.withExecHealthcheck).
+			Doc(`Adds a command readiness probe to the service.`,
+				`The service is only considered started once the command, run inside
+				the service container, exits successfully, after all of its exposed
+				ports are reachable.`).
+			ArgDoc("args", `Command to run, with the same environment, user and
+				working directory as the service.`).
+			ArgDoc("interval", `How long to wait between attempts (e.g., "1s").`).
+			ArgDoc("timeout", `How long a single attempt may take before it fails (e.g., "10s").`).
+			ArgDoc("retries", `How many times to retry a failed attempt before giving up.`),
+
+		dagql.NodeFunc("status", s.status).
+			Impure("Reflects the current runtime state of the service.").
+			Doc(`The current state of the service.`),
+
+		dagql.NodeFunc("logs", s.logs).
+			Impure("Reflects the current output of the running service.").
+			Doc(`Retrieves the output of the running service.`,
+				`The service must already be running. Only the most recent output is retained.`).
+			ArgDoc("since", `Only return output written after this time, as either a
+				duration relative to now (e.g., "5m") or an RFC 3339 timestamp.`).
+			ArgDoc("follow", `Stream output to the caller until the service exits, then return all of it.`),
+
+		dagql.NodeFunc("exec", s.exec).
+			Impure("Runs a command against runtime state.").
+			Doc(`Runs a command inside the running service and returns its stdout.`,
+				`The command runs with the same environment, user and working directory
+				as the service. The service must already be running.`).
+			ArgDoc("args", `Command to run.`),
+
 		dagql.NodeFunc("start", s.start).
 			Impure("Imperatively mutates runtime state.").
 			Doc(`Start the service and wait for its health checks to succeed.`,
@@ -58,8 +104,20 @@ func (s *serviceSchema) Install() {
 	}.Install(s.srv)
 }
 
-func (s *serviceSchema) containerAsService(ctx context.Context, parent *core.Container, args struct{}) (*core.Service, error) {
-	return parent.Service(ctx)
+type containerAsServiceArgs struct {
+	RestartPolicy core.ServiceRestartPolicy `default:"NEVER"`
+	MaxRetries    int                       `default:"0"`
+}
+
+func (s *serviceSchema) containerAsService(ctx context.Context, parent *core.Container, args containerAsServiceArgs) (*core.Service, error) {
+	svc, err := parent.Service(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if args.RestartPolicy == core.ServiceRestartNever && args.MaxRetries == 0 {
+		return svc, nil
+	}
+	return svc.WithRestartPolicy(args.RestartPolicy, args.MaxRetries)
 }
 
 func (s *serviceSchema) hostname(ctx context.Context, parent dagql.Instance[*core.Service], args struct{}) (dagql.String, error) {
@@ -74,6 +132,47 @@ func (s *serviceSchema) ports(ctx context.Context, parent dagql.Instance[*core.S
 	return parent.Self.Ports(ctx, parent.ID())
 }
 
+func (s *serviceSchema) status(ctx context.Context, parent dagql.Instance[*core.Service], args struct{}) (core.ServiceStatus, error) {
+	svcs, err := parent.Self.Query.Services(ctx)
+	if err != nil {
+		return "", err
+	}
+	return svcs.Status(ctx, parent.ID())
+}
+
+type serviceLogsArgs struct {
+	Since  string `default:""`
+	Follow bool   `default:"false"`
+}
+
+func (s *serviceSchema) logs(ctx context.Context, parent dagql.Instance[*core.Service], args serviceLogsArgs) (dagql.String, error) {
+	var since time.Time
+	if args.Since != "" {
+		if d, err := time.ParseDuration(args.Since); err == nil {
+			since = time.Now().Add(-d)
+		} else if since, err = time.Parse(time.RFC3339, args.Since); err != nil {
+			return "", fmt.Errorf("invalid since %q: expected a duration or RFC 3339 timestamp", args.Since)
+		}
+	}
+	logs, err := parent.Self.Logs(ctx, parent.ID(), since, args.Follow)
+	if err != nil {
+		return "", err
+	}
+	return dagql.NewString(logs), nil
+}
+
+type serviceExecArgs struct {
+	Args []string
+}
+
+func (s *serviceSchema) exec(ctx context.Context, parent dagql.Instance[*core.Service], args serviceExecArgs) (dagql.String, error) {
+	stdout, err := parent.Self.Exec(ctx, parent.ID(), args.Args)
+	if err != nil {
+		return "", err
+	}
+	return dagql.NewString(stdout), nil
+}
+
 type serviceEndpointArgs struct {
 	Port   dagql.Optional[dagql.Int]
 	Scheme string `default:""`
@@ -87,6 +186,66 @@ func (s *serviceSchema) endpoint(ctx context.Context, parent dagql.Instance[*cor
 	return dagql.NewString(str), nil
 }
 
+type serviceHealthcheckArgs struct {
+	Interval string `default:"1s"`
+	Timeout  string `default:"10s"`
+	Retries  int    `default:"30"`
+}
+
+func (args serviceHealthcheckArgs) healthcheck() (core.ServiceHealthcheck, error) {
+	interval, err := time.ParseDuration(args.Interval)
+	if err != nil {
+		return core.ServiceHealthcheck{}, fmt.Errorf("invalid healthcheck interval: %w", err)
+	}
+	timeout, err := time.ParseDuration(args.Timeout)
+	if err != nil {
+		return core.ServiceHealthcheck{}, fmt.Errorf("invalid healthcheck timeout: %w", err)
+	}
+	return core.ServiceHealthcheck{
+		Interval: interval,
+		Timeout:  timeout,
+		Retries:  args.Retries,
+	}, nil
+}
+
+type serviceWithHTTPHealthcheckArgs struct {
+	Port           int
+	Path           string `default:"/"`
+	ExpectedStatus int    `default:"200"`
+
+	serviceHealthcheckArgs
+}
+
+func (s *serviceSchema) withHTTPHealthcheck(ctx context.Context, parent *core.Service, args serviceWithHTTPHealthcheckArgs) (*core.Service, error) {
+	check, err := args.healthcheck()
+	if err != nil {
+		return nil, err
+	}
+	check.HTTP = &core.HTTPHealthcheck{
+		Port:           args.Port,
+		Path:           args.Path,
+		ExpectedStatus: args.ExpectedStatus,
+	}
+	return parent.WithHealthcheck(check)
+}
+
+type serviceWithExecHealthcheckArgs struct {
+	Args []string
+
+	serviceHealthcheckArgs
+}
+
+func (s *serviceSchema) withExecHealthcheck(ctx context.Context, parent *core.Service, args serviceWithExecHealthcheckArgs) (*core.Service, error) {
+	check, err := args.healthcheck()
+	if err != nil {
+		return nil, err
+	}
+	check.Exec = &core.ExecHealthcheck{
+		Args: args.Args,
+	}
+	return parent.WithHealthcheck(check)
+}
+
 func (s *serviceSchema) start(ctx context.Context, parent dagql.Instance[*core.Service], args struct{}) (core.ServiceID, error) {
 	defer func() {
 		if err := recover(); err != nil {
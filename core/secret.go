@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/opencontainers/go-digest"
@@ -44,6 +45,10 @@ type SecretStore struct {
 	mu      sync.RWMutex
 }
 
+// SecretRefetchFunc re-reads the plaintext of a secret from wherever it
+// originated, e.g. the client that set it.
+type SecretRefetchFunc func(ctx context.Context) ([]byte, error)
+
 // storedSecret has the actual metadata of the Secret. The Secret type is just it's key into the
 // SecretStore, which allows us to pass it around but still more easily enforce that any code that
 // wants to access it has to go through the SecretStore. So storedSecret has all the actual data
@@ -56,6 +61,19 @@ type storedSecret struct {
 
 	// The plaintext value of the secret.
 	Plaintext []byte
+
+	// How long the plaintext is valid for once fetched. If zero, the plaintext
+	// never expires.
+	TTL time.Duration
+
+	// When the plaintext was last fetched.
+	FetchedAt time.Time
+
+	// Called to get a fresh plaintext once the current one has expired.
+	Refetch SecretRefetchFunc
+
+	// Guards Plaintext and FetchedAt, which change when the secret is refetched.
+	mu sync.Mutex
 }
 
 func NewSecretStore() *SecretStore {
@@ -65,6 +83,19 @@ func NewSecretStore() *SecretStore {
 }
 
 func (store *SecretStore) AddSecret(secret *Secret, name string, plaintext []byte) error {
+	return store.AddExpiringSecret(secret, name, plaintext, 0, nil)
+}
+
+// AddExpiringSecret adds a secret whose plaintext is only valid for the given
+// TTL. Once expired, the next access of the secret calls refetch to get its
+// current value.
+func (store *SecretStore) AddExpiringSecret(
+	secret *Secret,
+	name string,
+	plaintext []byte,
+	ttl time.Duration,
+	refetch SecretRefetchFunc,
+) error {
 	if secret == nil {
 		return fmt.Errorf("secret must not be nil")
 	}
@@ -74,6 +105,12 @@ func (store *SecretStore) AddSecret(secret *Secret, name string, plaintext []byt
 	if secret.IDDigest == "" {
 		return fmt.Errorf("secret must have an ID digest")
 	}
+	if ttl < 0 {
+		return fmt.Errorf("secret ttl must not be negative")
+	}
+	if ttl > 0 && refetch == nil {
+		return fmt.Errorf("secret with a ttl must be refetchable")
+	}
 
 	store.mu.Lock()
 	defer store.mu.Unlock()
@@ -81,6 +118,9 @@ func (store *SecretStore) AddSecret(secret *Secret, name string, plaintext []byt
 		Secret:    secret,
 		Name:      name,
 		Plaintext: plaintext,
+		TTL:       ttl,
+		FetchedAt: time.Now(),
+		Refetch:   refetch,
 	}
 	return nil
 }
@@ -92,7 +132,23 @@ func (store *SecretStore) AddSecretFromOtherStore(secret *Secret, otherStore *Se
 	if !ok {
 		return fmt.Errorf("secret %s not found in other store", secret.IDDigest)
 	}
-	return store.AddSecret(secret, secretVals.Name, secretVals.Plaintext)
+
+	secretVals.mu.Lock()
+	plaintext := secretVals.Plaintext
+	fetchedAt := secretVals.FetchedAt
+	secretVals.mu.Unlock()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.secrets[secret.IDDigest] = &storedSecret{
+		Secret:    secret,
+		Name:      secretVals.Name,
+		Plaintext: plaintext,
+		TTL:       secretVals.TTL,
+		FetchedAt: fetchedAt,
+		Refetch:   secretVals.Refetch,
+	}
+	return nil
 }
 
 func (store *SecretStore) HasSecret(idDgst digest.Digest) bool {
@@ -112,14 +168,33 @@ func (store *SecretStore) GetSecretName(idDgst digest.Digest) (string, bool) {
 	return secret.Name, true
 }
 
-func (store *SecretStore) GetSecretPlaintext(idDgst digest.Digest) ([]byte, bool) {
+// GetSecretPlaintext returns the current plaintext of a secret, refetching it
+// first if it has expired.
+func (store *SecretStore) GetSecretPlaintext(ctx context.Context, idDgst digest.Digest) ([]byte, error) {
 	store.mu.RLock()
-	defer store.mu.RUnlock()
 	secret, ok := store.secrets[idDgst]
+	store.mu.RUnlock()
 	if !ok {
-		return nil, false
+		return nil, fmt.Errorf("secret %s: %w", idDgst, secrets.ErrNotFound)
 	}
-	return secret.Plaintext, true
+	return secret.plaintext(ctx)
+}
+
+func (secret *storedSecret) plaintext(ctx context.Context) ([]byte, error) {
+	secret.mu.Lock()
+	defer secret.mu.Unlock()
+
+	if secret.TTL == 0 || time.Since(secret.FetchedAt) < secret.TTL {
+		return secret.Plaintext, nil
+	}
+
+	plaintext, err := secret.Refetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("refetch expired secret %q: %w", secret.Name, err)
+	}
+	secret.Plaintext = plaintext
+	secret.FetchedAt = time.Now()
+	return plaintext, nil
 }
 
 func (store *SecretStore) AsBuildkitSecretStore() secrets.SecretStore {
@@ -133,10 +208,6 @@ type buildkitSecretStore struct {
 
 var _ secrets.SecretStore = &buildkitSecretStore{}
 
-func (bkStore *buildkitSecretStore) GetSecret(_ context.Context, llbID string) ([]byte, error) {
-	plaintext, ok := bkStore.inner.GetSecretPlaintext(digest.Digest(llbID))
-	if !ok {
-		return nil, fmt.Errorf("secret %s: %w", llbID, secrets.ErrNotFound)
-	}
-	return plaintext, nil
+func (bkStore *buildkitSecretStore) GetSecret(ctx context.Context, llbID string) ([]byte, error) {
+	return bkStore.inner.GetSecretPlaintext(ctx, digest.Digest(llbID))
 }
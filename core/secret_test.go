@@ -2,7 +2,10 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/moby/buildkit/session/secrets"
 	"github.com/stretchr/testify/require"
@@ -18,11 +21,54 @@ func TestSecretStore(t *testing.T) {
 	name, ok := store.GetSecretName("dgst")
 	require.True(t, ok)
 	require.Equal(t, "foo", name)
-	plaintext, ok := store.GetSecretPlaintext("dgst")
-	require.True(t, ok)
+	plaintext, err := store.GetSecretPlaintext(context.Background(), "dgst")
+	require.NoError(t, err)
 	require.Equal(t, []byte("bar"), plaintext)
 }
 
+func TestSecretStoreExpiring(t *testing.T) {
+	ctx := context.Background()
+	store := NewSecretStore()
+
+	var refetches int
+	refetch := func(context.Context) ([]byte, error) {
+		refetches++
+		return []byte(fmt.Sprintf("bar-%d", refetches)), nil
+	}
+	require.ErrorContains(t, store.AddExpiringSecret(&Secret{
+		Query:    &Query{},
+		IDDigest: "dgst",
+	}, "foo", []byte("bar"), time.Minute, nil), "must be refetchable")
+	require.NoError(t, store.AddExpiringSecret(&Secret{
+		Query:    &Query{},
+		IDDigest: "dgst",
+	}, "foo", []byte("bar"), time.Minute, refetch))
+
+	// still fresh
+	plaintext, err := store.GetSecretPlaintext(ctx, "dgst")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar"), plaintext)
+	require.Equal(t, 0, refetches)
+
+	// expired, so refetched once and then cached again
+	store.secrets["dgst"].FetchedAt = time.Now().Add(-time.Hour)
+	plaintext, err = store.AsBuildkitSecretStore().GetSecret(ctx, "dgst")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar-1"), plaintext)
+	plaintext, err = store.GetSecretPlaintext(ctx, "dgst")
+	require.NoError(t, err)
+	require.Equal(t, []byte("bar-1"), plaintext)
+	require.Equal(t, 1, refetches)
+
+	// refetch failures are surfaced without touching the old value
+	store.secrets["dgst"].FetchedAt = time.Now().Add(-time.Hour)
+	store.secrets["dgst"].Refetch = func(context.Context) ([]byte, error) {
+		return nil, errors.New("client went away")
+	}
+	_, err = store.GetSecretPlaintext(ctx, "dgst")
+	require.ErrorContains(t, err, "client went away")
+}
+
 func TestSecretStoreNotFound(t *testing.T) {
 	store := NewSecretStore()
 	_, err := store.AsBuildkitSecretStore().GetSecret(context.Background(), "foo")
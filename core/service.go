@@ -8,10 +8,12 @@ import (
 	"net"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	bkgw "github.com/moby/buildkit/frontend/gateway/client"
+	bkgwpb "github.com/moby/buildkit/frontend/gateway/pb"
 	"github.com/moby/buildkit/identity"
 	"github.com/moby/buildkit/solver/pb"
 	"github.com/vektah/gqlparser/v2/ast"
@@ -23,11 +25,20 @@ import (
 	"github.com/dagger/dagger/dagql/call"
 	"github.com/dagger/dagger/engine"
 	"github.com/dagger/dagger/engine/buildkit"
+	"github.com/dagger/dagger/engine/slog"
 	"github.com/dagger/dagger/network"
 )
 
 const (
 	ShimEnableTTYEnvVar = "_DAGGER_ENABLE_TTY"
+
+	// serviceLogLines is how many lines of output to retain for a running
+	// service.
+	serviceLogLines = 10000
+
+	// serviceCrashLogLines is how many lines of output to report when a
+	// service crashes.
+	serviceCrashLogLines = 20
 )
 
 type Service struct {
@@ -43,6 +54,17 @@ type Service struct {
 
 	// The sockets on the host to reverse tunnel
 	HostSockets []*Socket `json:"host_sockets,omitempty"`
+
+	// Healthchecks are readiness probes that must pass before the service is
+	// considered started, in addition to its ports being reachable.
+	Healthchecks []ServiceHealthcheck `json:"healthchecks,omitempty"`
+
+	// RestartPolicy configures whether the service is restarted when it exits
+	// without being stopped.
+	RestartPolicy ServiceRestartPolicy `json:"restart_policy,omitempty"`
+	// MaxRetries limits how many times an ON_FAILURE service is restarted. Zero
+	// means no limit.
+	MaxRetries int `json:"max_retries,omitempty"`
 }
 
 func (*Service) Type() *ast.Type {
@@ -68,9 +90,71 @@ func (svc *Service) Clone() *Service {
 	}
 	cp.TunnelPorts = cloneSlice(cp.TunnelPorts)
 	cp.HostSockets = cloneSlice(cp.HostSockets)
+	cp.Healthchecks = cloneSlice(cp.Healthchecks)
 	return &cp
 }
 
+func (svc *Service) WithRestartPolicy(policy ServiceRestartPolicy, maxRetries int) (*Service, error) {
+	if maxRetries < 0 {
+		return nil, fmt.Errorf("max retries must not be negative")
+	}
+	if maxRetries > 0 && policy != ServiceRestartOnFailure {
+		return nil, fmt.Errorf("max retries is only supported with the %s restart policy", ServiceRestartOnFailure)
+	}
+	svc = svc.Clone()
+	svc.RestartPolicy = policy
+	svc.MaxRetries = maxRetries
+	return svc, nil
+}
+
+// shouldRestart returns whether the service should be restarted after exiting
+// on its own with the given exit code, having already been restarted the given
+// number of times.
+func (svc *Service) shouldRestart(exitCode int, restarts int) bool {
+	switch svc.RestartPolicy {
+	case ServiceRestartAlways:
+		return true
+	case ServiceRestartOnFailure:
+		return exitCode != 0 && (svc.MaxRetries == 0 || restarts < svc.MaxRetries)
+	default:
+		return false
+	}
+}
+
+func (svc *Service) WithHealthcheck(check ServiceHealthcheck) (*Service, error) {
+	if svc.Container == nil {
+		return nil, fmt.Errorf("healthchecks are only supported for container services")
+	}
+	if check.Interval <= 0 {
+		return nil, fmt.Errorf("healthcheck interval must be positive")
+	}
+	if check.Timeout <= 0 {
+		return nil, fmt.Errorf("healthcheck timeout must be positive")
+	}
+	if check.Retries < 0 {
+		return nil, fmt.Errorf("healthcheck retries must not be negative")
+	}
+	switch {
+	case check.HTTP != nil:
+		if check.HTTP.Port <= 0 || check.HTTP.Port > 65535 {
+			return nil, fmt.Errorf("invalid healthcheck port: %d", check.HTTP.Port)
+		}
+		if !strings.HasPrefix(check.HTTP.Path, "/") {
+			check.HTTP.Path = "/" + check.HTTP.Path
+		}
+	case check.Exec != nil:
+		if len(check.Exec.Args) == 0 {
+			return nil, fmt.Errorf("healthcheck command must not be empty")
+		}
+	default:
+		return nil, fmt.Errorf("healthcheck must be an HTTP or exec check")
+	}
+
+	svc = svc.Clone()
+	svc.Healthchecks = append(svc.Healthchecks, check)
+	return svc, nil
+}
+
 func (svc *Service) Hostname(ctx context.Context, id *call.ID) (string, error) {
 	switch {
 	case svc.TunnelUpstream != nil: // host=>container (127.0.0.1)
@@ -194,6 +278,86 @@ func (svc *Service) Stop(ctx context.Context, id *call.ID, kill bool) error {
 	return svcs.Stop(ctx, id, kill)
 }
 
+// Logs returns the output of the running service written at or after since.
+//
+// If follow is set, output is also streamed to the caller's span until the
+// service exits or ctx is canceled, and all of it is returned.
+func (svc *Service) Logs(ctx context.Context, id *call.ID, since time.Time, follow bool) (string, error) {
+	running, err := svc.running(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if running.Logs == nil {
+		return "", fmt.Errorf("logs are only available for container services")
+	}
+
+	if !follow {
+		lines := running.Logs.Since(since)
+		if len(lines) == 0 {
+			return "", nil
+		}
+		return strings.Join(lines, "\n") + "\n", nil
+	}
+
+	stdio := telemetry.SpanStdio(ctx, InstrumentationLibrary)
+	defer stdio.Close()
+
+	out := new(strings.Builder)
+	err = running.Logs.Follow(ctx, since, func(line string) error {
+		out.WriteString(line)
+		out.WriteString("\n")
+		_, err := fmt.Fprintln(stdio.Stdout, line)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Exec runs a command inside the running service and returns its stdout.
+func (svc *Service) Exec(ctx context.Context, id *call.ID, args []string) (string, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("command must not be empty")
+	}
+	running, err := svc.running(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if running.Exec == nil {
+		return "", fmt.Errorf("exec is only available for container services")
+	}
+
+	ctx, span := Tracer().Start(ctx, "exec "+strings.Join(args, " "))
+	defer telemetry.End(span, func() error { return err })
+	stdio := telemetry.SpanStdio(ctx, InstrumentationLibrary)
+	defer stdio.Close()
+
+	stdout, stderr := new(strings.Builder), new(strings.Builder)
+	err = running.Exec(ctx, args,
+		io.MultiWriter(stdout, stdio.Stdout),
+		io.MultiWriter(stderr, stdio.Stderr))
+	if err != nil {
+		exitCode := -1
+		var exitErr *bkgwpb.ExitError
+		if errors.As(err, &exitErr) {
+			exitCode = int(exitErr.ExitCode)
+		}
+		return "", buildkit.NewExecError(err, args, exitCode,
+			strings.TrimSpace(stdout.String()),
+			strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+func (svc *Service) running(ctx context.Context, id *call.ID) (*RunningService, error) {
+	svcs, err := svc.Query.Services(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return svcs.Get(ctx, id)
+}
+
 func (svc *Service) Start(
 	ctx context.Context,
 	id *call.ID,
@@ -371,11 +535,6 @@ func (svc *Service) startContainer(
 		}
 	}()
 
-	checked := make(chan error, 1)
-	go func() {
-		checked <- newHealth(bk, gc, fullHost, ctr.Ports).Check(ctx)
-	}()
-
 	env := append([]string{}, execOp.Meta.Env...)
 	env = append(env, telemetry.PropagationEnv(ctx)...)
 
@@ -393,7 +552,17 @@ func (svc *Service) startContainer(
 		stderrClient, stderrCtr = io.Pipe()
 	}
 
-	svcProc, err := gc.Start(ctx, bkgw.StartRequest{
+	// keep recent output around to retrieve later or report if the service
+	// crashes
+	logs := NewServiceLogs(serviceLogLines)
+	if stdoutCtr == nil {
+		stdoutCtr = nopWriteCloser{logs.Stream()}
+	}
+	if stderrCtr == nil {
+		stderrCtr = nopWriteCloser{logs.Stream()}
+	}
+
+	startReq := bkgw.StartRequest{
 		Args:         execOp.Meta.Args,
 		Env:          env,
 		Cwd:          execOp.Meta.Cwd,
@@ -404,11 +573,23 @@ func (svc *Service) startContainer(
 		Stdout:       stdoutCtr,
 		Stderr:       stderrCtr,
 		SecurityMode: execOp.Security,
-	})
+	}
+	svcProc, err := gc.Start(ctx, startReq)
 	if err != nil {
 		return nil, fmt.Errorf("start container: %w", err)
 	}
 
+	// NB: check health only once the service process is started, since exec
+	// healthchecks run alongside it in the same container
+	checked := make(chan error, 1)
+	go func() {
+		if err := newHealth(bk, gc, fullHost, ctr.Ports).Check(ctx); err != nil {
+			checked <- err
+			return
+		}
+		checked <- newProbeHealth(bk, gc, fullHost, startReq, svc.Healthchecks).Check(ctx)
+	}()
+
 	if forwardStdin != nil {
 		forwardStdin(stdinClient, svcProc)
 	}
@@ -420,6 +601,7 @@ func (svc *Service) startContainer(
 	}
 
 	var exitErr error
+	var stopping atomic.Bool
 	exited := make(chan struct{})
 	go func() {
 		defer func() {
@@ -440,6 +622,33 @@ func (svc *Service) startContainer(
 		defer span.End()
 
 		exitErr = svcProc.Wait()
+		logs.Close()
+
+		if !stopping.Load() && !interactive {
+			// the service exited on its own, so it's probably not what whoever
+			// depends on it expected; report why
+			exitCode := 0
+			var bkExitErr *bkgwpb.ExitError
+			if errors.As(exitErr, &bkExitErr) {
+				exitCode = int(bkExitErr.ExitCode)
+			} else if exitErr != nil {
+				exitCode = int(bkgwpb.UnknownExitStatus)
+			}
+			lines := logs.Tail(serviceCrashLogLines)
+			event := "Service crashed"
+			if exitCode == 0 {
+				event = "Service exited"
+			}
+			span.AddEvent(event, trace.WithAttributes(
+				attribute.Int("exit.code", exitCode),
+				attribute.String("logs", strings.Join(lines, "\n")),
+			))
+			exitErr = &ServiceExitError{
+				ExitCode: exitCode,
+				Logs:     lines,
+				Err:      exitErr,
+			}
+		}
 
 		// detach dependent services when process exits
 		detachDeps()
@@ -453,6 +662,13 @@ func (svc *Service) startContainer(
 	}()
 
 	stopSvc := func(ctx context.Context, force bool) error {
+		stopping.Store(true)
+		select {
+		case <-exited:
+			// already exited, e.g. while waiting to be restarted
+			return nil
+		default:
+		}
 		sig := syscall.SIGTERM
 		if force {
 			sig = syscall.SIGKILL
@@ -480,6 +696,12 @@ func (svc *Service) startContainer(
 	select {
 	case err := <-checked:
 		if err != nil {
+			// don't leave behind a service that never became healthy
+			stopCtx, stopCancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			defer stopCancel()
+			if stopErr := stopSvc(stopCtx, true); stopErr != nil {
+				slog.Warn("failed to stop unhealthy service", "error", stopErr)
+			}
 			return nil, fmt.Errorf("health check errored: %w", err)
 		}
 
@@ -493,6 +715,10 @@ func (svc *Service) startContainer(
 			},
 			Stop: stopSvc,
 			Wait: waitSvc,
+			Logs: logs,
+			Exec: func(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+				return execInService(ctx, gc, startReq, args, stdout, stderr)
+			},
 		}, nil
 	case <-exited:
 		if exitErr != nil {
@@ -502,6 +728,23 @@ func (svc *Service) startContainer(
 	}
 }
 
+// execInService runs a command in a service's container, alongside its main
+// process. The command inherits the environment, working directory and user of
+// the service from req.
+func execInService(ctx context.Context, ctr *buildkit.Container, req bkgw.StartRequest, args []string, stdout, stderr io.Writer) error {
+	req.Args = args
+	req.Tty = false
+	req.Stdin = nil
+	req.Stdout = nopWriteCloser{stdout}
+	req.Stderr = nopWriteCloser{stderr}
+
+	proc, err := ctr.Start(ctx, req)
+	if err != nil {
+		return fmt.Errorf("start: %w", err)
+	}
+	return proc.Wait()
+}
+
 func (svc *Service) startTunnel(ctx context.Context, id *call.ID) (running *RunningService, rerr error) {
 	svcCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
 	defer func() {
@@ -777,3 +1020,99 @@ func (bndp *ServiceBindings) Merge(other ServiceBindings) {
 
 	*bndp = merged
 }
+
+type ServiceRestartPolicy string
+
+var ServiceRestartPolicyEnum = dagql.NewEnum[ServiceRestartPolicy]()
+
+var (
+	ServiceRestartNever = ServiceRestartPolicyEnum.Register("NEVER",
+		"Never restart the service")
+	ServiceRestartOnFailure = ServiceRestartPolicyEnum.Register("ON_FAILURE",
+		"Restart the service when it exits with a non-zero exit code")
+	ServiceRestartAlways = ServiceRestartPolicyEnum.Register("ALWAYS",
+		"Restart the service whenever it exits without being stopped")
+)
+
+func (policy ServiceRestartPolicy) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ServiceRestartPolicy",
+		NonNull:   true,
+	}
+}
+
+func (policy ServiceRestartPolicy) TypeDescription() string {
+	return "When to restart a service that exits on its own."
+}
+
+func (policy ServiceRestartPolicy) Decoder() dagql.InputDecoder {
+	return ServiceRestartPolicyEnum
+}
+
+func (policy ServiceRestartPolicy) ToLiteral() call.Literal {
+	return ServiceRestartPolicyEnum.Literal(policy)
+}
+
+type ServiceStatus string
+
+var ServiceStatusEnum = dagql.NewEnum[ServiceStatus]()
+
+var (
+	ServiceStopped = ServiceStatusEnum.Register("STOPPED",
+		"The service is not running")
+	ServiceStarting = ServiceStatusEnum.Register("STARTING",
+		"The service is starting and waiting for its health checks to pass")
+	ServiceRunning = ServiceStatusEnum.Register("RUNNING",
+		"The service is running")
+	ServiceRestarting = ServiceStatusEnum.Register("RESTARTING",
+		"The service exited and is being restarted according to its restart policy")
+	ServiceCrashed = ServiceStatusEnum.Register("CRASHED",
+		"The service exited without being stopped and was not restarted")
+)
+
+func (status ServiceStatus) Type() *ast.Type {
+	return &ast.Type{
+		NamedType: "ServiceStatus",
+		NonNull:   true,
+	}
+}
+
+func (status ServiceStatus) TypeDescription() string {
+	return "The lifecycle state of a service."
+}
+
+func (status ServiceStatus) Decoder() dagql.InputDecoder {
+	return ServiceStatusEnum
+}
+
+func (status ServiceStatus) ToLiteral() call.Literal {
+	return ServiceStatusEnum.Literal(status)
+}
+
+// ServiceExitError is returned when waiting on a service that exited without
+// being stopped.
+type ServiceExitError struct {
+	// The exit code of the service's process.
+	ExitCode int
+
+	// The last lines of the service's output.
+	Logs []string
+
+	// The error the process exited with, if any.
+	Err error
+}
+
+func (err *ServiceExitError) Error() string {
+	msg := fmt.Sprintf("service exited with code %d", err.ExitCode)
+	if err.Err != nil {
+		msg += ": " + err.Err.Error()
+	}
+	if len(err.Logs) > 0 {
+		msg += "\n" + strings.Join(err.Logs, "\n")
+	}
+	return msg
+}
+
+func (err *ServiceExitError) Unwrap() error {
+	return err.Err
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// ServiceLogs records the most recent output of a running service, so that it
+// can be retrieved or followed after the fact.
+type ServiceLogs struct {
+	max int
+
+	// lines holds at most max lines; dropped counts the lines that have been
+	// discarded before them, so followers can keep their position
+	lines   []serviceLogLine
+	dropped int
+
+	streams []*serviceLogStream
+
+	// changed is closed and replaced whenever lines are added or the logs are
+	// closed
+	changed chan struct{}
+	closed  bool
+
+	mu sync.Mutex
+}
+
+type serviceLogLine struct {
+	Time time.Time
+	Text string
+}
+
+func NewServiceLogs(max int) *ServiceLogs {
+	return &ServiceLogs{
+		max:     max,
+		changed: make(chan struct{}),
+	}
+}
+
+// Stream returns a writer for one of the service's output streams. Each stream
+// buffers its own partial lines, so that interleaved writes to stdout and
+// stderr don't get mixed up.
+func (logs *ServiceLogs) Stream() io.Writer {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	stream := &serviceLogStream{logs: logs}
+	logs.streams = append(logs.streams, stream)
+	return stream
+}
+
+// Close flushes any partial lines and wakes up any followers. It is called when
+// the service exits.
+func (logs *ServiceLogs) Close() {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if logs.closed {
+		return
+	}
+	for _, stream := range logs.streams {
+		if len(stream.partial) > 0 {
+			logs.appendLocked(string(stream.partial))
+			stream.partial = nil
+		}
+	}
+	logs.closed = true
+	close(logs.changed)
+}
+
+// Since returns the lines written at or after the given time. A zero time
+// returns all retained lines.
+func (logs *ServiceLogs) Since(since time.Time) []string {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	var lines []string
+	for _, line := range logs.lines {
+		if !line.Time.Before(since) {
+			lines = append(lines, line.Text)
+		}
+	}
+	return lines
+}
+
+// Tail returns the last n lines, including any partial lines not yet
+// terminated by a newline.
+func (logs *ServiceLogs) Tail(n int) []string {
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	lines := make([]string, 0, n)
+	for _, line := range logs.lines {
+		lines = append(lines, line.Text)
+	}
+	for _, stream := range logs.streams {
+		if len(stream.partial) > 0 {
+			lines = append(lines, string(stream.partial))
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// Follow calls fn with each line written at or after the given time, including
+// lines written in the future, until the logs are closed or ctx is canceled.
+func (logs *ServiceLogs) Follow(ctx context.Context, since time.Time, fn func(string) error) error {
+	logs.mu.Lock()
+	cursor := logs.dropped
+	for _, line := range logs.lines {
+		if !line.Time.Before(since) {
+			break
+		}
+		cursor++
+	}
+	logs.mu.Unlock()
+
+	for {
+		logs.mu.Lock()
+		if cursor < logs.dropped {
+			// fell behind; skip what's been discarded
+			cursor = logs.dropped
+		}
+		pending := append([]serviceLogLine(nil), logs.lines[cursor-logs.dropped:]...)
+		cursor += len(pending)
+		changed, closed := logs.changed, logs.closed
+		logs.mu.Unlock()
+
+		for _, line := range pending {
+			if err := fn(line.Text); err != nil {
+				return err
+			}
+		}
+		if closed {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-changed:
+		}
+	}
+}
+
+func (logs *ServiceLogs) appendLocked(text string) {
+	logs.lines = append(logs.lines, serviceLogLine{
+		Time: time.Now(),
+		Text: text,
+	})
+	if over := len(logs.lines) - logs.max; over > 0 {
+		logs.lines = append([]serviceLogLine(nil), logs.lines[over:]...)
+		logs.dropped += over
+	}
+}
+
+type serviceLogStream struct {
+	logs    *ServiceLogs
+	partial []byte
+}
+
+func (stream *serviceLogStream) Write(p []byte) (int, error) {
+	logs := stream.logs
+	logs.mu.Lock()
+	defer logs.mu.Unlock()
+	if logs.closed {
+		return len(p), nil
+	}
+
+	buf := append(stream.partial, p...)
+	wrote := false
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			break
+		}
+		logs.appendLocked(string(buf[:i]))
+		buf = buf[i+1:]
+		wrote = true
+	}
+	stream.partial = append([]byte(nil), buf...)
+
+	if wrote {
+		close(logs.changed)
+		logs.changed = make(chan struct{})
+	}
+	return len(p), nil
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceLogs(t *testing.T) {
+	logs := NewServiceLogs(3)
+	stdout, stderr := logs.Stream(), logs.Stream()
+
+	fmt.Fprint(stdout, "one\ntw")
+	fmt.Fprint(stderr, "err\n")
+	fmt.Fprint(stdout, "o\n")
+	require.Equal(t, []string{"one", "err", "two"}, logs.Since(time.Time{}))
+
+	// partial lines are included in the tail, and old lines are discarded
+	fmt.Fprint(stdout, "three\nfou")
+	require.Equal(t, []string{"err", "two", "three"}, logs.Since(time.Time{}))
+	require.Equal(t, []string{"three", "fou"}, logs.Tail(2))
+	require.Empty(t, logs.Since(time.Now().Add(time.Minute)))
+
+	// closing flushes partial lines
+	logs.Close()
+	require.Equal(t, []string{"two", "three", "fou"}, logs.Since(time.Time{}))
+}
+
+func TestServiceLogsFollow(t *testing.T) {
+	logs := NewServiceLogs(100)
+	stdout := logs.Stream()
+	fmt.Fprint(stdout, "before\n")
+
+	lines := make(chan string, 100)
+	done := make(chan error, 1)
+	go func() {
+		done <- logs.Follow(context.Background(), time.Time{}, func(line string) error {
+			lines <- line
+			return nil
+		})
+	}()
+	require.Equal(t, "before", <-lines)
+
+	fmt.Fprint(stdout, "during\n")
+	require.Equal(t, "during", <-lines)
+
+	fmt.Fprint(stdout, "last")
+	logs.Close()
+	require.Equal(t, "last", <-lines)
+	require.NoError(t, <-done)
+
+	// canceling stops following
+	logs = NewServiceLogs(100)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		done <- logs.Follow(ctx, time.Time{}, func(string) error { return nil })
+	}()
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
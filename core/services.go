@@ -28,15 +28,23 @@ const (
 	// TerminateGracePeriod is an arbitrary amount of time between when a service is
 	// sent a graceful stop (SIGTERM) and when it is sent an immediate stop (SIGKILL).
 	TerminateGracePeriod = 10 * time.Second
+
+	// RestartDelay is an arbitrary amount of time between when a service exits
+	// and when it is restarted according to its restart policy, to avoid a
+	// crashing service from restarting in a tight loop.
+	RestartDelay = time.Second
 )
 
 // Services manages the lifecycle of services, ensuring the same service only
 // runs once per client.
 type Services struct {
-	starting map[ServiceKey]*sync.WaitGroup
-	running  map[ServiceKey]*RunningService
-	bindings map[ServiceKey]int
-	l        sync.Mutex
+	starting   map[ServiceKey]*sync.WaitGroup
+	running    map[ServiceKey]*RunningService
+	bindings   map[ServiceKey]int
+	restarting map[ServiceKey]bool
+	monitors   map[ServiceKey]context.CancelFunc
+	crashed    map[ServiceKey]*ServiceExitError
+	l          sync.Mutex
 }
 
 // RunningService represents a service that is actively running.
@@ -67,6 +75,14 @@ type RunningService struct {
 
 	// Block until the service has exited or the provided context is canceled.
 	Wait func(ctx context.Context) error
+
+	// Logs records the recent output of the service. It is only set for
+	// Container services.
+	Logs *ServiceLogs
+
+	// Exec runs a command inside the service. It is only set for Container
+	// services.
+	Exec func(ctx context.Context, args []string, stdout, stderr io.Writer) error
 }
 
 // ServiceKey is a unique identifier for a service.
@@ -78,9 +94,12 @@ type ServiceKey struct {
 // NewServices returns a new Services.
 func NewServices() *Services {
 	return &Services{
-		starting: map[ServiceKey]*sync.WaitGroup{},
-		running:  map[ServiceKey]*RunningService{},
-		bindings: map[ServiceKey]int{},
+		starting:   map[ServiceKey]*sync.WaitGroup{},
+		running:    map[ServiceKey]*RunningService{},
+		bindings:   map[ServiceKey]int{},
+		restarting: map[ServiceKey]bool{},
+		monitors:   map[ServiceKey]context.CancelFunc{},
+		crashed:    map[ServiceKey]*ServiceExitError{},
 	}
 }
 
@@ -120,6 +139,35 @@ func (ss *Services) Get(ctx context.Context, id *call.ID) (*RunningService, erro
 	}
 }
 
+// Status returns the lifecycle state of the given service.
+func (ss *Services) Status(ctx context.Context, id *call.ID) (ServiceStatus, error) {
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	key := ServiceKey{
+		Digest:    id.Digest(),
+		SessionID: clientMetadata.SessionID,
+	}
+
+	ss.l.Lock()
+	defer ss.l.Unlock()
+
+	switch {
+	case ss.restarting[key]:
+		return ServiceRestarting, nil
+	case ss.starting[key] != nil:
+		return ServiceStarting, nil
+	case ss.running[key] != nil:
+		return ServiceRunning, nil
+	case ss.crashed[key] != nil:
+		return ServiceCrashed, nil
+	default:
+		return ServiceStopped, nil
+	}
+}
+
 type Startable interface {
 	Start(
 		ctx context.Context,
@@ -184,17 +232,108 @@ dance:
 		return nil, err
 	}
 
+	var monitorCtx context.Context
 	ss.l.Lock()
 	delete(ss.starting, key)
+	delete(ss.crashed, key)
 	ss.running[key] = running
 	ss.bindings[key] = 1
+	if running.Wait != nil {
+		// canceled when the service is stopped, so a pending restart is
+		// abandoned rather than waited out
+		var cancel context.CancelFunc
+		monitorCtx, cancel = context.WithCancel(svcCtx)
+		ss.monitors[key] = cancel
+	}
 	ss.l.Unlock()
 
+	if monitorCtx != nil {
+		go ss.monitor(monitorCtx, key, id, svc, running)
+	}
+
 	_ = stop // leave it running
 
 	return running, nil
 }
 
+// monitor waits for a running service to exit. If it exits on its own, it is
+// either restarted according to its restart policy or marked as crashed.
+func (ss *Services) monitor(ctx context.Context, key ServiceKey, id *call.ID, svc Startable, running *RunningService) {
+	for restarts := 0; ; restarts++ {
+		var exitErr *ServiceExitError
+		if err := running.Wait(ctx); !errors.As(err, &exitErr) {
+			// stopped as normal
+			return
+		}
+
+		slog := slog.With("service", running.Host, "exitCode", exitErr.ExitCode)
+
+		ss.l.Lock()
+		if ss.running[key] != running {
+			// stopped while exiting
+			ss.l.Unlock()
+			return
+		}
+		if running.Service == nil || !running.Service.shouldRestart(exitErr.ExitCode, restarts) {
+			if exitErr.ExitCode == 0 {
+				slog.Debug("service exited")
+			} else {
+				slog.Debug("service crashed")
+			}
+			delete(ss.running, key)
+			delete(ss.bindings, key)
+			ss.stopMonitor(key)
+			ss.crashed[key] = exitErr
+			ss.l.Unlock()
+			return
+		}
+		ss.restarting[key] = true
+		ss.l.Unlock()
+
+		slog.Debug("restarting service", "restarts", restarts)
+
+		delay := time.NewTimer(RestartDelay)
+		select {
+		case <-ctx.Done():
+			// stopped while waiting to restart
+			delay.Stop()
+			ss.l.Lock()
+			delete(ss.restarting, key)
+			ss.l.Unlock()
+			return
+		case <-delay.C:
+		}
+
+		next, err := svc.Start(ctx, id, false, nil, nil, nil)
+
+		ss.l.Lock()
+		delete(ss.restarting, key)
+		if ss.running[key] != running {
+			// stopped while restarting
+			ss.l.Unlock()
+			if err == nil {
+				if err := next.Stop(context.WithoutCancel(ctx), true); err != nil {
+					slog.Warn("failed to stop restarted service", "error", err)
+				}
+			}
+			return
+		}
+		if err != nil {
+			slog.Warn("failed to restart service", "error", err)
+			delete(ss.running, key)
+			delete(ss.bindings, key)
+			ss.stopMonitor(key)
+			ss.crashed[key] = exitErr
+			ss.l.Unlock()
+			return
+		}
+		ss.running[key] = next
+		ss.l.Unlock()
+
+		running = next
+	}
+}
+
 // StartBindings starts each of the bound services in parallel and returns a
 // function that will detach from all of them after 10 seconds.
 func (ss *Services) StartBindings(ctx context.Context, bindings ServiceBindings) (_ func(), _ []*RunningService, err error) {
@@ -350,11 +489,21 @@ func (ss *Services) stop(ctx context.Context, running *RunningService, force boo
 	ss.l.Lock()
 	delete(ss.bindings, running.Key)
 	delete(ss.running, running.Key)
+	ss.stopMonitor(running.Key)
 	ss.l.Unlock()
 
 	return nil
 }
 
+// stopMonitor stops monitoring the service for restarts. It must be called with
+// the lock held.
+func (ss *Services) stopMonitor(key ServiceKey) {
+	if cancel, ok := ss.monitors[key]; ok {
+		cancel()
+		delete(ss.monitors, key)
+	}
+}
+
 func (ss *Services) stopGraceful(ctx context.Context, running *RunningService, timeout time.Duration) error {
 	// attempt to gentle stop within a timeout
 	cause := errors.New("service did not terminate")
@@ -371,6 +520,7 @@ func (ss *Services) stopGraceful(ctx context.Context, running *RunningService, t
 	ss.l.Lock()
 	delete(ss.bindings, running.Key)
 	delete(ss.running, running.Key)
+	ss.stopMonitor(running.Key)
 	ss.l.Unlock()
 	return nil
 }
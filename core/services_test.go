@@ -274,6 +274,126 @@ func TestServicesStartConcurrentSadThenHappy(t *testing.T) {
 	require.Equal(t, 2, stub.Starts())
 }
 
+func TestServicesRestartPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctx = engine.ContextWithClientMetadata(ctx, &engine.ClientMetadata{
+		ClientID: "fake-client",
+	})
+
+	services := core.NewServices()
+
+	stub := newStartable("fake")
+	svc := &core.Service{
+		RestartPolicy: core.ServiceRestartOnFailure,
+		MaxRetries:    1,
+	}
+
+	status := func() core.ServiceStatus {
+		status, err := services.Status(ctx, stub.ID())
+		require.NoError(t, err)
+		return status
+	}
+	require.Equal(t, core.ServiceStopped, status())
+
+	first, exitFirst := stub.SucceedWithExit(svc)
+	running, err := services.Start(ctx, stub.ID(), stub)
+	require.NoError(t, err)
+	require.Equal(t, first, running)
+	require.Equal(t, core.ServiceRunning, status())
+
+	// crash once; it should be restarted
+	second, exitSecond := stub.SucceedWithExit(svc)
+	exitFirst <- &core.ServiceExitError{ExitCode: 1}
+	require.Eventually(t, func() bool {
+		running, err := services.Get(ctx, stub.ID())
+		return err == nil && running == second
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, stub.Starts())
+	require.Equal(t, core.ServiceRunning, status())
+
+	// crash again; out of retries, so it stays down
+	exitSecond <- &core.ServiceExitError{ExitCode: 1}
+	require.Eventually(t, func() bool {
+		return status() == core.ServiceCrashed
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, stub.Starts())
+
+	_, err = services.Get(ctx, stub.ID())
+	require.Error(t, err)
+
+	// starting it again clears the crash
+	stub.Succeed()
+	_, err = services.Start(ctx, stub.ID(), stub)
+	require.NoError(t, err)
+	require.Equal(t, core.ServiceRunning, status())
+}
+
+func TestServicesRestartPolicyNever(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctx = engine.ContextWithClientMetadata(ctx, &engine.ClientMetadata{
+		ClientID: "fake-client",
+	})
+
+	services := core.NewServices()
+
+	stub := newStartable("fake")
+
+	_, exit := stub.SucceedWithExit(&core.Service{})
+	_, err := services.Start(ctx, stub.ID(), stub)
+	require.NoError(t, err)
+
+	// a clean exit is still unexpected for a service
+	exit <- &core.ServiceExitError{ExitCode: 0}
+	require.Eventually(t, func() bool {
+		status, err := services.Status(ctx, stub.ID())
+		require.NoError(t, err)
+		return status == core.ServiceCrashed
+	}, 10*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, stub.Starts())
+}
+
+func TestServicesStopWhileRestarting(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ctx = engine.ContextWithClientMetadata(ctx, &engine.ClientMetadata{
+		ClientID:  "fake-client",
+		SessionID: "doesnt-matter",
+	})
+
+	services := core.NewServices()
+
+	stub := newStartable("fake")
+
+	_, exit := stub.SucceedWithExit(&core.Service{
+		RestartPolicy: core.ServiceRestartAlways,
+	})
+	_, err := services.Start(ctx, stub.ID(), stub)
+	require.NoError(t, err)
+
+	exit <- &core.ServiceExitError{ExitCode: 0}
+	require.Eventually(t, func() bool {
+		status, err := services.Status(ctx, stub.ID())
+		require.NoError(t, err)
+		return status == core.ServiceRestarting
+	}, 10*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, services.Stop(ctx, stub.ID(), true))
+
+	// the pending restart is abandoned rather than waited out
+	require.Eventually(t, func() bool {
+		status, err := services.Status(ctx, stub.ID())
+		require.NoError(t, err)
+		return status == core.ServiceStopped
+	}, core.RestartDelay/2, 10*time.Millisecond)
+	time.Sleep(2 * core.RestartDelay)
+	require.Equal(t, 1, stub.Starts())
+}
+
 type fakeStartable struct {
 	name   string
 	digest digest.Digest
@@ -288,13 +408,14 @@ type startResult struct {
 }
 
 func newStartable(id string) *fakeStartable {
-	return &fakeStartable{
-		name:   id,
-		digest: digest.FromString(id),
+	f := &fakeStartable{
+		name: id,
 
 		// just buffer 100 to keep things simple
 		startResults: make(chan startResult, 100),
 	}
+	f.digest = f.ID().Digest()
+	return f
 }
 
 func (f *fakeStartable) ID() *call.ID {
@@ -331,6 +452,37 @@ func (f *fakeStartable) Succeed() *core.RunningService {
 	return running
 }
 
+// SucceedWithExit is like Succeed, but the running service exits with the
+// error sent on the returned channel.
+func (f *fakeStartable) SucceedWithExit(svc *core.Service) (*core.RunningService, chan<- error) {
+	exit := make(chan error, 1)
+	running := &core.RunningService{
+		Service: svc,
+		Key: core.ServiceKey{
+			Digest:    f.digest,
+			SessionID: "doesnt-matter",
+		},
+		Host: f.name + "-host",
+		Stop: func(context.Context, bool) error {
+			return nil
+		},
+		Wait: func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-exit:
+				return err
+			}
+		},
+	}
+
+	f.startResults <- startResult{
+		Started: running,
+	}
+
+	return running, exit
+}
+
 func (f *fakeStartable) Fail() error {
 	err := errors.New("oh no")
 	f.startResults <- startResult{
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/fs"
 
+	bkcache "github.com/moby/buildkit/cache"
 	cacheconfig "github.com/moby/buildkit/cache/config"
 	"github.com/moby/buildkit/client/llb"
 	bkgw "github.com/moby/buildkit/frontend/gateway/client"
@@ -49,14 +50,22 @@ func (c *Client) DefToBlob(
 	if !ok {
 		return nil, desc, fmt.Errorf("invalid ref: %T", cachedRes.Sys())
 	}
-	ref := workerRef.ImmutableRef
+	return c.refToBlob(ctx, workerRef.ImmutableRef, compressionType)
+}
 
+// refToBlob converts the given immutable ref to a content addressed blob, like
+// DefToBlob.
+func (c *Client) refToBlob(
+	ctx context.Context,
+	ref bkcache.ImmutableRef,
+	compressionType compression.Type,
+) (_ *bksolverpb.Definition, desc specs.Descriptor, _ error) {
 	// Force an unlazy of the copy in case it was lazy due to remote caching; we
 	// need it to exist locally or else blob source won't work.
 	// NOTE: in theory we could keep it lazy if we could get the descriptor handlers
 	// for the remote over to the blob source code, but the plumbing to accomplish that
 	// is tricky and ultimately only result in a marginal performance optimization.
-	err = ref.Extract(ctx, nil)
+	err := ref.Extract(ctx, nil)
 	if err != nil {
 		return nil, desc, fmt.Errorf("failed to extract ref: %w", err)
 	}
@@ -0,0 +1,190 @@
+package buildkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/containerd/continuity/fs"
+	bkcache "github.com/moby/buildkit/cache"
+	bksession "github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/solver/llbsolver/mounts"
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/compression"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	fscopy "github.com/tonistiigi/fsutil/copy"
+)
+
+// CacheVolumeSize returns the disk space used by the contents of the cache
+// volume with the given id, or 0 if it doesn't exist yet.
+func (c *Client) CacheVolumeSize(ctx context.Context, id string) (int64, error) {
+	var size int64
+	err := c.withCacheVolume(ctx, id, true, func(root string) error {
+		entries, err := os.ReadDir(root)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		paths := make([]string, len(entries))
+		for i, entry := range entries {
+			paths[i] = filepath.Join(root, entry.Name())
+		}
+		usage, err := fs.DiskUsage(ctx, paths...)
+		if err != nil {
+			return fmt.Errorf("failed to get disk usage: %w", err)
+		}
+		size = usage.Size
+		return nil
+	})
+	return size, err
+}
+
+// CacheVolumeSnapshot copies the current contents of the cache volume with the
+// given id into a content addressed blob, like DefToBlob.
+func (c *Client) CacheVolumeSnapshot(ctx context.Context, id string) (_ *bksolverpb.Definition, desc specs.Descriptor, rerr error) {
+	cm := c.Worker.CacheManager()
+	group := bksession.NewGroup(c.ID())
+
+	mref, err := cm.New(ctx, nil, group,
+		bkcache.WithDescription(fmt.Sprintf("snapshot of cache volume %s", id)))
+	if err != nil {
+		return nil, desc, fmt.Errorf("failed to create snapshot ref: %w", err)
+	}
+	defer func() {
+		if mref != nil {
+			mref.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mountable, err := mref.Mount(ctx, false, group)
+	if err != nil {
+		return nil, desc, fmt.Errorf("failed to mount snapshot ref: %w", err)
+	}
+	err = withMount(mountable, func(dst string) error {
+		return c.withCacheVolume(ctx, id, true, func(src string) error {
+			return copyDirContents(ctx, src, "/", dst)
+		})
+	})
+	if err != nil {
+		return nil, desc, err
+	}
+
+	iref, err := mref.Commit(ctx)
+	if err != nil {
+		return nil, desc, fmt.Errorf("failed to commit snapshot ref: %w", err)
+	}
+	mref = nil
+	defer iref.Release(context.WithoutCancel(ctx))
+
+	return c.refToBlob(ctx, iref, compression.Zstd)
+}
+
+// SeedCacheVolume replaces the contents of the cache volume with the given id
+// with the contents of srcPath in the given llb definition, creating the
+// volume if needed.
+func (c *Client) SeedCacheVolume(ctx context.Context, id string, pbDef *bksolverpb.Definition, srcPath string) error {
+	srcRef, err := c.solveImmutableRef(ctx, pbDef)
+	if err != nil {
+		return err
+	}
+
+	return c.withCacheVolumeCreate(ctx, id, func(dst string) error {
+		if err := removeAllUnderDir(dst); err != nil {
+			return fmt.Errorf("failed to clear cache volume: %w", err)
+		}
+		if srcRef == nil {
+			// scratch
+			return nil
+		}
+		mountable, err := srcRef.Mount(ctx, true, bksession.NewGroup(c.ID()))
+		if err != nil {
+			return fmt.Errorf("failed to mount source: %w", err)
+		}
+		return withMount(mountable, func(src string) error {
+			return copyDirContents(ctx, src, srcPath, dst)
+		})
+	})
+}
+
+// ClearCacheVolume removes the contents of the cache volume with the given
+// id, if it exists.
+func (c *Client) ClearCacheVolume(ctx context.Context, id string) error {
+	return c.withCacheVolume(ctx, id, false, func(root string) error {
+		if err := removeAllUnderDir(root); err != nil {
+			return fmt.Errorf("failed to clear cache volume: %w", err)
+		}
+		return nil
+	})
+}
+
+// withCacheVolume mounts the cache volume with the given id and calls cb with
+// its root. The cache volume is shared with any execs currently using it. If
+// the volume doesn't exist yet, cb is not called.
+func (c *Client) withCacheVolume(ctx context.Context, id string, readonly bool, cb func(root string) error) error {
+	mds, err := mounts.SearchCacheDir(ctx, c.Worker.CacheManager(), id)
+	if err != nil {
+		return fmt.Errorf("failed to search cache volume: %w", err)
+	}
+	if len(mds) == 0 {
+		return nil
+	}
+	return c.mountCacheVolume(ctx, id, readonly, cb)
+}
+
+// withCacheVolumeCreate is like withCacheVolume, but creates the volume if it
+// doesn't exist yet.
+func (c *Client) withCacheVolumeCreate(ctx context.Context, id string, cb func(root string) error) error {
+	return c.mountCacheVolume(ctx, id, false, cb)
+}
+
+func (c *Client) mountCacheVolume(ctx context.Context, id string, readonly bool, cb func(root string) error) error {
+	group := bksession.NewGroup(c.ID())
+	mountManager := mounts.NewMountManager("dagger cache volume", c.Worker.CacheManager(), c.SessionManager)
+	ref, err := mountManager.MountableCache(ctx, &bksolverpb.Mount{
+		CacheOpt: &bksolverpb.CacheOpt{
+			ID:      id,
+			Sharing: bksolverpb.CacheSharingOpt_SHARED,
+		},
+	}, nil, group)
+	if err != nil {
+		return fmt.Errorf("failed to get cache volume ref: %w", err)
+	}
+	defer ref.Release(context.WithoutCancel(ctx))
+
+	mountable, err := ref.Mount(ctx, readonly, group)
+	if err != nil {
+		return fmt.Errorf("failed to mount cache volume: %w", err)
+	}
+	return withMount(mountable, cb)
+}
+
+func copyDirContents(ctx context.Context, srcRoot, srcPath, dstRoot string) error {
+	srcPath = filepath.Clean("/" + srcPath)
+	if _, err := os.Stat(filepath.Join(srcRoot, srcPath)); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", srcPath, err)
+	}
+	err := fscopy.Copy(ctx, srcRoot, srcPath, dstRoot, "/", fscopy.WithCopyInfo(fscopy.CopyInfo{
+		CopyDirContents: true,
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to copy %s: %w", srcPath, err)
+	}
+	return nil
+}
+
+// removeAllUnderDir removes the contents of dir, but not dir itself.
+func removeAllUnderDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
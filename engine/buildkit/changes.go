@@ -0,0 +1,169 @@
+package buildkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containerd/continuity/fs"
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+)
+
+// Change is a change to a path between two directories.
+type Change struct {
+	Kind fs.ChangeKind
+	// Path is relative to the root of the directories.
+	Path string
+
+	// Lower is the path's info in the lower directory, unless it was added.
+	Lower os.FileInfo
+	// Upper is the path's info in the upper directory, unless it was deleted.
+	Upper os.FileInfo
+}
+
+// DirectoryChanges returns the changes from lowerPath in the lower llb
+// definition to upperPath in the upper one, using the same diffing as
+// llb.Diff. Paths whose metadata changed but whose content didn't, like
+// directories with modified children or files with new timestamps, are
+// omitted.
+func (c *Client) DirectoryChanges(
+	ctx context.Context,
+	lowerDef *bksolverpb.Definition, lowerPath string,
+	upperDef *bksolverpb.Definition, upperPath string,
+) ([]Change, error) {
+	var changes []Change
+	err := c.withReadonlyDef(ctx, lowerDef, func(lowerRoot string) error {
+		return c.withReadonlyDef(ctx, upperDef, func(upperRoot string) error {
+			lower, err := rootPath(lowerRoot, lowerPath)
+			if err != nil {
+				return err
+			}
+			upper, err := rootPath(upperRoot, upperPath)
+			if err != nil {
+				return err
+			}
+			changes, err = diffChanges(ctx, lower, upper)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func diffChanges(ctx context.Context, lower, upper string) ([]Change, error) {
+	var changes []Change
+	err := fs.Changes(ctx, lower, upper, func(kind fs.ChangeKind, p string, upperInfo os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		change := Change{
+			Kind: kind,
+			Path: strings.TrimPrefix(p, "/"),
+		}
+		if kind != fs.ChangeKindDelete {
+			change.Upper = upperInfo
+		}
+		if kind != fs.ChangeKindAdd {
+			change.Lower, err = os.Lstat(filepath.Join(lower, p))
+			if err != nil {
+				return fmt.Errorf("failed to stat %s: %w", p, err)
+			}
+		}
+		if kind == fs.ChangeKindModify {
+			same, err := sameContent(filepath.Join(lower, p), change.Lower, filepath.Join(upper, p), change.Upper)
+			if err != nil {
+				return err
+			}
+			if same {
+				return nil
+			}
+		}
+		changes = append(changes, change)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// rootPath joins p to root, evaluating symlinks within root, and fails if it
+// doesn't exist.
+func rootPath(root, p string) (string, error) {
+	full, err := fs.RootPath(root, p)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Lstat(full); err != nil {
+		return "", fmt.Errorf("failed to stat %s: %w", p, err)
+	}
+	return full, nil
+}
+
+// sameContent reports whether two versions of a modified path have the same
+// type, permissions and content.
+func sameContent(lowerPath string, lower os.FileInfo, upperPath string, upper os.FileInfo) (bool, error) {
+	if lower.Mode() != upper.Mode() {
+		return false, nil
+	}
+	switch {
+	case lower.IsDir():
+		return true, nil
+	case lower.Mode()&os.ModeSymlink != 0:
+		lowerTarget, err := os.Readlink(lowerPath)
+		if err != nil {
+			return false, err
+		}
+		upperTarget, err := os.Readlink(upperPath)
+		if err != nil {
+			return false, err
+		}
+		return lowerTarget == upperTarget, nil
+	case lower.Mode().IsRegular():
+		if lower.Size() != upper.Size() {
+			return false, nil
+		}
+		return sameFileContent(lowerPath, upperPath)
+	default:
+		// devices, fifos, etc. have no content to compare
+		return false, nil
+	}
+}
+
+func sameFileContent(a, b string) (bool, error) {
+	fa, err := os.Open(a)
+	if err != nil {
+		return false, err
+	}
+	defer fa.Close()
+	fb, err := os.Open(b)
+	if err != nil {
+		return false, err
+	}
+	defer fb.Close()
+
+	bufA := make([]byte, 32*1024)
+	bufB := make([]byte, 32*1024)
+	for {
+		na, errA := io.ReadFull(fa, bufA)
+		nb, errB := io.ReadFull(fb, bufB)
+		if !bytes.Equal(bufA[:na], bufB[:nb]) {
+			return false, nil
+		}
+		if errA == io.EOF || errA == io.ErrUnexpectedEOF {
+			return errB == io.EOF || errB == io.ErrUnexpectedEOF, nil
+		}
+		if errA != nil {
+			return false, errA
+		}
+		if errB != nil {
+			return false, errB
+		}
+	}
+}
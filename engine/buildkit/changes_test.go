@@ -0,0 +1,86 @@
+package buildkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/containerd/continuity/fs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffChanges(t *testing.T) {
+	lower := t.TempDir()
+	upper := t.TempDir()
+
+	write := func(root, path, content string, mode os.FileMode) {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), mode))
+		require.NoError(t, os.Chmod(full, mode))
+	}
+
+	write(lower, "same.txt", "same", 0o644)
+	write(upper, "same.txt", "same", 0o644)
+	// same content, different timestamps
+	write(lower, "sub/touched.txt", "touched", 0o644)
+	write(upper, "sub/touched.txt", "touched", 0o644)
+	require.NoError(t, os.Chtimes(filepath.Join(upper, "sub/touched.txt"), time.Now(), time.Now().Add(time.Hour)))
+	write(lower, "sub/modified.txt", "old", 0o644)
+	write(upper, "sub/modified.txt", "new", 0o644)
+	// like llb.Diff, files with the same size and timestamp are assumed to be
+	// unchanged, so make sure they differ as they would across snapshots
+	require.NoError(t, os.Chtimes(filepath.Join(upper, "sub/modified.txt"), time.Now(), time.Now().Add(time.Hour)))
+	write(lower, "chmod.sh", "#!/bin/sh", 0o644)
+	write(upper, "chmod.sh", "#!/bin/sh", 0o755)
+	write(lower, "deleted.txt", "deleted", 0o644)
+	write(upper, "added.txt", "added!", 0o600)
+	require.NoError(t, os.Symlink("same.txt", filepath.Join(lower, "link")))
+	require.NoError(t, os.Symlink("added.txt", filepath.Join(upper, "link")))
+
+	changes, err := diffChanges(context.Background(), lower, upper)
+	require.NoError(t, err)
+
+	type summary struct {
+		Kind fs.ChangeKind
+		Path string
+	}
+	var summaries []summary
+	for _, change := range changes {
+		summaries = append(summaries, summary{change.Kind, change.Path})
+		switch change.Kind {
+		case fs.ChangeKindAdd:
+			require.Nil(t, change.Lower)
+			require.NotNil(t, change.Upper)
+		case fs.ChangeKindDelete:
+			require.NotNil(t, change.Lower)
+			require.Nil(t, change.Upper)
+		default:
+			require.NotNil(t, change.Lower)
+			require.NotNil(t, change.Upper)
+		}
+	}
+	require.ElementsMatch(t, []summary{
+		{fs.ChangeKindAdd, "added.txt"},
+		{fs.ChangeKindModify, "chmod.sh"},
+		{fs.ChangeKindDelete, "deleted.txt"},
+		{fs.ChangeKindModify, "link"},
+		{fs.ChangeKindModify, "sub/modified.txt"},
+	}, summaries)
+}
+
+func TestRootPath(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0o755))
+	require.NoError(t, os.Symlink("/etc", filepath.Join(root, "escape")))
+
+	p, err := rootPath(root, "sub")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(root, "sub"), p)
+
+	// symlinks are resolved within the root
+	_, err = rootPath(root, "escape/passwd")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -18,6 +18,7 @@ import (
 	bkcontainer "github.com/moby/buildkit/frontend/gateway/container"
 	"github.com/moby/buildkit/identity"
 	bksession "github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
 	bksolver "github.com/moby/buildkit/solver"
 	bksolverpb "github.com/moby/buildkit/solver/pb"
 	solverresult "github.com/moby/buildkit/solver/result"
@@ -37,6 +38,7 @@ const (
 
 	// from buildkit, cannot change
 	EntitlementsJobKey = "llb.entitlements"
+	SourcePolicyJobKey = "llb.sourcepolicy"
 
 	// OCIStoreName is the name of the OCI content store used for OCI tarball
 	// imports.
@@ -59,6 +61,7 @@ type Opts struct {
 	Entitlements         entitlements.Set
 	UpstreamCacheImports []bkgw.CacheOptionsEntry
 	Frontends            map[string]bkfrontend.Frontend
+	ImagePolicy          ImagePolicy
 
 	Refs         map[Reference]struct{}
 	RefsMu       *sync.Mutex
@@ -453,6 +456,32 @@ func (c *Client) GetSessionCaller(ctx context.Context, wait bool) (_ bksession.C
 	return caller, nil
 }
 
+// ReadCallerSecret asks the caller's session for the current plaintext of
+// the secret with the given id.
+func (c *Client) ReadCallerSecret(ctx context.Context, id string) ([]byte, error) {
+	ctx, cancel, err := c.withClientCloseCancel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	clientCaller, err := c.GetSessionCaller(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get requester session: %w", err)
+	}
+	return secrets.GetSecret(ctx, clientCaller, id)
+}
+
+// CallerServesSecrets returns whether the caller's session can answer
+// ReadCallerSecret, which only clients that attach a secret store do.
+func (c *Client) CallerServesSecrets(ctx context.Context) (bool, error) {
+	clientCaller, err := c.GetSessionCaller(ctx, false)
+	if err != nil {
+		return false, fmt.Errorf("failed to get requester session: %w", err)
+	}
+	return clientCaller.Supports(bksession.MethodURL("moby.buildkit.secrets.v1.Secrets", "GetSecret")), nil
+}
+
 func (c *Client) ListenHostToContainer(
 	ctx context.Context,
 	hostListenAddr, proto, upstream string,
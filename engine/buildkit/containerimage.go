@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"strconv"
 	"strings"
 
 	"github.com/containerd/platforms"
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	bkcache "github.com/moby/buildkit/cache"
 	bkclient "github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/moby/buildkit/frontend/attestations/sbom"
 	bkgw "github.com/moby/buildkit/frontend/gateway/client"
+	bkgwpb "github.com/moby/buildkit/frontend/gateway/pb"
 	bksolverpb "github.com/moby/buildkit/solver/pb"
 	solverresult "github.com/moby/buildkit/solver/result"
 	specs "github.com/opencontainers/image-spec/specs-go/v1"
@@ -23,12 +27,93 @@ import (
 type ContainerExport struct {
 	Definition *bksolverpb.Definition
 	Config     specs.ImageConfig
+
+	// Attestations are attached to the image in an attestation manifest.
+	Attestations []ContainerAttestation
+
+	// Annotations are set on this platform's manifest only, in addition to
+	// ImageExportOpts.ManifestAnnotations.
+	Annotations map[string]string
+}
+
+// ImageExportOpts configures how container images are published or exported.
+type ImageExportOpts struct {
+	// Names are the references to push the image to.
+	Names []string
+
+	// OCIMediaTypes uses OCI rather than Docker media types.
+	OCIMediaTypes bool
+
+	// Compression forces each layer to be compressed with this algorithm, e.g.
+	// "gzip", "zstd" or "estargz", at CompressionLevel if it's set. If it's
+	// empty, layers keep the compression of their cached blobs if they have
+	// any, or are compressed with gzip.
+	Compression      string
+	CompressionLevel *int
+
+	// Annotations to set on each image manifest and on the index listing them.
+	ManifestAnnotations map[string]string
+	IndexAnnotations    map[string]string
+}
+
+func (opts ImageExportOpts) exporterAttrs(inputByPlatform map[string]ContainerExport) (map[string]string, error) {
+	attrs := map[string]string{
+		string(exptypes.OptKeyOCITypes): strconv.FormatBool(opts.OCIMediaTypes),
+	}
+	if len(opts.Names) > 0 {
+		attrs[string(exptypes.OptKeyName)] = strings.Join(opts.Names, ",")
+	}
+	if opts.Compression != "" {
+		attrs[string(exptypes.OptKeyLayerCompression)] = opts.Compression
+		attrs[string(exptypes.OptKeyForceCompression)] = strconv.FormatBool(true)
+	}
+	if opts.CompressionLevel != nil {
+		attrs[string(exptypes.OptKeyCompressionLevel)] = strconv.Itoa(*opts.CompressionLevel)
+	}
+	for k, v := range opts.ManifestAnnotations {
+		attrs[exptypes.AnnotationManifestKey(nil, k)] = v
+	}
+	for k, v := range opts.IndexAnnotations {
+		attrs[exptypes.AnnotationIndexKey(k)] = v
+	}
+	for platformString, input := range inputByPlatform {
+		// a single platform is exported without an index, which only gets the
+		// annotations that aren't specific to a platform
+		var platform *specs.Platform
+		if len(inputByPlatform) > 1 {
+			p, err := platforms.Parse(platformString)
+			if err != nil {
+				return nil, err
+			}
+			platform = &p
+		}
+		for k, v := range input.Annotations {
+			attrs[exptypes.AnnotationManifestKey(platform, k)] = v
+		}
+	}
+	return attrs, nil
+}
+
+// ContainerAttestation is an in-toto attestation about a container image, like
+// an SPDX SBOM or SLSA provenance.
+type ContainerAttestation struct {
+	PredicateType string
+
+	// Definition and Path locate the file containing the predicate or, if
+	// Bundle is set, a directory of complete in-toto statements like the ones
+	// BuildKit SBOM scanners write.
+	Definition *bksolverpb.Definition
+	Path       string
+	Bundle     bool
+
+	// Content is the predicate, if it's not read from Definition.
+	Content []byte
 }
 
 func (c *Client) PublishContainerImage(
 	ctx context.Context,
 	inputByPlatform map[string]ContainerExport,
-	opts map[string]string, // TODO: make this an actual type, this leaks too much untyped buildkit api
+	opts ImageExportOpts,
 ) (map[string]string, error) {
 	ctx = buildkitTelemetryContext(ctx)
 	ctx, cancel, err := c.withClientCloseCancel(ctx)
@@ -47,7 +132,12 @@ func (c *Client) PublishContainerImage(
 		return nil, err
 	}
 
-	expInstance, err := exporter.Resolve(ctx, 0, opts)
+	attrs, err := opts.exporterAttrs(inputByPlatform)
+	if err != nil {
+		return nil, err
+	}
+	attrs[string(exptypes.OptKeyPush)] = strconv.FormatBool(true)
+	expInstance, err := exporter.Resolve(ctx, 0, attrs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve exporter: %w", err)
 	}
@@ -66,7 +156,7 @@ func (c *Client) ExportContainerImage(
 	ctx context.Context,
 	inputByPlatform map[string]ContainerExport,
 	destPath string,
-	opts map[string]string, // TODO: make this an actual type, this leaks too much untyped buildkit api
+	opts ImageExportOpts,
 ) (map[string]string, error) {
 	ctx = buildkitTelemetryContext(ctx)
 	ctx, cancel, err := c.withClientCloseCancel(ctx)
@@ -86,7 +176,8 @@ func (c *Client) ExportContainerImage(
 	}
 
 	exporterName := bkclient.ExporterDocker
-	if len(combinedResult.Refs) > 1 {
+	if len(combinedResult.Refs) > 1 || len(combinedResult.Attestations) > 0 {
+		// the docker exporter can't export the index attestations require
 		exporterName = bkclient.ExporterOCI
 	}
 
@@ -95,7 +186,12 @@ func (c *Client) ExportContainerImage(
 		return nil, err
 	}
 
-	expInstance, err := exporter.Resolve(ctx, 0, opts)
+	attrs, err := opts.exporterAttrs(inputByPlatform)
+	if err != nil {
+		return nil, err
+	}
+	attrs["tar"] = strconv.FormatBool(true)
+	expInstance, err := exporter.Resolve(ctx, 0, attrs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve exporter: %w", err)
 	}
@@ -125,7 +221,7 @@ func (c *Client) ContainerImageToTarball(
 	engineHostPlatform specs.Platform,
 	fileName string,
 	inputByPlatform map[string]ContainerExport,
-	opts map[string]string,
+	opts ImageExportOpts,
 ) (*bksolverpb.Definition, error) {
 	ctx = buildkitTelemetryContext(ctx)
 	ctx, cancel, err := c.withClientCloseCancel(ctx)
@@ -140,7 +236,8 @@ func (c *Client) ContainerImageToTarball(
 	}
 
 	exporterName := bkclient.ExporterDocker
-	if len(combinedResult.Refs) > 1 {
+	if len(combinedResult.Refs) > 1 || len(combinedResult.Attestations) > 0 {
+		// the docker exporter can't export the index attestations require
 		exporterName = bkclient.ExporterOCI
 	}
 
@@ -149,7 +246,12 @@ func (c *Client) ContainerImageToTarball(
 		return nil, err
 	}
 
-	expInstance, err := exporter.Resolve(ctx, 0, opts)
+	attrs, err := opts.exporterAttrs(inputByPlatform)
+	if err != nil {
+		return nil, err
+	}
+	attrs["tar"] = strconv.FormatBool(true)
+	expInstance, err := exporter.Resolve(ctx, 0, attrs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve exporter: %w", err)
 	}
@@ -206,6 +308,10 @@ func (c *Client) getContainerResult(
 		if err != nil {
 			return nil, err
 		}
+		atts, err := c.containerAttestations(ctx, input.Attestations)
+		if err != nil {
+			return nil, err
+		}
 
 		platform, err := platforms.Parse(platformString)
 		if err != nil {
@@ -227,6 +333,14 @@ func (c *Client) getContainerResult(
 		if len(inputByPlatform) == 1 {
 			combinedResult.AddMeta(exptypes.ExporterImageConfigKey, cfgBytes)
 			combinedResult.SetRef(ref)
+
+			// attestations are keyed by the platform the exporter derives from
+			// the image config when there's a single one
+			ps, err := exptypes.ParsePlatforms(combinedResult.Metadata)
+			if err != nil {
+				return nil, err
+			}
+			platformString = ps.Platforms[0].ID
 		} else {
 			expPlatforms.Platforms[len(combinedResult.Refs)] = exptypes.Platform{
 				ID:       platformString,
@@ -234,6 +348,9 @@ func (c *Client) getContainerResult(
 			}
 			combinedResult.AddRef(platformString, ref)
 		}
+		for _, att := range atts {
+			combinedResult.AddAttestation(platformString, att)
+		}
 	}
 
 	if len(combinedResult.Refs) > 1 {
@@ -246,3 +363,62 @@ func (c *Client) getContainerResult(
 
 	return combinedResult, nil
 }
+
+func (c *Client) containerAttestations(
+	ctx context.Context,
+	inputs []ContainerAttestation,
+) ([]solverresult.Attestation[bkcache.ImmutableRef], error) {
+	atts := make([]solverresult.Attestation[bkcache.ImmutableRef], 0, len(inputs))
+	for _, input := range inputs {
+		att := solverresult.Attestation[bkcache.ImmutableRef]{
+			Kind:     bkgwpb.AttestationKindInToto,
+			Metadata: map[string][]byte{},
+			Path:     input.Path,
+			InToto: solverresult.InTotoAttestation{
+				PredicateType: input.PredicateType,
+			},
+		}
+		switch {
+		case strings.HasPrefix(input.PredicateType, "https://slsa.dev/provenance/"):
+			att.Metadata[solverresult.AttestationReasonKey] = []byte(solverresult.AttestationReasonProvenance)
+		case input.PredicateType == intoto.PredicateSPDX:
+			att.Metadata[solverresult.AttestationReasonKey] = []byte(solverresult.AttestationReasonSBOM)
+		}
+
+		if input.Definition == nil {
+			content := input.Content
+			att.ContentFunc = func() ([]byte, error) {
+				return content, nil
+			}
+			atts = append(atts, att)
+			continue
+		}
+
+		res, err := c.Solve(ctx, bkgw.SolveRequest{
+			Definition: input.Definition,
+			Evaluate:   true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to solve attestation: %w", err)
+		}
+		cacheRes, err := ConvertToWorkerCacheResult(ctx, res)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert result: %w", err)
+		}
+		att.Ref, err = cacheRes.SingleRef()
+		if err != nil {
+			return nil, err
+		}
+		if input.Bundle {
+			att.Kind = bkgwpb.AttestationKindBundle
+			// the statements' paths are joined to the bundle's, and BuildKit
+			// only recognizes the scanners' core SBOM if it's at the top level
+			att.Path = strings.TrimPrefix(path.Clean("/"+input.Path), "/")
+			if input.PredicateType == intoto.PredicateSPDX {
+				att.Metadata[solverresult.AttestationSBOMCore] = []byte(sbom.CoreSBOMName)
+			}
+		}
+		atts = append(atts, att)
+	}
+	return atts, nil
+}
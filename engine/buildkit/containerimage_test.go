@@ -0,0 +1,55 @@
+package buildkit
+
+import (
+	"testing"
+
+	"github.com/moby/buildkit/exporter/containerimage/exptypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageExportOptsExporterAttrs(t *testing.T) {
+	attrs, err := ImageExportOpts{}.exporterAttrs(nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		string(exptypes.OptKeyOCITypes): "false",
+	}, attrs)
+
+	level := 19
+	attrs, err = ImageExportOpts{
+		Names:            []string{"registry.example.com/app:v1", "registry.example.com/app:latest"},
+		OCIMediaTypes:    true,
+		Compression:      "zstd",
+		CompressionLevel: &level,
+		ManifestAnnotations: map[string]string{
+			"org.opencontainers.image.source": "https://github.com/dagger/dagger",
+		},
+		IndexAnnotations: map[string]string{
+			"org.opencontainers.image.version": "v1",
+		},
+	}.exporterAttrs(map[string]ContainerExport{
+		"linux/amd64": {Annotations: map[string]string{"org.opencontainers.image.title": "amd64"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		string(exptypes.OptKeyName):                           "registry.example.com/app:v1,registry.example.com/app:latest",
+		string(exptypes.OptKeyOCITypes):                       "true",
+		string(exptypes.OptKeyLayerCompression):               "zstd",
+		string(exptypes.OptKeyForceCompression):               "true",
+		string(exptypes.OptKeyCompressionLevel):               "19",
+		"annotation-manifest.org.opencontainers.image.source": "https://github.com/dagger/dagger",
+		"annotation-index.org.opencontainers.image.version":   "v1",
+		"annotation-manifest.org.opencontainers.image.title":  "amd64",
+	}, attrs)
+
+	// each platform's own annotations are scoped to its manifest
+	attrs, err = ImageExportOpts{OCIMediaTypes: true}.exporterAttrs(map[string]ContainerExport{
+		"linux/amd64": {Annotations: map[string]string{"org.opencontainers.image.title": "amd64"}},
+		"linux/arm64": {Annotations: map[string]string{"org.opencontainers.image.title": "arm64"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		string(exptypes.OptKeyOCITypes):                                   "true",
+		"annotation-manifest[linux/amd64].org.opencontainers.image.title": "amd64",
+		"annotation-manifest[linux/arm64].org.opencontainers.image.title": "arm64",
+	}, attrs)
+}
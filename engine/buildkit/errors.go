@@ -9,6 +9,18 @@ type ExecError struct {
 	Stderr   string
 }
 
+// NewExecError returns an ExecError for a command that failed with the given
+// error.
+func NewExecError(original error, cmd []string, exitCode int, stdout, stderr string) *ExecError {
+	return &ExecError{
+		original: original,
+		Cmd:      cmd,
+		ExitCode: exitCode,
+		Stdout:   stdout,
+		Stderr:   stderr,
+	}
+}
+
 func (e *ExecError) Error() string {
 	return e.original.Error()
 }
@@ -14,6 +14,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"sync"
 	"syscall"
@@ -82,6 +83,16 @@ type ExecutionMetadata struct {
 	RedirectStdoutPath string
 	RedirectStderrPath string
 
+	// Exit codes that are considered a successful execution; if unset, only
+	// an exit code of 0 is allowed.
+	ValidExitCodes []int
+
+	// Resource limits applied to the container; zero values mean no limit.
+	Timeout     time.Duration
+	MemoryLimit int64
+	CPUQuota    int64
+	PidsLimit   int64
+
 	SecretEnvNames  []string
 	SecretFilePaths []string
 
@@ -150,6 +161,7 @@ func (w *Worker) Run(
 		w.injectDumbInit,
 		w.generateBaseSpec,
 		w.filterEnvs,
+		w.setupResourceLimits,
 		w.setupRootfs,
 		w.setUserGroup,
 		w.setExitCodePath,
@@ -253,7 +265,17 @@ func (w *Worker) run(
 		})
 		return err
 	}
-	err = exitError(ctx, state.exitCodePath, w.callWithIO(ctx, state.procInfo, startedCallback, killer, runcCall))
+	var validExitCodes []int
+	if w.execMD != nil {
+		validExitCodes = w.execMD.ValidExitCodes
+	}
+	runCtx := ctx
+	if state.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeoutCause(ctx, state.timeout, fmt.Errorf("timed out after %s", state.timeout))
+		defer cancel()
+	}
+	err = exitError(runCtx, state.exitCodePath, w.callWithIO(runCtx, state.procInfo, startedCallback, killer, runcCall), validExitCodes)
 	if err != nil {
 		w.runc.Delete(context.TODO(), state.id, &runc.DeleteOpts{})
 		return err
@@ -402,7 +424,7 @@ func (w *Worker) Exec(ctx context.Context, id string, process executor.ProcessIn
 	}
 
 	err = w.exec(ctx, id, spec.Process, process, nil)
-	return exitError(ctx, "", err)
+	return exitError(ctx, "", err, nil)
 }
 
 func (w *Worker) exec(ctx context.Context, id string, specsProcess *specs.Process, process executor.ProcessInfo, started func()) error {
@@ -428,9 +450,11 @@ func (w *Worker) validateEntitlements(meta executor.Meta) error {
 	})
 }
 
-func exitError(ctx context.Context, exitCodePath string, err error) error {
+func exitError(ctx context.Context, exitCodePath string, err error, validExitCodes []int) error {
+	exitCode := 0
+	var exitErr *gatewayapi.ExitError
 	if err != nil {
-		exitErr := &gatewayapi.ExitError{
+		exitErr = &gatewayapi.ExitError{
 			ExitCode: gatewayapi.UnknownExitStatus,
 			Err:      err,
 		}
@@ -440,34 +464,50 @@ func exitError(ctx context.Context, exitCodePath string, err error) error {
 				ExitCode: uint32(runcExitError.Status),
 			}
 		}
+		exitCode = int(exitErr.ExitCode)
+	}
 
-		if exitCodePath != "" {
-			if err := os.WriteFile(exitCodePath, []byte(fmt.Sprintf("%d", exitErr.ExitCode)), 0o600); err != nil {
-				bklog.G(ctx).Errorf("failed to write exit code %d to %s: %v", exitErr.ExitCode, exitCodePath, err)
-			}
-		}
-
-		trace.SpanFromContext(ctx).AddEvent(
-			"Container exited",
-			trace.WithAttributes(
-				attribute.Int("exit.code", int(exitErr.ExitCode)),
-			),
-		)
-
-		select {
-		case <-ctx.Done():
-			exitErr.Err = fmt.Errorf("%s: %w", exitErr.Error(), context.Cause(ctx))
-			return exitErr
-		default:
-			return stack.Enable(exitErr)
+	if exitCodePath != "" {
+		if err := os.WriteFile(exitCodePath, []byte(fmt.Sprintf("%d", exitCode)), 0o600); err != nil {
+			bklog.G(ctx).Errorf("failed to write exit code %d to %s: %v", exitCode, exitCodePath, err)
 		}
 	}
 
 	trace.SpanFromContext(ctx).AddEvent(
 		"Container exited",
-		trace.WithAttributes(attribute.Int("exit.code", 0)),
+		trace.WithAttributes(
+			attribute.Int("exit.code", exitCode),
+		),
 	)
-	return nil
+
+	select {
+	case <-ctx.Done():
+		if exitErr == nil {
+			return nil
+		}
+		exitErr.Err = fmt.Errorf("%s: %w", exitErr.Error(), context.Cause(ctx))
+		return exitErr
+	default:
+	}
+
+	if len(validExitCodes) == 0 {
+		// by default, only a zero exit code is valid
+		validExitCodes = []int{0}
+	}
+	if exitErr != nil && exitErr.Err != nil {
+		// the process failed for some reason other than its exit status
+		return stack.Enable(exitErr)
+	}
+	if slices.Contains(validExitCodes, exitCode) {
+		return nil
+	}
+	if exitErr == nil {
+		exitErr = &gatewayapi.ExitError{
+			ExitCode: uint32(exitCode),
+			Err:      fmt.Errorf("exit code %d was not expected", exitCode),
+		}
+	}
+	return stack.Enable(exitErr)
 }
 
 type forwardIO struct {
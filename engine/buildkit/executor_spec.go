@@ -54,6 +54,8 @@ const (
 	DaggerRedirectStdoutEnv  = "_DAGGER_REDIRECT_STDOUT"
 	DaggerRedirectStderrEnv  = "_DAGGER_REDIRECT_STDERR"
 	DaggerHostnameAliasesEnv = "_DAGGER_HOSTNAME_ALIASES"
+	DaggerExpectEnv          = "_DAGGER_EXPECT"
+	DaggerResourceLimitsEnv  = "_DAGGER_RESOURCE_LIMITS"
 
 	DaggerSessionPortEnv  = "DAGGER_SESSION_PORT"
 	DaggerSessionTokenEnv = "DAGGER_SESSION_TOKEN"
@@ -73,6 +75,10 @@ const (
 	OTelMetricsEndpointEnv  = "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"
 
 	buildkitQemuEmulatorMountPoint = "/dev/.buildkit_qemu_emulator"
+
+	// CPUPeriod is the CFS period, in microseconds, that CPU quotas are
+	// relative to.
+	CPUPeriod = 100000
 )
 
 var removeEnvs = map[string]struct{}{
@@ -82,6 +88,8 @@ var removeEnvs = map[string]struct{}{
 	DaggerRedirectStdoutEnv:  {},
 	DaggerRedirectStderrEnv:  {},
 	DaggerHostnameAliasesEnv: {},
+	DaggerExpectEnv:          {},
+	DaggerResourceLimitsEnv:  {},
 }
 
 type execState struct {
@@ -102,6 +110,7 @@ type execState struct {
 	hostsFilePath    string
 	exitCodePath     string
 	metaMount        *specs.Mount
+	timeout          time.Duration
 	origEnvMap       map[string]string
 
 	doneErr error
@@ -379,6 +388,51 @@ func (w *Worker) filterEnvs(_ context.Context, state *execState) error {
 	return nil
 }
 
+func (w *Worker) setupResourceLimits(_ context.Context, state *execState) error {
+	if w.execMD == nil {
+		return nil
+	}
+	state.timeout = w.execMD.Timeout
+
+	if w.execMD.MemoryLimit == 0 && w.execMD.CPUQuota == 0 && w.execMD.PidsLimit == 0 {
+		return nil
+	}
+
+	if state.spec.Linux == nil {
+		state.spec.Linux = &specs.Linux{}
+	}
+	if state.spec.Linux.Resources == nil {
+		state.spec.Linux.Resources = &specs.LinuxResources{}
+	}
+	resources := state.spec.Linux.Resources
+
+	if w.execMD.MemoryLimit > 0 {
+		limit := w.execMD.MemoryLimit
+		if resources.Memory == nil {
+			resources.Memory = &specs.LinuxMemory{}
+		}
+		resources.Memory.Limit = &limit
+		// disallow swap usage beyond the memory limit
+		resources.Memory.Swap = &limit
+	}
+
+	if w.execMD.CPUQuota > 0 {
+		quota := w.execMD.CPUQuota
+		period := uint64(CPUPeriod)
+		if resources.CPU == nil {
+			resources.CPU = &specs.LinuxCPU{}
+		}
+		resources.CPU.Quota = &quota
+		resources.CPU.Period = &period
+	}
+
+	if w.execMD.PidsLimit > 0 {
+		resources.Pids = &specs.LinuxPids{Limit: w.execMD.PidsLimit}
+	}
+
+	return nil
+}
+
 func (w *Worker) setupRootfs(ctx context.Context, state *execState) error {
 	var err error
 	state.rootfsPath, err = os.MkdirTemp("", "rootfs")
@@ -706,6 +760,9 @@ func (w *Worker) setupSecretScrubbing(ctx context.Context, state *execState) err
 		return nil
 	}
 
+	// NB: secret values are read from the container's env and mounts, which
+	// are loaded fresh for every exec, so secrets that have been refetched after
+	// expiring are scrubbed by their current value.
 	ctrCwd := state.spec.Process.Cwd
 	if ctrCwd == "" {
 		ctrCwd = "/"
@@ -0,0 +1,183 @@
+package buildkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containerd/continuity/fs"
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	fscopy "github.com/tonistiigi/fsutil/copy"
+	"golang.org/x/sys/unix"
+
+	"github.com/dagger/dagger/engine/sources/gitdns"
+)
+
+// ReplaceInFile returns a blob with a copy of the file at filePath in the llb
+// definition, named after its base name, with the first or all occurrences of
+// search replaced. The copy keeps the permissions, ownership and timestamps of
+// the original.
+func (c *Client) ReplaceInFile(
+	ctx context.Context,
+	pbDef *bksolverpb.Definition,
+	filePath string,
+	search, replace string,
+	all bool,
+) (*bksolverpb.Definition, specs.Descriptor, error) {
+	if search == "" {
+		return nil, specs.Descriptor{}, fmt.Errorf("search must not be empty")
+	}
+
+	var content []byte
+	var info os.FileInfo
+	err := c.withReadonlyDef(ctx, pbDef, func(root string) error {
+		full, err := rootPath(root, filePath)
+		if err != nil {
+			return err
+		}
+		info, err = os.Lstat(full)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%s is not a regular file", filePath)
+		}
+		content, err = os.ReadFile(full)
+		return err
+	})
+	if err != nil {
+		return nil, specs.Descriptor{}, err
+	}
+
+	if !bytes.Contains(content, []byte(search)) {
+		return nil, specs.Descriptor{}, fmt.Errorf("%q not found in %s", search, filePath)
+	}
+	n := 1
+	if all {
+		n = -1
+	}
+	content = bytes.Replace(content, []byte(search), []byte(replace), n)
+
+	return c.newSnapshotBlob(ctx, "replace in "+filePath, func(root string) error {
+		dest := filepath.Join(root, filepath.Base(filePath))
+		if err := os.WriteFile(dest, content, info.Mode().Perm()); err != nil {
+			return err
+		}
+		return copyMetadata(dest, info)
+	})
+}
+
+// Symlink returns a blob with a single symlink named "link" pointing to
+// target. Its timestamps are set to the Unix epoch so that the blob only
+// depends on the target.
+func (c *Client) Symlink(ctx context.Context, target string) (*bksolverpb.Definition, specs.Descriptor, error) {
+	return c.newSnapshotBlob(ctx, "symlink "+target, func(root string) error {
+		link := filepath.Join(root, "link")
+		if err := os.Symlink(target, link); err != nil {
+			return err
+		}
+		epoch := []unix.Timespec{{}, {}}
+		if err := unix.UtimesNanoAt(unix.AT_FDCWD, link, epoch, unix.AT_SYMLINK_NOFOLLOW); err != nil {
+			return fmt.Errorf("failed to set symlink timestamps: %w", err)
+		}
+		return os.Chtimes(root, time.Unix(0, 0), time.Unix(0, 0))
+	})
+}
+
+// ApplyPatch applies a patch in the unified diff format produced by git diff
+// to dir in the llb definition. Rather than a copy of the whole patched
+// directory, it returns a blob with only the paths the patch added or
+// modified, to be copied on top of dir after removing the deleted paths. The
+// blob is nil if the patch only deletes paths.
+func (c *Client) ApplyPatch(
+	ctx context.Context,
+	pbDef *bksolverpb.Definition,
+	dir string,
+	patch []byte,
+) (_ *bksolverpb.Definition, desc specs.Descriptor, deleted []string, _ error) {
+	var def *bksolverpb.Definition
+	err := c.withReadonlyDef(ctx, pbDef, func(lowerRoot string) error {
+		lower, err := rootPath(lowerRoot, dir)
+		if err != nil {
+			return err
+		}
+		return c.withMutableCopy(ctx, pbDef, "apply patch", func(upperRoot string) error {
+			upper, err := rootPath(upperRoot, dir)
+			if err != nil {
+				return err
+			}
+			if err := gitdns.Apply(ctx, upper, patch); err != nil {
+				return err
+			}
+			changes, err := diffChanges(ctx, lower, upper)
+			if err != nil {
+				return err
+			}
+
+			var patched []string
+			patched, deleted = splitChanges(changes)
+			if len(patched) == 0 {
+				return nil
+			}
+
+			def, desc, err = c.newSnapshotBlob(ctx, "patched files", func(root string) error {
+				return fscopy.Copy(ctx, upper, "/", root, "/", fscopy.WithCopyInfo(fscopy.CopyInfo{
+					CopyDirContents: true,
+					IncludePatterns: patched,
+				}))
+			})
+			return err
+		})
+	})
+	if err != nil {
+		return nil, desc, nil, err
+	}
+	return def, desc, deleted, nil
+}
+
+// splitChanges returns include patterns for the paths that were added or
+// modified and can be copied on top of the original directory, and the paths
+// that must be deleted from it first.
+func splitChanges(changes []Change) (patched, deleted []string) {
+	for _, change := range changes {
+		switch {
+		case change.Kind == fs.ChangeKindDelete:
+			deleted = append(deleted, change.Path)
+			continue
+		case change.Kind == fs.ChangeKindModify &&
+			change.Lower.Mode().Type() != change.Upper.Mode().Type():
+			// the path changed type, so it can't be copied over
+			deleted = append(deleted, change.Path)
+		}
+		patched = append(patched, escapePattern(change.Path))
+	}
+	return patched, deleted
+}
+
+// copyMetadata applies the ownership and timestamps of info to path.
+func copyMetadata(path string, info os.FileInfo) error {
+	if st, ok := info.Sys().(*unix.Stat_t); ok {
+		if err := os.Lchown(path, int(st.Uid), int(st.Gid)); err != nil {
+			return err
+		}
+	}
+	return os.Chtimes(path, info.ModTime(), info.ModTime())
+}
+
+// escapePattern escapes a path to be matched literally by an include pattern.
+func escapePattern(p string) string {
+	var sb strings.Builder
+	for _, r := range p {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			sb.WriteRune('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package buildkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	fscopy "github.com/tonistiigi/fsutil/copy"
+
+	"github.com/dagger/dagger/engine/sources/gitdns"
+)
+
+func TestPatchedFiles(t *testing.T) {
+	ctx := context.Background()
+	lower := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(lower, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+	write("same.txt", "same\n")
+	write("src/main.go", "package main\n")
+	write("src/other.go", "package main\n")
+	write("old[1].txt", "old\n")
+
+	upper := t.TempDir()
+	require.NoError(t, fscopy.Copy(ctx, lower, "/", upper, "/", fscopy.WithCopyInfo(fscopy.CopyInfo{
+		CopyDirContents: true,
+	})))
+	require.NoError(t, gitdns.Apply(ctx, upper, []byte(`diff --git a/src/main.go b/src/main.go
+--- a/src/main.go
++++ b/src/main.go
+@@ -1 +1,3 @@
+ package main
++
++func main() {}
+diff --git a/old[1].txt b/old[1].txt
+deleted file mode 100644
+--- a/old[1].txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+diff --git a/new/[x].txt b/new/[x].txt
+new file mode 100644
+--- /dev/null
++++ b/new/[x].txt
+@@ -0,0 +1 @@
++new
+`)))
+
+	changes, err := diffChanges(ctx, lower, upper)
+	require.NoError(t, err)
+	patched, deleted := splitChanges(changes)
+	require.Equal(t, []string{"old[1].txt"}, deleted)
+
+	dest := t.TempDir()
+	require.NoError(t, fscopy.Copy(ctx, upper, "/", dest, "/", fscopy.WithCopyInfo(fscopy.CopyInfo{
+		CopyDirContents: true,
+		IncludePatterns: patched,
+	})))
+
+	var paths []string
+	require.NoError(t, filepath.WalkDir(dest, func(path string, d os.DirEntry, err error) error {
+		require.NoError(t, err)
+		if !d.IsDir() {
+			rel, err := filepath.Rel(dest, path)
+			require.NoError(t, err)
+			paths = append(paths, rel)
+		}
+		return nil
+	}))
+	require.ElementsMatch(t, []string{"src/main.go", "new/[x].txt"}, paths)
+
+	content, err := os.ReadFile(filepath.Join(dest, "src/main.go"))
+	require.NoError(t, err)
+	require.Equal(t, "package main\n\nfunc main() {}\n", string(content))
+}
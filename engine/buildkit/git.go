@@ -0,0 +1,75 @@
+package buildkit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	bksession "github.com/moby/buildkit/session"
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/dagger/dagger/engine"
+	"github.com/dagger/dagger/engine/sources/gitdns"
+)
+
+// ReadGitCommit reads the HEAD commit of the git repository at dir in the
+// given llb definition, which must include its .git directory.
+func (c *Client) ReadGitCommit(ctx context.Context, pbDef *bksolverpb.Definition, dir string) (*object.Commit, error) {
+	var commit *object.Commit
+	err := c.withReadonlyDef(ctx, pbDef, func(root string) error {
+		repo, err := git.PlainOpen(filepath.Join(root, filepath.Clean("/"+dir)))
+		if err != nil {
+			return fmt.Errorf("failed to open git repository: %w", err)
+		}
+		head, err := repo.Head()
+		if err != nil {
+			return fmt.Errorf("failed to resolve HEAD: %w", err)
+		}
+		commit, err = repo.CommitObject(head.Hash())
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", head.Hash(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return commit, nil
+}
+
+// GitCommit commits the contents of the llb definition as a new git commit,
+// on top of the repository in its .git directory if it has one, and returns
+// the result as a content addressed blob, like DefToBlob.
+func (c *Client) GitCommit(ctx context.Context, pbDef *bksolverpb.Definition, opts gitdns.CommitOpts) (_ *bksolverpb.Definition, _ string, desc specs.Descriptor, rerr error) {
+	var commit string
+	def, desc, err := c.newSnapshotBlob(ctx, "git commit", func(root string) (err error) {
+		if err := c.copyDef(ctx, pbDef, "/", root); err != nil {
+			return err
+		}
+		commit, err = gitdns.Commit(ctx, root, opts)
+		return err
+	})
+	if err != nil {
+		return nil, "", desc, err
+	}
+	return def, commit, desc, nil
+}
+
+// GitPush pushes the HEAD commit of the git repository at dir in the llb
+// definition to a remote.
+func (c *Client) GitPush(ctx context.Context, pbDef *bksolverpb.Definition, dir string, opts gitdns.PushOpts) error {
+	clientMetadata, err := engine.ClientMetadataFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	opts.Namespace = clientMetadata.SessionID
+	opts.DNS = c.Worker.dns
+
+	// push from a throwaway copy, since git may update the repo as it goes
+	return c.withMutableCopy(ctx, pbDef, "git push", func(root string) error {
+		return gitdns.Push(ctx, c.SessionManager, bksession.NewGroup(c.ID()), filepath.Join(root, filepath.Clean("/"+dir)), opts)
+	})
+}
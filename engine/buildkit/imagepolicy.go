@@ -0,0 +1,138 @@
+package buildkit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/distribution/reference"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+)
+
+// ImagePolicy configures how the engine resolves the images containers are
+// pulled from, including the base images of Dockerfiles.
+type ImagePolicy struct {
+	// Rewrites are applied to image references before they're pulled, the
+	// first matching one winning.
+	Rewrites []ImageRewrite `toml:"rewrite"`
+
+	// RequireDigests rejects image references that aren't pinned to a digest.
+	RequireDigests bool `toml:"requireDigests"`
+}
+
+// ImageRewrite rewrites references to the repositories under a prefix, e.g.
+// to pull images from an internal mirror:
+//
+//	[[image.rewrite]]
+//	from = "docker.io"
+//	to = "mirror.internal/dockerhub"
+//
+// rewrites docker.io/library/alpine:3.20 to
+// mirror.internal/dockerhub/library/alpine:3.20.
+type ImageRewrite struct {
+	// From is a registry host or repository prefix, optionally followed by
+	// "/*". It's matched against whole path components of normalized
+	// references, so "docker.io" matches "alpine".
+	From string `toml:"from"`
+
+	// To replaces the matched prefix.
+	To string `toml:"to"`
+}
+
+func (rewrite ImageRewrite) prefix() string {
+	return strings.TrimSuffix(strings.TrimSuffix(rewrite.From, "/*"), "/")
+}
+
+// Validate checks that the policy's rewrites are well formed.
+func (policy ImagePolicy) Validate() error {
+	for i, rewrite := range policy.Rewrites {
+		if rewrite.prefix() == "" || rewrite.To == "" {
+			return fmt.Errorf("invalid image rewrite %d: from and to are required", i)
+		}
+		if _, err := reference.ParseNormalizedNamed(strings.TrimSuffix(rewrite.To, "/") + "/image"); err != nil {
+			return fmt.Errorf("invalid image rewrite %d: invalid to %q: %w", i, rewrite.To, err)
+		}
+	}
+	return nil
+}
+
+// Check returns an error if the policy doesn't allow pulling ref.
+func (policy ImagePolicy) Check(ref reference.Named) error {
+	if _, ok := ref.(reference.Digested); !ok && policy.RequireDigests {
+		return fmt.Errorf("image %s is not pinned to a digest, which the engine requires", reference.FamiliarString(ref))
+	}
+	return nil
+}
+
+// Rewrite returns the reference to pull ref from, which is ref itself unless
+// a rewrite matches it. Tags and digests are kept as is.
+func (policy ImagePolicy) Rewrite(ref reference.Named) (reference.Named, error) {
+	name := ref.Name()
+	for _, rewrite := range policy.Rewrites {
+		prefix := rewrite.prefix()
+		if name != prefix && !strings.HasPrefix(name, prefix+"/") {
+			continue
+		}
+		rewritten, err := reference.ParseNormalizedNamed(strings.TrimSuffix(rewrite.To, "/") + strings.TrimPrefix(name, prefix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite image %s: %w", reference.FamiliarString(ref), err)
+		}
+		if tagged, ok := ref.(reference.Tagged); ok {
+			rewritten, err = reference.WithTag(rewritten, tagged.Tag())
+			if err != nil {
+				return nil, err
+			}
+		}
+		if digested, ok := ref.(reference.Digested); ok {
+			rewritten, err = reference.WithDigest(rewritten, digested.Digest())
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rewritten, nil
+	}
+	return ref, nil
+}
+
+// imageRewrittenAttr marks image sources rewritten by the policy, so that only
+// the first matching rewrite applies to them.
+const imageRewrittenAttr = "dagger.image.rewritten"
+
+// SourcePolicy returns the buildkit source policy that applies the policy to
+// images pulled by buildkit itself, e.g. by the Dockerfile frontend, or nil if
+// the policy is empty.
+func (policy ImagePolicy) SourcePolicy() *spb.Policy {
+	var rules []*spb.Rule
+	for _, rewrite := range policy.Rewrites {
+		rules = append(rules, &spb.Rule{
+			Action: spb.PolicyAction_CONVERT,
+			Selector: &spb.Selector{
+				// the rest of the path, then the tag and digest, if any
+				Identifier: "^docker-image://" + regexp.QuoteMeta(rewrite.prefix()) + `((?:/[^:@]*)?(?::[\w][\w.-]*)?(?:@.+)?)$`,
+				MatchType:  spb.MatchType_REGEX,
+				Constraints: []*spb.AttrConstraint{{
+					Key:       imageRewrittenAttr,
+					Value:     "true",
+					Condition: spb.AttrMatch_NOTEQUAL,
+				}},
+			},
+			Updates: &spb.Update{
+				Identifier: "docker-image://" + strings.TrimSuffix(rewrite.To, "/") + "${1}",
+				Attrs:      map[string]string{imageRewrittenAttr: "true"},
+			},
+		})
+	}
+	if policy.RequireDigests {
+		rules = append(rules, &spb.Rule{
+			Action: spb.PolicyAction_DENY,
+			Selector: &spb.Selector{
+				Identifier: "^docker-image://[^@]+$",
+				MatchType:  spb.MatchType_REGEX,
+			},
+		})
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return &spb.Policy{Version: 1, Rules: rules}
+}
@@ -0,0 +1,105 @@
+package buildkit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/distribution/reference"
+	"github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/sourcepolicy"
+	spb "github.com/moby/buildkit/sourcepolicy/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImagePolicyRewrite(t *testing.T) {
+	policy := ImagePolicy{Rewrites: []ImageRewrite{
+		{From: "docker.io/library/golang", To: "golang.mirror.internal/golang"},
+		{From: "docker.io/*", To: "mirror.internal/dockerhub/"},
+		{From: "ghcr.io/dagger", To: "mirror.internal/dagger"},
+	}}
+	require.NoError(t, policy.Validate())
+
+	const dgst = "sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5"
+	for ref, want := range map[string]string{
+		"alpine:3.20":                 "mirror.internal/dockerhub/library/alpine:3.20",
+		"alpine@" + dgst:              "mirror.internal/dockerhub/library/alpine@" + dgst,
+		"alpine:3.20@" + dgst:         "mirror.internal/dockerhub/library/alpine:3.20@" + dgst,
+		"golang:1.22":                 "golang.mirror.internal/golang:1.22",
+		"dagger/dagger":               "mirror.internal/dockerhub/dagger/dagger",
+		"ghcr.io/dagger/engine:v0.12": "mirror.internal/dagger/engine:v0.12",
+		"ghcr.io/daggerverse/foo":     "ghcr.io/daggerverse/foo",
+		"registry.example.com/app":    "registry.example.com/app",
+	} {
+		named, err := reference.ParseNormalizedNamed(ref)
+		require.NoError(t, err)
+		rewritten, err := policy.Rewrite(named)
+		require.NoError(t, err)
+		require.Equal(t, want, rewritten.String(), ref)
+	}
+}
+
+func TestImagePolicySourcePolicy(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, ImagePolicy{}.SourcePolicy())
+
+	evaluate := func(pol *spb.Policy, ref string) (string, error) {
+		named, err := reference.ParseNormalizedNamed(ref)
+		require.NoError(t, err)
+		op := &pb.SourceOp{Identifier: "docker-image://" + reference.TagNameOnly(named).String()}
+		_, err = sourcepolicy.NewEngine([]*spb.Policy{pol}).Evaluate(ctx, op)
+		return op.Identifier, err
+	}
+
+	// applies the same rewrites as Rewrite, once
+	policy := ImagePolicy{Rewrites: []ImageRewrite{
+		{From: "docker.io/library/golang", To: "golang.mirror.internal/golang"},
+		{From: "docker.io/*", To: "mirror.internal/dockerhub/"},
+		{From: "ghcr.io/dagger", To: "mirror.internal/dagger"},
+		{From: "mirror.internal", To: "docker.io/mirror"},
+	}}
+	const dgst = "sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5"
+	for ref, want := range map[string]string{
+		"alpine:3.20":                 "mirror.internal/dockerhub/library/alpine:3.20",
+		"alpine@" + dgst:              "mirror.internal/dockerhub/library/alpine@" + dgst,
+		"alpine:3.20@" + dgst:         "mirror.internal/dockerhub/library/alpine:3.20@" + dgst,
+		"golang:1.22":                 "golang.mirror.internal/golang:1.22",
+		"dagger/dagger":               "mirror.internal/dockerhub/dagger/dagger:latest",
+		"ghcr.io/dagger/engine:v0.12": "mirror.internal/dagger/engine:v0.12",
+		"ghcr.io/daggerverse/foo":     "ghcr.io/daggerverse/foo:latest",
+		"registry.example.com/app":    "registry.example.com/app:latest",
+		"docker.io:5000/app":          "docker.io:5000/app:latest",
+	} {
+		id, err := evaluate(policy.SourcePolicy(), ref)
+		require.NoError(t, err)
+		require.Equal(t, "docker-image://"+want, id, ref)
+	}
+
+	policy = ImagePolicy{
+		Rewrites:       []ImageRewrite{{From: "docker.io", To: "mirror.internal/dockerhub"}},
+		RequireDigests: true,
+	}
+	id, err := evaluate(policy.SourcePolicy(), "alpine@"+dgst)
+	require.NoError(t, err)
+	require.Equal(t, "docker-image://mirror.internal/dockerhub/library/alpine@"+dgst, id)
+	_, err = evaluate(policy.SourcePolicy(), "alpine:3.20")
+	require.ErrorIs(t, err, sourcepolicy.ErrSourceDenied)
+}
+
+func TestImagePolicyCheck(t *testing.T) {
+	unpinned, err := reference.ParseNormalizedNamed("alpine:3.20")
+	require.NoError(t, err)
+	pinned, err := reference.ParseNormalizedNamed("alpine@sha256:0a4eaa0eecf5f8c050e5bba433f58c052be7587ee8af3e8b3910ef9ab5fbe9f5")
+	require.NoError(t, err)
+
+	require.NoError(t, ImagePolicy{}.Check(unpinned))
+
+	policy := ImagePolicy{RequireDigests: true}
+	require.NoError(t, policy.Check(pinned))
+	require.ErrorContains(t, policy.Check(unpinned), "alpine:3.20 is not pinned to a digest")
+}
+
+func TestImagePolicyValidate(t *testing.T) {
+	require.ErrorContains(t, ImagePolicy{Rewrites: []ImageRewrite{{From: "docker.io"}}}.Validate(), "from and to are required")
+	require.ErrorContains(t, ImagePolicy{Rewrites: []ImageRewrite{{From: "/*", To: "mirror.internal"}}}.Validate(), "from and to are required")
+	require.ErrorContains(t, ImagePolicy{Rewrites: []ImageRewrite{{From: "docker.io", To: "Mirror.Internal:notaport"}}}.Validate(), "invalid to")
+}
@@ -0,0 +1,395 @@
+package buildkit
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/containerd/containerd/content"
+	"github.com/containerd/containerd/remotes"
+	"github.com/containerd/errdefs"
+	"github.com/distribution/reference"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/util/leaseutil"
+	"github.com/moby/buildkit/util/push"
+	"github.com/moby/buildkit/util/resolver"
+	"github.com/opencontainers/go-digest"
+	specsgo "github.com/opencontainers/image-spec/specs-go"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/signature/payload"
+)
+
+// Media type and annotations of cosign signatures, which are pushed as the
+// layers of a manifest tagged after the digest of the signed image.
+const (
+	cosignPayloadMediaType      = "application/vnd.dev.cosign.simplesigning.v1+json"
+	cosignSignatureAnnotation   = "dev.cosignproject.cosign/signature"
+	cosignCertificateAnnotation = "dev.sigstore.cosign/certificate"
+	cosignChainAnnotation       = "dev.sigstore.cosign/chain"
+)
+
+type ImageSigningOpts struct {
+	// Key is a PEM encoded private key, which may be a cosign key encrypted
+	// with Password.
+	Key      []byte
+	Password []byte
+
+	// Certificate is an optional PEM encoded certificate for Key, followed by
+	// its chain, attached to the signature like in cosign's keyless mode.
+	Certificate []byte
+}
+
+// Validate checks that the key can be decrypted and parsed, and that the
+// certificate, if any, is for it.
+func (opts ImageSigningOpts) Validate() error {
+	signer, err := parseSigningKey(opts.Key, opts.Password)
+	if err != nil {
+		return err
+	}
+	if len(opts.Certificate) > 0 {
+		if _, _, err := splitCertificates(opts.Certificate, signer.Public()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SignContainerImage pushes a cosign compatible signature of the image with
+// the given digest, next to it in the repository of ref. The signature isn't
+// uploaded to a transparency log, so it has no bundle for cosign to check.
+func (c *Client) SignContainerImage(
+	ctx context.Context,
+	ref string,
+	dgst digest.Digest,
+	opts ImageSigningOpts,
+) error {
+	ctx = buildkitTelemetryContext(ctx)
+	ctx, cancel, err := c.withClientCloseCancel(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	signer, err := parseSigningKey(opts.Key, opts.Password)
+	if err != nil {
+		return err
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	sigRef := signatureRef(named, dgst)
+
+	payload, err := signaturePayload(named, dgst)
+	if err != nil {
+		return err
+	}
+	sig, err := signPayload(signer, payload)
+	if err != nil {
+		return err
+	}
+	layer := specs.Descriptor{
+		MediaType: cosignPayloadMediaType,
+		Digest:    digest.FromBytes(payload),
+		Size:      int64(len(payload)),
+		Annotations: map[string]string{
+			cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	}
+	if len(opts.Certificate) > 0 {
+		cert, chain, err := splitCertificates(opts.Certificate, signer.Public())
+		if err != nil {
+			return err
+		}
+		layer.Annotations[cosignCertificateAnnotation] = string(cert)
+		if len(chain) > 0 {
+			layer.Annotations[cosignChainAnnotation] = string(chain)
+		}
+	}
+
+	ctx, done, err := leaseutil.WithLease(ctx, c.Worker.LeaseManager(), leaseutil.MakeTemporary)
+	if err != nil {
+		return err
+	}
+	defer done(context.WithoutCancel(ctx))
+	store := c.Worker.ContentStore()
+
+	// keep the signatures already pushed for the image, like cosign does
+	blobs := map[digest.Digest][]byte{layer.Digest: payload}
+	layers := []specs.Descriptor{layer}
+	existing, err := c.fetchSignatures(ctx, sigRef)
+	if err != nil {
+		return err
+	}
+	for _, sig := range existing {
+		if sig.desc.Digest == layer.Digest &&
+			sig.desc.Annotations[cosignSignatureAnnotation] == layer.Annotations[cosignSignatureAnnotation] {
+			continue
+		}
+		blobs[sig.desc.Digest] = sig.payload
+		layers = append(layers, sig.desc)
+	}
+
+	diffIDs := make([]digest.Digest, len(layers))
+	for i, layer := range layers {
+		diffIDs[i] = layer.Digest
+	}
+	config, err := json.Marshal(specs.Image{
+		RootFS: specs.RootFS{
+			Type:    "layers",
+			DiffIDs: diffIDs,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	configDesc := specs.Descriptor{
+		MediaType: specs.MediaTypeImageConfig,
+		Digest:    digest.FromBytes(config),
+		Size:      int64(len(config)),
+	}
+	blobs[configDesc.Digest] = config
+
+	manifest, err := json.Marshal(specs.Manifest{
+		Versioned: specsgo.Versioned{SchemaVersion: 2},
+		MediaType: specs.MediaTypeImageManifest,
+		Config:    configDesc,
+		Layers:    layers,
+	})
+	if err != nil {
+		return err
+	}
+	manifestDesc := specs.Descriptor{
+		MediaType: specs.MediaTypeImageManifest,
+		Digest:    digest.FromBytes(manifest),
+		Size:      int64(len(manifest)),
+	}
+	blobs[manifestDesc.Digest] = manifest
+
+	for dgst, blob := range blobs {
+		desc := specs.Descriptor{Digest: dgst, Size: int64(len(blob))}
+		if err := content.WriteBlob(ctx, store, "cosign-"+dgst.String(), bytes.NewReader(blob), desc); err != nil {
+			return fmt.Errorf("failed to write signature: %w", err)
+		}
+	}
+
+	err = push.Push(ctx, c.SessionManager, c.ID(), store, store, manifestDesc.Digest, sigRef, false, c.Worker.RegistryHosts, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to push signature: %w", err)
+	}
+	return nil
+}
+
+// VerifyContainerImage checks that the image with the given digest, in the
+// repository of ref, has a cosign compatible signature made with the private
+// key of a PEM encoded public key or certificate.
+func (c *Client) VerifyContainerImage(
+	ctx context.Context,
+	ref string,
+	dgst digest.Digest,
+	publicKey []byte,
+) error {
+	ctx = buildkitTelemetryContext(ctx)
+	ctx, cancel, err := c.withClientCloseCancel(ctx)
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	pub, err := parsePublicKey(publicKey)
+	if err != nil {
+		return err
+	}
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return err
+	}
+	sigs, err := c.fetchSignatures(ctx, signatureRef(named, dgst))
+	if err != nil {
+		return err
+	}
+	if len(sigs) == 0 {
+		return fmt.Errorf("no signatures found for %s@%s", reference.FamiliarName(named), dgst)
+	}
+	for _, sig := range sigs {
+		if verifySignature(pub, sig, dgst) == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("no signature of %s@%s matches the public key", reference.FamiliarName(named), dgst)
+}
+
+type imageSignature struct {
+	desc    specs.Descriptor
+	payload []byte
+}
+
+// fetchSignatures returns the cosign signatures in the manifest at sigRef,
+// or none if there's no such manifest.
+func (c *Client) fetchSignatures(ctx context.Context, sigRef string) ([]imageSignature, error) {
+	res := resolver.DefaultPool.GetResolver(c.Worker.RegistryHosts, sigRef, "pull", c.SessionManager, session.NewGroup(c.ID()))
+	name, desc, err := res.Resolve(ctx, sigRef)
+	if err != nil {
+		if errdefs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve signatures: %w", err)
+	}
+	fetcher, err := res.Fetcher(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest specs.Manifest
+	if err := fetchJSON(ctx, fetcher, desc, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to fetch signatures: %w", err)
+	}
+	var sigs []imageSignature
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != cosignPayloadMediaType {
+			continue
+		}
+		payload, err := fetchBlob(ctx, fetcher, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch signature: %w", err)
+		}
+		sigs = append(sigs, imageSignature{desc: layer, payload: payload})
+	}
+	return sigs, nil
+}
+
+func fetchBlob(ctx context.Context, fetcher remotes.Fetcher, desc specs.Descriptor) ([]byte, error) {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	blob, err := io.ReadAll(io.LimitReader(rc, desc.Size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(blob)) != desc.Size || digest.FromBytes(blob) != desc.Digest {
+		return nil, fmt.Errorf("content of %s does not match its descriptor", desc.Digest)
+	}
+	return blob, nil
+}
+
+func fetchJSON(ctx context.Context, fetcher remotes.Fetcher, desc specs.Descriptor, v any) error {
+	blob, err := fetchBlob(ctx, fetcher, desc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(blob, v)
+}
+
+// signatureRef returns the tag cosign pushes the signatures of an image to,
+// e.g. "registry.example.com/app:sha256-<hex>.sig".
+func signatureRef(named reference.Named, dgst digest.Digest) string {
+	return fmt.Sprintf("%s:%s-%s.sig", named.Name(), dgst.Algorithm(), dgst.Encoded())
+}
+
+// signaturePayload returns the "simple signing" payload cosign signs for the
+// image with the given digest.
+func signaturePayload(named reference.Named, dgst digest.Digest) ([]byte, error) {
+	image, err := name.NewDigest(named.Name() + "@" + dgst.String())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload.Cosign{Image: image})
+}
+
+// signPayload signs a payload like cosign does, with SHA-256.
+func signPayload(signer crypto.Signer, msg []byte) ([]byte, error) {
+	s, err := signature.LoadSigner(signer, crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	return s.SignMessage(bytes.NewReader(msg))
+}
+
+// verifySignature checks a signature made by signPayload, and that its
+// payload is about the image with the given digest.
+func verifySignature(pub crypto.PublicKey, sig imageSignature, dgst digest.Digest) error {
+	raw, err := base64.StdEncoding.DecodeString(sig.desc.Annotations[cosignSignatureAnnotation])
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	verifier, err := signature.LoadVerifier(pub, crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	if err := verifier.VerifySignature(bytes.NewReader(raw), bytes.NewReader(sig.payload)); err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	var signed payload.Cosign
+	if err := json.Unmarshal(sig.payload, &signed); err != nil {
+		return fmt.Errorf("invalid signature payload: %w", err)
+	}
+	if signed.Image.DigestStr() != dgst.String() {
+		return fmt.Errorf("signature is for %s, not %s", signed.Image.DigestStr(), dgst)
+	}
+	return nil
+}
+
+// parseSigningKey parses a PEM encoded private key: an unencrypted PKCS #8,
+// PKCS #1 or SEC 1 key, or an encrypted cosign key.
+func parseSigningKey(key, password []byte) (crypto.Signer, error) {
+	parsed, err := cryptoutils.UnmarshalPEMToPrivateKey(key, cryptoutils.StaticPasswordFunc(password))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key type %T", parsed)
+	}
+	return signer, nil
+}
+
+// parsePublicKey parses a PEM encoded public key or certificate.
+func parsePublicKey(key []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("public key is not PEM encoded")
+	}
+	if block.Type != "CERTIFICATE" {
+		pub, err := cryptoutils.UnmarshalPEMToPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		return pub, nil
+	}
+	certs, err := cryptoutils.UnmarshalCertificatesFromPEM(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	return certs[0].PublicKey, nil
+}
+
+// splitCertificates splits PEM encoded certificates into the first one, which
+// must be for pub, and the rest of the chain.
+func splitCertificates(certs []byte, pub crypto.PublicKey) (cert, chain []byte, _ error) {
+	block, rest := pem.Decode(certs)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, errors.New("signing certificate is not a PEM encoded certificate")
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse signing certificate: %w", err)
+	}
+	certPub, ok := parsed.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !certPub.Equal(pub) {
+		return nil, nil, errors.New("signing certificate is not for the signing key")
+	}
+	return pem.EncodeToMemory(block), bytes.TrimSpace(rest), nil
+}
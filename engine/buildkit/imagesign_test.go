@@ -0,0 +1,138 @@
+package buildkit
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageSignatures(t *testing.T) {
+	named, err := reference.ParseNormalizedNamed("registry.example.com/app:v1")
+	require.NoError(t, err)
+	dgst := digest.FromString("image")
+	require.Equal(t,
+		"registry.example.com/app:sha256-"+dgst.Encoded()+".sig",
+		signatureRef(named, dgst))
+
+	payload, err := signaturePayload(named, dgst)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for name, key := range map[string]crypto.Signer{
+		"ecdsa":   ecKey,
+		"ed25519": edKey,
+		"rsa":     rsaKey,
+	} {
+		t.Run(name, func(t *testing.T) {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			require.NoError(t, err)
+			signer, err := parseSigningKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil)
+			require.NoError(t, err)
+
+			raw, err := signPayload(signer, payload)
+			require.NoError(t, err)
+			sig := imageSignature{
+				desc: specs.Descriptor{Annotations: map[string]string{
+					cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(raw),
+				}},
+				payload: payload,
+			}
+
+			pubDER, err := x509.MarshalPKIXPublicKey(key.Public())
+			require.NoError(t, err)
+			pub, err := parsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+			require.NoError(t, err)
+			require.NoError(t, verifySignature(pub, sig, dgst))
+			require.ErrorContains(t, verifySignature(pub, sig, digest.FromString("other")), "signature is for")
+
+			tampered := sig
+			tampered.payload = append([]byte(" "), payload...)
+			require.ErrorContains(t, verifySignature(pub, tampered, dgst), "invalid signature")
+		})
+	}
+
+	t.Run("wrong key", func(t *testing.T) {
+		raw, err := signPayload(ecKey, payload)
+		require.NoError(t, err)
+		otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		err = verifySignature(otherKey.Public(), imageSignature{
+			desc: specs.Descriptor{Annotations: map[string]string{
+				cosignSignatureAnnotation: base64.StdEncoding.EncodeToString(raw),
+			}},
+			payload: payload,
+		}, dgst)
+		require.ErrorContains(t, err, "invalid signature")
+	})
+}
+
+func TestParseEncryptedSigningKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	// encrypt the key the way cosign generate-key-pair does
+	data, err := cryptoutils.MarshalPrivateKeyToEncryptedDER(key, cryptoutils.StaticPasswordFunc([]byte("hunter2")))
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED SIGSTORE PRIVATE KEY", Bytes: data})
+
+	signer, err := parseSigningKey(keyPEM, []byte("hunter2"))
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(signer.Public()))
+
+	_, err = parseSigningKey(keyPEM, []byte("wrong"))
+	require.ErrorContains(t, err, "decryption failed")
+}
+
+func TestSplitCertificates(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newCert := func(pub crypto.PublicKey) []byte {
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "signer"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, key)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+	leaf := newCert(key.Public())
+	root := newCert(key.Public())
+
+	cert, chain, err := splitCertificates(append(append([]byte{}, leaf...), root...), key.Public())
+	require.NoError(t, err)
+	require.Equal(t, leaf, cert)
+	require.Equal(t, string(root[:len(root)-1]), string(chain))
+
+	pub, err := parsePublicKey(leaf)
+	require.NoError(t, err)
+	require.True(t, key.PublicKey.Equal(pub))
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, _, err = splitCertificates(leaf, otherKey.Public())
+	require.ErrorContains(t, err, "not for the signing key")
+}
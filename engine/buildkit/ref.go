@@ -566,6 +566,37 @@ func walkDir(ctx context.Context, mount snapshot.Mountable, req WalkDirRequest)
 	})
 }
 
+// solveImmutableRef solves the given llb definition and returns the resulting
+// cache ref, or nil if it's scratch.
+func (c *Client) solveImmutableRef(ctx context.Context, pbDef *bksolverpb.Definition) (bkcache.ImmutableRef, error) {
+	res, err := c.Solve(ctx, bkgw.SolveRequest{
+		Definition: pbDef,
+		Evaluate:   true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resultProxy, err := res.SingleRef()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get single ref: %w", err)
+	}
+	cachedRes, err := resultProxy.Result(ctx)
+	if err != nil {
+		return nil, wrapError(ctx, err, c)
+	}
+	if cachedRes == nil {
+		return nil, nil
+	}
+	workerRef, ok := cachedRes.Sys().(*bkworker.WorkerRef)
+	if !ok {
+		return nil, fmt.Errorf("invalid ref: %T", cachedRes.Sys())
+	}
+	if workerRef == nil {
+		return nil, nil
+	}
+	return workerRef.ImmutableRef, nil
+}
+
 // withMount is copied directly from buildkit
 func withMount(mount snapshot.Mountable, cb func(string) error) error {
 	lm := snapshot.LocalMounter(mount)
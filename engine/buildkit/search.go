@@ -0,0 +1,193 @@
+package buildkit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/patternmatcher"
+)
+
+// errSearchDone stops a search once enough matches were found.
+var errSearchDone = errors.New("search done")
+
+type SearchOpts struct {
+	// Pattern is the literal text to search for, or a regular expression if
+	// Regex is set.
+	Pattern string
+	Regex   bool
+
+	// Globs restricts the search to the files matching these patterns, which
+	// may be negated with a leading "!". If every pattern is negated, all
+	// files except the excluded ones are searched.
+	Globs []string
+
+	// MaxResults stops the search after this many matches, if positive.
+	MaxResults int
+}
+
+// SearchMatch is a line matching a search.
+type SearchMatch struct {
+	// Path is relative to the searched directory.
+	Path string
+	// LineNumber starts at 1.
+	LineNumber int
+	// Line is the text of the line, without its line ending.
+	Line string
+}
+
+// SearchDirectory searches the lines of the regular files under dir in the
+// llb definition, skipping binary files and without following symlinks.
+func (c *Client) SearchDirectory(
+	ctx context.Context,
+	pbDef *bksolverpb.Definition,
+	dir string,
+	opts SearchOpts,
+) ([]SearchMatch, error) {
+	var matches []SearchMatch
+	err := c.withReadonlyDef(ctx, pbDef, func(root string) error {
+		root, err := rootPath(root, dir)
+		if err != nil {
+			return err
+		}
+		matches, err = searchDir(ctx, root, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+func searchDir(ctx context.Context, root string, opts SearchOpts) ([]SearchMatch, error) {
+	var match func([]byte) bool
+	if opts.Regex {
+		re, err := regexp.Compile(opts.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		match = re.Match
+	} else {
+		if opts.Pattern == "" {
+			return nil, errors.New("pattern must not be empty")
+		}
+		pattern := []byte(opts.Pattern)
+		match = func(line []byte) bool {
+			return bytes.Contains(line, pattern)
+		}
+	}
+
+	var pm *patternmatcher.PatternMatcher
+	if len(opts.Globs) > 0 {
+		globs := opts.Globs
+		if onlyExclusions(globs) {
+			globs = append([]string{"**"}, globs...)
+		}
+		var err error
+		pm, err = patternmatcher.New(globs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid globs: %w", err)
+		}
+	}
+
+	matches := []SearchMatch{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if pm != nil {
+			ok, err := pm.MatchesOrParentMatches(rel)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+		return searchFile(path, match, func(lineNumber int, line []byte) error {
+			matches = append(matches, SearchMatch{
+				Path:       filepath.ToSlash(rel),
+				LineNumber: lineNumber,
+				Line:       string(line),
+			})
+			if opts.MaxResults > 0 && len(matches) >= opts.MaxResults {
+				return errSearchDone
+			}
+			return nil
+		})
+	})
+	if err != nil && !errors.Is(err, errSearchDone) {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// onlyExclusions returns true if every glob is negated, in which case they
+// exclude files from everything rather than from nothing.
+func onlyExclusions(globs []string) bool {
+	for _, glob := range globs {
+		if !strings.HasPrefix(glob, "!") {
+			return false
+		}
+	}
+	return true
+}
+
+// binaryCheckSize is how much of a file is checked for NUL bytes to decide
+// whether it's binary, like git and grep do.
+const binaryCheckSize = 8000
+
+func searchFile(path string, match func([]byte) bool, cb func(int, []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 64*1024)
+	head, err := r.Peek(binaryCheckSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	if bytes.IndexByte(head, 0) != -1 {
+		return nil
+	}
+
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimSuffix(line, []byte("\n"))
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if match(line) {
+				if err := cb(lineNumber, line); err != nil {
+					return err
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package buildkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchDir(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(root, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0o755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0o644))
+	}
+	write("main.go", "package main\n\n// TODO: fix\nfunc main() {}\n")
+	write("lib/lib.go", "package lib\r\n// TODO(someone): later\r\n")
+	write("README.md", "no todos here\nTODO")
+	write("vendor/dep/dep.go", "// TODO: not ours\n")
+	write("image.bin", "TODO\x00TODO\n")
+	require.NoError(t, os.Symlink("main.go", filepath.Join(root, "link.go")))
+
+	search := func(opts SearchOpts) []SearchMatch {
+		t.Helper()
+		matches, err := searchDir(context.Background(), root, opts)
+		require.NoError(t, err)
+		return matches
+	}
+
+	require.Equal(t, []SearchMatch{
+		{Path: "README.md", LineNumber: 2, Line: "TODO"},
+		{Path: "lib/lib.go", LineNumber: 2, Line: "// TODO(someone): later"},
+		{Path: "main.go", LineNumber: 3, Line: "// TODO: fix"},
+		{Path: "vendor/dep/dep.go", LineNumber: 1, Line: "// TODO: not ours"},
+	}, search(SearchOpts{Pattern: "TODO"}))
+
+	require.Equal(t, []SearchMatch{
+		{Path: "lib/lib.go", LineNumber: 2, Line: "// TODO(someone): later"},
+		{Path: "main.go", LineNumber: 3, Line: "// TODO: fix"},
+	}, search(SearchOpts{Pattern: "TODO", Globs: []string{"**/*.go", "!vendor"}}))
+
+	require.Equal(t, []SearchMatch{
+		{Path: "README.md", LineNumber: 2, Line: "TODO"},
+		{Path: "lib/lib.go", LineNumber: 2, Line: "// TODO(someone): later"},
+		{Path: "main.go", LineNumber: 3, Line: "// TODO: fix"},
+	}, search(SearchOpts{Pattern: "TODO", Globs: []string{"!vendor"}}))
+
+	require.Equal(t, []SearchMatch{
+		{Path: "lib/lib.go", LineNumber: 1, Line: "package lib"},
+	}, search(SearchOpts{Pattern: `^package \w+b$`, Regex: true}))
+
+	require.Equal(t, []SearchMatch{
+		{Path: "README.md", LineNumber: 2, Line: "TODO"},
+	}, search(SearchOpts{Pattern: "TODO", MaxResults: 1}))
+
+	_, err := searchDir(context.Background(), root, SearchOpts{Pattern: "(", Regex: true})
+	require.ErrorContains(t, err, "invalid regular expression")
+}
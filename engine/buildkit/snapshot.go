@@ -0,0 +1,103 @@
+package buildkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	bkcache "github.com/moby/buildkit/cache"
+	bksession "github.com/moby/buildkit/session"
+	bksolverpb "github.com/moby/buildkit/solver/pb"
+	"github.com/moby/buildkit/util/compression"
+	specs "github.com/opencontainers/image-spec/specs-go/v1"
+	fscopy "github.com/tonistiigi/fsutil/copy"
+)
+
+// withReadonlyDef solves the given llb definition and calls cb with a
+// read-only mount of its result, or an empty directory if it's scratch.
+func (c *Client) withReadonlyDef(ctx context.Context, pbDef *bksolverpb.Definition, cb func(root string) error) error {
+	ref, err := c.solveImmutableRef(ctx, pbDef)
+	if err != nil {
+		return err
+	}
+	if ref == nil {
+		root, err := os.MkdirTemp("", "dagger-scratch")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(root)
+		return cb(root)
+	}
+	mountable, err := ref.Mount(ctx, true, bksession.NewGroup(c.ID()))
+	if err != nil {
+		return fmt.Errorf("failed to mount ref: %w", err)
+	}
+	return withMount(mountable, cb)
+}
+
+// withMutableCopy solves the given llb definition and calls cb with a
+// throwaway writable mount of its result.
+func (c *Client) withMutableCopy(ctx context.Context, pbDef *bksolverpb.Definition, desc string, cb func(root string) error) error {
+	parent, err := c.solveImmutableRef(ctx, pbDef)
+	if err != nil {
+		return err
+	}
+
+	group := bksession.NewGroup(c.ID())
+	mref, err := c.Worker.CacheManager().New(ctx, parent, group, bkcache.WithDescription(desc))
+	if err != nil {
+		return fmt.Errorf("failed to create mutable ref: %w", err)
+	}
+	defer mref.Release(context.WithoutCancel(ctx))
+
+	mountable, err := mref.Mount(ctx, false, group)
+	if err != nil {
+		return fmt.Errorf("failed to mount mutable ref: %w", err)
+	}
+	return withMount(mountable, cb)
+}
+
+// newSnapshotBlob calls cb with a writable mount of a new empty snapshot, and
+// returns its result as a content addressed blob, like DefToBlob.
+//
+// The snapshot has no parent, so that it's a single layer.
+func (c *Client) newSnapshotBlob(ctx context.Context, desc string, cb func(root string) error) (_ *bksolverpb.Definition, _ specs.Descriptor, rerr error) {
+	group := bksession.NewGroup(c.ID())
+	mref, err := c.Worker.CacheManager().New(ctx, nil, group, bkcache.WithDescription(desc))
+	if err != nil {
+		return nil, specs.Descriptor{}, fmt.Errorf("failed to create mutable ref: %w", err)
+	}
+	defer func() {
+		if mref != nil {
+			mref.Release(context.WithoutCancel(ctx))
+		}
+	}()
+
+	mountable, err := mref.Mount(ctx, false, group)
+	if err != nil {
+		return nil, specs.Descriptor{}, fmt.Errorf("failed to mount mutable ref: %w", err)
+	}
+	if err := withMount(mountable, cb); err != nil {
+		return nil, specs.Descriptor{}, err
+	}
+
+	iref, err := mref.Commit(ctx)
+	if err != nil {
+		return nil, specs.Descriptor{}, fmt.Errorf("failed to commit mutable ref: %w", err)
+	}
+	mref = nil
+	defer iref.Release(context.WithoutCancel(ctx))
+
+	return c.refToBlob(ctx, iref, compression.Zstd)
+}
+
+// copyDef copies the contents of src in the given llb definition to destRoot,
+// preserving ownership and permissions.
+func (c *Client) copyDef(ctx context.Context, pbDef *bksolverpb.Definition, src, destRoot string) error {
+	return c.withReadonlyDef(ctx, pbDef, func(root string) error {
+		return fscopy.Copy(ctx, root, filepath.Clean("/"+src), destRoot, "/", fscopy.WithCopyInfo(fscopy.CopyInfo{
+			CopyDirContents: true,
+		}))
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/containerd/containerd/archive"
@@ -89,17 +90,20 @@ func (m *manager) StartCacheMountSynchronization(ctx context.Context) error {
 	m.stopCacheMountSync = func(ctx context.Context) error {
 		var eg errgroup.Group
 
-		seenCacheMounts := map[string]struct{}{}
+		// map the name each cache mount is synced under to its internal key
+		seenCacheMounts := map[string]string{}
 		core.SeenCacheKeys.Range(func(k any, v any) bool {
-			seenCacheMounts[k.(string)] = struct{}{}
+			cache := v.(*core.CacheVolume)
+			if len(cache.Keys) == 1 {
+				seenCacheMounts[cacheMountName(cache)] = k.(string)
+			}
 			return true
 		})
 
-		for cacheMountName := range seenCacheMounts {
-			cacheMountName := cacheMountName
+		for cacheMountName, cacheKey := range seenCacheMounts {
+			cacheMountName, cacheKey := cacheMountName, cacheKey
 			eg.Go(func() error {
 				bklog.G(ctx).Debugf("syncing cache mount remotely %s", cacheMountName)
-				cacheKey := cacheKeyFromMountName(cacheMountName)
 
 				return withCacheMount(ctx, m.MountManager, cacheKey, func(ctx context.Context, mnt mount.Mount) error {
 					// First compress the mount into the content store. We can't stream direct to S3 because we want
@@ -198,11 +202,30 @@ func (m *manager) StartCacheMountSynchronization(ctx context.Context) error {
 	return nil
 }
 
+// cacheMountNamespaceSeparator separates the namespace of a scoped cache
+// volume from its key in the name it is synced under. Namespaces never
+// contain it.
+const cacheMountNamespaceSeparator = "::"
+
+// cacheMountName returns the human-readable name a cache volume is synced
+// under. Global volumes are named by their key, as they were before volumes
+// were scoped, and scoped volumes by their namespace and key.
+func cacheMountName(cache *core.CacheVolume) string {
+	if cache.Namespace == "" {
+		return cache.Keys[0]
+	}
+	return cache.Namespace + cacheMountNamespaceSeparator + cache.Keys[0]
+}
+
 func cacheKeyFromMountName(name string) string {
 	// Turn the human-readable name into the key we use internally
 	// NOTE: this will be problematic if backwards incompatible changes are made
 	// to the key format and client<->server are out of sync. That's a general
 	// problem though too, so just accepting it for now.
+	namespace, key, ok := strings.Cut(name, cacheMountNamespaceSeparator)
+	if ok && (strings.HasPrefix(namespace, "module:") || strings.HasPrefix(namespace, "client:")) {
+		return (&core.CacheVolume{Keys: []string{key}, Namespace: namespace}).Sum()
+	}
 	return core.NewCache(name).Sum()
 }
 
@@ -0,0 +1,26 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/core"
+)
+
+func TestCacheMountName(t *testing.T) {
+	for _, cache := range []*core.CacheVolume{
+		core.NewCache("go-mod"),
+		core.NewScopedCache(core.CacheVolumeScopeGlobal, "", "go-mod"),
+		core.NewScopedCache(core.CacheVolumeScopeModule, "module:go@https://github.com/dagger/dagger/modules/go", "go-mod"),
+		core.NewScopedCache(core.CacheVolumeScopeModule, "module:local@sha256:abc", "go::mod"),
+		core.NewScopedCache(core.CacheVolumeScopeClient, "client:abc", "go-mod"),
+	} {
+		name := cacheMountName(cache)
+		require.Equal(t, cache.Sum(), cacheKeyFromMountName(name), name)
+	}
+
+	// global volumes are still synced by their key
+	require.Equal(t, "go-mod", cacheMountName(core.NewCache("go-mod")))
+	require.Equal(t, core.NewCache("a::b").Sum(), cacheKeyFromMountName("a::b"))
+}
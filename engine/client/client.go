@@ -27,6 +27,8 @@ import (
 	"github.com/moby/buildkit/identity"
 	bksession "github.com/moby/buildkit/session"
 	"github.com/moby/buildkit/session/auth/authprovider"
+	"github.com/moby/buildkit/session/secrets"
+	"github.com/moby/buildkit/session/secrets/secretsprovider"
 	"github.com/moby/buildkit/util/grpcerrors"
 	"github.com/moby/buildkit/util/tracing"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
@@ -80,6 +82,11 @@ type Params struct {
 	Interactive bool
 
 	WithTerminal session.WithTerminalFunc
+
+	// SecretStore, if set, answers requests from the engine for the current
+	// value of secrets set by this client, which happen when a secret set with
+	// a TTL expires.
+	SecretStore secrets.SecretStore
 }
 
 type Client struct {
@@ -362,6 +369,10 @@ func (c *Client) startSession(ctx context.Context) (rerr error) {
 		}
 		attachables = append(attachables, filesyncer.AsSource(), filesyncer.AsTarget())
 	}
+	// secrets
+	if c.SecretStore != nil {
+		attachables = append(attachables, secretsprovider.NewSecretProvider(c.SecretStore))
+	}
 
 	sessionConn, err := c.DialContext(ctx, "", "")
 	if err != nil {
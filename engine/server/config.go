@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/pelletier/go-toml"
+
+	"github.com/dagger/dagger/core"
+	"github.com/dagger/dagger/engine/buildkit"
+)
+
+// EngineConfig holds the Dagger-specific engine settings. They are read from
+// the same file as the buildkit config, which ignores them.
+type EngineConfig struct {
+	GC GCConfig `toml:"gc"`
+
+	// Image configures the images containers are pulled from, e.g.:
+	//
+	//	[image]
+	//	requireDigests = true
+	//
+	//	[[image.rewrite]]
+	//	from = "docker.io"
+	//	to = "mirror.internal/dockerhub"
+	Image buildkit.ImagePolicy `toml:"image"`
+}
+
+// GCConfig configures automatic garbage collection of the local cache.
+type GCConfig struct {
+	// Policies are applied in order whenever the engine garbage collects.
+	// When set, they replace the buildkit gcpolicy rules of the OCI worker.
+	Policies []GCPolicy `toml:"policy"`
+}
+
+// GCPolicy is a single rule for garbage collecting the local cache, e.g.:
+//
+//	[[gc.policy]]
+//	types = ["cache-mount"]
+//	keepDuration = "7d"
+//
+//	[[gc.policy]]
+//	types = ["image-layer"]
+//	keepBytes = "50GB"
+type GCPolicy struct {
+	// The types of cache entries the policy applies to, e.g. "cache-mount"
+	// or "image-layer". Applies to all entries if empty.
+	Types []string `toml:"types"`
+
+	// Keep entries that have been used within this duration, e.g. "24h" or
+	// "7d".
+	KeepDuration GCDuration `toml:"keepDuration"`
+
+	// Only prune matching entries while the whole cache is over this size,
+	// e.g. "50GB" or "10%".
+	KeepBytes config.DiskSpace `toml:"keepBytes"`
+
+	// Also apply to internal and shared entries, which are otherwise skipped.
+	All bool `toml:"all"`
+}
+
+// GCDuration is a duration in the engine config, which may also be given as a
+// number of days (e.g. "7d").
+type GCDuration struct {
+	time.Duration
+}
+
+func (d *GCDuration) UnmarshalText(text []byte) error {
+	s := strings.Trim(string(text), `"`)
+	if s == "" {
+		return nil
+	}
+	duration, err := core.ParseEngineCacheDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+func (policy GCPolicy) pruneInfo(root string) bkclient.PruneInfo {
+	filters, _ := policy.filters()
+	return bkclient.PruneInfo{
+		Filter:       filters,
+		All:          policy.All,
+		KeepBytes:    policy.KeepBytes.AsBytes(root),
+		KeepDuration: policy.KeepDuration.Duration,
+	}
+}
+
+func (policy GCPolicy) filters() ([]string, error) {
+	var filters []string
+	for _, name := range policy.Types {
+		typ, err := core.ParseEngineCacheEntryType(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gc policy type %q: %w", name, err)
+		}
+		typFilters, ok := engineCacheEntryTypeFilters[typ]
+		if !ok {
+			return nil, fmt.Errorf("invalid gc policy type %q: cannot be garbage collected by type", name)
+		}
+		filters = append(filters, typFilters...)
+	}
+	return filters, nil
+}
+
+// LoadEngineConfig parses the Dagger-specific settings from an engine config
+// file.
+func LoadEngineConfig(r io.Reader) (EngineConfig, error) {
+	var cfg EngineConfig
+	t, err := toml.LoadReader(r)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to parse engine config: %w", err)
+	}
+	if err := t.Unmarshal(&cfg); err != nil {
+		return cfg, fmt.Errorf("failed to parse engine config: %w", err)
+	}
+	for i, policy := range cfg.GC.Policies {
+		if _, err := policy.filters(); err != nil {
+			return cfg, fmt.Errorf("invalid gc policy %d: %w", i, err)
+		}
+	}
+	if err := cfg.Image.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// LoadEngineConfigFile is like LoadEngineConfig, but reads the given file. A
+// missing file results in an empty config.
+func LoadEngineConfigFile(fp string) (EngineConfig, error) {
+	f, err := os.Open(fp)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return EngineConfig{}, nil
+		}
+		return EngineConfig{}, fmt.Errorf("failed to load engine config from %s: %w", fp, err)
+	}
+	defer f.Close()
+	return LoadEngineConfig(f)
+}
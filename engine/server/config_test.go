@@ -0,0 +1,149 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/containerd/containerd/filters"
+	bkclient "github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/cmd/buildkitd/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dagger/dagger/core"
+	"github.com/dagger/dagger/engine/buildkit"
+)
+
+func TestLoadEngineConfig(t *testing.T) {
+	cfg, err := LoadEngineConfig(strings.NewReader(`
+debug = true
+
+[worker.oci]
+  gc = true
+
+[[gc.policy]]
+  types = ["cache-mount"]
+  keepDuration = "7d"
+
+[[gc.policy]]
+  types = ["IMAGE_LAYER"]
+  keepBytes = "50GB"
+
+[[gc.policy]]
+  types = ["exec", "local-import"]
+  keepDuration = "24h"
+
+[[gc.policy]]
+  all = true
+  keepBytes = 1000
+
+[registry."docker.io"]
+  mirrors = ["mirror.gcr.io"]
+
+[image]
+  requireDigests = true
+
+[[image.rewrite]]
+  from = "docker.io"
+  to = "mirror.internal/dockerhub"
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, buildkit.ImagePolicy{
+		RequireDigests: true,
+		Rewrites: []buildkit.ImageRewrite{
+			{From: "docker.io", To: "mirror.internal/dockerhub"},
+		},
+	}, cfg.Image)
+
+	bkCfg := config.GCConfig{}
+	require.Equal(t, []bkclient.PruneInfo{
+		{
+			Filter:       []string{"type==exec.cachemount"},
+			KeepDuration: 7 * 24 * time.Hour,
+		},
+		{
+			Filter:    []string{`type==regular,description~="^pulled from "`},
+			KeepBytes: 50 << 30,
+		},
+		{
+			Filter:       []string{`type==regular,description~="^mount .* from exec "`, "type==source.local"},
+			KeepDuration: 24 * time.Hour,
+		},
+		{
+			All:       true,
+			KeepBytes: 1000,
+		},
+	}, getGCPolicy(bkCfg, cfg.GC, t.TempDir()))
+
+	// buildkit's own policies are used when none are configured
+	require.Len(t, getGCPolicy(bkCfg, GCConfig{}, t.TempDir()), len(defaultGCPolicy(config.DiskSpace{})))
+
+	disabled := false
+	require.Empty(t, getGCPolicy(config.GCConfig{GC: &disabled}, cfg.GC, t.TempDir()))
+}
+
+func TestEngineCacheEntryTypeFilters(t *testing.T) {
+	for _, tc := range []struct {
+		recordType  string
+		description string
+		want        core.EngineCacheEntryType
+	}{
+		{"exec.cachemount", "cached mount /cache from exec go build", core.EngineCacheEntryCacheMount},
+		{"regular", "pulled from docker.io/library/alpine:latest", core.EngineCacheEntryImageLayer},
+		{"regular", "mount / from exec touch /foo", core.EngineCacheEntryExec},
+		{"source.local", "shared local source for /src", core.EngineCacheEntryLocalImport},
+		{"source.git.checkout", "git snapshot for github.com/dagger/dagger", core.EngineCacheEntryGitCheckout},
+		{"frontend", "", core.EngineCacheEntryInternal},
+		{"regular", "dagger blob source for sha256:abcd", core.EngineCacheEntryOther},
+	} {
+		require.Equal(t, tc.want, engineCacheEntryType(bkclient.UsageRecordType(tc.recordType), tc.description))
+
+		adaptor := filters.AdapterFunc(func(fieldpath []string) (string, bool) {
+			switch fieldpath[0] {
+			case "type":
+				return tc.recordType, true
+			case "description":
+				return tc.description, tc.description != ""
+			}
+			return "", false
+		})
+		for typ, typFilters := range engineCacheEntryTypeFilters {
+			filter, err := filters.ParseAll(typFilters...)
+			require.NoError(t, err, typ)
+			require.Equal(t, typ == tc.want, filter.Match(adaptor), "%s should match %s: %t", tc.description, typ, typ == tc.want)
+		}
+	}
+}
+
+func TestEngineCacheEntryTypeUntyped(t *testing.T) {
+	// records without a type are regular, both when listed and when pruned
+	require.Equal(t, core.EngineCacheEntryImageLayer, engineCacheEntryType("", "pulled from docker.io/library/alpine:latest"))
+	require.Equal(t, core.EngineCacheEntryExec, engineCacheEntryType("", "mount / from exec touch /foo"))
+}
+
+func TestLoadEngineConfigInvalid(t *testing.T) {
+	_, err := LoadEngineConfig(strings.NewReader(`
+[[gc.policy]]
+  types = ["nope"]
+`))
+	require.ErrorContains(t, err, `invalid gc policy type "nope"`)
+
+	_, err = LoadEngineConfig(strings.NewReader(`
+[[gc.policy]]
+  types = ["other"]
+`))
+	require.ErrorContains(t, err, `invalid gc policy type "other"`)
+
+	_, err = LoadEngineConfig(strings.NewReader(`
+[[gc.policy]]
+  keepDuration = "a week"
+`))
+	require.ErrorContains(t, err, "a week")
+
+	_, err = LoadEngineConfig(strings.NewReader(`
+[[image.rewrite]]
+  from = "docker.io"
+`))
+	require.ErrorContains(t, err, "invalid image rewrite 0")
+}
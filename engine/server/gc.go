@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -22,13 +24,20 @@ func (srv *Server) EngineLocalCacheKeepBytes() int64 {
 	return srv.workerGCKeepBytes
 }
 
-// Return all the cache entries in the local cache. No support for filtering yet.
+// Return all the cache entries in the local cache.
 func (srv *Server) EngineLocalCacheEntries(ctx context.Context) (*core.EngineCacheEntrySet, error) {
 	du, err := srv.baseWorker.DiskUsage(ctx, bkclient.DiskUsageInfo{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get disk usage from worker: %w", err)
 	}
 
+	// map cache mount checksums back to the volumes they were created from
+	cacheVolumes := map[string]*core.CacheVolume{}
+	core.SeenCacheKeys.Range(func(k, v any) bool {
+		cacheVolumes[k.(string)] = v.(*core.CacheVolume)
+		return true
+	})
+
 	set := &core.EngineCacheEntrySet{}
 	for _, r := range du {
 		cacheEnt := &core.EngineCacheEntry{
@@ -36,6 +45,13 @@ func (srv *Server) EngineLocalCacheEntries(ctx context.Context) (*core.EngineCac
 			DiskSpaceBytes:      int(r.Size),
 			ActivelyUsed:        r.InUse,
 			CreatedTimeUnixNano: int(r.CreatedAt.UnixNano()),
+			RecordType:          engineCacheEntryType(r.RecordType, r.Description),
+		}
+		if cacheEnt.RecordType == core.EngineCacheEntryCacheMount {
+			if cache, ok := cacheVolumes[srv.cacheMountID(r.ID)]; ok {
+				cacheEnt.CacheMountKey = strings.Join(cache.Keys, ",")
+				cacheEnt.CacheMountNamespace = cache.Namespace
+			}
 		}
 		if r.LastUsedAt != nil {
 			cacheEnt.MostRecentUseTimeUnixNano = int(r.LastUsedAt.UnixNano())
@@ -48,8 +64,96 @@ func (srv *Server) EngineLocalCacheEntries(ctx context.Context) (*core.EngineCac
 	return set, nil
 }
 
-// Prune everything that is releasable in the local cache. No support for filtering yet.
-func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context) (*core.EngineCacheEntrySet, error) {
+// cacheMountID returns the ID of the cache mount that a cache record was
+// created for.
+func (srv *Server) cacheMountID(recordID string) string {
+	si, ok := srv.workerCacheMetaDB.Get(recordID)
+	if !ok {
+		return ""
+	}
+	v := si.Get(cacheDirMetadataKey)
+	if v == nil {
+		return ""
+	}
+	var id string
+	if err := v.Unmarshal(&id); err != nil {
+		return ""
+	}
+	// mounts with a source directory are suffixed with the source's ref ID
+	id, _, _ = strings.Cut(id, ":")
+	return id
+}
+
+// cacheDirMetadataKey is the metadata key under which buildkit records the ID
+// of a cache mount.
+const cacheDirMetadataKey = "cache-dir"
+
+// engineCacheEntryType determines the type of a cache record. It must agree
+// with engineCacheEntryTypeFilters.
+func engineCacheEntryType(recordType bkclient.UsageRecordType, description string) core.EngineCacheEntryType {
+	switch recordType {
+	case bkclient.UsageRecordTypeCacheMount:
+		return core.EngineCacheEntryCacheMount
+	case bkclient.UsageRecordTypeLocalSource:
+		return core.EngineCacheEntryLocalImport
+	case bkclient.UsageRecordTypeGitCheckout:
+		return core.EngineCacheEntryGitCheckout
+	case bkclient.UsageRecordTypeInternal, bkclient.UsageRecordTypeFrontend:
+		return core.EngineCacheEntryInternal
+	case bkclient.UsageRecordTypeRegular, "":
+		switch {
+		case strings.HasPrefix(description, "pulled from "):
+			return core.EngineCacheEntryImageLayer
+		case execSnapshotDescription.MatchString(description):
+			return core.EngineCacheEntryExec
+		}
+	}
+	return core.EngineCacheEntryOther
+}
+
+var execSnapshotDescription = regexp.MustCompile("^mount .* from exec ")
+
+// engineCacheEntryTypeFilters are the buildkit prune filters selecting each
+// type of cache entry. Multiple filters are ORed together. There is no filter
+// for core.EngineCacheEntryOther, so it can't be pruned by type.
+var engineCacheEntryTypeFilters = map[core.EngineCacheEntryType][]string{
+	core.EngineCacheEntryCacheMount:  {"type==exec.cachemount"},
+	core.EngineCacheEntryImageLayer:  {`type==regular,description~="^pulled from "`},
+	core.EngineCacheEntryExec:        {`type==regular,description~="^mount .* from exec "`},
+	core.EngineCacheEntryLocalImport: {"type==source.local"},
+	core.EngineCacheEntryGitCheckout: {"type==source.git.checkout"},
+	core.EngineCacheEntryInternal:    {"type==internal", "type==frontend"},
+}
+
+// Prune the releasable entries in the local cache that match the given options.
+func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context, opts core.EngineCachePruneOpts) (*core.EngineCacheEntrySet, error) {
+	if _, ok := engineCacheEntryTypeFilters[opts.Type]; opts.Type != "" && !ok {
+		return nil, fmt.Errorf("cannot prune cache entries of type %s", opts.Type)
+	}
+	var pruneFilters []string
+	if opts.Type != "" || opts.MinSize > 0 {
+		// buildkit can't filter by size, and its record type filters don't
+		// classify entries exactly like engineCacheEntryType (e.g. records
+		// without a type), so select the matching records by ID to prune
+		// exactly what's listed
+		du, err := srv.baseWorker.DiskUsage(ctx, bkclient.DiskUsageInfo{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get disk usage from worker: %w", err)
+		}
+		for _, r := range du {
+			if opts.Type != "" && engineCacheEntryType(r.RecordType, r.Description) != opts.Type {
+				continue
+			}
+			if r.Size < opts.MinSize {
+				continue
+			}
+			pruneFilters = append(pruneFilters, "id=="+r.ID)
+		}
+		if len(pruneFilters) == 0 {
+			return &core.EngineCacheEntrySet{}, nil
+		}
+	}
+
 	srv.daggerSessionsMu.RLock()
 	cancelLeases := len(srv.daggerSessions) == 0
 	srv.daggerSessionsMu.RUnlock()
@@ -68,7 +172,12 @@ func (srv *Server) PruneEngineLocalCacheEntries(ctx context.Context) (*core.Engi
 		}
 	}()
 
-	err := srv.baseWorker.Prune(ctx, ch, bkclient.PruneInfo{All: true})
+	err := srv.baseWorker.Prune(ctx, ch, bkclient.PruneInfo{
+		All:          true,
+		Filter:       pruneFilters,
+		KeepDuration: opts.OlderThan,
+		KeepBytes:    opts.KeepBytes,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("worker failed to prune local cache: %w", err)
 	}
@@ -139,10 +248,17 @@ func (srv *Server) gc() {
 	}
 }
 
-func getGCPolicy(cfg config.GCConfig, root string) []bkclient.PruneInfo {
+func getGCPolicy(cfg config.GCConfig, engineCfg GCConfig, root string) []bkclient.PruneInfo {
 	if cfg.GC != nil && !*cfg.GC {
 		return nil
 	}
+	if len(engineCfg.Policies) > 0 {
+		out := make([]bkclient.PruneInfo, 0, len(engineCfg.Policies))
+		for _, policy := range engineCfg.Policies {
+			out = append(out, policy.pruneInfo(root))
+		}
+		return out
+	}
 	if len(cfg.GCPolicy) == 0 {
 		cfg.GCPolicy = defaultGCPolicy(cfg.GCKeepStorage)
 	}
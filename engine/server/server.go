@@ -151,6 +151,7 @@ type Server struct {
 	enabledPlatforms []ocispecs.Platform
 	defaultPlatform  ocispecs.Platform
 	registryHosts    docker.RegistryHosts
+	imagePolicy      buildkit.ImagePolicy
 
 	//
 	// telemetry config+state
@@ -173,8 +174,9 @@ type Server struct {
 }
 
 type NewServerOpts struct {
-	Config *config.Config
-	Name   string
+	Config       *config.Config
+	EngineConfig *EngineConfig
+	Name         string
 
 	TelemetryPubSub *enginetel.PubSub
 }
@@ -183,6 +185,10 @@ type NewServerOpts struct {
 func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 	cfg := opts.Config
 	ociCfg := cfg.Workers.OCI
+	engineCfg := EngineConfig{}
+	if opts.EngineConfig != nil {
+		engineCfg = *opts.EngineConfig
+	}
 
 	srv := &Server{
 		engineName: opts.Name,
@@ -191,6 +197,8 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 
 		frontends: map[string]frontend.Frontend{},
 
+		imagePolicy: engineCfg.Image,
+
 		cgroupParent:    ociCfg.DefaultCgroupParent,
 		processMode:     oci.ProcessSandbox,
 		apparmorProfile: ociCfg.ApparmorProfile,
@@ -367,7 +375,7 @@ func NewServer(ctx context.Context, opts *NewServerOpts) (*Server, error) {
 		ID:        workerID,
 		Labels:    baseLabels,
 		Platforms: srv.enabledPlatforms,
-		GCPolicy:  getGCPolicy(ociCfg.GCConfig, srv.rootDir),
+		GCPolicy:  getGCPolicy(ociCfg.GCConfig, engineCfg.GC, srv.rootDir),
 		BuildkitVersion: bkclient.BuildkitVersion{
 			Package:  version.Package,
 			Version:  version.Version,
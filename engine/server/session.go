@@ -50,6 +50,7 @@ import (
 type daggerSession struct {
 	sessionID          string
 	mainClientCallerID string
+	mainClientStableID string
 
 	state   daggerSessionState
 	stateMu sync.RWMutex
@@ -126,6 +127,9 @@ type daggerClient struct {
 	// metadata of that ongoing function call
 	fnCall *core.FunctionCall
 
+	// If this is a nested client, the client ID of the caller that created it
+	callerClientID string
+
 	// buildkit job-related state/config
 	buildkitSession     *bksession.Session
 	getMainClientCaller func() (bksession.Caller, error)
@@ -154,6 +158,7 @@ func (srv *Server) initializeDaggerSession(
 
 	sess.sessionID = clientMetadata.SessionID
 	sess.mainClientCallerID = clientMetadata.ClientID
+	sess.mainClientStableID = clientMetadata.ClientStableID
 	sess.clients = map[string]*daggerClient{}
 	sess.endpoints = map[string]http.Handler{}
 	sess.shutdownCh = make(chan struct{})
@@ -341,6 +346,7 @@ func (srv *Server) initializeDaggerClient(
 	// initialize all the buildkit+session attachable state for the client
 	client.secretStore = core.NewSecretStore()
 	client.socketStore = core.NewSocketStore(srv.bkSessionManager)
+	client.callerClientID = opts.CallerClientID
 	if opts.CallID != nil {
 		if opts.CallerClientID == "" {
 			return fmt.Errorf("caller client ID is not set")
@@ -401,6 +407,10 @@ func (srv *Server) initializeDaggerClient(
 
 	client.job.SessionID = client.buildkitSession.ID()
 	client.job.SetValue(buildkit.EntitlementsJobKey, srv.entitlements)
+	if pol := srv.imagePolicy.SourcePolicy(); pol != nil {
+		// applies the image policy to images pulled by frontends too
+		client.job.SetValue(buildkit.SourcePolicyJobKey, *pol)
+	}
 
 	br := client.llbSolver.Bridge(client.job)
 	client.llbBridge = br
@@ -455,6 +465,7 @@ func (srv *Server) initializeDaggerClient(
 		Entitlements:         srv.entitlements,
 		UpstreamCacheImports: client.daggerSession.cacheImporterCfgs,
 		Frontends:            srv.frontends,
+		ImagePolicy:          srv.imagePolicy,
 
 		Refs:         client.daggerSession.refs,
 		RefsMu:       &client.daggerSession.refsMu,
@@ -995,6 +1006,30 @@ func (srv *Server) CurrentFunctionCall(ctx context.Context) (*core.FunctionCall,
 	return client.fnCall, nil
 }
 
+// If the current client is coming from a function, return the module of the client that called
+// that function
+func (srv *Server) CallerModule(ctx context.Context) (*core.Module, error) {
+	client, err := srv.clientFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sess := client.daggerSession
+	if client.clientID == sess.mainClientCallerID || client.callerClientID == "" {
+		return nil, fmt.Errorf("%w: client has no caller", core.ErrNoCurrentModule)
+	}
+	caller, ok := srv.clientFromIDs(sess.sessionID, client.callerClientID)
+	if !ok {
+		return nil, fmt.Errorf("caller client %q not found", client.callerClientID)
+	}
+	if caller.clientID == sess.mainClientCallerID {
+		return nil, fmt.Errorf("%w: main client caller has no current module", core.ErrNoCurrentModule)
+	}
+	if caller.mod == nil {
+		return nil, core.ErrNoCurrentModule
+	}
+	return caller.mod, nil
+}
+
 // Return the list of deps being served to the current client
 func (srv *Server) CurrentServedDeps(ctx context.Context) (*core.ModDeps, error) {
 	client, err := srv.clientFromContext(ctx)
@@ -1014,6 +1049,18 @@ func (srv *Server) MainClientCallerID(ctx context.Context) (string, error) {
 	return client.daggerSession.mainClientCallerID, nil
 }
 
+// The stable ID of the main client caller, which persists across its sessions
+func (srv *Server) MainClientStableID(ctx context.Context) (string, error) {
+	client, err := srv.clientFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	if client.daggerSession.mainClientStableID == "" {
+		return "", fmt.Errorf("main client caller has no stable ID")
+	}
+	return client.daggerSession.mainClientStableID, nil
+}
+
 // The default deps of every user module (currently just core)
 func (srv *Server) DefaultDeps(ctx context.Context) (*core.ModDeps, error) {
 	client, err := srv.clientFromContext(ctx)
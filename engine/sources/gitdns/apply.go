@@ -0,0 +1,36 @@
+package gitdns
+
+import (
+	"context"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// Apply applies a patch in the unified diff format produced by git diff to
+// the worktree at dir, which doesn't need to be a git repository.
+func Apply(ctx context.Context, dir string, patch []byte) error {
+	f, err := os.CreateTemp("", "dagger-patch")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(patch); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	git, cleanup, err := newGitCLI("", dir, "", "", nil, nil)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if _, err := git.run(ctx, "apply", "--whitespace=nowarn", f.Name()); err != nil {
+		return errors.Wrap(err, "failed to apply patch")
+	}
+	return nil
+}
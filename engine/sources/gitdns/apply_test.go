@@ -0,0 +1,57 @@
+package gitdns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "f.txt"), []byte("a\nb\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "gone.txt"), []byte("bye\n"), 0o644))
+
+	err := Apply(ctx, dir, []byte(`diff --git a/f.txt b/f.txt
+--- a/f.txt
++++ b/f.txt
+@@ -1,2 +1,2 @@
+ a
+-b
++c
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+diff --git a/bin/new.sh b/bin/new.sh
+new file mode 100755
+--- /dev/null
++++ b/bin/new.sh
+@@ -0,0 +1 @@
++echo hi
+`))
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, "f.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "a\nc\n", string(content))
+	require.NoFileExists(t, filepath.Join(dir, "gone.txt"))
+	info, err := os.Stat(filepath.Join(dir, "bin/new.sh"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// the patch no longer applies
+	err = Apply(ctx, dir, []byte(`--- a/f.txt
++++ b/f.txt
+@@ -1,2 +1,2 @@
+ a
+-b
++d
+`))
+	require.ErrorContains(t, err, "failed to apply patch")
+}
@@ -4,10 +4,23 @@ import (
 	bkgit "github.com/moby/buildkit/source/git"
 )
 
-const AttrDNSNamespace = "dagger.dns.namespace"
+const (
+	AttrDNSNamespace = "dagger.dns.namespace"
+
+	AttrDepth       = "dagger.git.depth"
+	AttrSubmodules  = "dagger.git.submodules"
+	AttrSparsePaths = "dagger.git.sparsepaths"
+)
 
 type GitIdentifier struct {
 	bkgit.GitIdentifier
 
 	Namespace string
+
+	// Depth is the depth of history to fetch, or 0 to fetch all of it.
+	Depth int
+	// SkipSubmodules disables checking out submodules.
+	SkipSubmodules bool
+	// SparsePaths, if set, are the only paths checked out.
+	SparsePaths []string
 }
@@ -0,0 +1,158 @@
+package gitdns
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/buildkit/executor/oci"
+	"github.com/moby/buildkit/session"
+	bkgit "github.com/moby/buildkit/source/git"
+	"github.com/moby/buildkit/util/gitutil"
+	"github.com/moby/buildkit/util/urlutil"
+	"github.com/pkg/errors"
+)
+
+type CommitOpts struct {
+	Message     string
+	AuthorName  string
+	AuthorEmail string
+}
+
+// Commit commits all changes to the worktree at dir, initializing a new
+// repository there if it doesn't have a .git directory yet. It returns the id
+// of the new commit.
+func Commit(ctx context.Context, dir string, opts CommitOpts) (string, error) {
+	git, cleanup, err := newGitCLI(filepath.Join(dir, ".git"), dir, "", "", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	if _, err := os.Lstat(filepath.Join(dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if _, err := git.run(ctx, "-c", "init.defaultBranch=main", "init", dir); err != nil {
+			return "", errors.Wrap(err, "failed to init repo")
+		}
+	} else if err != nil {
+		return "", err
+	}
+
+	if _, err := git.run(ctx, "add", "--all"); err != nil {
+		return "", errors.Wrap(err, "failed to stage changes")
+	}
+	status, err := git.run(ctx, "status", "--porcelain")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get status")
+	}
+	if status.Len() == 0 {
+		return "", errors.New("no changes to commit")
+	}
+	_, err = git.run(ctx,
+		"-c", "user.name="+opts.AuthorName,
+		"-c", "user.email="+opts.AuthorEmail,
+		"commit", "--no-verify", "--no-gpg-sign", "--message", opts.Message)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to commit")
+	}
+
+	buf, err := git.run(ctx, "rev-parse", "HEAD")
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve commit")
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+type PushOpts struct {
+	// Remote is the URL of the repository to push to.
+	Remote string
+	// Ref is the fully-qualified ref to update in the remote.
+	Ref string
+	// Force allows the ref to be updated to a commit that doesn't descend
+	// from its current one.
+	Force bool
+
+	AuthTokenSecret  string
+	AuthHeaderSecret string
+	MountSSHSock     string
+	KnownSSHHosts    string
+
+	Namespace string
+	DNS       *oci.DNSConfig
+}
+
+// Push pushes the HEAD commit of the repository at dir to a remote, using the
+// same authentication and DNS configuration as fetching does.
+func Push(ctx context.Context, sm *session.Manager, g session.Group, dir string, opts PushOpts) error {
+	// normalize the remote like Git does, leaving it to the git CLI to reject
+	// it if it can't be parsed
+	remoteURL := opts.Remote
+	remote, err := gitutil.ParseURL(remoteURL)
+	if errors.Is(err, gitutil.ErrUnknownProtocol) {
+		remote, _ = gitutil.ParseURL("https://" + remoteURL)
+	}
+	if remote != nil {
+		remoteURL = remote.Remote
+	}
+
+	gs := &gitSourceHandler{
+		gitSource: &gitSource{dns: opts.DNS},
+		src: GitIdentifier{
+			GitIdentifier: bkgit.GitIdentifier{
+				Remote:           remoteURL,
+				AuthTokenSecret:  opts.AuthTokenSecret,
+				AuthHeaderSecret: opts.AuthHeaderSecret,
+				MountSSHSock:     opts.MountSSHSock,
+				KnownSSHHosts:    opts.KnownSSHHosts,
+			},
+			Namespace: opts.Namespace,
+		},
+		sm: sm,
+	}
+	if opts.AuthTokenSecret != "" || opts.AuthHeaderSecret != "" {
+		if err := gs.getAuthToken(ctx, g); err != nil {
+			return err
+		}
+	}
+
+	var sock string
+	if gs.src.MountSSHSock != "" {
+		var unmountSock func() error
+		sock, unmountSock, err = gs.mountSSHAuthSock(ctx, gs.src.MountSSHSock, g)
+		if err != nil {
+			return err
+		}
+		defer unmountSock()
+	}
+
+	var knownHosts string
+	if gs.src.KnownSSHHosts != "" {
+		var unmountKnownHosts func() error
+		knownHosts, unmountKnownHosts, err = gs.mountKnownHosts()
+		if err != nil {
+			return err
+		}
+		defer unmountKnownHosts()
+	}
+
+	var netConf *oci.DNSConfig
+	if gs.dns != nil {
+		netConf = gs.dnsConfig()
+	}
+
+	git, cleanup, err := newGitCLI(filepath.Join(dir, ".git"), dir, sock, knownHosts, gs.auth, netConf)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	args := []string{"push"}
+	if opts.Force {
+		args = append(args, "--force")
+	}
+	args = append(args, remoteURL, "HEAD:"+opts.Ref)
+	if _, err := git.run(ctx, args...); err != nil {
+		return errors.Wrapf(err, "failed to push to %s", urlutil.RedactCredentials(remoteURL))
+	}
+	return nil
+}
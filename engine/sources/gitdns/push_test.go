@@ -0,0 +1,54 @@
+package gitdns
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitAndPush(t *testing.T) {
+	ctx := context.Background()
+	opts := CommitOpts{
+		Message:     "init",
+		AuthorName:  "Release Bot",
+		AuthorEmail: "bot@example.com",
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a"), 0o644))
+	first, err := Commit(ctx, dir, opts)
+	require.NoError(t, err)
+
+	_, err = Commit(ctx, dir, opts)
+	require.ErrorContains(t, err, "no changes to commit")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("b"), 0o644))
+	opts.Message = "second"
+	second, err := Commit(ctx, dir, opts)
+	require.NoError(t, err)
+
+	remote := t.TempDir()
+	require.NoError(t, exec.Command("git", "init", "--bare", remote).Run())
+	err = Push(ctx, nil, nil, dir, PushOpts{
+		Remote: "file://" + remote,
+		Ref:    "refs/heads/release",
+	})
+	require.NoError(t, err)
+
+	out, err := exec.Command("git", "--git-dir", remote, "log", "--format=%H %P %an <%ae> %cn %s", "release").Output()
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		second + " " + first + " Release Bot <bot@example.com> Release Bot second",
+		first + "  Release Bot <bot@example.com> Release Bot init",
+	}, strings.Split(strings.TrimSpace(string(out)), "\n"))
+
+	out, err = exec.Command("git", "--git-dir", remote, "ls-tree", "--name-only", "release").Output()
+	require.NoError(t, err)
+	require.Equal(t, "b.txt\n", string(out))
+}
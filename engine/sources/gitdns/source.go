@@ -3,6 +3,7 @@ package gitdns
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -28,6 +29,7 @@ import (
 	"github.com/moby/buildkit/util/bklog"
 	"github.com/moby/buildkit/util/urlutil"
 	"github.com/moby/locker"
+	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -82,6 +84,22 @@ func (gs *gitSource) Identifier(scheme, ref string, attrs map[string]string, pla
 		id.Namespace = v
 	}
 
+	id.Depth = 1
+	if v, ok := attrs[AttrDepth]; ok {
+		id.Depth, err = strconv.Atoi(v)
+		if err != nil || id.Depth < 0 {
+			return nil, errors.Errorf("invalid git depth %q", v)
+		}
+	}
+	if v, ok := attrs[AttrSubmodules]; ok {
+		id.SkipSubmodules = v == "false"
+	}
+	if v, ok := attrs[AttrSparsePaths]; ok {
+		if err := json.Unmarshal([]byte(v), &id.SparsePaths); err != nil {
+			return nil, errors.Wrapf(err, "invalid git sparse paths %q", v)
+		}
+	}
+
 	return id, nil
 }
 
@@ -99,8 +117,19 @@ func (gs *gitSource) Resolve(ctx context.Context, id source.Identifier, sm *sess
 }
 
 // needs to be called with repo lock
-func (gs *gitSource) mountRemote(ctx context.Context, remote string, auth []string, g session.Group) (target string, release func(), retErr error) {
-	sis, err := searchGitRemote(ctx, gs.cache, remote)
+//
+// Partial repos are kept apart from full ones, since a full checkout that
+// keeps its git dir fetches every blob from the shared repo, which can't fetch
+// the blobs it's missing itself.
+func (gs *gitSource) mountRemote(ctx context.Context, remote string, partial bool, auth []string, g session.Group) (target string, release func(), retErr error) {
+	key := remote
+	desc := "shared git repo for %s"
+	if partial {
+		key += "#partial"
+		desc = "shared partial git repo for %s"
+	}
+
+	sis, err := searchGitRemote(ctx, gs.cache, key)
 	if err != nil {
 		return "", nil, errors.Wrapf(err, "failed to search metadata for %s", urlutil.RedactCredentials(remote))
 	}
@@ -121,7 +150,7 @@ func (gs *gitSource) mountRemote(ctx context.Context, remote string, auth []stri
 
 	initializeRepo := false
 	if remoteRef == nil {
-		remoteRef, err = gs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf("shared git repo for %s", urlutil.RedactCredentials(remote))))
+		remoteRef, err = gs.cache.New(ctx, nil, g, cache.CachePolicyRetain, cache.WithDescription(fmt.Sprintf(desc, urlutil.RedactCredentials(remote))))
 		if err != nil {
 			return "", nil, errors.Wrapf(err, "failed to create new mutable for %s", urlutil.RedactCredentials(remote))
 		}
@@ -174,9 +203,17 @@ func (gs *gitSource) mountRemote(ctx context.Context, remote string, auth []stri
 			return "", nil, errors.Wrapf(err, "failed add origin repo at %s", dir)
 		}
 
+		if partial {
+			// allow checkouts that keep their git dir to fetch from this repo
+			// without the blobs it doesn't have
+			if _, err := git.run(ctx, "config", "uploadpack.allowFilter", "true"); err != nil {
+				return "", nil, errors.Wrapf(err, "failed to configure repo at %s", dir)
+			}
+		}
+
 		// save new remote metadata
 		md := cacheRefMetadata{remoteRef}
-		if err := md.setGitRemote(remote); err != nil {
+		if err := md.setGitRemote(key); err != nil {
 			return "", nil, err
 		}
 	}
@@ -198,6 +235,15 @@ func (gs *gitSourceHandler) shaToCacheKey(sha string) string {
 	key := sha
 	if gs.src.KeepGitDir {
 		key += ".git"
+		if gs.src.Depth != 1 {
+			key += ".depth" + strconv.Itoa(gs.src.Depth)
+		}
+	}
+	if gs.src.SkipSubmodules {
+		key += ".nosubmodules"
+	}
+	if len(gs.src.SparsePaths) > 0 {
+		key += ".sparse" + digest.FromString(strings.Join(gs.src.SparsePaths, "\x00")).Encoded()
 	}
 	if gs.src.Subdir != "" {
 		key += ":" + gs.src.Subdir
@@ -343,7 +389,7 @@ func (gs *gitSourceHandler) CacheKey(ctx context.Context, g session.Group, index
 
 	gs.getAuthToken(ctx, g)
 
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, remote, gs.auth, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, remote, false, gs.auth, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
@@ -456,9 +502,13 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		return gs.cache.Get(ctx, sis[0].ID(), nil)
 	}
 
+	// sparse checkouts only fetch the blobs of their paths, lazily as they're
+	// checked out
+	partial := len(gs.src.SparsePaths) > 0
+
 	gs.locker.Lock(gs.src.Remote)
 	defer gs.locker.Unlock(gs.src.Remote)
-	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, gs.auth, g)
+	gitDir, unmountGitDir, err := gs.mountRemote(ctx, gs.src.Remote, partial, gs.auth, g)
 	if err != nil {
 		return nil, err
 	}
@@ -502,8 +552,10 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 
 	doFetch := true
 	if isCommitSHA(ref) {
-		// skip fetch if commit already exists
-		if _, err := git.run(ctx, "cat-file", "-e", ref+"^{commit}"); err == nil {
+		// skip fetch if commit already exists, unless its full history is
+		// needed and the repo is shallow
+		_, shallowErr := os.Lstat(filepath.Join(gitDir, "shallow"))
+		if _, err := git.run(ctx, "cat-file", "-e", ref+"^{commit}"); err == nil && (gs.src.Depth > 0 || shallowErr != nil) {
 			doFetch = false
 		}
 	}
@@ -513,12 +565,21 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		os.RemoveAll(filepath.Join(gitDir, "shallow.lock"))
 
 		args := []string{"fetch"}
+		_, shallowErr := os.Lstat(filepath.Join(gitDir, "shallow"))
+		isShallow := shallowErr == nil
 		if !isCommitSHA(ref) { // TODO: find a branch from ls-remote?
-			args = append(args, "--depth=1", "--no-tags")
-		} else {
-			if _, err := os.Lstat(filepath.Join(gitDir, "shallow")); err == nil {
+			if gs.src.Depth > 0 {
+				args = append(args, "--depth="+strconv.Itoa(gs.src.Depth))
+			} else if isShallow {
 				args = append(args, "--unshallow")
 			}
+			args = append(args, "--no-tags")
+		} else if isShallow {
+			args = append(args, "--unshallow")
+		}
+		if partial {
+			// servers without filter support ignore it and send every blob
+			args = append(args, "--filter=blob:none")
 		}
 		args = append(args, "origin")
 		if !isCommitSHA(ref) {
@@ -604,17 +665,35 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		default:
 			pullref += ":" + pullref
 		}
-		_, err = checkoutGit.run(ctx, "fetch", "-u", "--depth=1", "origin", pullref)
+		fetchArgs := []string{"fetch", "-u"}
+		if gs.src.Depth > 0 {
+			fetchArgs = append(fetchArgs, "--depth="+strconv.Itoa(gs.src.Depth))
+		}
+		if partial {
+			fetchArgs = append(fetchArgs, "--filter=blob:none")
+		}
+		_, err = checkoutGit.run(ctx, append(fetchArgs, "origin", pullref)...)
 		if err != nil {
 			return nil, err
 		}
-		_, err = checkoutGit.run(ctx, "checkout", "FETCH_HEAD")
+		// blobs missing from a partial repo are fetched from the remote itself,
+		// until the submodules are updated
+		_, err = checkoutGit.run(ctx, "remote", "set-url", "origin", gs.src.Remote)
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
+			return nil, errors.Wrapf(err, "failed to set remote origin to %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
-		_, err = checkoutGit.run(ctx, "remote", "set-url", "origin", urlutil.RedactCredentials(gs.src.Remote))
+		if len(gs.src.SparsePaths) > 0 {
+			sparseArgs := []string{"sparse-checkout", "set", "--no-cone"}
+			for _, p := range gs.src.SparsePaths {
+				sparseArgs = append(sparseArgs, path.Join("/", p))
+			}
+			if _, err := checkoutGit.run(ctx, sparseArgs...); err != nil {
+				return nil, errors.Wrapf(err, "failed to set sparse checkout paths for %s", urlutil.RedactCredentials(gs.src.Remote))
+			}
+		}
+		_, err = checkoutGit.run(ctx, "checkout", "FETCH_HEAD")
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to set remote origin to %s", urlutil.RedactCredentials(gs.src.Remote))
+			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
 		_, err = checkoutGit.run(ctx, "reflog", "expire", "--all", "--expire=now")
 		if err != nil {
@@ -632,7 +711,13 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 				return nil, errors.Wrapf(err, "failed to create temporary checkout dir")
 			}
 		}
-		_, err = git.withinDir(gitDir, cd).run(ctx, "checkout", ref, "--", ".")
+		checkoutArgs := []string{"checkout", ref, "--"}
+		if len(gs.src.SparsePaths) > 0 {
+			checkoutArgs = append(checkoutArgs, gs.src.SparsePaths...)
+		} else {
+			checkoutArgs = append(checkoutArgs, ".")
+		}
+		_, err = git.withinDir(gitDir, cd).run(ctx, checkoutArgs...)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to checkout remote %s", urlutil.RedactCredentials(gs.src.Remote))
 		}
@@ -665,9 +750,22 @@ func (gs *gitSourceHandler) Snapshot(ctx context.Context, g session.Group) (out
 		}
 	}
 
-	_, err = git.withinDir(gitDir, checkoutDir).run(ctx, "submodule", "update", "--init", "--recursive", "--depth=1")
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to update submodules for %s", urlutil.RedactCredentials(gs.src.Remote))
+	if !gs.src.SkipSubmodules {
+		submoduleArgs := []string{"submodule", "update", "--init", "--recursive", "--depth=1"}
+		if len(gs.src.SparsePaths) > 0 {
+			submoduleArgs = append(append(submoduleArgs, "--"), gs.src.SparsePaths...)
+		}
+		_, err = git.withinDir(gitDir, checkoutDir).run(ctx, submoduleArgs...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to update submodules for %s", urlutil.RedactCredentials(gs.src.Remote))
+		}
+	}
+
+	if gs.src.KeepGitDir && subdir == "." {
+		_, err = git.withinDir(gitDir, checkoutDir).run(ctx, "remote", "set-url", "origin", urlutil.RedactCredentials(gs.src.Remote))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to set remote origin to %s", urlutil.RedactCredentials(gs.src.Remote))
+		}
 	}
 
 	if idmap := mount.IdentityMapping(); idmap != nil {
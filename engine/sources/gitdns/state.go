@@ -1,7 +1,9 @@
 package gitdns
 
 import (
+	"encoding/json"
 	"path"
+	"strconv"
 
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/solver/pb"
@@ -10,9 +12,19 @@ import (
 	"github.com/pkg/errors"
 )
 
+// CheckoutOpts are the dagger-specific options for checking out a git ref.
+type CheckoutOpts struct {
+	// Depth is the depth of history to fetch, or 0 to fetch all of it.
+	Depth int
+	// SkipSubmodules disables checking out submodules.
+	SkipSubmodules bool
+	// SparsePaths, if set, are the only paths checked out.
+	SparsePaths []string
+}
+
 // Git is a helper mimicking the llb.Git function, but with the ability to
 // set additional attributes.
-func Git(url, ref string, namespace string, opts ...llb.GitOption) llb.State {
+func Git(url, ref string, namespace string, checkout CheckoutOpts, opts ...llb.GitOption) llb.State {
 	remote, err := gitutil.ParseURL(url)
 	if errors.Is(err, gitutil.ErrUnknownProtocol) {
 		url = "https://" + url
@@ -77,6 +89,20 @@ func Git(url, ref string, namespace string, opts ...llb.GitOption) llb.State {
 
 	attrs[AttrDNSNamespace] = namespace
 
+	if checkout.Depth != 1 {
+		attrs[AttrDepth] = strconv.Itoa(checkout.Depth)
+	}
+	if checkout.SkipSubmodules {
+		attrs[AttrSubmodules] = "false"
+	}
+	if len(checkout.SparsePaths) > 0 {
+		sparse, err := json.Marshal(checkout.SparsePaths)
+		if err != nil {
+			panic(err) // a []string always marshals
+		}
+		attrs[AttrSparsePaths] = string(sparse)
+	}
+
 	source := llb.NewSource("git://"+id, attrs, gi.Constraints)
 	return llb.NewState(source.Output())
 }
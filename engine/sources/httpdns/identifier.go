@@ -1,13 +1,26 @@
 package httpdns
 
 import (
+	"net/http"
+
 	bkhttp "github.com/moby/buildkit/source/http"
 )
 
-const AttrDNSNamespace = "dagger.dns.namespace"
+const (
+	AttrDNSNamespace = "dagger.dns.namespace"
+
+	AttrHeaders          = "dagger.http.headers"
+	AttrAuthHeaderSecret = "dagger.http.authheadersecret"
+)
 
 type HTTPIdentifier struct {
 	bkhttp.HTTPIdentifier
 
 	Namespace string
+
+	// Headers are extra headers to send with requests.
+	Headers http.Header
+	// AuthHeaderSecret is the name of a secret to send as the Authorization
+	// header.
+	AuthHeaderSecret string
 }
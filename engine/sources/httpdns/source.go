@@ -19,6 +19,7 @@ import (
 	"github.com/moby/buildkit/cache"
 	"github.com/moby/buildkit/executor/oci"
 	"github.com/moby/buildkit/session"
+	"github.com/moby/buildkit/session/secrets"
 	"github.com/moby/buildkit/snapshot"
 	"github.com/moby/buildkit/solver"
 	"github.com/moby/buildkit/solver/pb"
@@ -79,6 +80,14 @@ func (hs *httpSource) Identifier(scheme, ref string, attrs map[string]string, pl
 	if v, ok := attrs[AttrDNSNamespace]; ok {
 		id.Namespace = v
 	}
+	if v, ok := attrs[AttrHeaders]; ok {
+		if err := json.Unmarshal([]byte(v), &id.Headers); err != nil {
+			return nil, errors.Wrapf(err, "invalid http headers %q", v)
+		}
+	}
+	if v, ok := attrs[AttrAuthHeaderSecret]; ok {
+		id.AuthHeaderSecret = v
+	}
 
 	return id, nil
 }
@@ -116,17 +125,46 @@ func (hs *httpSourceHandler) client(g session.Group) *http.Client {
 	return &http.Client{Transport: newTransport(hs.transport, hs.sm, g, &dns)}
 }
 
+// newRequest creates a GET request for the URL with the configured headers.
+func (hs *httpSourceHandler) newRequest(ctx context.Context, g session.Group) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", hs.src.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range hs.src.Headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if hs.src.AuthHeaderSecret != "" {
+		err := hs.sm.Any(ctx, g, func(ctx context.Context, _ string, caller session.Caller) error {
+			dt, err := secrets.GetSecret(ctx, caller, hs.src.AuthHeaderSecret)
+			if err != nil {
+				return err
+			}
+			req.Header.Set("Authorization", string(dt))
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get auth header secret")
+		}
+	}
+	return req, nil
+}
+
 // urlHash is internal hash the etag is stored by that doesn't leak outside
 // this package.
 func (hs *httpSourceHandler) urlHash() (digest.Digest, error) {
 	dt, err := json.Marshal(struct {
 		Filename       string
 		Perm, UID, GID int
+		Headers        http.Header `json:",omitempty"`
 	}{
 		Filename: getFileName(hs.src.URL, hs.src.Filename, nil),
 		Perm:     hs.src.Perm,
 		UID:      hs.src.UID,
 		GID:      hs.src.GID,
+		Headers:  hs.src.Headers,
 	})
 	if err != nil {
 		return "", err
@@ -171,11 +209,10 @@ func (hs *httpSourceHandler) CacheKey(ctx context.Context, g session.Group, inde
 		return "", "", nil, false, errors.Wrapf(err, "failed to search metadata for %s", uh)
 	}
 
-	req, err := http.NewRequest("GET", hs.src.URL, nil)
+	req, err := hs.newRequest(ctx, g)
 	if err != nil {
 		return "", "", nil, false, err
 	}
-	req = req.WithContext(ctx)
 	m := map[string]cacheRefMetadata{}
 
 	// If we request a single ETag in 'If-None-Match', some servers omit the
@@ -422,11 +459,10 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 		}
 	}
 
-	req, err := http.NewRequest("GET", hs.src.URL, nil)
+	req, err := hs.newRequest(ctx, g)
 	if err != nil {
 		return nil, err
 	}
-	req = req.WithContext(ctx)
 
 	client := hs.client(g)
 
@@ -437,6 +473,9 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, errors.Errorf("invalid response status %d", resp.StatusCode)
+	}
 
 	ref, dgst, err := hs.save(ctx, resp, g)
 	if err != nil {
@@ -444,6 +483,9 @@ func (hs *httpSourceHandler) Snapshot(ctx context.Context, g session.Group) (cac
 	}
 	if dgst != hs.cacheKey {
 		ref.Release(context.TODO())
+		if hs.src.Checksum != "" {
+			return nil, errors.Errorf("checksum mismatch for %s: expected %s, got %s", hs.src.URL, hs.src.Checksum, dgst)
+		}
 		return nil, errors.Errorf("digest mismatch %s: %s", dgst, hs.cacheKey)
 	}
 
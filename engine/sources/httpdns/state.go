@@ -1,15 +1,26 @@
 package httpdns
 
 import (
+	"encoding/json"
+	"net/http"
 	"strconv"
 
 	"github.com/moby/buildkit/client/llb"
 	"github.com/moby/buildkit/solver/pb"
 )
 
+// RequestOpts are the dagger-specific options for fetching a URL.
+type RequestOpts struct {
+	// Headers are extra headers to send with requests.
+	Headers http.Header
+	// AuthHeaderSecret is the name of a secret to send as the Authorization
+	// header.
+	AuthHeaderSecret string
+}
+
 // HTTP is a helper mimicking the llb.HTTP function, but with the ability to
 // set additional attributes.
-func HTTP(url string, namespace string, opts ...llb.HTTPOption) llb.State {
+func HTTP(url string, namespace string, req RequestOpts, opts ...llb.HTTPOption) llb.State {
 	hi := &llb.HTTPInfo{}
 	for _, o := range opts {
 		o.SetHTTPOption(hi)
@@ -33,6 +44,17 @@ func HTTP(url string, namespace string, opts ...llb.HTTPOption) llb.State {
 
 	attrs[AttrDNSNamespace] = namespace
 
+	if len(req.Headers) > 0 {
+		headers, err := json.Marshal(req.Headers)
+		if err != nil {
+			panic(err) // an http.Header always marshals
+		}
+		attrs[AttrHeaders] = string(headers)
+	}
+	if req.AuthHeaderSecret != "" {
+		attrs[AttrAuthHeaderSecret] = req.AuthHeaderSecret
+	}
+
 	source := llb.NewSource(url, attrs, hi.Constraints)
 	return llb.NewState(source.Output())
 }
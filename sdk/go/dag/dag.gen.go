@@ -53,9 +53,9 @@ func BuiltinContainer(digest string) *dagger.Container {
 }
 
 // Constructs a cache volume for a given cache key.
-func CacheVolume(key string) *dagger.CacheVolume {
+func CacheVolume(key string, opts ...dagger.CacheVolumeOpts) *dagger.CacheVolume {
 	client := initClient()
-	return client.CacheVolume(key)
+	return client.CacheVolume(key, opts...)
 }
 
 // Creates a scratch container.
@@ -176,6 +176,12 @@ func LoadDaggerEngineFromID(id dagger.DaggerEngineID) *dagger.DaggerEngine {
 	return client.LoadDaggerEngineFromID(id)
 }
 
+// Load a DirectoryChange from its ID.
+func LoadDirectoryChangeFromID(id dagger.DirectoryChangeID) *dagger.DirectoryChange {
+	client := initClient()
+	return client.LoadDirectoryChangeFromID(id)
+}
+
 // Load a Directory from its ID.
 func LoadDirectoryFromID(id dagger.DirectoryID) *dagger.Directory {
 	client := initClient()
@@ -242,6 +248,18 @@ func LoadGeneratedCodeFromID(id dagger.GeneratedCodeID) *dagger.GeneratedCode {
 	return client.LoadGeneratedCodeFromID(id)
 }
 
+// Load a GitActor from its ID.
+func LoadGitActorFromID(id dagger.GitActorID) *dagger.GitActor {
+	client := initClient()
+	return client.LoadGitActorFromID(id)
+}
+
+// Load a GitCommit from its ID.
+func LoadGitCommitFromID(id dagger.GitCommitID) *dagger.GitCommit {
+	client := initClient()
+	return client.LoadGitCommitFromID(id)
+}
+
 // Load a GitModuleSource from its ID.
 func LoadGitModuleSourceFromID(id dagger.GitModuleSourceID) *dagger.GitModuleSource {
 	client := initClient()
@@ -338,6 +356,12 @@ func LoadScalarTypeDefFromID(id dagger.ScalarTypeDefID) *dagger.ScalarTypeDef {
 	return client.LoadScalarTypeDefFromID(id)
 }
 
+// Load a SearchMatch from its ID.
+func LoadSearchMatchFromID(id dagger.SearchMatchID) *dagger.SearchMatch {
+	client := initClient()
+	return client.LoadSearchMatchFromID(id)
+}
+
 // Load a Secret from its ID.
 func LoadSecretFromID(id dagger.SecretID) *dagger.Secret {
 	client := initClient()
@@ -403,9 +427,9 @@ func Secret(name string, opts ...dagger.SecretOpts) *dagger.Secret {
 // Sets a secret given a user defined name to its plaintext and returns the secret.
 //
 // The plaintext value is limited to a size of 128000 bytes.
-func SetSecret(name string, plaintext string) *dagger.Secret {
+func SetSecret(name string, plaintext string, opts ...dagger.SetSecretOpts) *dagger.Secret {
 	client := initClient()
-	return client.SetSecret(name, plaintext)
+	return client.SetSecret(name, plaintext, opts...)
 }
 
 // Create a new TypeDef.
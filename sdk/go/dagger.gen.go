@@ -130,6 +130,9 @@ type DaggerEngineCacheID string
 // The `DaggerEngineID` scalar type represents an identifier for an object of type DaggerEngine.
 type DaggerEngineID string
 
+// The `DirectoryChangeID` scalar type represents an identifier for an object of type DirectoryChange.
+type DirectoryChangeID string
+
 // The `DirectoryID` scalar type represents an identifier for an object of type Directory.
 type DirectoryID string
 
@@ -163,6 +166,12 @@ type FunctionID string
 // The `GeneratedCodeID` scalar type represents an identifier for an object of type GeneratedCode.
 type GeneratedCodeID string
 
+// The `GitActorID` scalar type represents an identifier for an object of type GitActor.
+type GitActorID string
+
+// The `GitCommitID` scalar type represents an identifier for an object of type GitCommit.
+type GitCommitID string
+
 // The `GitModuleSourceID` scalar type represents an identifier for an object of type GitModuleSource.
 type GitModuleSourceID string
 
@@ -219,6 +228,9 @@ type PortID string
 // The `ScalarTypeDefID` scalar type represents an identifier for an object of type ScalarTypeDef.
 type ScalarTypeDefID string
 
+// The `SearchMatchID` scalar type represents an identifier for an object of type SearchMatch.
+type SearchMatchID string
+
 // The `SecretID` scalar type represents an identifier for an object of type Secret.
 type SecretID string
 
@@ -248,6 +260,24 @@ type BuildArg struct {
 	Value string `json:"value"`
 }
 
+// Key value object that represents an HTTP header.
+type HTTPHeader struct {
+	// The header name.
+	Name string `json:"name"`
+
+	// The header value.
+	Value string `json:"value"`
+}
+
+// Key value object that represents an OCI image annotation.
+type ImageAnnotation struct {
+	// The annotation name, e.g. org.opencontainers.image.source.
+	Name string `json:"name"`
+
+	// The annotation value.
+	Value string `json:"value"`
+}
+
 // Key value object that represents a pipeline label.
 type PipelineLabel struct {
 	// Label name.
@@ -273,7 +303,12 @@ type PortForward struct {
 type CacheVolume struct {
 	query *querybuilder.Selection
 
-	id *CacheVolumeID
+	clear     *Void
+	id        *CacheVolumeID
+	namespace *string
+	scope     *CacheVolumeScope
+	seed      *Void
+	size      *int
 }
 
 func (r *CacheVolume) WithGraphQLQuery(q *querybuilder.Selection) *CacheVolume {
@@ -282,6 +317,18 @@ func (r *CacheVolume) WithGraphQLQuery(q *querybuilder.Selection) *CacheVolume {
 	}
 }
 
+// Removes the contents of the cache volume.
+//
+// Containers currently using the cache volume will see it emptied.
+func (r *CacheVolume) Clear(ctx context.Context) error {
+	if r.clear != nil {
+		return nil
+	}
+	q := r.query.Select("clear")
+
+	return q.Execute(ctx)
+}
+
 // A unique identifier for this CacheVolume.
 func (r *CacheVolume) ID(ctx context.Context) (CacheVolumeID, error) {
 	if r.id != nil {
@@ -322,11 +369,76 @@ func (r *CacheVolume) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// The namespace of the cache volume, derived from its scope. Empty for global cache volumes.
+func (r *CacheVolume) Namespace(ctx context.Context) (string, error) {
+	if r.namespace != nil {
+		return *r.namespace, nil
+	}
+	q := r.query.Select("namespace")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The scope the cache volume was created with.
+func (r *CacheVolume) Scope(ctx context.Context) (CacheVolumeScope, error) {
+	if r.scope != nil {
+		return *r.scope, nil
+	}
+	q := r.query.Select("scope")
+
+	var response CacheVolumeScope
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// Replaces the contents of the cache volume with the contents of the given directory.
+//
+// Containers currently using the cache volume will see the new contents.
+func (r *CacheVolume) Seed(ctx context.Context, source *Directory) error {
+	assertNotNil("source", source)
+	if r.seed != nil {
+		return nil
+	}
+	q := r.query.Select("seed")
+	q = q.Arg("source", source)
+
+	return q.Execute(ctx)
+}
+
+// The disk space used by the cache volume, in bytes.
+func (r *CacheVolume) Size(ctx context.Context) (int, error) {
+	if r.size != nil {
+		return *r.size, nil
+	}
+	q := r.query.Select("size")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// Returns a copy of the current contents of the cache volume.
+//
+// Changes to the cache volume after the snapshot is taken are not reflected in the returned directory.
+func (r *CacheVolume) Snapshot() *Directory {
+	q := r.query.Select("snapshot")
+
+	return &Directory{
+		query: q,
+	}
+}
+
 // An OCI-compatible container, also known as a Docker container.
 type Container struct {
 	query *querybuilder.Selection
 
 	envVariable *string
+	exitCode    *int
 	export      *string
 	id          *ContainerID
 	imageRef    *string
@@ -354,11 +466,31 @@ func (r *Container) WithGraphQLQuery(q *querybuilder.Selection) *Container {
 	}
 }
 
+// ContainerAsServiceOpts contains options for Container.AsService
+type ContainerAsServiceOpts struct {
+	// When to restart the service if it exits without being stopped.
+	RestartPolicy ServiceRestartPolicy
+	// The maximum number of times to restart the service with the ON_FAILURE restart policy.
+	//
+	// Zero means no limit.
+	MaxRetries int
+}
+
 // Turn the container into a Service.
 //
 // Be sure to set any exposed ports before this conversion.
-func (r *Container) AsService() *Service {
+func (r *Container) AsService(opts ...ContainerAsServiceOpts) *Service {
 	q := r.query.Select("asService")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `restartPolicy` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RestartPolicy) {
+			q = q.Arg("restartPolicy", opts[i].RestartPolicy)
+		}
+		// `maxRetries` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxRetries) {
+			q = q.Arg("maxRetries", opts[i].MaxRetries)
+		}
+	}
 
 	return &Service{
 		query: q,
@@ -375,10 +507,22 @@ type ContainerAsTarballOpts struct {
 	//
 	// If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
 	ForcedCompression ImageLayerCompression
+	// The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+	CompressionLevel int
 	// Use the specified media types for the image's layers.
 	//
 	// Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
 	MediaTypes ImageMediaTypes
+	// Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+	Provenance bool
+	// OCI annotations to set on the image's manifests.
+	//
+	// Annotations require OCI media types.
+	Annotations []ImageAnnotation
+	// Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+	AnnotateFromLabels bool
+	// OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+	IndexAnnotations []ImageAnnotation
 }
 
 // Returns a File representing the container serialized to a tarball.
@@ -393,10 +537,30 @@ func (r *Container) AsTarball(opts ...ContainerAsTarballOpts) *File {
 		if !querybuilder.IsZeroValue(opts[i].ForcedCompression) {
 			q = q.Arg("forcedCompression", opts[i].ForcedCompression)
 		}
+		// `compressionLevel` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CompressionLevel) {
+			q = q.Arg("compressionLevel", opts[i].CompressionLevel)
+		}
 		// `mediaTypes` optional argument
 		if !querybuilder.IsZeroValue(opts[i].MediaTypes) {
 			q = q.Arg("mediaTypes", opts[i].MediaTypes)
 		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
+		// `annotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Annotations) {
+			q = q.Arg("annotations", opts[i].Annotations)
+		}
+		// `annotateFromLabels` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AnnotateFromLabels) {
+			q = q.Arg("annotateFromLabels", opts[i].AnnotateFromLabels)
+		}
+		// `indexAnnotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].IndexAnnotations) {
+			q = q.Arg("indexAnnotations", opts[i].IndexAnnotations)
+		}
 	}
 
 	return &File{
@@ -528,6 +692,21 @@ func (r *Container) EnvVariables(ctx context.Context) ([]EnvVariable, error) {
 	return convert(response), nil
 }
 
+// The exit code of the last executed command.
+//
+// Returns an error if no command was set.
+func (r *Container) ExitCode(ctx context.Context) (int, error) {
+	if r.exitCode != nil {
+		return *r.exitCode, nil
+	}
+	q := r.query.Select("exitCode")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // EXPERIMENTAL API! Subject to change/removal at any time.
 //
 // Configures all available GPUs on the host to be accessible to this container.
@@ -565,10 +744,22 @@ type ContainerExportOpts struct {
 	//
 	// If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
 	ForcedCompression ImageLayerCompression
+	// The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+	CompressionLevel int
 	// Use the specified media types for the exported image's layers.
 	//
 	// Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
 	MediaTypes ImageMediaTypes
+	// Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+	Provenance bool
+	// OCI annotations to set on the image's manifests.
+	//
+	// Annotations require OCI media types.
+	Annotations []ImageAnnotation
+	// Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+	AnnotateFromLabels bool
+	// OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+	IndexAnnotations []ImageAnnotation
 }
 
 // Writes the container as an OCI tarball to the destination file path on the host.
@@ -588,10 +779,30 @@ func (r *Container) Export(ctx context.Context, path string, opts ...ContainerEx
 		if !querybuilder.IsZeroValue(opts[i].ForcedCompression) {
 			q = q.Arg("forcedCompression", opts[i].ForcedCompression)
 		}
+		// `compressionLevel` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CompressionLevel) {
+			q = q.Arg("compressionLevel", opts[i].CompressionLevel)
+		}
 		// `mediaTypes` optional argument
 		if !querybuilder.IsZeroValue(opts[i].MediaTypes) {
 			q = q.Arg("mediaTypes", opts[i].MediaTypes)
 		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
+		// `annotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Annotations) {
+			q = q.Arg("annotations", opts[i].Annotations)
+		}
+		// `annotateFromLabels` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AnnotateFromLabels) {
+			q = q.Arg("annotateFromLabels", opts[i].AnnotateFromLabels)
+		}
+		// `indexAnnotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].IndexAnnotations) {
+			q = q.Arg("indexAnnotations", opts[i].IndexAnnotations)
+		}
 	}
 	q = q.Arg("path", path)
 
@@ -648,9 +859,31 @@ func (r *Container) File(path string) *File {
 	}
 }
 
+// ContainerFromOpts contains options for Container.From
+type ContainerFromOpts struct {
+	// A PEM encoded public key or certificate to verify the image's signature with, as pushed by cosign or Container.publish.
+	//
+	// The image must have a valid signature made with the matching private key.
+	Verify string
+	// Require the address to be pinned to a digest (e.g., "alpine@sha256:...").
+	//
+	// Unpinned images can be pinned to the digest they resolve to with imageRef.
+	RequireDigest bool
+}
+
 // Initializes this container from a pulled base image.
-func (r *Container) From(address string) *Container {
+func (r *Container) From(address string, opts ...ContainerFromOpts) *Container {
 	q := r.query.Select("from")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `verify` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Verify) {
+			q = q.Arg("verify", opts[i].Verify)
+		}
+		// `requireDigest` optional argument
+		if !querybuilder.IsZeroValue(opts[i].RequireDigest) {
+			q = q.Arg("requireDigest", opts[i].RequireDigest)
+		}
+	}
 	q = q.Arg("address", address)
 
 	return &Container{
@@ -699,6 +932,8 @@ func (r *Container) MarshalJSON() ([]byte, error) {
 }
 
 // The unique image reference which can only be retrieved immediately after the 'Container.From' call.
+//
+// It's the address the container was initialized from, pinned to the digest it resolved to, even if the engine pulled it from a mirror.
 func (r *Container) ImageRef(ctx context.Context) (string, error) {
 	if r.imageRef != nil {
 		return *r.imageRef, nil
@@ -844,10 +1079,34 @@ type ContainerPublishOpts struct {
 	//
 	// If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
 	ForcedCompression ImageLayerCompression
+	// The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+	CompressionLevel int
 	// Use the specified media types for the published image's layers.
 	//
 	// Defaults to OCI, which is largely compatible with most recent registries, but Docker may be needed for older registries without OCI support.
 	MediaTypes ImageMediaTypes
+	// Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+	Provenance bool
+	// OCI annotations to set on the image's manifests.
+	//
+	// Annotations require OCI media types.
+	Annotations []ImageAnnotation
+	// Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+	AnnotateFromLabels bool
+	// OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+	IndexAnnotations []ImageAnnotation
+	// Additional tags to push the image to, in the repository of address (e.g. ["v1.2.3", "latest"]).
+	Tags []string
+	// Sign the published image with this PEM encoded private key, pushing the signature next to it in a format cosign can verify.
+	//
+	// Keys encrypted by cosign generate-key-pair are supported.
+	//
+	// The signature isn't recorded in a transparency log, so verifying it with cosign verify --key requires --insecure-ignore-tlog.
+	SigningKey *Secret
+	// The password of an encrypted signingKey.
+	SigningKeyPassword *Secret
+	// A PEM encoded certificate for signingKey, followed by its chain, to attach to the signature.
+	SigningCertificate string
 }
 
 // Publishes this container as a new image to the specified address.
@@ -869,10 +1128,46 @@ func (r *Container) Publish(ctx context.Context, address string, opts ...Contain
 		if !querybuilder.IsZeroValue(opts[i].ForcedCompression) {
 			q = q.Arg("forcedCompression", opts[i].ForcedCompression)
 		}
+		// `compressionLevel` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CompressionLevel) {
+			q = q.Arg("compressionLevel", opts[i].CompressionLevel)
+		}
 		// `mediaTypes` optional argument
 		if !querybuilder.IsZeroValue(opts[i].MediaTypes) {
 			q = q.Arg("mediaTypes", opts[i].MediaTypes)
 		}
+		// `provenance` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Provenance) {
+			q = q.Arg("provenance", opts[i].Provenance)
+		}
+		// `annotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Annotations) {
+			q = q.Arg("annotations", opts[i].Annotations)
+		}
+		// `annotateFromLabels` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AnnotateFromLabels) {
+			q = q.Arg("annotateFromLabels", opts[i].AnnotateFromLabels)
+		}
+		// `indexAnnotations` optional argument
+		if !querybuilder.IsZeroValue(opts[i].IndexAnnotations) {
+			q = q.Arg("indexAnnotations", opts[i].IndexAnnotations)
+		}
+		// `tags` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Tags) {
+			q = q.Arg("tags", opts[i].Tags)
+		}
+		// `signingKey` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SigningKey) {
+			q = q.Arg("signingKey", opts[i].SigningKey)
+		}
+		// `signingKeyPassword` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SigningKeyPassword) {
+			q = q.Arg("signingKeyPassword", opts[i].SigningKeyPassword)
+		}
+		// `signingCertificate` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SigningCertificate) {
+			q = q.Arg("signingCertificate", opts[i].SigningCertificate)
+		}
 	}
 	q = q.Arg("address", address)
 
@@ -1127,6 +1422,18 @@ type ContainerWithExecOpts struct {
 	ExperimentalPrivilegedNesting bool
 	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
 	InsecureRootCapabilities bool
+	// Exit codes this command is allowed to exit with without error
+	Expect ReturnType
+	// Maximum duration the command may run for before it is killed and the exec fails (e.g., "30s", "5m").
+	Timeout string
+	// Maximum amount of memory the command may use (e.g., "512m", "2g").
+	MemoryLimit string
+	// Maximum number of CPUs the command may use (e.g., "0.5", "2").
+	CPUQuota string
+	// Maximum number of processes the command may create.
+	//
+	// If zero, no limit is applied.
+	PidsLimit int
 }
 
 // Retrieves this container after executing the specified command inside it.
@@ -1161,6 +1468,26 @@ func (r *Container) WithExec(args []string, opts ...ContainerWithExecOpts) *Cont
 		if !querybuilder.IsZeroValue(opts[i].InsecureRootCapabilities) {
 			q = q.Arg("insecureRootCapabilities", opts[i].InsecureRootCapabilities)
 		}
+		// `expect` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Expect) {
+			q = q.Arg("expect", opts[i].Expect)
+		}
+		// `timeout` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Timeout) {
+			q = q.Arg("timeout", opts[i].Timeout)
+		}
+		// `memoryLimit` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MemoryLimit) {
+			q = q.Arg("memoryLimit", opts[i].MemoryLimit)
+		}
+		// `cpuQuota` optional argument
+		if !querybuilder.IsZeroValue(opts[i].CPUQuota) {
+			q = q.Arg("cpuQuota", opts[i].CPUQuota)
+		}
+		// `pidsLimit` optional argument
+		if !querybuilder.IsZeroValue(opts[i].PidsLimit) {
+			q = q.Arg("pidsLimit", opts[i].PidsLimit)
+		}
 	}
 	q = q.Arg("args", args)
 
@@ -1497,6 +1824,35 @@ func (r *Container) WithRootfs(directory *Directory) *Container {
 	}
 }
 
+// ContainerWithSBOMOpts contains options for Container.WithSBOM
+type ContainerWithSBOMOpts struct {
+	// An SPDX JSON document describing the container's contents.
+	Sbom *File
+	// A BuildKit SBOM scanner image to generate the SBOM with, if none is given.
+	Scanner string
+}
+
+// Retrieves this container with an SPDX SBOM attached to its image as an attestation when it's published or exported.
+//
+// If no SBOM is given, one is generated by scanning the container's root filesystem when its image is published or exported, like `docker buildx build --sbom` does.
+func (r *Container) WithSBOM(opts ...ContainerWithSBOMOpts) *Container {
+	q := r.query.Select("withSBOM")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `sbom` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sbom) {
+			q = q.Arg("sbom", opts[i].Sbom)
+		}
+		// `scanner` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Scanner) {
+			q = q.Arg("scanner", opts[i].Scanner)
+		}
+	}
+
+	return &Container{
+		query: q,
+	}
+}
+
 // Retrieves this container plus an env variable containing the given secret.
 func (r *Container) WithSecretVariable(name string, secret *Secret) *Container {
 	assertNotNil("secret", secret)
@@ -1698,6 +2054,15 @@ func (r *Container) WithoutRegistryAuth(address string) *Container {
 	}
 }
 
+// Retrieves this container without an SBOM attached to its image.
+func (r *Container) WithoutSBOM() *Container {
+	q := r.query.Select("withoutSBOM")
+
+	return &Container{
+		query: q,
+	}
+}
+
 // Retrieves this container minus the given environment variable containing the secret.
 func (r *Container) WithoutSecretVariable(name string) *Container {
 	q := r.query.Select("withoutSecretVariable")
@@ -1944,9 +2309,61 @@ func (r *DaggerEngineCache) WithGraphQLQuery(q *querybuilder.Selection) *DaggerE
 	}
 }
 
+// DaggerEngineCacheEntrySetOpts contains options for DaggerEngineCache.EntrySet
+type DaggerEngineCacheEntrySetOpts struct {
+	// Only include entries whose description matches this glob, where "*"
+	//
+	// matches any sequence of characters (e.g. "pulled from *alpine*").
+	Description string
+	// Only include entries of this type.
+	Type DaggerEngineCacheEntryType
+	// Only include entries that are (or aren't) actively being used.
+	InUse bool
+	// Only include entries using at least this many bytes.
+	MinSize int
+	// Only include entries last used before this time, given as an RFC 3339
+	//
+	// timestamp or a duration ago (e.g. "24h" or "7d").
+	LastUsedBefore string
+	// The order to list entries in.
+	SortBy DaggerEngineCacheEntrySortBy
+	// The maximum number of entries to include.
+	Limit int
+}
+
 // The current set of entries in the cache
-func (r *DaggerEngineCache) EntrySet() *DaggerEngineCacheEntrySet {
+func (r *DaggerEngineCache) EntrySet(opts ...DaggerEngineCacheEntrySetOpts) *DaggerEngineCacheEntrySet {
 	q := r.query.Select("entrySet")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `description` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Description) {
+			q = q.Arg("description", opts[i].Description)
+		}
+		// `type` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Type) {
+			q = q.Arg("type", opts[i].Type)
+		}
+		// `inUse` optional argument
+		if !querybuilder.IsZeroValue(opts[i].InUse) {
+			q = q.Arg("inUse", opts[i].InUse)
+		}
+		// `minSize` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MinSize) {
+			q = q.Arg("minSize", opts[i].MinSize)
+		}
+		// `lastUsedBefore` optional argument
+		if !querybuilder.IsZeroValue(opts[i].LastUsedBefore) {
+			q = q.Arg("lastUsedBefore", opts[i].LastUsedBefore)
+		}
+		// `sortBy` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SortBy) {
+			q = q.Arg("sortBy", opts[i].SortBy)
+		}
+		// `limit` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Limit) {
+			q = q.Arg("limit", opts[i].Limit)
+		}
+	}
 
 	return &DaggerEngineCacheEntrySet{
 		query: q,
@@ -2006,12 +2423,46 @@ func (r *DaggerEngineCache) KeepBytes(ctx context.Context) (int, error) {
 	return response, q.Execute(ctx)
 }
 
+// DaggerEngineCachePruneOpts contains options for DaggerEngineCache.Prune
+type DaggerEngineCachePruneOpts struct {
+	// Only prune entries that have not been used for at least this long,
+	//
+	// e.g. "24h" or "7d".
+	OlderThan string
+	// Only prune entries of this type.
+	Type DaggerEngineCacheEntryType
+	// Only prune entries using at least this many bytes.
+	MinSize int
+	// Only prune while the cache uses more than this many bytes, starting
+	//
+	// with the least recently used entries.
+	KeepBytes int
+}
+
 // Prune the cache of releaseable entries
-func (r *DaggerEngineCache) Prune(ctx context.Context) error {
+func (r *DaggerEngineCache) Prune(ctx context.Context, opts ...DaggerEngineCachePruneOpts) error {
 	if r.prune != nil {
 		return nil
 	}
 	q := r.query.Select("prune")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `olderThan` optional argument
+		if !querybuilder.IsZeroValue(opts[i].OlderThan) {
+			q = q.Arg("olderThan", opts[i].OlderThan)
+		}
+		// `type` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Type) {
+			q = q.Arg("type", opts[i].Type)
+		}
+		// `minSize` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MinSize) {
+			q = q.Arg("minSize", opts[i].MinSize)
+		}
+		// `keepBytes` optional argument
+		if !querybuilder.IsZeroValue(opts[i].KeepBytes) {
+			q = q.Arg("keepBytes", opts[i].KeepBytes)
+		}
+	}
 
 	return q.Execute(ctx)
 }
@@ -2021,11 +2472,14 @@ type DaggerEngineCacheEntry struct {
 	query *querybuilder.Selection
 
 	activelyUsed              *bool
+	cacheMountKey             *string
+	cacheMountNamespace       *string
 	createdTimeUnixNano       *int
 	description               *string
 	diskSpaceBytes            *int
 	id                        *DaggerEngineCacheEntryID
 	mostRecentUseTimeUnixNano *int
+	recordType                *DaggerEngineCacheEntryType
 }
 
 func (r *DaggerEngineCacheEntry) WithGraphQLQuery(q *querybuilder.Selection) *DaggerEngineCacheEntry {
@@ -2047,6 +2501,32 @@ func (r *DaggerEngineCacheEntry) ActivelyUsed(ctx context.Context) (bool, error)
 	return response, q.Execute(ctx)
 }
 
+// The key of the cache volume owning the cache entry, if it is a cache mount. Only known for cache volumes mounted since the engine started.
+func (r *DaggerEngineCacheEntry) CacheMountKey(ctx context.Context) (string, error) {
+	if r.cacheMountKey != nil {
+		return *r.cacheMountKey, nil
+	}
+	q := r.query.Select("cacheMountKey")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The namespace of the cache volume owning the cache entry, derived from its scope. Empty for global cache volumes. Only known for cache volumes mounted since the engine started.
+func (r *DaggerEngineCacheEntry) CacheMountNamespace(ctx context.Context) (string, error) {
+	if r.cacheMountNamespace != nil {
+		return *r.cacheMountNamespace, nil
+	}
+	q := r.query.Select("cacheMountNamespace")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // The time the cache entry was created, in Unix nanoseconds.
 func (r *DaggerEngineCacheEntry) CreatedTimeUnixNano(ctx context.Context) (int, error) {
 	if r.createdTimeUnixNano != nil {
@@ -2139,6 +2619,19 @@ func (r *DaggerEngineCacheEntry) MostRecentUseTimeUnixNano(ctx context.Context)
 	return response, q.Execute(ctx)
 }
 
+// The type of data held by the cache entry.
+func (r *DaggerEngineCacheEntry) RecordType(ctx context.Context) (DaggerEngineCacheEntryType, error) {
+	if r.recordType != nil {
+		return *r.recordType, nil
+	}
+	q := r.query.Select("recordType")
+
+	var response DaggerEngineCacheEntryType
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A set of cache entries returned by a query to a cache
 type DaggerEngineCacheEntrySet struct {
 	query *querybuilder.Selection
@@ -2167,9 +2660,27 @@ func (r *DaggerEngineCacheEntrySet) DiskSpaceBytes(ctx context.Context) (int, er
 	return response, q.Execute(ctx)
 }
 
+// DaggerEngineCacheEntrySetEntriesOpts contains options for DaggerEngineCacheEntrySet.Entries
+type DaggerEngineCacheEntrySetEntriesOpts struct {
+	// The number of entries to skip.
+	Offset int
+	// The maximum number of entries to include.
+	Limit int
+}
+
 // The list of individual cache entries in the set
-func (r *DaggerEngineCacheEntrySet) Entries(ctx context.Context) ([]DaggerEngineCacheEntry, error) {
+func (r *DaggerEngineCacheEntrySet) Entries(ctx context.Context, opts ...DaggerEngineCacheEntrySetEntriesOpts) ([]DaggerEngineCacheEntry, error) {
 	q := r.query.Select("entries")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `offset` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Offset) {
+			q = q.Arg("offset", opts[i].Offset)
+		}
+		// `limit` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Limit) {
+			q = q.Arg("limit", opts[i].Limit)
+		}
+	}
 
 	q = q.Select("id")
 
@@ -2276,6 +2787,31 @@ func (r *Directory) WithGraphQLQuery(q *querybuilder.Selection) *Directory {
 	}
 }
 
+// DirectoryAsGitCommitOpts contains options for Directory.AsGitCommit
+type DirectoryAsGitCommitOpts struct {
+	// The commit to build on. If not set, a new repository is created.
+	Parent *GitRef
+}
+
+// Commits the contents of this directory to a new git commit.
+//
+// Any .git directory in it is ignored.
+func (r *Directory) AsGitCommit(message string, author string, opts ...DirectoryAsGitCommitOpts) *GitRef {
+	q := r.query.Select("asGitCommit")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `parent` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Parent) {
+			q = q.Arg("parent", opts[i].Parent)
+		}
+	}
+	q = q.Arg("message", message)
+	q = q.Arg("author", author)
+
+	return &GitRef{
+		query: q,
+	}
+}
+
 // DirectoryAsModuleOpts contains options for Directory.AsModule
 type DirectoryAsModuleOpts struct {
 	// An optional subpath of the directory which contains the module's configuration file.
@@ -2307,6 +2843,43 @@ func (r *Directory) AsModule(opts ...DirectoryAsModuleOpts) *Module {
 	}
 }
 
+// Lists the paths that were added, modified or deleted in another directory compared to this one.
+//
+// Paths whose metadata changed but whose content and permissions are the same, like files with new timestamps, are not listed.
+func (r *Directory) Changes(ctx context.Context, other *Directory) ([]DirectoryChange, error) {
+	assertNotNil("other", other)
+	q := r.query.Select("changes")
+	q = q.Arg("other", other)
+
+	q = q.Select("id")
+
+	type changes struct {
+		Id DirectoryChangeID
+	}
+
+	convert := func(fields []changes) []DirectoryChange {
+		out := []DirectoryChange{}
+
+		for i := range fields {
+			val := DirectoryChange{id: &fields[i].Id}
+			val.query = q.Root().Select("loadDirectoryChangeFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []changes
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
 // Gets the difference between this directory and an another directory.
 func (r *Directory) Diff(other *Directory) *Directory {
 	assertNotNil("other", other)
@@ -2514,34 +3087,96 @@ func (r *Directory) Pipeline(name string, opts ...DirectoryPipelineOpts) *Direct
 	}
 }
 
-// Force evaluation in the engine.
-func (r *Directory) Sync(ctx context.Context) (*Directory, error) {
-	q := r.query.Select("sync")
-
-	var id DirectoryID
-	if err := q.Bind(&id).Execute(ctx); err != nil {
-		return nil, err
-	}
-	return &Directory{
-		query: q.Root().Select("loadDirectoryFromID").Arg("id", id),
-	}, nil
-}
-
-// DirectoryTerminalOpts contains options for Directory.Terminal
-type DirectoryTerminalOpts struct {
-	// If set, override the container's default terminal command and invoke these command arguments instead.
-	Cmd []string
-	// Provides Dagger access to the executed command.
+// DirectorySearchOpts contains options for Directory.Search
+type DirectorySearchOpts struct {
+	// Interpret the pattern as a regular expression, using RE2 syntax (e.g., "TODO\(\w+\)").
+	Regex bool
+	// Only search files matching these patterns (e.g., ["**/*.go", "!vendor"]).
 	//
-	// Do not use this option unless you trust the command being executed; the command being executed WILL BE GRANTED FULL ACCESS TO YOUR HOST FILESYSTEM.
-	ExperimentalPrivilegedNesting bool
-	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
-	InsecureRootCapabilities bool
-	// If set, override the default container used for the terminal.
-	Container *Container
+	// If every pattern is an exclusion, all other files are searched.
+	Globs []string
+	// Stop after this many matches. Zero means no limit.
+	MaxResults int
 }
 
-// Opens an interactive terminal in new container with this directory mounted inside.
+// Searches the contents of the files in this directory, returning the matching lines.
+//
+// Binary files are skipped and symlinks are not followed.
+func (r *Directory) Search(ctx context.Context, pattern string, opts ...DirectorySearchOpts) ([]SearchMatch, error) {
+	q := r.query.Select("search")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `regex` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Regex) {
+			q = q.Arg("regex", opts[i].Regex)
+		}
+		// `globs` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Globs) {
+			q = q.Arg("globs", opts[i].Globs)
+		}
+		// `maxResults` optional argument
+		if !querybuilder.IsZeroValue(opts[i].MaxResults) {
+			q = q.Arg("maxResults", opts[i].MaxResults)
+		}
+	}
+	q = q.Arg("pattern", pattern)
+
+	q = q.Select("id")
+
+	type search struct {
+		Id SearchMatchID
+	}
+
+	convert := func(fields []search) []SearchMatch {
+		out := []SearchMatch{}
+
+		for i := range fields {
+			val := SearchMatch{id: &fields[i].Id}
+			val.query = q.Root().Select("loadSearchMatchFromID").Arg("id", fields[i].Id)
+			out = append(out, val)
+		}
+
+		return out
+	}
+	var response []search
+
+	q = q.Bind(&response)
+
+	err := q.Execute(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return convert(response), nil
+}
+
+// Force evaluation in the engine.
+func (r *Directory) Sync(ctx context.Context) (*Directory, error) {
+	q := r.query.Select("sync")
+
+	var id DirectoryID
+	if err := q.Bind(&id).Execute(ctx); err != nil {
+		return nil, err
+	}
+	return &Directory{
+		query: q.Root().Select("loadDirectoryFromID").Arg("id", id),
+	}, nil
+}
+
+// DirectoryTerminalOpts contains options for Directory.Terminal
+type DirectoryTerminalOpts struct {
+	// If set, override the container's default terminal command and invoke these command arguments instead.
+	Cmd []string
+	// Provides Dagger access to the executed command.
+	//
+	// Do not use this option unless you trust the command being executed; the command being executed WILL BE GRANTED FULL ACCESS TO YOUR HOST FILESYSTEM.
+	ExperimentalPrivilegedNesting bool
+	// Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
+	InsecureRootCapabilities bool
+	// If set, override the default container used for the terminal.
+	Container *Container
+}
+
+// Opens an interactive terminal in new container with this directory mounted inside.
 func (r *Directory) Terminal(opts ...DirectoryTerminalOpts) *Directory {
 	q := r.query.Select("terminal")
 	for i := len(opts) - 1; i >= 0; i-- {
@@ -2690,6 +3325,37 @@ func (r *Directory) WithNewFile(path string, contents string, opts ...DirectoryW
 	}
 }
 
+// Retrieves this directory with the owner of all of its files and directories set to the given user and group.
+func (r *Directory) WithOwner(owner string) *Directory {
+	q := r.query.Select("withOwner")
+	q = q.Arg("owner", owner)
+
+	return &Directory{
+		query: q,
+	}
+}
+
+// Retrieves this directory with the given patch applied.
+func (r *Directory) WithPatch(patch string) *Directory {
+	q := r.query.Select("withPatch")
+	q = q.Arg("patch", patch)
+
+	return &Directory{
+		query: q,
+	}
+}
+
+// Retrieves this directory plus a symlink at the given path.
+func (r *Directory) WithSymlink(target string, linkName string) *Directory {
+	q := r.query.Select("withSymlink")
+	q = q.Arg("target", target)
+	q = q.Arg("linkName", linkName)
+
+	return &Directory{
+		query: q,
+	}
+}
+
 // Retrieves this directory with all file/dir timestamps set to the given time.
 func (r *Directory) WithTimestamps(timestamp int) *Directory {
 	q := r.query.Select("withTimestamps")
@@ -2720,6 +3386,157 @@ func (r *Directory) WithoutFile(path string) *Directory {
 	}
 }
 
+// A path that was added, modified or deleted between two directories.
+type DirectoryChange struct {
+	query *querybuilder.Selection
+
+	id           *DirectoryChangeID
+	isDirectory  *bool
+	kind         *DirectoryChangeKind
+	mode         *int
+	path         *string
+	previousMode *int
+	previousSize *int
+	size         *int
+}
+
+func (r *DirectoryChange) WithGraphQLQuery(q *querybuilder.Selection) *DirectoryChange {
+	return &DirectoryChange{
+		query: q,
+	}
+}
+
+// A unique identifier for this DirectoryChange.
+func (r *DirectoryChange) ID(ctx context.Context) (DirectoryChangeID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response DirectoryChangeID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *DirectoryChange) XXX_GraphQLType() string {
+	return "DirectoryChange"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *DirectoryChange) XXX_GraphQLIDType() string {
+	return "DirectoryChangeID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *DirectoryChange) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *DirectoryChange) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// Whether the path is a directory.
+func (r *DirectoryChange) IsDirectory(ctx context.Context) (bool, error) {
+	if r.isDirectory != nil {
+		return *r.isDirectory, nil
+	}
+	q := r.query.Select("isDirectory")
+
+	var response bool
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// How the path changed.
+func (r *DirectoryChange) Kind(ctx context.Context) (DirectoryChangeKind, error) {
+	if r.kind != nil {
+		return *r.kind, nil
+	}
+	q := r.query.Select("kind")
+
+	var response DirectoryChangeKind
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The permissions of the path, unless it was deleted.
+func (r *DirectoryChange) Mode(ctx context.Context) (int, error) {
+	if r.mode != nil {
+		return *r.mode, nil
+	}
+	q := r.query.Select("mode")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The path that changed, relative to the directory.
+func (r *DirectoryChange) Path(ctx context.Context) (string, error) {
+	if r.path != nil {
+		return *r.path, nil
+	}
+	q := r.query.Select("path")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The previous permissions of the path, unless it was added.
+func (r *DirectoryChange) PreviousMode(ctx context.Context) (int, error) {
+	if r.previousMode != nil {
+		return *r.previousMode, nil
+	}
+	q := r.query.Select("previousMode")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The previous size of the path in bytes, unless it was added.
+func (r *DirectoryChange) PreviousSize(ctx context.Context) (int, error) {
+	if r.previousSize != nil {
+		return *r.previousSize, nil
+	}
+	q := r.query.Select("previousSize")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The size of the path in bytes, unless it was deleted.
+func (r *DirectoryChange) Size(ctx context.Context) (int, error) {
+	if r.size != nil {
+		return *r.size, nil
+	}
+	q := r.query.Select("size")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A definition of a custom enum defined in a Module.
 type EnumTypeDef struct {
 	query *querybuilder.Selection
@@ -3106,6 +3923,7 @@ func (r *FieldTypeDef) TypeDef() *TypeDef {
 type File struct {
 	query *querybuilder.Selection
 
+	asJSON   *JSON
 	contents *string
 	digest   *string
 	export   *string
@@ -3129,6 +3947,31 @@ func (r *File) WithGraphQLQuery(q *querybuilder.Selection) *File {
 	}
 }
 
+// FileAsJSONOpts contains options for File.AsJSON
+type FileAsJSONOpts struct {
+	// Format of the file. Inferred from its extension if not set.
+	Format DataFormat
+}
+
+// Parses the file as structured data and returns it as JSON.
+func (r *File) AsJSON(ctx context.Context, opts ...FileAsJSONOpts) (JSON, error) {
+	if r.asJSON != nil {
+		return *r.asJSON, nil
+	}
+	q := r.query.Select("asJSON")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `format` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Format) {
+			q = q.Arg("format", opts[i].Format)
+		}
+	}
+
+	var response JSON
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Retrieves the contents of the file.
 func (r *File) Contents(ctx context.Context) (string, error) {
 	if r.contents != nil {
@@ -3246,6 +4089,31 @@ func (r *File) Name(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// FileQueryOpts contains options for File.Query
+type FileQueryOpts struct {
+	// Format of the file. Inferred from its extension if not set.
+	Format DataFormat
+}
+
+// Parses the file as structured data and returns the JSON value at the given path.
+//
+// Returns an error if there is no value at the path.
+func (r *File) Query(ctx context.Context, path string, opts ...FileQueryOpts) (JSON, error) {
+	q := r.query.Select("query")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `format` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Format) {
+			q = q.Arg("format", opts[i].Format)
+		}
+	}
+	q = q.Arg("path", path)
+
+	var response JSON
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Retrieves the size of the file, in bytes.
 func (r *File) Size(ctx context.Context) (int, error) {
 	if r.size != nil {
@@ -3282,6 +4150,41 @@ func (r *File) WithName(name string) *File {
 	}
 }
 
+// Retrieves this file with its permissions set to the given value.
+func (r *File) WithPermissions(permissions int) *File {
+	q := r.query.Select("withPermissions")
+	q = q.Arg("permissions", permissions)
+
+	return &File{
+		query: q,
+	}
+}
+
+// FileWithReplacedOpts contains options for File.WithReplaced
+type FileWithReplacedOpts struct {
+	// Replace all occurrences instead of only the first one.
+	All bool
+}
+
+// Retrieves this file with the first or all occurrences of the given text replaced.
+//
+// Fails if the text does not occur in the file.
+func (r *File) WithReplaced(search string, replace string, opts ...FileWithReplacedOpts) *File {
+	q := r.query.Select("withReplaced")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `all` optional argument
+		if !querybuilder.IsZeroValue(opts[i].All) {
+			q = q.Arg("all", opts[i].All)
+		}
+	}
+	q = q.Arg("search", search)
+	q = q.Arg("replace", replace)
+
+	return &File{
+		query: q,
+	}
+}
+
 // Retrieves this file with its created/modified timestamps set to the given time.
 func (r *File) WithTimestamps(timestamp int) *File {
 	q := r.query.Select("withTimestamps")
@@ -3902,6 +4805,210 @@ func (r *GeneratedCode) WithVCSIgnoredPaths(paths []string) *GeneratedCode {
 	}
 }
 
+// The author or committer of a git commit.
+type GitActor struct {
+	query *querybuilder.Selection
+
+	email *string
+	id    *GitActorID
+	name  *string
+}
+
+func (r *GitActor) WithGraphQLQuery(q *querybuilder.Selection) *GitActor {
+	return &GitActor{
+		query: q,
+	}
+}
+
+// The email of the author or committer.
+func (r *GitActor) Email(ctx context.Context) (string, error) {
+	if r.email != nil {
+		return *r.email, nil
+	}
+	q := r.query.Select("email")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// A unique identifier for this GitActor.
+func (r *GitActor) ID(ctx context.Context) (GitActorID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response GitActorID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *GitActor) XXX_GraphQLType() string {
+	return "GitActor"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *GitActor) XXX_GraphQLIDType() string {
+	return "GitActorID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *GitActor) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *GitActor) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The name of the author or committer.
+func (r *GitActor) Name(ctx context.Context) (string, error) {
+	if r.name != nil {
+		return *r.name, nil
+	}
+	q := r.query.Select("name")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The metadata of a git commit.
+type GitCommit struct {
+	query *querybuilder.Selection
+
+	id        *GitCommitID
+	message   *string
+	sha       *string
+	timestamp *int
+}
+
+func (r *GitCommit) WithGraphQLQuery(q *querybuilder.Selection) *GitCommit {
+	return &GitCommit{
+		query: q,
+	}
+}
+
+// The author of the commit.
+func (r *GitCommit) Author() *GitActor {
+	q := r.query.Select("author")
+
+	return &GitActor{
+		query: q,
+	}
+}
+
+// The committer of the commit.
+func (r *GitCommit) Committer() *GitActor {
+	q := r.query.Select("committer")
+
+	return &GitActor{
+		query: q,
+	}
+}
+
+// A unique identifier for this GitCommit.
+func (r *GitCommit) ID(ctx context.Context) (GitCommitID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response GitCommitID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *GitCommit) XXX_GraphQLType() string {
+	return "GitCommit"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *GitCommit) XXX_GraphQLIDType() string {
+	return "GitCommitID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *GitCommit) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *GitCommit) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The full commit message.
+func (r *GitCommit) Message(ctx context.Context) (string, error) {
+	if r.message != nil {
+		return *r.message, nil
+	}
+	q := r.query.Select("message")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The SHAs of the parent commits.
+func (r *GitCommit) Parents(ctx context.Context) ([]string, error) {
+	q := r.query.Select("parents")
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The SHA of the commit.
+func (r *GitCommit) Sha(ctx context.Context) (string, error) {
+	if r.sha != nil {
+		return *r.sha, nil
+	}
+	q := r.query.Select("sha")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The time the commit was made, in Unix seconds.
+func (r *GitCommit) Timestamp(ctx context.Context) (int, error) {
+	if r.timestamp != nil {
+		return *r.timestamp, nil
+	}
+	q := r.query.Select("timestamp")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Module source originating from a git repo.
 type GitModuleSource struct {
 	query *querybuilder.Selection
@@ -4054,6 +5161,7 @@ type GitRef struct {
 
 	commit *string
 	id     *GitRefID
+	push   *Void
 }
 
 func (r *GitRef) WithGraphQLQuery(q *querybuilder.Selection) *GitRef {
@@ -4075,6 +5183,15 @@ func (r *GitRef) Commit(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// The metadata of the resolved commit at this ref.
+func (r *GitRef) CommitInfo() *GitCommit {
+	q := r.query.Select("commitInfo")
+
+	return &GitCommit{
+		query: q,
+	}
+}
+
 // A unique identifier for this GitRef.
 func (r *GitRef) ID(ctx context.Context) (GitRefID, error) {
 	if r.id != nil {
@@ -4115,9 +5232,71 @@ func (r *GitRef) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// GitRefPushOpts contains options for GitRef.Push
+type GitRefPushOpts struct {
+	// Update the ref even if the commit does not descend from its current commit.
+	Force bool
+	// Set SSH known hosts
+	SSHKnownHosts string
+	// Set SSH auth socket
+	SSHAuthSocket *Socket
+	// Secret used to populate the password during basic HTTP Authorization
+	AuthToken *Secret
+	// Secret used to populate the Authorization HTTP header
+	AuthHeader *Secret
+}
+
+// Pushes the commit at this ref to a remote repository.
+func (r *GitRef) Push(ctx context.Context, remote string, ref string, opts ...GitRefPushOpts) error {
+	if r.push != nil {
+		return nil
+	}
+	q := r.query.Select("push")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `force` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Force) {
+			q = q.Arg("force", opts[i].Force)
+		}
+		// `sshKnownHosts` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SSHKnownHosts) {
+			q = q.Arg("sshKnownHosts", opts[i].SSHKnownHosts)
+		}
+		// `sshAuthSocket` optional argument
+		if !querybuilder.IsZeroValue(opts[i].SSHAuthSocket) {
+			q = q.Arg("sshAuthSocket", opts[i].SSHAuthSocket)
+		}
+		// `authToken` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AuthToken) {
+			q = q.Arg("authToken", opts[i].AuthToken)
+		}
+		// `authHeader` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AuthHeader) {
+			q = q.Arg("authHeader", opts[i].AuthHeader)
+		}
+	}
+	q = q.Arg("remote", remote)
+	q = q.Arg("ref", ref)
+
+	return q.Execute(ctx)
+}
+
+// GitRefTreeOpts contains options for GitRef.Tree
+type GitRefTreeOpts struct {
+	// Only check out these paths (e.g., ["docs", "go.mod"]).
+	//
+	// Paths are relative to the root of the repository.
+	Sparse []string
+}
+
 // The filesystem tree at this ref.
-func (r *GitRef) Tree() *Directory {
+func (r *GitRef) Tree(opts ...GitRefTreeOpts) *Directory {
 	q := r.query.Select("tree")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `sparse` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Sparse) {
+			q = q.Arg("sparse", opts[i].Sparse)
+		}
+	}
 
 	return &Directory{
 		query: q,
@@ -4155,6 +5334,28 @@ func (r *GitRepository) Branch(name string) *GitRef {
 	}
 }
 
+// GitRepositoryBranchesOpts contains options for GitRepository.Branches
+type GitRepositoryBranchesOpts struct {
+	// Glob patterns (e.g., "refs/heads/release/*").
+	Patterns []string
+}
+
+// branches that match any of the given glob patterns.
+func (r *GitRepository) Branches(ctx context.Context, opts ...GitRepositoryBranchesOpts) ([]string, error) {
+	q := r.query.Select("branches")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `patterns` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Patterns) {
+			q = q.Arg("patterns", opts[i].Patterns)
+		}
+	}
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Returns details of a commit.
 func (r *GitRepository) Commit(id string) *GitRef {
 	q := r.query.Select("commit")
@@ -4224,6 +5425,28 @@ func (r *GitRepository) Ref(name string) *GitRef {
 	}
 }
 
+// GitRepositoryRefsOpts contains options for GitRepository.Refs
+type GitRepositoryRefsOpts struct {
+	// Glob patterns (e.g., "refs/pull/*").
+	Patterns []string
+}
+
+// Fully-qualified refs that match any of the given glob patterns.
+func (r *GitRepository) Refs(ctx context.Context, opts ...GitRepositoryRefsOpts) ([]string, error) {
+	q := r.query.Select("refs")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `patterns` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Patterns) {
+			q = q.Arg("patterns", opts[i].Patterns)
+		}
+	}
+
+	var response []string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Returns details of a tag.
 func (r *GitRepository) Tag(name string) *GitRef {
 	q := r.query.Select("tag")
@@ -4391,11 +5614,25 @@ func (r *Host) Service(ports []PortForward, opts ...HostServiceOpts) *Service {
 	}
 }
 
+// HostSetSecretFileOpts contains options for Host.SetSecretFile
+type HostSetSecretFileOpts struct {
+	// How long the file's contents are valid for (e.g., "15m").
+	//
+	// Once expired, the file is read again from the host.
+	TTL string
+}
+
 // Sets a secret given a user-defined name and the file path on the host, and returns the secret.
 //
 // The file is limited to a size of 512000 bytes.
-func (r *Host) SetSecretFile(name string, path string) *Secret {
+func (r *Host) SetSecretFile(name string, path string, opts ...HostSetSecretFileOpts) *Secret {
 	q := r.query.Select("setSecretFile")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `ttl` optional argument
+		if !querybuilder.IsZeroValue(opts[i].TTL) {
+			q = q.Arg("ttl", opts[i].TTL)
+		}
+	}
 	q = q.Arg("name", name)
 	q = q.Arg("path", path)
 
@@ -4907,6 +6144,7 @@ type Module struct {
 
 	description *string
 	id          *ModuleID
+	lock        *string
 	name        *string
 	sdk         *string
 	serve       *Void
@@ -5138,6 +6376,19 @@ func (r *Module) Interfaces(ctx context.Context) ([]TypeDef, error) {
 	return convert(response), nil
 }
 
+// The contents of the module's dagger.lock file, pinning what its functions resolved so far in this session, or an empty string if nothing is pinned.
+func (r *Module) Lock(ctx context.Context) (string, error) {
+	if r.lock != nil {
+		return *r.lock, nil
+	}
+	q := r.query.Select("lock")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // The name of the module
 func (r *Module) Name(ctx context.Context) (string, error) {
 	if r.name != nil {
@@ -6175,9 +7426,23 @@ func (r *Client) BuiltinContainer(digest string) *Container {
 	}
 }
 
+// CacheVolumeOpts contains options for Client.CacheVolume
+type CacheVolumeOpts struct {
+	// Who the cache volume is shared with. Defaults to MODULE_SCOPE within a
+	//
+	// module, and GLOBAL_SCOPE otherwise.
+	Scope CacheVolumeScope
+}
+
 // Constructs a cache volume for a given cache key.
-func (r *Client) CacheVolume(key string) *CacheVolume {
+func (r *Client) CacheVolume(key string, opts ...CacheVolumeOpts) *CacheVolume {
 	q := r.query.Select("cacheVolume")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `scope` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Scope) {
+			q = q.Arg("scope", opts[i].Scope)
+		}
+	}
 	q = q.Arg("key", key)
 
 	return &CacheVolume{
@@ -6316,6 +7581,12 @@ func (r *Client) GeneratedCode(code *Directory) *GeneratedCode {
 type GitOpts struct {
 	// Set to true to keep .git directory.
 	KeepGitDir bool
+	// Depth of history to fetch.
+	//
+	// Set to 0 to fetch the full history.
+	Depth int
+	// Set to false to skip checking out submodules.
+	WithSubmodules bool
 	// A service which must be started before the repo is fetched.
 	ExperimentalServiceHost *Service
 	// Set SSH known hosts
@@ -6332,6 +7603,14 @@ func (r *Client) Git(url string, opts ...GitOpts) *GitRepository {
 		if !querybuilder.IsZeroValue(opts[i].KeepGitDir) {
 			q = q.Arg("keepGitDir", opts[i].KeepGitDir)
 		}
+		// `depth` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Depth) {
+			q = q.Arg("depth", opts[i].Depth)
+		}
+		// `withSubmodules` optional argument
+		if !querybuilder.IsZeroValue(opts[i].WithSubmodules) {
+			q = q.Arg("withSubmodules", opts[i].WithSubmodules)
+		}
 		// `experimentalServiceHost` optional argument
 		if !querybuilder.IsZeroValue(opts[i].ExperimentalServiceHost) {
 			q = q.Arg("experimentalServiceHost", opts[i].ExperimentalServiceHost)
@@ -6363,6 +7642,20 @@ func (r *Client) Host() *Host {
 
 // HTTPOpts contains options for Client.HTTP
 type HTTPOpts struct {
+	// Expected digest of the content (e.g., "sha256:...").
+	//
+	// The download fails if the content doesn't match, and is skipped if
+	//
+	// content with the digest is already cached.
+	Checksum string
+	// Secret used to populate the Authorization HTTP header
+	AuthHeader *Secret
+	// Additional HTTP headers to send with the request.
+	Headers []HTTPHeader
+	// File name of the downloaded file.
+	Name string
+	// Permission given to the downloaded file (e.g., 0600).
+	Permissions int
 	// A service which must be started before the URL is fetched.
 	ExperimentalServiceHost *Service
 }
@@ -6371,6 +7664,26 @@ type HTTPOpts struct {
 func (r *Client) HTTP(url string, opts ...HTTPOpts) *File {
 	q := r.query.Select("http")
 	for i := len(opts) - 1; i >= 0; i-- {
+		// `checksum` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Checksum) {
+			q = q.Arg("checksum", opts[i].Checksum)
+		}
+		// `authHeader` optional argument
+		if !querybuilder.IsZeroValue(opts[i].AuthHeader) {
+			q = q.Arg("authHeader", opts[i].AuthHeader)
+		}
+		// `headers` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Headers) {
+			q = q.Arg("headers", opts[i].Headers)
+		}
+		// `name` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Name) {
+			q = q.Arg("name", opts[i].Name)
+		}
+		// `permissions` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Permissions) {
+			q = q.Arg("permissions", opts[i].Permissions)
+		}
 		// `experimentalServiceHost` optional argument
 		if !querybuilder.IsZeroValue(opts[i].ExperimentalServiceHost) {
 			q = q.Arg("experimentalServiceHost", opts[i].ExperimentalServiceHost)
@@ -6453,6 +7766,16 @@ func (r *Client) LoadDaggerEngineFromID(id DaggerEngineID) *DaggerEngine {
 	}
 }
 
+// Load a DirectoryChange from its ID.
+func (r *Client) LoadDirectoryChangeFromID(id DirectoryChangeID) *DirectoryChange {
+	q := r.query.Select("loadDirectoryChangeFromID")
+	q = q.Arg("id", id)
+
+	return &DirectoryChange{
+		query: q,
+	}
+}
+
 // Load a Directory from its ID.
 func (r *Client) LoadDirectoryFromID(id DirectoryID) *Directory {
 	q := r.query.Select("loadDirectoryFromID")
@@ -6563,6 +7886,26 @@ func (r *Client) LoadGeneratedCodeFromID(id GeneratedCodeID) *GeneratedCode {
 	}
 }
 
+// Load a GitActor from its ID.
+func (r *Client) LoadGitActorFromID(id GitActorID) *GitActor {
+	q := r.query.Select("loadGitActorFromID")
+	q = q.Arg("id", id)
+
+	return &GitActor{
+		query: q,
+	}
+}
+
+// Load a GitCommit from its ID.
+func (r *Client) LoadGitCommitFromID(id GitCommitID) *GitCommit {
+	q := r.query.Select("loadGitCommitFromID")
+	q = q.Arg("id", id)
+
+	return &GitCommit{
+		query: q,
+	}
+}
+
 // Load a GitModuleSource from its ID.
 func (r *Client) LoadGitModuleSourceFromID(id GitModuleSourceID) *GitModuleSource {
 	q := r.query.Select("loadGitModuleSourceFromID")
@@ -6723,6 +8066,16 @@ func (r *Client) LoadScalarTypeDefFromID(id ScalarTypeDefID) *ScalarTypeDef {
 	}
 }
 
+// Load a SearchMatch from its ID.
+func (r *Client) LoadSearchMatchFromID(id SearchMatchID) *SearchMatch {
+	q := r.query.Select("loadSearchMatchFromID")
+	q = q.Arg("id", id)
+
+	return &SearchMatch{
+		query: q,
+	}
+}
+
 // Load a Secret from its ID.
 func (r *Client) LoadSecretFromID(id SecretID) *Secret {
 	q := r.query.Select("loadSecretFromID")
@@ -6879,11 +8232,31 @@ func (r *Client) Secret(name string, opts ...SecretOpts) *Secret {
 	}
 }
 
+// SetSecretOpts contains options for Client.SetSecret
+type SetSecretOpts struct {
+	// How long the plaintext is valid for (e.g., "15m").
+	//
+	// Once expired, the secret's value is fetched again from the client that set it, by asking its session for the secret's source, or for the secret with this name if it has none. Clients connected through the dagger CLI support this; Dagger Functions get an error.
+	TTL string
+	// Where the client resolves the secret's value from once its ttl expires (e.g., "env:GITHUB_TOKEN", "file:./token.txt" or "cmd:gh auth token").
+	Source string
+}
+
 // Sets a secret given a user defined name to its plaintext and returns the secret.
 //
 // The plaintext value is limited to a size of 128000 bytes.
-func (r *Client) SetSecret(name string, plaintext string) *Secret {
+func (r *Client) SetSecret(name string, plaintext string, opts ...SetSecretOpts) *Secret {
 	q := r.query.Select("setSecret")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `ttl` optional argument
+		if !querybuilder.IsZeroValue(opts[i].TTL) {
+			q = q.Arg("ttl", opts[i].TTL)
+		}
+		// `source` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Source) {
+			q = q.Arg("source", opts[i].Source)
+		}
+	}
 	q = q.Arg("name", name)
 	q = q.Arg("plaintext", plaintext)
 
@@ -7006,6 +8379,101 @@ func (r *ScalarTypeDef) SourceModuleName(ctx context.Context) (string, error) {
 	return response, q.Execute(ctx)
 }
 
+// A line matching a search in a directory.
+type SearchMatch struct {
+	query *querybuilder.Selection
+
+	id         *SearchMatchID
+	line       *string
+	lineNumber *int
+	path       *string
+}
+
+func (r *SearchMatch) WithGraphQLQuery(q *querybuilder.Selection) *SearchMatch {
+	return &SearchMatch{
+		query: q,
+	}
+}
+
+// A unique identifier for this SearchMatch.
+func (r *SearchMatch) ID(ctx context.Context) (SearchMatchID, error) {
+	if r.id != nil {
+		return *r.id, nil
+	}
+	q := r.query.Select("id")
+
+	var response SearchMatchID
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// XXX_GraphQLType is an internal function. It returns the native GraphQL type name
+func (r *SearchMatch) XXX_GraphQLType() string {
+	return "SearchMatch"
+}
+
+// XXX_GraphQLIDType is an internal function. It returns the native GraphQL type name for the ID of this object
+func (r *SearchMatch) XXX_GraphQLIDType() string {
+	return "SearchMatchID"
+}
+
+// XXX_GraphQLID is an internal function. It returns the underlying type ID
+func (r *SearchMatch) XXX_GraphQLID(ctx context.Context) (string, error) {
+	id, err := r.ID(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(id), nil
+}
+
+func (r *SearchMatch) MarshalJSON() ([]byte, error) {
+	id, err := r.ID(marshalCtx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(id)
+}
+
+// The text of the matching line, without its line ending.
+func (r *SearchMatch) Line(ctx context.Context) (string, error) {
+	if r.line != nil {
+		return *r.line, nil
+	}
+	q := r.query.Select("line")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The number of the matching line, starting at 1.
+func (r *SearchMatch) LineNumber(ctx context.Context) (int, error) {
+	if r.lineNumber != nil {
+		return *r.lineNumber, nil
+	}
+	q := r.query.Select("lineNumber")
+
+	var response int
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
+// The path of the matching file, relative to the searched directory.
+func (r *SearchMatch) Path(ctx context.Context) (string, error) {
+	if r.path != nil {
+		return *r.path, nil
+	}
+	q := r.query.Select("path")
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // A reference to a secret value, which can be handled more safely than the value itself.
 type Secret struct {
 	query *querybuilder.Selection
@@ -7092,12 +8560,23 @@ type Service struct {
 	query *querybuilder.Selection
 
 	endpoint *string
+	exec     *string
 	hostname *string
 	id       *ServiceID
+	logs     *string
 	start    *ServiceID
+	status   *ServiceStatus
 	stop     *ServiceID
 	up       *Void
 }
+type WithServiceFunc func(r *Service) *Service
+
+// With calls the provided function with current Service.
+//
+// This is useful for reusability and readability by not breaking the calling chain.
+func (r *Service) With(f WithServiceFunc) *Service {
+	return f(r)
+}
 
 func (r *Service) WithGraphQLQuery(q *querybuilder.Selection) *Service {
 	return &Service{
@@ -7140,6 +8619,22 @@ func (r *Service) Endpoint(ctx context.Context, opts ...ServiceEndpointOpts) (st
 	return response, q.Execute(ctx)
 }
 
+// Runs a command inside the running service and returns its stdout.
+//
+// The command runs with the same environment, user and working directory as the service. The service must already be running.
+func (r *Service) Exec(ctx context.Context, args []string) (string, error) {
+	if r.exec != nil {
+		return *r.exec, nil
+	}
+	q := r.query.Select("exec")
+	q = q.Arg("args", args)
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Retrieves a hostname which can be used by clients to reach this container.
 func (r *Service) Hostname(ctx context.Context) (string, error) {
 	if r.hostname != nil {
@@ -7193,6 +8688,39 @@ func (r *Service) MarshalJSON() ([]byte, error) {
 	return json.Marshal(id)
 }
 
+// ServiceLogsOpts contains options for Service.Logs
+type ServiceLogsOpts struct {
+	// Only return output written after this time, as either a duration relative to now (e.g., "5m") or an RFC 3339 timestamp.
+	Since string
+	// Stream output to the caller until the service exits, then return all of it.
+	Follow bool
+}
+
+// Retrieves the output of the running service.
+//
+// The service must already be running. Only the most recent output is retained.
+func (r *Service) Logs(ctx context.Context, opts ...ServiceLogsOpts) (string, error) {
+	if r.logs != nil {
+		return *r.logs, nil
+	}
+	q := r.query.Select("logs")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `since` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Since) {
+			q = q.Arg("since", opts[i].Since)
+		}
+		// `follow` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Follow) {
+			q = q.Arg("follow", opts[i].Follow)
+		}
+	}
+
+	var response string
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // Retrieves the list of ports provided by the service.
 func (r *Service) Ports(ctx context.Context) ([]Port, error) {
 	q := r.query.Select("ports")
@@ -7241,6 +8769,19 @@ func (r *Service) Start(ctx context.Context) (*Service, error) {
 	}, nil
 }
 
+// The current state of the service.
+func (r *Service) Status(ctx context.Context) (ServiceStatus, error) {
+	if r.status != nil {
+		return *r.status, nil
+	}
+	q := r.query.Select("status")
+
+	var response ServiceStatus
+
+	q = q.Bind(&response)
+	return response, q.Execute(ctx)
+}
+
 // ServiceStopOpts contains options for Service.Stop
 type ServiceStopOpts struct {
 	// Immediately kill the service without waiting for a graceful exit
@@ -7296,6 +8837,90 @@ func (r *Service) Up(ctx context.Context, opts ...ServiceUpOpts) error {
 	return q.Execute(ctx)
 }
 
+// ServiceWithExecHealthcheckOpts contains options for Service.WithExecHealthcheck
+type ServiceWithExecHealthcheckOpts struct {
+	// How long to wait between attempts (e.g., "1s").
+	Interval string
+	// How long a single attempt may take before it fails (e.g., "10s").
+	Timeout string
+	// How many times to retry a failed attempt before giving up.
+	Retries int
+}
+
+// Adds a command readiness probe to the service.
+//
+// The service is only considered started once the command, run inside the service container, exits successfully, after all of its exposed ports are reachable.
+func (r *Service) WithExecHealthcheck(args []string, opts ...ServiceWithExecHealthcheckOpts) *Service {
+	q := r.query.Select("withExecHealthcheck")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `interval` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Interval) {
+			q = q.Arg("interval", opts[i].Interval)
+		}
+		// `timeout` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Timeout) {
+			q = q.Arg("timeout", opts[i].Timeout)
+		}
+		// `retries` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Retries) {
+			q = q.Arg("retries", opts[i].Retries)
+		}
+	}
+	q = q.Arg("args", args)
+
+	return &Service{
+		query: q,
+	}
+}
+
+// ServiceWithHTTPHealthcheckOpts contains options for Service.WithHTTPHealthcheck
+type ServiceWithHTTPHealthcheckOpts struct {
+	// The path to request.
+	Path string
+	// The HTTP status code that indicates the service is ready.
+	ExpectedStatus int
+	// How long to wait between attempts (e.g., "1s").
+	Interval string
+	// How long a single attempt may take before it fails (e.g., "10s").
+	Timeout string
+	// How many times to retry a failed attempt before giving up.
+	Retries int
+}
+
+// Adds an HTTP readiness probe to the service.
+//
+// The service is only considered started once a GET request to the given port and path responds with the expected status, after all of its exposed ports are reachable.
+func (r *Service) WithHTTPHealthcheck(port int, opts ...ServiceWithHTTPHealthcheckOpts) *Service {
+	q := r.query.Select("withHTTPHealthcheck")
+	for i := len(opts) - 1; i >= 0; i-- {
+		// `path` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Path) {
+			q = q.Arg("path", opts[i].Path)
+		}
+		// `expectedStatus` optional argument
+		if !querybuilder.IsZeroValue(opts[i].ExpectedStatus) {
+			q = q.Arg("expectedStatus", opts[i].ExpectedStatus)
+		}
+		// `interval` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Interval) {
+			q = q.Arg("interval", opts[i].Interval)
+		}
+		// `timeout` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Timeout) {
+			q = q.Arg("timeout", opts[i].Timeout)
+		}
+		// `retries` optional argument
+		if !querybuilder.IsZeroValue(opts[i].Retries) {
+			q = q.Arg("retries", opts[i].Retries)
+		}
+	}
+	q = q.Arg("port", port)
+
+	return &Service{
+		query: q,
+	}
+}
+
 // A Unix or TCP/IP socket that can be mounted into a container.
 type Socket struct {
 	query *querybuilder.Selection
@@ -7764,6 +9389,96 @@ const (
 	Shared CacheSharingMode = "SHARED"
 )
 
+type CacheVolumeScope string
+
+func (CacheVolumeScope) IsEnum() {}
+
+const (
+	// Shares the cache volume with the caller of the current function, as if the caller had created it with its default scope
+	CallerModuleScope CacheVolumeScope = "CALLER_MODULE_SCOPE"
+
+	// Keeps a cache volume for the client that started the session, e.g. the dagger CLI of a particular user
+	ClientScope CacheVolumeScope = "CLIENT_SCOPE"
+
+	// Shares the cache volume with every client and module using the same key
+	GlobalScope CacheVolumeScope = "GLOBAL_SCOPE"
+
+	// Keeps a cache volume for the current module, regardless of who calls it
+	ModuleScope CacheVolumeScope = "MODULE_SCOPE"
+)
+
+type DaggerEngineCacheEntrySortBy string
+
+func (DaggerEngineCacheEntrySortBy) IsEnum() {}
+
+const (
+	// Oldest entries first
+	Created DaggerEngineCacheEntrySortBy = "CREATED"
+
+	// Least recently used entries first
+	LastUsed DaggerEngineCacheEntrySortBy = "LAST_USED"
+
+	// Largest entries first
+	Size DaggerEngineCacheEntrySortBy = "SIZE"
+)
+
+type DaggerEngineCacheEntryType string
+
+func (DaggerEngineCacheEntryType) IsEnum() {}
+
+const (
+	// The contents of a cache volume mounted into an exec
+	CacheMount DaggerEngineCacheEntryType = "CACHE_MOUNT"
+
+	// A filesystem snapshot produced by an exec
+	Exec DaggerEngineCacheEntryType = "EXEC"
+
+	// A checkout of a git repository
+	GitCheckout DaggerEngineCacheEntryType = "GIT_CHECKOUT"
+
+	// A layer of a pulled container image
+	ImageLayer DaggerEngineCacheEntryType = "IMAGE_LAYER"
+
+	// Internal state of the engine
+	Internal DaggerEngineCacheEntryType = "INTERNAL"
+
+	// Files loaded from a client's host
+	LocalImport DaggerEngineCacheEntryType = "LOCAL_IMPORT"
+
+	// Any other data, such as files created through the API
+	Other DaggerEngineCacheEntryType = "OTHER"
+)
+
+type DataFormat string
+
+func (DataFormat) IsEnum() {}
+
+const (
+	// JSON, e.g. package.json
+	JsonFormat DataFormat = "JSON_FORMAT"
+
+	// TOML, e.g. Cargo.toml
+	TomlFormat DataFormat = "TOML_FORMAT"
+
+	// YAML, e.g. Chart.yaml. Only the first document of a stream is read.
+	YamlFormat DataFormat = "YAML_FORMAT"
+)
+
+type DirectoryChangeKind string
+
+func (DirectoryChangeKind) IsEnum() {}
+
+const (
+	// The path only exists in the other directory
+	Added DirectoryChangeKind = "ADDED"
+
+	// The path only exists in this directory
+	Deleted DirectoryChangeKind = "DELETED"
+
+	// The path exists in both directories with a different content, type or permissions
+	Modified DirectoryChangeKind = "MODIFIED"
+)
+
 type ImageLayerCompression string
 
 func (ImageLayerCompression) IsEnum() {}
@@ -7808,6 +9523,57 @@ const (
 	Udp NetworkProtocol = "UDP"
 )
 
+type ReturnType string
+
+func (ReturnType) IsEnum() {}
+
+const (
+	// Any execution (exit codes 0-127)
+	Any ReturnType = "ANY"
+
+	// A failed execution (exit codes 1-127)
+	Failure ReturnType = "FAILURE"
+
+	// A successful execution (exit code 0)
+	Success ReturnType = "SUCCESS"
+)
+
+type ServiceRestartPolicy string
+
+func (ServiceRestartPolicy) IsEnum() {}
+
+const (
+	// Restart the service whenever it exits without being stopped
+	Always ServiceRestartPolicy = "ALWAYS"
+
+	// Never restart the service
+	Never ServiceRestartPolicy = "NEVER"
+
+	// Restart the service when it exits with a non-zero exit code
+	OnFailure ServiceRestartPolicy = "ON_FAILURE"
+)
+
+type ServiceStatus string
+
+func (ServiceStatus) IsEnum() {}
+
+const (
+	// The service exited without being stopped and was not restarted
+	Crashed ServiceStatus = "CRASHED"
+
+	// The service exited and is being restarted according to its restart policy
+	Restarting ServiceStatus = "RESTARTING"
+
+	// The service is running
+	Running ServiceStatus = "RUNNING"
+
+	// The service is starting and waiting for its health checks to pass
+	Starting ServiceStatus = "STARTING"
+
+	// The service is not running
+	Stopped ServiceStatus = "STOPPED"
+)
+
 type TypeDefKind string
 
 func (TypeDefKind) IsEnum() {}
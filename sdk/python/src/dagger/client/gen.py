@@ -46,6 +46,11 @@ class DaggerEngineID(Scalar):
     object of type DaggerEngine."""
 
 
+class DirectoryChangeID(Scalar):
+    """The `DirectoryChangeID` scalar type represents an identifier for an
+    object of type DirectoryChange."""
+
+
 class DirectoryID(Scalar):
     """The `DirectoryID` scalar type represents an identifier for an
     object of type Directory."""
@@ -101,6 +106,16 @@ class GeneratedCodeID(Scalar):
     object of type GeneratedCode."""
 
 
+class GitActorID(Scalar):
+    """The `GitActorID` scalar type represents an identifier for an object
+    of type GitActor."""
+
+
+class GitCommitID(Scalar):
+    """The `GitCommitID` scalar type represents an identifier for an
+    object of type GitCommit."""
+
+
 class GitModuleSourceID(Scalar):
     """The `GitModuleSourceID` scalar type represents an identifier for an
     object of type GitModuleSource."""
@@ -191,6 +206,11 @@ class ScalarTypeDefID(Scalar):
     object of type ScalarTypeDef."""
 
 
+class SearchMatchID(Scalar):
+    """The `SearchMatchID` scalar type represents an identifier for an
+    object of type SearchMatch."""
+
+
 class SecretID(Scalar):
     """The `SecretID` scalar type represents an identifier for an object
     of type Secret."""
@@ -234,6 +254,86 @@ class CacheSharingMode(Enum):
     """Shares the cache volume amongst many build pipelines"""
 
 
+class CacheVolumeScope(Enum):
+    """Scope of a cache volume, which determines who it is shared with."""
+
+    CALLER_MODULE_SCOPE = "CALLER_MODULE_SCOPE"
+    """Shares the cache volume with the caller of the current function, as if the caller had created it with its default scope"""
+
+    CLIENT_SCOPE = "CLIENT_SCOPE"
+    """Keeps a cache volume for the client that started the session, e.g. the dagger CLI of a particular user"""
+
+    GLOBAL_SCOPE = "GLOBAL_SCOPE"
+    """Shares the cache volume with every client and module using the same key"""
+
+    MODULE_SCOPE = "MODULE_SCOPE"
+    """Keeps a cache volume for the current module, regardless of who calls it"""
+
+
+class DaggerEngineCacheEntrySortBy(Enum):
+    """The order to list cache entries in."""
+
+    CREATED = "CREATED"
+    """Oldest entries first"""
+
+    LAST_USED = "LAST_USED"
+    """Least recently used entries first"""
+
+    SIZE = "SIZE"
+    """Largest entries first"""
+
+
+class DaggerEngineCacheEntryType(Enum):
+    """The kind of data held by a cache entry."""
+
+    CACHE_MOUNT = "CACHE_MOUNT"
+    """The contents of a cache volume mounted into an exec"""
+
+    EXEC = "EXEC"
+    """A filesystem snapshot produced by an exec"""
+
+    GIT_CHECKOUT = "GIT_CHECKOUT"
+    """A checkout of a git repository"""
+
+    IMAGE_LAYER = "IMAGE_LAYER"
+    """A layer of a pulled container image"""
+
+    INTERNAL = "INTERNAL"
+    """Internal state of the engine"""
+
+    LOCAL_IMPORT = "LOCAL_IMPORT"
+    """Files loaded from a client's host"""
+
+    OTHER = "OTHER"
+    """Any other data, such as files created through the API"""
+
+
+class DataFormat(Enum):
+    """Format of a file containing structured data."""
+
+    JSON_FORMAT = "JSON_FORMAT"
+    """JSON, e.g. package.json"""
+
+    TOML_FORMAT = "TOML_FORMAT"
+    """TOML, e.g. Cargo.toml"""
+
+    YAML_FORMAT = "YAML_FORMAT"
+    """YAML, e.g. Chart.yaml. Only the first document of a stream is read."""
+
+
+class DirectoryChangeKind(Enum):
+    """The kind of a change between two directories."""
+
+    ADDED = "ADDED"
+    """The path only exists in the other directory"""
+
+    DELETED = "DELETED"
+    """The path only exists in this directory"""
+
+    MODIFIED = "MODIFIED"
+    """The path exists in both directories with a different content, type or permissions"""
+
+
 class ImageLayerCompression(Enum):
     """Compression algorithm to use for image layers."""
 
@@ -270,6 +370,51 @@ class NetworkProtocol(Enum):
     UDP = "UDP"
 
 
+class ReturnType(Enum):
+    """Expected return type of an execution"""
+
+    ANY = "ANY"
+    """Any execution (exit codes 0-127)"""
+
+    FAILURE = "FAILURE"
+    """A failed execution (exit codes 1-127)"""
+
+    SUCCESS = "SUCCESS"
+    """A successful execution (exit code 0)"""
+
+
+class ServiceRestartPolicy(Enum):
+    """When to restart a service that exits on its own."""
+
+    ALWAYS = "ALWAYS"
+    """Restart the service whenever it exits without being stopped"""
+
+    NEVER = "NEVER"
+    """Never restart the service"""
+
+    ON_FAILURE = "ON_FAILURE"
+    """Restart the service when it exits with a non-zero exit code"""
+
+
+class ServiceStatus(Enum):
+    """The lifecycle state of a service."""
+
+    CRASHED = "CRASHED"
+    """The service exited without being stopped and was not restarted"""
+
+    RESTARTING = "RESTARTING"
+    """The service exited and is being restarted according to its restart policy"""
+
+    RUNNING = "RUNNING"
+    """The service is running"""
+
+    STARTING = "STARTING"
+    """The service is starting and waiting for its health checks to pass"""
+
+    STOPPED = "STOPPED"
+    """The service is not running"""
+
+
 class TypeDefKind(Enum):
     """Distinguishes the different kinds of TypeDefs."""
 
@@ -331,6 +476,30 @@ class BuildArg(Input):
     """The build argument value."""
 
 
+@typecheck
+@dataclass(slots=True)
+class HTTPHeader(Input):
+    """Key value object that represents an HTTP header."""
+
+    name: str
+    """The header name."""
+
+    value: str
+    """The header value."""
+
+
+@typecheck
+@dataclass(slots=True)
+class ImageAnnotation(Input):
+    """Key value object that represents an OCI image annotation."""
+
+    name: str
+    """The annotation name, e.g. org.opencontainers.image.source."""
+
+    value: str
+    """The annotation value."""
+
+
 @typecheck
 @dataclass(slots=True)
 class PipelineLabel(Input):
@@ -362,6 +531,28 @@ class PortForward(Input):
 class CacheVolume(Type):
     """A directory whose contents persist across runs."""
 
+    async def clear(self) -> Void | None:
+        """Removes the contents of the cache volume.
+
+        Containers currently using the cache volume will see it emptied.
+
+        Returns
+        -------
+        Void | None
+            The absence of a value.  A Null Void is used as a placeholder for
+            resolvers that do not return anything.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("clear", _args)
+        await _ctx.execute()
+
     async def id(self) -> CacheVolumeID:
         """A unique identifier for this CacheVolume.
 
@@ -386,17 +577,136 @@ class CacheVolume(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(CacheVolumeID)
 
+    async def namespace(self) -> str:
+        """The namespace of the cache volume, derived from its scope. Empty for
+        global cache volumes.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("namespace", _args)
+        return await _ctx.execute(str)
+
+    async def scope(self) -> CacheVolumeScope:
+        """The scope the cache volume was created with.
+
+        Returns
+        -------
+        CacheVolumeScope
+            Scope of a cache volume, which determines who it is shared with.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("scope", _args)
+        return await _ctx.execute(CacheVolumeScope)
+
+    async def seed(self, source: "Directory") -> Void | None:
+        """Replaces the contents of the cache volume with the contents of the
+        given directory.
+
+        Containers currently using the cache volume will see the new contents.
+
+        Parameters
+        ----------
+        source:
+            Directory to copy into the cache volume.
+
+        Returns
+        -------
+        Void | None
+            The absence of a value.  A Null Void is used as a placeholder for
+            resolvers that do not return anything.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("source", source),
+        ]
+        _ctx = self._select("seed", _args)
+        await _ctx.execute()
+
+    async def size(self) -> int:
+        """The disk space used by the cache volume, in bytes.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("size", _args)
+        return await _ctx.execute(int)
+
+    def snapshot(self) -> "Directory":
+        """Returns a copy of the current contents of the cache volume.
+
+        Changes to the cache volume after the snapshot is taken are not
+        reflected in the returned directory.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("snapshot", _args)
+        return Directory(_ctx)
+
 
 @typecheck
 class Container(Type):
     """An OCI-compatible container, also known as a Docker container."""
 
-    def as_service(self) -> "Service":
+    def as_service(
+        self,
+        *,
+        restart_policy: ServiceRestartPolicy | None = ServiceRestartPolicy.NEVER,
+        max_retries: int | None = 0,
+    ) -> "Service":
         """Turn the container into a Service.
 
         Be sure to set any exposed ports before this conversion.
+
+        Parameters
+        ----------
+        restart_policy:
+            When to restart the service if it exits without being stopped.
+        max_retries:
+            The maximum number of times to restart the service with the
+            ON_FAILURE restart policy.
+            Zero means no limit.
         """
-        _args: list[Arg] = []
+        _args = [
+            Arg("restartPolicy", restart_policy, ServiceRestartPolicy.NEVER),
+            Arg("maxRetries", max_retries, 0),
+        ]
         _ctx = self._select("asService", _args)
         return Service(_ctx)
 
@@ -405,7 +715,12 @@ class Container(Type):
         *,
         platform_variants: "list[Container] | None" = None,
         forced_compression: ImageLayerCompression | None = None,
+        compression_level: int | None = None,
         media_types: ImageMediaTypes | None = ImageMediaTypes.OCIMediaTypes,
+        provenance: bool | None = False,
+        annotations: list[ImageAnnotation] | None = None,
+        annotate_from_labels: bool | None = False,
+        index_annotations: list[ImageAnnotation] | None = None,
     ) -> "File":
         """Returns a File representing the container serialized to a tarball.
 
@@ -422,11 +737,27 @@ class Container(Type):
             compression algorithms for different layers). If this is unset and
             a layer has no compressed blob in the engine's cache, then it will
             be compressed using Gzip.
+        compression_level:
+            The level to compress layers at with forcedCompression, e.g. 1-22
+            for Zstd. Requires forcedCompression.
         media_types:
             Use the specified media types for the image's layers.
             Defaults to OCI, which is largely compatible with most recent
             container runtimes, but Docker may be needed for older runtimes
             without OCI support.
+        provenance:
+            Attach SLSA provenance derived from the calls that produced the
+            container and its platform variants to the image.
+        annotations:
+            OCI annotations to set on the image's manifests.
+            Annotations require OCI media types.
+        annotate_from_labels:
+            Also set the org.opencontainers.image.* labels of the container
+            and of each platform variant as annotations on their own manifest,
+            unless overridden by annotations. Requires OCI media types.
+        index_annotations:
+            OCI annotations to set on the image index, which only exists for
+            multi-platform images or images with attestations.
         """
         _args = [
             Arg(
@@ -434,7 +765,15 @@ class Container(Type):
                 [] if platform_variants is None else platform_variants,
             ),
             Arg("forcedCompression", forced_compression, None),
+            Arg("compressionLevel", compression_level, None),
             Arg("mediaTypes", media_types, ImageMediaTypes.OCIMediaTypes),
+            Arg("provenance", provenance, False),
+            Arg("annotations", [] if annotations is None else annotations),
+            Arg("annotateFromLabels", annotate_from_labels, False),
+            Arg(
+                "indexAnnotations",
+                [] if index_annotations is None else index_annotations,
+            ),
         ]
         _ctx = self._select("asTarball", _args)
         return File(_ctx)
@@ -587,6 +926,29 @@ class Container(Type):
             for v in _ids
         ]
 
+    async def exit_code(self) -> int:
+        """The exit code of the last executed command.
+
+        Returns an error if no command was set.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("exitCode", _args)
+        return await _ctx.execute(int)
+
     def experimental_with_all_gp_us(self) -> Self:
         """EXPERIMENTAL API! Subject to change/removal at any time.
 
@@ -624,7 +986,12 @@ class Container(Type):
         *,
         platform_variants: "list[Container] | None" = None,
         forced_compression: ImageLayerCompression | None = None,
+        compression_level: int | None = None,
         media_types: ImageMediaTypes | None = ImageMediaTypes.OCIMediaTypes,
+        provenance: bool | None = False,
+        annotations: list[ImageAnnotation] | None = None,
+        annotate_from_labels: bool | None = False,
+        index_annotations: list[ImageAnnotation] | None = None,
     ) -> str:
         """Writes the container as an OCI tarball to the destination file path on
         the host.
@@ -647,11 +1014,27 @@ class Container(Type):
             compression algorithms for different layers). If this is unset and
             a layer has no compressed blob in the engine's cache, then it will
             be compressed using Gzip.
+        compression_level:
+            The level to compress layers at with forcedCompression, e.g. 1-22
+            for Zstd. Requires forcedCompression.
         media_types:
             Use the specified media types for the exported image's layers.
             Defaults to OCI, which is largely compatible with most recent
             container runtimes, but Docker may be needed for older runtimes
             without OCI support.
+        provenance:
+            Attach SLSA provenance derived from the calls that produced the
+            container and its platform variants to the image.
+        annotations:
+            OCI annotations to set on the image's manifests.
+            Annotations require OCI media types.
+        annotate_from_labels:
+            Also set the org.opencontainers.image.* labels of the container
+            and of each platform variant as annotations on their own manifest,
+            unless overridden by annotations. Requires OCI media types.
+        index_annotations:
+            OCI annotations to set on the image index, which only exists for
+            multi-platform images or images with attestations.
 
         Returns
         -------
@@ -674,7 +1057,15 @@ class Container(Type):
                 [] if platform_variants is None else platform_variants,
             ),
             Arg("forcedCompression", forced_compression, None),
+            Arg("compressionLevel", compression_level, None),
             Arg("mediaTypes", media_types, ImageMediaTypes.OCIMediaTypes),
+            Arg("provenance", provenance, False),
+            Arg("annotations", [] if annotations is None else annotations),
+            Arg("annotateFromLabels", annotate_from_labels, False),
+            Arg(
+                "indexAnnotations",
+                [] if index_annotations is None else index_annotations,
+            ),
         ]
         _ctx = self._select("export", _args)
         return await _ctx.execute(str)
@@ -720,7 +1111,13 @@ class Container(Type):
         _ctx = self._select("file", _args)
         return File(_ctx)
 
-    def from_(self, address: str) -> Self:
+    def from_(
+        self,
+        address: str,
+        *,
+        verify: str | None = None,
+        require_digest: bool | None = False,
+    ) -> Self:
         """Initializes this container from a pulled base image.
 
         Parameters
@@ -729,9 +1126,21 @@ class Container(Type):
             Image's address from its registry.
             Formatted as [host]/[user]/[repo]:[tag] (e.g.,
             "docker.io/dagger/dagger:main").
+        verify:
+            A PEM encoded public key or certificate to verify the image's
+            signature with, as pushed by cosign or Container.publish.
+            The image must have a valid signature made with the matching
+            private key.
+        require_digest:
+            Require the address to be pinned to a digest (e.g.,
+            "alpine@sha256:...").
+            Unpinned images can be pinned to the digest they resolve to with
+            imageRef.
         """
         _args = [
             Arg("address", address),
+            Arg("verify", verify, None),
+            Arg("requireDigest", require_digest, False),
         ]
         _ctx = self._select("from", _args)
         return Container(_ctx)
@@ -764,6 +1173,9 @@ class Container(Type):
         """The unique image reference which can only be retrieved immediately
         after the 'Container.From' call.
 
+        It's the address the container was initialized from, pinned to the
+        digest it resolved to, even if the engine pulled it from a mirror.
+
         Returns
         -------
         str
@@ -937,7 +1349,16 @@ class Container(Type):
         *,
         platform_variants: "list[Container] | None" = None,
         forced_compression: ImageLayerCompression | None = None,
+        compression_level: int | None = None,
         media_types: ImageMediaTypes | None = ImageMediaTypes.OCIMediaTypes,
+        provenance: bool | None = False,
+        annotations: list[ImageAnnotation] | None = None,
+        annotate_from_labels: bool | None = False,
+        index_annotations: list[ImageAnnotation] | None = None,
+        tags: list[str] | None = None,
+        signing_key: "Secret | None" = None,
+        signing_key_password: "Secret | None" = None,
+        signing_certificate: str | None = "",
     ) -> str:
         """Publishes this container as a new image to the specified address.
 
@@ -962,11 +1383,41 @@ class Container(Type):
             compression algorithms for different layers). If this is unset and
             a layer has no compressed blob in the engine's cache, then it will
             be compressed using Gzip.
+        compression_level:
+            The level to compress layers at with forcedCompression, e.g. 1-22
+            for Zstd. Requires forcedCompression.
         media_types:
             Use the specified media types for the published image's layers.
             Defaults to OCI, which is largely compatible with most recent
             registries, but Docker may be needed for older registries without
             OCI support.
+        provenance:
+            Attach SLSA provenance derived from the calls that produced the
+            container and its platform variants to the image.
+        annotations:
+            OCI annotations to set on the image's manifests.
+            Annotations require OCI media types.
+        annotate_from_labels:
+            Also set the org.opencontainers.image.* labels of the container
+            and of each platform variant as annotations on their own manifest,
+            unless overridden by annotations. Requires OCI media types.
+        index_annotations:
+            OCI annotations to set on the image index, which only exists for
+            multi-platform images or images with attestations.
+        tags:
+            Additional tags to push the image to, in the repository of address
+            (e.g. ["v1.2.3", "latest"]).
+        signing_key:
+            Sign the published image with this PEM encoded private key,
+            pushing the signature next to it in a format cosign can verify.
+            Keys encrypted by cosign generate-key-pair are supported.
+            The signature isn't recorded in a transparency log, so verifying
+            it with cosign verify --key requires --insecure-ignore-tlog.
+        signing_key_password:
+            The password of an encrypted signingKey.
+        signing_certificate:
+            A PEM encoded certificate for signingKey, followed by its chain,
+            to attach to the signature.
 
         Returns
         -------
@@ -989,7 +1440,19 @@ class Container(Type):
                 [] if platform_variants is None else platform_variants,
             ),
             Arg("forcedCompression", forced_compression, None),
+            Arg("compressionLevel", compression_level, None),
             Arg("mediaTypes", media_types, ImageMediaTypes.OCIMediaTypes),
+            Arg("provenance", provenance, False),
+            Arg("annotations", [] if annotations is None else annotations),
+            Arg("annotateFromLabels", annotate_from_labels, False),
+            Arg(
+                "indexAnnotations",
+                [] if index_annotations is None else index_annotations,
+            ),
+            Arg("tags", [] if tags is None else tags),
+            Arg("signingKey", signing_key, None),
+            Arg("signingKeyPassword", signing_key_password, None),
+            Arg("signingCertificate", signing_certificate, ""),
         ]
         _ctx = self._select("publish", _args)
         return await _ctx.execute(str)
@@ -1280,6 +1743,11 @@ class Container(Type):
         redirect_stderr: str | None = "",
         experimental_privileged_nesting: bool | None = False,
         insecure_root_capabilities: bool | None = False,
+        expect: ReturnType | None = ReturnType.SUCCESS,
+        timeout: str | None = "",
+        memory_limit: str | None = "",
+        cpu_quota: str | None = "",
+        pids_limit: int | None = 0,
     ) -> Self:
         """Retrieves this container after executing the specified command inside
         it.
@@ -1315,6 +1783,18 @@ class Container(Type):
             --privileged" flag. Containerization does not provide any security
             guarantees when using this option. It should only be used when
             absolutely necessary and only with trusted commands.
+        expect:
+            Exit codes this command is allowed to exit with without error
+        timeout:
+            Maximum duration the command may run for before it is killed and
+            the exec fails (e.g., "30s", "5m").
+        memory_limit:
+            Maximum amount of memory the command may use (e.g., "512m", "2g").
+        cpu_quota:
+            Maximum number of CPUs the command may use (e.g., "0.5", "2").
+        pids_limit:
+            Maximum number of processes the command may create.
+            If zero, no limit is applied.
         """
         _args = [
             Arg("args", args),
@@ -1327,6 +1807,11 @@ class Container(Type):
                 "experimentalPrivilegedNesting", experimental_privileged_nesting, False
             ),
             Arg("insecureRootCapabilities", insecure_root_capabilities, False),
+            Arg("expect", expect, ReturnType.SUCCESS),
+            Arg("timeout", timeout, ""),
+            Arg("memoryLimit", memory_limit, ""),
+            Arg("cpuQuota", cpu_quota, ""),
+            Arg("pidsLimit", pids_limit, 0),
         ]
         _ctx = self._select("withExec", _args)
         return Container(_ctx)
@@ -1686,6 +2171,34 @@ class Container(Type):
         _ctx = self._select("withRootfs", _args)
         return Container(_ctx)
 
+    def with_sbom(
+        self,
+        *,
+        sbom: "File | None" = None,
+        scanner: str | None = "docker/buildkit-syft-scanner:stable-1",
+    ) -> Self:
+        """Retrieves this container with an SPDX SBOM attached to its image as an
+        attestation when it's published or exported.
+
+        If no SBOM is given, one is generated by scanning the container's root
+        filesystem when its image is published or exported, like `docker
+        buildx build --sbom` does.
+
+        Parameters
+        ----------
+        sbom:
+            An SPDX JSON document describing the container's contents.
+        scanner:
+            A BuildKit SBOM scanner image to generate the SBOM with, if none
+            is given.
+        """
+        _args = [
+            Arg("sbom", sbom, None),
+            Arg("scanner", scanner, "docker/buildkit-syft-scanner:stable-1"),
+        ]
+        _ctx = self._select("withSBOM", _args)
+        return Container(_ctx)
+
     def with_secret_variable(self, name: str, secret: "Secret") -> Self:
         """Retrieves this container plus an env variable containing the given
         secret.
@@ -1936,6 +2449,12 @@ class Container(Type):
         _ctx = self._select("withoutRegistryAuth", _args)
         return Container(_ctx)
 
+    def without_sbom(self) -> Self:
+        """Retrieves this container without an SBOM attached to its image."""
+        _args: list[Arg] = []
+        _ctx = self._select("withoutSBOM", _args)
+        return Container(_ctx)
+
     def without_secret_variable(self, name: str) -> Self:
         """Retrieves this container minus the given environment variable
         containing the secret.
@@ -2157,9 +2676,49 @@ class DaggerEngine(Type):
 class DaggerEngineCache(Type):
     """A cache storage for the Dagger engine"""
 
-    def entry_set(self) -> "DaggerEngineCacheEntrySet":
-        """The current set of entries in the cache"""
-        _args: list[Arg] = []
+    def entry_set(
+        self,
+        *,
+        description: str | None = "",
+        type: DaggerEngineCacheEntryType | None = None,
+        in_use: bool | None = None,
+        min_size: int | None = 0,
+        last_used_before: str | None = "",
+        sort_by: DaggerEngineCacheEntrySortBy | None = None,
+        limit: int | None = 0,
+    ) -> "DaggerEngineCacheEntrySet":
+        """The current set of entries in the cache
+
+        Parameters
+        ----------
+        description:
+            Only include entries whose description matches this glob, where
+            "*"
+            matches any sequence of characters (e.g. "pulled from *alpine*").
+        type:
+            Only include entries of this type.
+        in_use:
+            Only include entries that are (or aren't) actively being used.
+        min_size:
+            Only include entries using at least this many bytes.
+        last_used_before:
+            Only include entries last used before this time, given as an RFC
+            3339
+            timestamp or a duration ago (e.g. "24h" or "7d").
+        sort_by:
+            The order to list entries in.
+        limit:
+            The maximum number of entries to include.
+        """
+        _args = [
+            Arg("description", description, ""),
+            Arg("type", type, None),
+            Arg("inUse", in_use, None),
+            Arg("minSize", min_size, 0),
+            Arg("lastUsedBefore", last_used_before, ""),
+            Arg("sortBy", sort_by, None),
+            Arg("limit", limit, 0),
+        ]
         _ctx = self._select("entrySet", _args)
         return DaggerEngineCacheEntrySet(_ctx)
 
@@ -2209,9 +2768,30 @@ class DaggerEngineCache(Type):
         _ctx = self._select("keepBytes", _args)
         return await _ctx.execute(int)
 
-    async def prune(self) -> Void | None:
+    async def prune(
+        self,
+        *,
+        older_than: str | None = "",
+        type: DaggerEngineCacheEntryType | None = None,
+        min_size: int | None = 0,
+        keep_bytes: int | None = 0,
+    ) -> Void | None:
         """Prune the cache of releaseable entries
 
+        Parameters
+        ----------
+        older_than:
+            Only prune entries that have not been used for at least this long,
+            e.g. "24h" or "7d".
+        type:
+            Only prune entries of this type.
+        min_size:
+            Only prune entries using at least this many bytes.
+        keep_bytes:
+            Only prune while the cache uses more than this many bytes,
+            starting
+            with the least recently used entries.
+
         Returns
         -------
         Void | None
@@ -2225,7 +2805,12 @@ class DaggerEngineCache(Type):
         QueryError
             If the API returns an error.
         """
-        _args: list[Arg] = []
+        _args = [
+            Arg("olderThan", older_than, ""),
+            Arg("type", type, None),
+            Arg("minSize", min_size, 0),
+            Arg("keepBytes", keep_bytes, 0),
+        ]
         _ctx = self._select("prune", _args)
         await _ctx.execute()
 
@@ -2253,15 +2838,16 @@ class DaggerEngineCacheEntry(Type):
         _ctx = self._select("activelyUsed", _args)
         return await _ctx.execute(bool)
 
-    async def created_time_unix_nano(self) -> int:
-        """The time the cache entry was created, in Unix nanoseconds.
+    async def cache_mount_key(self) -> str:
+        """The key of the cache volume owning the cache entry, if it is a cache
+        mount. Only known for cache volumes mounted since the engine started.
 
         Returns
         -------
-        int
-            The `Int` scalar type represents non-fractional signed whole
-            numeric values. Int can represent values between -(2^31) and 2^31
-            - 1.
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
 
         Raises
         ------
@@ -2271,8 +2857,52 @@ class DaggerEngineCacheEntry(Type):
             If the API returns an error.
         """
         _args: list[Arg] = []
-        _ctx = self._select("createdTimeUnixNano", _args)
-        return await _ctx.execute(int)
+        _ctx = self._select("cacheMountKey", _args)
+        return await _ctx.execute(str)
+
+    async def cache_mount_namespace(self) -> str:
+        """The namespace of the cache volume owning the cache entry, derived from
+        its scope. Empty for global cache volumes. Only known for cache
+        volumes mounted since the engine started.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("cacheMountNamespace", _args)
+        return await _ctx.execute(str)
+
+    async def created_time_unix_nano(self) -> int:
+        """The time the cache entry was created, in Unix nanoseconds.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("createdTimeUnixNano", _args)
+        return await _ctx.execute(int)
 
     async def description(self) -> str:
         """The description of the cache entry.
@@ -2361,6 +2991,25 @@ class DaggerEngineCacheEntry(Type):
         _ctx = self._select("mostRecentUseTimeUnixNano", _args)
         return await _ctx.execute(int)
 
+    async def record_type(self) -> DaggerEngineCacheEntryType:
+        """The type of data held by the cache entry.
+
+        Returns
+        -------
+        DaggerEngineCacheEntryType
+            The kind of data held by a cache entry.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("recordType", _args)
+        return await _ctx.execute(DaggerEngineCacheEntryType)
+
 
 @typecheck
 class DaggerEngineCacheEntrySet(Type):
@@ -2387,9 +3036,25 @@ class DaggerEngineCacheEntrySet(Type):
         _ctx = self._select("diskSpaceBytes", _args)
         return await _ctx.execute(int)
 
-    async def entries(self) -> list[DaggerEngineCacheEntry]:
-        """The list of individual cache entries in the set"""
-        _args: list[Arg] = []
+    async def entries(
+        self,
+        *,
+        offset: int | None = 0,
+        limit: int | None = 0,
+    ) -> list[DaggerEngineCacheEntry]:
+        """The list of individual cache entries in the set
+
+        Parameters
+        ----------
+        offset:
+            The number of entries to skip.
+        limit:
+            The maximum number of entries to include.
+        """
+        _args = [
+            Arg("offset", offset, 0),
+            Arg("limit", limit, 0),
+        ]
         _ctx = self._select("entries", _args)
         _ctx = DaggerEngineCacheEntry(_ctx)._select("id", [])
 
@@ -2458,6 +3123,35 @@ class DaggerEngineCacheEntrySet(Type):
 class Directory(Type):
     """A directory."""
 
+    def as_git_commit(
+        self,
+        message: str,
+        author: str,
+        *,
+        parent: "GitRef | None" = None,
+    ) -> "GitRef":
+        """Commits the contents of this directory to a new git commit.
+
+        Any .git directory in it is ignored.
+
+        Parameters
+        ----------
+        message:
+            The commit message.
+        author:
+            The author and committer of the commit (e.g., "Jane Doe
+            <jane@example.com>").
+        parent:
+            The commit to build on. If not set, a new repository is created.
+        """
+        _args = [
+            Arg("message", message),
+            Arg("author", author),
+            Arg("parent", parent, None),
+        ]
+        _ctx = self._select("asGitCommit", _args)
+        return GitRef(_ctx)
+
     def as_module(
         self,
         *,
@@ -2487,6 +3181,39 @@ class Directory(Type):
         _ctx = self._select("asModule", _args)
         return Module(_ctx)
 
+    async def changes(self, other: Self) -> list["DirectoryChange"]:
+        """Lists the paths that were added, modified or deleted in another
+        directory compared to this one.
+
+        Paths whose metadata changed but whose content and permissions are the
+        same, like files with new timestamps, are not listed.
+
+        Parameters
+        ----------
+        other:
+            Identifier of the directory to compare.
+        """
+        _args = [
+            Arg("other", other),
+        ]
+        _ctx = self._select("changes", _args)
+        _ctx = DirectoryChange(_ctx)._select("id", [])
+
+        @dataclass
+        class Response:
+            id: DirectoryChangeID
+
+        _ids = await _ctx.execute(list[Response])
+        return [
+            DirectoryChange(
+                Client.from_context(_ctx)._select(
+                    "loadDirectoryChangeFromID",
+                    [Arg("id", v.id)],
+                )
+            )
+            for v in _ids
+        ]
+
     def diff(self, other: Self) -> Self:
         """Gets the difference between this directory and an another directory.
 
@@ -2720,6 +3447,57 @@ class Directory(Type):
         _ctx = self._select("pipeline", _args)
         return Directory(_ctx)
 
+    async def search(
+        self,
+        pattern: str,
+        *,
+        regex: bool | None = False,
+        globs: list[str] | None = None,
+        max_results: int | None = 0,
+    ) -> list["SearchMatch"]:
+        """Searches the contents of the files in this directory, returning the
+        matching lines.
+
+        Binary files are skipped and symlinks are not followed.
+
+        Parameters
+        ----------
+        pattern:
+            Text to search for, or a regular expression if regex is true.
+        regex:
+            Interpret the pattern as a regular expression, using RE2 syntax
+            (e.g., "TODO\(\w+\)").
+        globs:
+            Only search files matching these patterns (e.g., ["**/*.go",
+            "!vendor"]).
+            If every pattern is an exclusion, all other files are searched.
+        max_results:
+            Stop after this many matches. Zero means no limit.
+        """
+        _args = [
+            Arg("pattern", pattern),
+            Arg("regex", regex, False),
+            Arg("globs", [] if globs is None else globs),
+            Arg("maxResults", max_results, 0),
+        ]
+        _ctx = self._select("search", _args)
+        _ctx = SearchMatch(_ctx)._select("id", [])
+
+        @dataclass
+        class Response:
+            id: SearchMatchID
+
+        _ids = await _ctx.execute(list[Response])
+        return [
+            SearchMatch(
+                Client.from_context(_ctx)._select(
+                    "loadSearchMatchFromID",
+                    [Arg("id", v.id)],
+                )
+            )
+            for v in _ids
+        ]
+
     async def sync(self) -> Self:
         """Force evaluation in the engine.
 
@@ -2917,6 +3695,58 @@ class Directory(Type):
         _ctx = self._select("withNewFile", _args)
         return Directory(_ctx)
 
+    def with_owner(self, owner: str) -> Self:
+        """Retrieves this directory with the owner of all of its files and
+        directories set to the given user and group.
+
+        Parameters
+        ----------
+        owner:
+            A user:group to set for the directory and its contents.
+            The user and group can either be an ID (1000:1000) or a name
+            (foo:bar) looked up in the directory's own etc/passwd and
+            etc/group files.
+            If the group is omitted, it defaults to the same as the user.
+        """
+        _args = [
+            Arg("owner", owner),
+        ]
+        _ctx = self._select("withOwner", _args)
+        return Directory(_ctx)
+
+    def with_patch(self, patch: str) -> Self:
+        """Retrieves this directory with the given patch applied.
+
+        Parameters
+        ----------
+        patch:
+            Patch in the unified diff format produced by "git diff", with
+            paths relative to this directory.
+        """
+        _args = [
+            Arg("patch", patch),
+        ]
+        _ctx = self._select("withPatch", _args)
+        return Directory(_ctx)
+
+    def with_symlink(self, target: str, link_name: str) -> Self:
+        """Retrieves this directory plus a symlink at the given path.
+
+        Parameters
+        ----------
+        target:
+            Location the symlink points to (e.g., "../lib/libfoo.so.1"), which
+            doesn't need to exist.
+        link_name:
+            Location of the symlink to create (e.g., "lib/libfoo.so").
+        """
+        _args = [
+            Arg("target", target),
+            Arg("linkName", link_name),
+        ]
+        _ctx = self._select("withSymlink", _args)
+        return Directory(_ctx)
+
     def with_timestamps(self, timestamp: int) -> Self:
         """Retrieves this directory with all file/dir timestamps set to the given
         time.
@@ -2969,6 +3799,179 @@ class Directory(Type):
         return cb(self)
 
 
+@typecheck
+class DirectoryChange(Type):
+    """A path that was added, modified or deleted between two
+    directories."""
+
+    async def id(self) -> DirectoryChangeID:
+        """A unique identifier for this DirectoryChange.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        DirectoryChangeID
+            The `DirectoryChangeID` scalar type represents an identifier for
+            an object of type DirectoryChange.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(DirectoryChangeID)
+
+    async def is_directory(self) -> bool:
+        """Whether the path is a directory.
+
+        Returns
+        -------
+        bool
+            The `Boolean` scalar type represents `true` or `false`.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("isDirectory", _args)
+        return await _ctx.execute(bool)
+
+    async def kind(self) -> DirectoryChangeKind:
+        """How the path changed.
+
+        Returns
+        -------
+        DirectoryChangeKind
+            The kind of a change between two directories.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("kind", _args)
+        return await _ctx.execute(DirectoryChangeKind)
+
+    async def mode(self) -> int:
+        """The permissions of the path, unless it was deleted.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("mode", _args)
+        return await _ctx.execute(int)
+
+    async def path(self) -> str:
+        """The path that changed, relative to the directory.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("path", _args)
+        return await _ctx.execute(str)
+
+    async def previous_mode(self) -> int:
+        """The previous permissions of the path, unless it was added.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("previousMode", _args)
+        return await _ctx.execute(int)
+
+    async def previous_size(self) -> int:
+        """The previous size of the path in bytes, unless it was added.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("previousSize", _args)
+        return await _ctx.execute(int)
+
+    async def size(self) -> int:
+        """The size of the path in bytes, unless it was deleted.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("size", _args)
+        return await _ctx.execute(int)
+
+
 @typecheck
 class EnumTypeDef(Type):
     """A definition of a custom enum defined in a Module."""
@@ -3309,6 +4312,36 @@ class FieldTypeDef(Type):
 class File(Type):
     """A file."""
 
+    async def as_json(
+        self,
+        *,
+        format: DataFormat | None = None,
+    ) -> JSON:
+        """Parses the file as structured data and returns it as JSON.
+
+        Parameters
+        ----------
+        format:
+            Format of the file. Inferred from its extension if not set.
+
+        Returns
+        -------
+        JSON
+            An arbitrary JSON-encoded value.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("format", format, None),
+        ]
+        _ctx = self._select("asJSON", _args)
+        return await _ctx.execute(JSON)
+
     async def contents(self) -> str:
         """Retrieves the contents of the file.
 
@@ -3443,9 +4476,49 @@ class File(Type):
         QueryError
             If the API returns an error.
         """
-        _args: list[Arg] = []
-        _ctx = self._select("name", _args)
-        return await _ctx.execute(str)
+        _args: list[Arg] = []
+        _ctx = self._select("name", _args)
+        return await _ctx.execute(str)
+
+    async def query(
+        self,
+        path: str,
+        *,
+        format: DataFormat | None = None,
+    ) -> JSON:
+        """Parses the file as structured data and returns the JSON value at the
+        given path.
+
+        Returns an error if there is no value at the path.
+
+        Parameters
+        ----------
+        path:
+            Path of the value to return, in a subset of JSONPath (e.g.,
+            "version", "$.dependencies['@types/node']" or "workspaces[0]").
+            Keys are selected with ".key" or "['key']", and array elements
+            with "[n]", counting from the end if negative.
+        format:
+            Format of the file. Inferred from its extension if not set.
+
+        Returns
+        -------
+        JSON
+            An arbitrary JSON-encoded value.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("path", path),
+            Arg("format", format, None),
+        ]
+        _ctx = self._select("query", _args)
+        return await _ctx.execute(JSON)
 
     async def size(self) -> int:
         """Retrieves the size of the file, in bytes.
@@ -3501,6 +4574,49 @@ class File(Type):
         _ctx = self._select("withName", _args)
         return File(_ctx)
 
+    def with_permissions(self, permissions: int) -> Self:
+        """Retrieves this file with its permissions set to the given value.
+
+        Parameters
+        ----------
+        permissions:
+            Permissions to set on the file (e.g., 0755).
+        """
+        _args = [
+            Arg("permissions", permissions),
+        ]
+        _ctx = self._select("withPermissions", _args)
+        return File(_ctx)
+
+    def with_replaced(
+        self,
+        search: str,
+        replace: str,
+        *,
+        all: bool | None = False,
+    ) -> Self:
+        """Retrieves this file with the first or all occurrences of the given
+        text replaced.
+
+        Fails if the text does not occur in the file.
+
+        Parameters
+        ----------
+        search:
+            Text to replace.
+        replace:
+            Text to replace it with.
+        all:
+            Replace all occurrences instead of only the first one.
+        """
+        _args = [
+            Arg("search", search),
+            Arg("replace", replace),
+            Arg("all", all, False),
+        ]
+        _ctx = self._select("withReplaced", _args)
+        return File(_ctx)
+
     def with_timestamps(self, timestamp: int) -> Self:
         """Retrieves this file with its created/modified timestamps set to the
         given time.
@@ -4088,6 +5204,202 @@ class GeneratedCode(Type):
         return cb(self)
 
 
+@typecheck
+class GitActor(Type):
+    """The author or committer of a git commit."""
+
+    async def email(self) -> str:
+        """The email of the author or committer.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("email", _args)
+        return await _ctx.execute(str)
+
+    async def id(self) -> GitActorID:
+        """A unique identifier for this GitActor.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        GitActorID
+            The `GitActorID` scalar type represents an identifier for an
+            object of type GitActor.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(GitActorID)
+
+    async def name(self) -> str:
+        """The name of the author or committer.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("name", _args)
+        return await _ctx.execute(str)
+
+
+@typecheck
+class GitCommit(Type):
+    """The metadata of a git commit."""
+
+    def author(self) -> GitActor:
+        """The author of the commit."""
+        _args: list[Arg] = []
+        _ctx = self._select("author", _args)
+        return GitActor(_ctx)
+
+    def committer(self) -> GitActor:
+        """The committer of the commit."""
+        _args: list[Arg] = []
+        _ctx = self._select("committer", _args)
+        return GitActor(_ctx)
+
+    async def id(self) -> GitCommitID:
+        """A unique identifier for this GitCommit.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        GitCommitID
+            The `GitCommitID` scalar type represents an identifier for an
+            object of type GitCommit.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(GitCommitID)
+
+    async def message(self) -> str:
+        """The full commit message.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("message", _args)
+        return await _ctx.execute(str)
+
+    async def parents(self) -> list[str]:
+        """The SHAs of the parent commits.
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("parents", _args)
+        return await _ctx.execute(list[str])
+
+    async def sha(self) -> str:
+        """The SHA of the commit.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("sha", _args)
+        return await _ctx.execute(str)
+
+    async def timestamp(self) -> int:
+        """The time the commit was made, in Unix seconds.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("timestamp", _args)
+        return await _ctx.execute(int)
+
+
 @typecheck
 class GitModuleSource(Type):
     """Module source originating from a git repo."""
@@ -4278,6 +5590,12 @@ class GitRef(Type):
         _ctx = self._select("commit", _args)
         return await _ctx.execute(str)
 
+    def commit_info(self) -> GitCommit:
+        """The metadata of the resolved commit at this ref."""
+        _args: list[Arg] = []
+        _ctx = self._select("commitInfo", _args)
+        return GitCommit(_ctx)
+
     async def id(self) -> GitRefID:
         """A unique identifier for this GitRef.
 
@@ -4302,9 +5620,76 @@ class GitRef(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(GitRefID)
 
-    def tree(self) -> Directory:
-        """The filesystem tree at this ref."""
-        _args: list[Arg] = []
+    async def push(
+        self,
+        remote: str,
+        ref: str,
+        *,
+        force: bool | None = False,
+        ssh_known_hosts: str | None = "",
+        ssh_auth_socket: "Socket | None" = None,
+        auth_token: "Secret | None" = None,
+        auth_header: "Secret | None" = None,
+    ) -> Void | None:
+        """Pushes the commit at this ref to a remote repository.
+
+        Parameters
+        ----------
+        remote:
+            URL of the repository to push to.
+        ref:
+            Ref to update in the remote (e.g., "main" or "refs/tags/v1.0.0").
+            Names that are not fully-qualified are treated as branches.
+        force:
+            Update the ref even if the commit does not descend from its
+            current commit.
+        ssh_known_hosts:
+            Set SSH known hosts
+        ssh_auth_socket:
+            Set SSH auth socket
+        auth_token:
+            Secret used to populate the password during basic HTTP
+            Authorization
+        auth_header:
+            Secret used to populate the Authorization HTTP header
+
+        Returns
+        -------
+        Void | None
+            The absence of a value.  A Null Void is used as a placeholder for
+            resolvers that do not return anything.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("remote", remote),
+            Arg("ref", ref),
+            Arg("force", force, False),
+            Arg("sshKnownHosts", ssh_known_hosts, ""),
+            Arg("sshAuthSocket", ssh_auth_socket, None),
+            Arg("authToken", auth_token, None),
+            Arg("authHeader", auth_header, None),
+        ]
+        _ctx = self._select("push", _args)
+        await _ctx.execute()
+
+    def tree(self, *, sparse: list[str] | None = None) -> Directory:
+        """The filesystem tree at this ref.
+
+        Parameters
+        ----------
+        sparse:
+            Only check out these paths (e.g., ["docs", "go.mod"]).
+            Paths are relative to the root of the repository.
+        """
+        _args = [
+            Arg("sparse", sparse, None),
+        ]
         _ctx = self._select("tree", _args)
         return Directory(_ctx)
 
@@ -4327,6 +5712,38 @@ class GitRepository(Type):
         _ctx = self._select("branch", _args)
         return GitRef(_ctx)
 
+    async def branches(
+        self,
+        *,
+        patterns: list[str] | None = None,
+    ) -> list[str]:
+        """branches that match any of the given glob patterns.
+
+        Parameters
+        ----------
+        patterns:
+            Glob patterns (e.g., "refs/heads/release/*").
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("patterns", patterns, None),
+        ]
+        _ctx = self._select("branches", _args)
+        return await _ctx.execute(list[str])
+
     def commit(self, id: str) -> GitRef:
         """Returns details of a commit.
 
@@ -4387,6 +5804,38 @@ class GitRepository(Type):
         _ctx = self._select("ref", _args)
         return GitRef(_ctx)
 
+    async def refs(
+        self,
+        *,
+        patterns: list[str] | None = None,
+    ) -> list[str]:
+        """Fully-qualified refs that match any of the given glob patterns.
+
+        Parameters
+        ----------
+        patterns:
+            Glob patterns (e.g., "refs/pull/*").
+
+        Returns
+        -------
+        list[str]
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("patterns", patterns, None),
+        ]
+        _ctx = self._select("refs", _args)
+        return await _ctx.execute(list[str])
+
     def tag(self, name: str) -> GitRef:
         """Returns details of a tag.
 
@@ -4569,7 +6018,14 @@ class Host(Type):
         _ctx = self._select("service", _args)
         return Service(_ctx)
 
-    def set_secret_file(self, name: str, path: str) -> "Secret":
+    def set_secret_file(
+        self,
+        name: str,
+        path: str,
+        *,
+        ttl: str | None = "",
+        source: str | None = "",
+    ) -> "Secret":
         """Sets a secret given a user-defined name and the file path on the host,
         and returns the secret.
 
@@ -4581,10 +6037,14 @@ class Host(Type):
             The user defined name for this secret.
         path:
             Location of the file to set as a secret.
+        ttl:
+            How long the file's contents are valid for (e.g., "15m").
+            Once expired, the file is read again from the host.
         """
         _args = [
             Arg("name", name),
             Arg("path", path),
+            Arg("ttl", ttl, ""),
         ]
         _ctx = self._select("setSecretFile", _args)
         return Secret(_ctx)
@@ -5150,6 +6610,29 @@ class Module(Type):
             for v in _ids
         ]
 
+    async def lock(self) -> str:
+        """The contents of the module's dagger.lock file, pinning what its
+        functions resolved so far in this session, or an empty string if
+        nothing is pinned.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("lock", _args)
+        return await _ctx.execute(str)
+
     async def name(self) -> str:
         """The name of the module
 
@@ -6203,7 +7686,12 @@ class Client(Root):
         _ctx = self._select("builtinContainer", _args)
         return Container(_ctx)
 
-    def cache_volume(self, key: str) -> CacheVolume:
+    def cache_volume(
+        self,
+        key: str,
+        *,
+        scope: CacheVolumeScope | None = None,
+    ) -> CacheVolume:
         """Constructs a cache volume for a given cache key.
 
         Parameters
@@ -6211,9 +7699,14 @@ class Client(Root):
         key:
             A string identifier to target this cache volume (e.g., "modules-
             cache").
+        scope:
+            Who the cache volume is shared with. Defaults to MODULE_SCOPE
+            within a
+            module, and GLOBAL_SCOPE otherwise.
         """
         _args = [
             Arg("key", key),
+            Arg("scope", scope, None),
         ]
         _ctx = self._select("cacheVolume", _args)
         return CacheVolume(_ctx)
@@ -6346,6 +7839,8 @@ class Client(Root):
         url: str,
         *,
         keep_git_dir: bool | None = False,
+        depth: int | None = 1,
+        with_submodules: bool | None = True,
         experimental_service_host: "Service | None" = None,
         ssh_known_hosts: str | None = "",
         ssh_auth_socket: "Socket | None" = None,
@@ -6361,6 +7856,11 @@ class Client(Root):
             Suffix ".git" is optional.
         keep_git_dir:
             Set to true to keep .git directory.
+        depth:
+            Depth of history to fetch.
+            Set to 0 to fetch the full history.
+        with_submodules:
+            Set to false to skip checking out submodules.
         experimental_service_host:
             A service which must be started before the repo is fetched.
         ssh_known_hosts:
@@ -6371,6 +7871,8 @@ class Client(Root):
         _args = [
             Arg("url", url),
             Arg("keepGitDir", keep_git_dir, False),
+            Arg("depth", depth, 1),
+            Arg("withSubmodules", with_submodules, True),
             Arg("experimentalServiceHost", experimental_service_host, None),
             Arg("sshKnownHosts", ssh_known_hosts, ""),
             Arg("sshAuthSocket", ssh_auth_socket, None),
@@ -6388,6 +7890,11 @@ class Client(Root):
         self,
         url: str,
         *,
+        checksum: str | None = None,
+        auth_header: "Secret | None" = None,
+        headers: list[HTTPHeader] | None = None,
+        name: str | None = None,
+        permissions: int | None = None,
         experimental_service_host: "Service | None" = None,
     ) -> File:
         """Returns a file containing an http remote url content.
@@ -6396,11 +7903,28 @@ class Client(Root):
         ----------
         url:
             HTTP url to get the content from (e.g., "https://docs.dagger.io").
+        checksum:
+            Expected digest of the content (e.g., "sha256:...").
+            The download fails if the content doesn't match, and is skipped if
+            content with the digest is already cached.
+        auth_header:
+            Secret used to populate the Authorization HTTP header
+        headers:
+            Additional HTTP headers to send with the request.
+        name:
+            File name of the downloaded file.
+        permissions:
+            Permission given to the downloaded file (e.g., 0600).
         experimental_service_host:
             A service which must be started before the URL is fetched.
         """
         _args = [
             Arg("url", url),
+            Arg("checksum", checksum, None),
+            Arg("authHeader", auth_header, None),
+            Arg("headers", [] if headers is None else headers),
+            Arg("name", name, None),
+            Arg("permissions", permissions, None),
             Arg("experimentalServiceHost", experimental_service_host, None),
         ]
         _ctx = self._select("http", _args)
@@ -6468,6 +7992,14 @@ class Client(Root):
         _ctx = self._select("loadDaggerEngineFromID", _args)
         return DaggerEngine(_ctx)
 
+    def load_directory_change_from_id(self, id: DirectoryChangeID) -> DirectoryChange:
+        """Load a DirectoryChange from its ID."""
+        _args = [
+            Arg("id", id),
+        ]
+        _ctx = self._select("loadDirectoryChangeFromID", _args)
+        return DirectoryChange(_ctx)
+
     def load_directory_from_id(self, id: DirectoryID) -> Directory:
         """Load a Directory from its ID."""
         _args = [
@@ -6560,6 +8092,22 @@ class Client(Root):
         _ctx = self._select("loadGeneratedCodeFromID", _args)
         return GeneratedCode(_ctx)
 
+    def load_git_actor_from_id(self, id: GitActorID) -> GitActor:
+        """Load a GitActor from its ID."""
+        _args = [
+            Arg("id", id),
+        ]
+        _ctx = self._select("loadGitActorFromID", _args)
+        return GitActor(_ctx)
+
+    def load_git_commit_from_id(self, id: GitCommitID) -> GitCommit:
+        """Load a GitCommit from its ID."""
+        _args = [
+            Arg("id", id),
+        ]
+        _ctx = self._select("loadGitCommitFromID", _args)
+        return GitCommit(_ctx)
+
     def load_git_module_source_from_id(self, id: GitModuleSourceID) -> GitModuleSource:
         """Load a GitModuleSource from its ID."""
         _args = [
@@ -6696,6 +8244,14 @@ class Client(Root):
         _ctx = self._select("loadScalarTypeDefFromID", _args)
         return ScalarTypeDef(_ctx)
 
+    def load_search_match_from_id(self, id: SearchMatchID) -> "SearchMatch":
+        """Load a SearchMatch from its ID."""
+        _args = [
+            Arg("id", id),
+        ]
+        _ctx = self._select("loadSearchMatchFromID", _args)
+        return SearchMatch(_ctx)
+
     def load_secret_from_id(self, id: SecretID) -> "Secret":
         """Load a Secret from its ID."""
         _args = [
@@ -6838,7 +8394,13 @@ class Client(Root):
         _ctx = self._select("secret", _args)
         return Secret(_ctx)
 
-    def set_secret(self, name: str, plaintext: str) -> "Secret":
+    def set_secret(
+        self,
+        name: str,
+        plaintext: str,
+        *,
+        ttl: str | None = "",
+    ) -> "Secret":
         """Sets a secret given a user defined name to its plaintext and returns
         the secret.
 
@@ -6850,10 +8412,23 @@ class Client(Root):
             The user defined name for this secret
         plaintext:
             The plaintext of the secret
+        ttl:
+            How long the plaintext is valid for (e.g., "15m").
+            Once expired, the secret's value is fetched again from the client
+            that set it, by asking its session for the secret's source, or for
+            the secret with this name if it has none. Clients connected
+            through the dagger CLI support this; Dagger Functions get an
+            error.
+        source:
+            Where the client resolves the secret's value from once its ttl
+            expires (e.g., "env:GITHUB_TOKEN", "file:./token.txt" or "cmd:gh
+            auth token").
         """
         _args = [
             Arg("name", name),
             Arg("plaintext", plaintext),
+            Arg("ttl", ttl, ""),
+            Arg("source", source, ""),
         ]
         _ctx = self._select("setSecret", _args)
         return Secret(_ctx)
@@ -6986,6 +8561,98 @@ class ScalarTypeDef(Type):
         return await _ctx.execute(str)
 
 
+@typecheck
+class SearchMatch(Type):
+    """A line matching a search in a directory."""
+
+    async def id(self) -> SearchMatchID:
+        """A unique identifier for this SearchMatch.
+
+        Note
+        ----
+        This is lazily evaluated, no operation is actually run.
+
+        Returns
+        -------
+        SearchMatchID
+            The `SearchMatchID` scalar type represents an identifier for an
+            object of type SearchMatch.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("id", _args)
+        return await _ctx.execute(SearchMatchID)
+
+    async def line(self) -> str:
+        """The text of the matching line, without its line ending.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("line", _args)
+        return await _ctx.execute(str)
+
+    async def line_number(self) -> int:
+        """The number of the matching line, starting at 1.
+
+        Returns
+        -------
+        int
+            The `Int` scalar type represents non-fractional signed whole
+            numeric values. Int can represent values between -(2^31) and 2^31
+            - 1.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("lineNumber", _args)
+        return await _ctx.execute(int)
+
+    async def path(self) -> str:
+        """The path of the matching file, relative to the searched directory.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("path", _args)
+        return await _ctx.execute(str)
+
+
 @typecheck
 class Secret(Type):
     """A reference to a secret value, which can be handled more safely
@@ -7104,6 +8771,37 @@ class Service(Type):
         _ctx = self._select("endpoint", _args)
         return await _ctx.execute(str)
 
+    async def exec(self, args: list[str]) -> str:
+        """Runs a command inside the running service and returns its stdout.
+
+        The command runs with the same environment, user and working directory
+        as the service. The service must already be running.
+
+        Parameters
+        ----------
+        args:
+            Command to run.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("args", args),
+        ]
+        _ctx = self._select("exec", _args)
+        return await _ctx.execute(str)
+
     async def hostname(self) -> str:
         """Retrieves a hostname which can be used by clients to reach this
         container.
@@ -7150,6 +8848,47 @@ class Service(Type):
         _ctx = self._select("id", _args)
         return await _ctx.execute(ServiceID)
 
+    async def logs(
+        self,
+        *,
+        since: str | None = "",
+        follow: bool | None = False,
+    ) -> str:
+        """Retrieves the output of the running service.
+
+        The service must already be running. Only the most recent output is
+        retained.
+
+        Parameters
+        ----------
+        since:
+            Only return output written after this time, as either a duration
+            relative to now (e.g., "5m") or an RFC 3339 timestamp.
+        follow:
+            Stream output to the caller until the service exits, then return
+            all of it.
+
+        Returns
+        -------
+        str
+            The `String` scalar type represents textual data, represented as
+            UTF-8 character sequences. The String type is most often used by
+            GraphQL to represent free-form human-readable text.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args = [
+            Arg("since", since, ""),
+            Arg("follow", follow, False),
+        ]
+        _ctx = self._select("logs", _args)
+        return await _ctx.execute(str)
+
     async def ports(self) -> list[Port]:
         """Retrieves the list of ports provided by the service."""
         _args: list[Arg] = []
@@ -7189,6 +8928,25 @@ class Service(Type):
         _ctx = Client.from_context(_ctx)._select("loadServiceFromID", [Arg("id", _id)])
         return Service(_ctx)
 
+    async def status(self) -> ServiceStatus:
+        """The current state of the service.
+
+        Returns
+        -------
+        ServiceStatus
+            The lifecycle state of a service.
+
+        Raises
+        ------
+        ExecuteTimeoutError
+            If the time to execute the query exceeds the configured timeout.
+        QueryError
+            If the API returns an error.
+        """
+        _args: list[Arg] = []
+        _ctx = self._select("status", _args)
+        return await _ctx.execute(ServiceStatus)
+
     async def stop(self, *, kill: bool | None = False) -> Self:
         """Stop the service.
 
@@ -7250,6 +9008,90 @@ class Service(Type):
         _ctx = self._select("up", _args)
         await _ctx.execute()
 
+    def with_exec_healthcheck(
+        self,
+        args: list[str],
+        *,
+        interval: str | None = "1s",
+        timeout: str | None = "10s",
+        retries: int | None = 30,
+    ) -> Self:
+        """Adds a command readiness probe to the service.
+
+        The service is only considered started once the command, run inside
+        the service container, exits successfully, after all of its exposed
+        ports are reachable.
+
+        Parameters
+        ----------
+        args:
+            Command to run, with the same environment, user and working
+            directory as the service.
+        interval:
+            How long to wait between attempts (e.g., "1s").
+        timeout:
+            How long a single attempt may take before it fails (e.g., "10s").
+        retries:
+            How many times to retry a failed attempt before giving up.
+        """
+        _args = [
+            Arg("args", args),
+            Arg("interval", interval, "1s"),
+            Arg("timeout", timeout, "10s"),
+            Arg("retries", retries, 30),
+        ]
+        _ctx = self._select("withExecHealthcheck", _args)
+        return Service(_ctx)
+
+    def with_http_healthcheck(
+        self,
+        port: int,
+        *,
+        path: str | None = "/",
+        expected_status: int | None = 200,
+        interval: str | None = "1s",
+        timeout: str | None = "10s",
+        retries: int | None = 30,
+    ) -> Self:
+        """Adds an HTTP readiness probe to the service.
+
+        The service is only considered started once a GET request to the given
+        port and path responds with the expected status, after all of its
+        exposed ports are reachable.
+
+        Parameters
+        ----------
+        port:
+            The port to send the request to.
+        path:
+            The path to request.
+        expected_status:
+            The HTTP status code that indicates the service is ready.
+        interval:
+            How long to wait between attempts (e.g., "1s").
+        timeout:
+            How long a single attempt may take before it fails (e.g., "10s").
+        retries:
+            How many times to retry a failed attempt before giving up.
+        """
+        _args = [
+            Arg("port", port),
+            Arg("path", path, "/"),
+            Arg("expectedStatus", expected_status, 200),
+            Arg("interval", interval, "1s"),
+            Arg("timeout", timeout, "10s"),
+            Arg("retries", retries, 30),
+        ]
+        _ctx = self._select("withHTTPHealthcheck", _args)
+        return Service(_ctx)
+
+    def with_(self, cb: Callable[["Service"], "Service"]) -> "Service":
+        """Call the provided callable with current Service.
+
+        This is useful for reusability and readability by not breaking the calling chain.
+        """
+        return cb(self)
+
 
 @typecheck
 class Socket(Type):
@@ -7629,6 +9471,7 @@ __all__ = [
     "CacheSharingMode",
     "CacheVolume",
     "CacheVolumeID",
+    "CacheVolumeScope",
     "Client",
     "Container",
     "ContainerID",
@@ -7640,9 +9483,15 @@ __all__ = [
     "DaggerEngineCacheEntryID",
     "DaggerEngineCacheEntrySet",
     "DaggerEngineCacheEntrySetID",
+    "DaggerEngineCacheEntrySortBy",
+    "DaggerEngineCacheEntryType",
     "DaggerEngineCacheID",
     "DaggerEngineID",
+    "DataFormat",
     "Directory",
+    "DirectoryChange",
+    "DirectoryChangeID",
+    "DirectoryChangeKind",
     "DirectoryID",
     "EnumTypeDef",
     "EnumTypeDefID",
@@ -7664,14 +9513,20 @@ __all__ = [
     "FunctionID",
     "GeneratedCode",
     "GeneratedCodeID",
+    "GitActor",
+    "GitActorID",
+    "GitCommit",
+    "GitCommitID",
     "GitModuleSource",
     "GitModuleSourceID",
     "GitRef",
     "GitRefID",
     "GitRepository",
     "GitRepositoryID",
+    "HTTPHeader",
     "Host",
     "HostID",
+    "ImageAnnotation",
     "ImageLayerCompression",
     "ImageMediaTypes",
     "InputTypeDef",
@@ -7702,12 +9557,17 @@ __all__ = [
     "Port",
     "PortForward",
     "PortID",
+    "ReturnType",
     "ScalarTypeDef",
     "ScalarTypeDefID",
+    "SearchMatch",
+    "SearchMatchID",
     "Secret",
     "SecretID",
     "Service",
     "ServiceID",
+    "ServiceRestartPolicy",
+    "ServiceStatus",
     "Socket",
     "SocketID",
     "Terminal",
@@ -83,6 +83,44 @@ export enum CacheSharingMode {
  */
 export type CacheVolumeID = string & { __CacheVolumeID: never }
 
+/**
+ * Scope of a cache volume, which determines who it is shared with.
+ */
+export enum CacheVolumeScope {
+  /**
+   * Shares the cache volume with the caller of the current function, as if the caller had created it with its default scope
+   */
+  CallerModuleScope = "CALLER_MODULE_SCOPE",
+
+  /**
+   * Keeps a cache volume for the client that started the session, e.g. the dagger CLI of a particular user
+   */
+  ClientScope = "CLIENT_SCOPE",
+
+  /**
+   * Shares the cache volume with every client and module using the same key
+   */
+  GlobalScope = "GLOBAL_SCOPE",
+
+  /**
+   * Keeps a cache volume for the current module, regardless of who calls it
+   */
+  ModuleScope = "MODULE_SCOPE",
+}
+export type ContainerAsServiceOpts = {
+  /**
+   * When to restart the service if it exits without being stopped.
+   */
+  restartPolicy?: ServiceRestartPolicy
+
+  /**
+   * The maximum number of times to restart the service with the ON_FAILURE restart policy.
+   *
+   * Zero means no limit.
+   */
+  maxRetries?: number
+}
+
 export type ContainerAsTarballOpts = {
   /**
    * Identifiers for other platform specific containers.
@@ -98,12 +136,39 @@ export type ContainerAsTarballOpts = {
    */
   forcedCompression?: ImageLayerCompression
 
+  /**
+   * The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+   */
+  compressionLevel?: number
+
   /**
    * Use the specified media types for the image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
    */
   mediaTypes?: ImageMediaTypes
+
+  /**
+   * Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   */
+  provenance?: boolean
+
+  /**
+   * OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   */
+  annotations?: ImageAnnotation[]
+
+  /**
+   * Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   */
+  annotateFromLabels?: boolean
+
+  /**
+   * OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+   */
+  indexAnnotations?: ImageAnnotation[]
 }
 
 export type ContainerBuildOpts = {
@@ -147,12 +212,55 @@ export type ContainerExportOpts = {
    */
   forcedCompression?: ImageLayerCompression
 
+  /**
+   * The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+   */
+  compressionLevel?: number
+
   /**
    * Use the specified media types for the exported image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
    */
   mediaTypes?: ImageMediaTypes
+
+  /**
+   * Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   */
+  provenance?: boolean
+
+  /**
+   * OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   */
+  annotations?: ImageAnnotation[]
+
+  /**
+   * Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   */
+  annotateFromLabels?: boolean
+
+  /**
+   * OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+   */
+  indexAnnotations?: ImageAnnotation[]
+}
+
+export type ContainerFromOpts = {
+  /**
+   * A PEM encoded public key or certificate to verify the image's signature with, as pushed by cosign or Container.publish.
+   *
+   * The image must have a valid signature made with the matching private key.
+   */
+  verify?: string
+
+  /**
+   * Require the address to be pinned to a digest (e.g., "alpine@sha256:...").
+   *
+   * Unpinned images can be pinned to the digest they resolve to with imageRef.
+   */
+  requireDigest?: boolean
 }
 
 export type ContainerImportOpts = {
@@ -189,12 +297,63 @@ export type ContainerPublishOpts = {
    */
   forcedCompression?: ImageLayerCompression
 
+  /**
+   * The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
+   */
+  compressionLevel?: number
+
   /**
    * Use the specified media types for the published image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent registries, but Docker may be needed for older registries without OCI support.
    */
   mediaTypes?: ImageMediaTypes
+
+  /**
+   * Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   */
+  provenance?: boolean
+
+  /**
+   * OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   */
+  annotations?: ImageAnnotation[]
+
+  /**
+   * Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   */
+  annotateFromLabels?: boolean
+
+  /**
+   * OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+   */
+  indexAnnotations?: ImageAnnotation[]
+
+  /**
+   * Additional tags to push the image to, in the repository of address (e.g. ["v1.2.3", "latest"]).
+   */
+  tags?: string[]
+
+  /**
+   * Sign the published image with this PEM encoded private key, pushing the signature next to it in a format cosign can verify.
+   *
+   * Keys encrypted by cosign generate-key-pair are supported.
+   *
+   * The signature isn't recorded in a transparency log, so verifying it with cosign verify --key requires --insecure-ignore-tlog.
+   */
+  signingKey?: Secret
+
+  /**
+   * The password of an encrypted signingKey.
+   */
+  signingKeyPassword?: Secret
+
+  /**
+   * A PEM encoded certificate for signingKey, followed by its chain, to attach to the signature.
+   */
+  signingCertificate?: string
 }
 
 export type ContainerTerminalOpts = {
@@ -302,6 +461,33 @@ export type ContainerWithExecOpts = {
    * Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
    */
   insecureRootCapabilities?: boolean
+
+  /**
+   * Exit codes this command is allowed to exit with without error
+   */
+  expect?: ReturnType
+
+  /**
+   * Maximum duration the command may run for before it is killed and the exec fails (e.g., "30s", "5m").
+   */
+  timeout?: string
+
+  /**
+   * Maximum amount of memory the command may use (e.g., "512m", "2g").
+   */
+  memoryLimit?: string
+
+  /**
+   * Maximum number of CPUs the command may use (e.g., "0.5", "2").
+   */
+  cpuQuota?: string
+
+  /**
+   * Maximum number of processes the command may create.
+   *
+   * If zero, no limit is applied.
+   */
+  pidsLimit?: number
 }
 
 export type ContainerWithExposedPortOpts = {
@@ -432,6 +618,18 @@ export type ContainerWithNewFileOpts = {
   owner?: string
 }
 
+export type ContainerWithSbomOpts = {
+  /**
+   * An SPDX JSON document describing the container's contents.
+   */
+  sbom?: File
+
+  /**
+   * A BuildKit SBOM scanner image to generate the SBOM with, if none is given.
+   */
+  scanner?: string
+}
+
 export type ContainerWithUnixSocketOpts = {
   /**
    * A user:group to set for the mounted socket.
@@ -479,6 +677,73 @@ export type CurrentModuleWorkdirOpts = {
  */
 export type CurrentModuleID = string & { __CurrentModuleID: never }
 
+export type DaggerEngineCacheEntrySetOpts = {
+  /**
+   * Only include entries whose description matches this glob, where "*"
+   *
+   * matches any sequence of characters (e.g. "pulled from *alpine*").
+   */
+  description?: string
+
+  /**
+   * Only include entries of this type.
+   */
+  type?: DaggerEngineCacheEntryType
+
+  /**
+   * Only include entries that are (or aren't) actively being used.
+   */
+  inUse?: boolean
+
+  /**
+   * Only include entries using at least this many bytes.
+   */
+  minSize?: number
+
+  /**
+   * Only include entries last used before this time, given as an RFC 3339
+   *
+   * timestamp or a duration ago (e.g. "24h" or "7d").
+   */
+  lastUsedBefore?: string
+
+  /**
+   * The order to list entries in.
+   */
+  sortBy?: DaggerEngineCacheEntrySortBy
+
+  /**
+   * The maximum number of entries to include.
+   */
+  limit?: number
+}
+
+export type DaggerEngineCachePruneOpts = {
+  /**
+   * Only prune entries that have not been used for at least this long,
+   *
+   * e.g. "24h" or "7d".
+   */
+  olderThan?: string
+
+  /**
+   * Only prune entries of this type.
+   */
+  type?: DaggerEngineCacheEntryType
+
+  /**
+   * Only prune entries using at least this many bytes.
+   */
+  minSize?: number
+
+  /**
+   * Only prune while the cache uses more than this many bytes, starting
+   *
+   * with the least recently used entries.
+   */
+  keepBytes?: number
+}
+
 /**
  * The `DaggerEngineCacheEntryID` scalar type represents an identifier for an object of type DaggerEngineCacheEntry.
  */
@@ -486,6 +751,18 @@ export type DaggerEngineCacheEntryID = string & {
   __DaggerEngineCacheEntryID: never
 }
 
+export type DaggerEngineCacheEntrySetEntriesOpts = {
+  /**
+   * The number of entries to skip.
+   */
+  offset?: number
+
+  /**
+   * The maximum number of entries to include.
+   */
+  limit?: number
+}
+
 /**
  * The `DaggerEngineCacheEntrySetID` scalar type represents an identifier for an object of type DaggerEngineCacheEntrySet.
  */
@@ -493,6 +770,64 @@ export type DaggerEngineCacheEntrySetID = string & {
   __DaggerEngineCacheEntrySetID: never
 }
 
+/**
+ * The order to list cache entries in.
+ */
+export enum DaggerEngineCacheEntrySortBy {
+  /**
+   * Oldest entries first
+   */
+  Created = "CREATED",
+
+  /**
+   * Least recently used entries first
+   */
+  LastUsed = "LAST_USED",
+
+  /**
+   * Largest entries first
+   */
+  Size = "SIZE",
+}
+/**
+ * The kind of data held by a cache entry.
+ */
+export enum DaggerEngineCacheEntryType {
+  /**
+   * The contents of a cache volume mounted into an exec
+   */
+  CacheMount = "CACHE_MOUNT",
+
+  /**
+   * A filesystem snapshot produced by an exec
+   */
+  Exec = "EXEC",
+
+  /**
+   * A checkout of a git repository
+   */
+  GitCheckout = "GIT_CHECKOUT",
+
+  /**
+   * A layer of a pulled container image
+   */
+  ImageLayer = "IMAGE_LAYER",
+
+  /**
+   * Internal state of the engine
+   */
+  Internal = "INTERNAL",
+
+  /**
+   * Files loaded from a client's host
+   */
+  LocalImport = "LOCAL_IMPORT",
+
+  /**
+   * Any other data, such as files created through the API
+   */
+  Other = "OTHER",
+}
 /**
  * The `DaggerEngineCacheID` scalar type represents an identifier for an object of type DaggerEngineCache.
  */
@@ -503,6 +838,32 @@ export type DaggerEngineCacheID = string & { __DaggerEngineCacheID: never }
  */
 export type DaggerEngineID = string & { __DaggerEngineID: never }
 
+/**
+ * Format of a file containing structured data.
+ */
+export enum DataFormat {
+  /**
+   * JSON, e.g. package.json
+   */
+  JsonFormat = "JSON_FORMAT",
+
+  /**
+   * TOML, e.g. Cargo.toml
+   */
+  TomlFormat = "TOML_FORMAT",
+
+  /**
+   * YAML, e.g. Chart.yaml. Only the first document of a stream is read.
+   */
+  YamlFormat = "YAML_FORMAT",
+}
+export type DirectoryAsGitCommitOpts = {
+  /**
+   * The commit to build on. If not set, a new repository is created.
+   */
+  parent?: GitRef
+}
+
 export type DirectoryAsModuleOpts = {
   /**
    * An optional subpath of the directory which contains the module's configuration file.
@@ -574,6 +935,25 @@ export type DirectoryPipelineOpts = {
   labels?: PipelineLabel[]
 }
 
+export type DirectorySearchOpts = {
+  /**
+   * Interpret the pattern as a regular expression, using RE2 syntax (e.g., "TODO\(\w+\)").
+   */
+  regex?: boolean
+
+  /**
+   * Only search files matching these patterns (e.g., ["**/*.go", "!vendor"]).
+   *
+   * If every pattern is an exclusion, all other files are searched.
+   */
+  globs?: string[]
+
+  /**
+   * Stop after this many matches. Zero means no limit.
+   */
+  maxResults?: number
+}
+
 export type DirectoryTerminalOpts = {
   /**
    * If set, override the container's default terminal command and invoke these command arguments instead.
@@ -638,6 +1018,30 @@ export type DirectoryWithNewFileOpts = {
   permissions?: number
 }
 
+/**
+ * The `DirectoryChangeID` scalar type represents an identifier for an object of type DirectoryChange.
+ */
+export type DirectoryChangeID = string & { __DirectoryChangeID: never }
+
+/**
+ * The kind of a change between two directories.
+ */
+export enum DirectoryChangeKind {
+  /**
+   * The path only exists in the other directory
+   */
+  Added = "ADDED",
+
+  /**
+   * The path only exists in this directory
+   */
+  Deleted = "DELETED",
+
+  /**
+   * The path exists in both directories with a different content, type or permissions
+   */
+  Modified = "MODIFIED",
+}
 /**
  * The `DirectoryID` scalar type represents an identifier for an object of type Directory.
  */
@@ -663,6 +1067,13 @@ export type EnvVariableID = string & { __EnvVariableID: never }
  */
 export type FieldTypeDefID = string & { __FieldTypeDefID: never }
 
+export type FileAsJsonOpts = {
+  /**
+   * Format of the file. Inferred from its extension if not set.
+   */
+  format?: DataFormat
+}
+
 export type FileDigestOpts = {
   /**
    * If true, exclude metadata from the digest.
@@ -677,6 +1088,20 @@ export type FileExportOpts = {
   allowParentDirPath?: boolean
 }
 
+export type FileQueryOpts = {
+  /**
+   * Format of the file. Inferred from its extension if not set.
+   */
+  format?: DataFormat
+}
+
+export type FileWithReplacedOpts = {
+  /**
+   * Replace all occurrences instead of only the first one.
+   */
+  all?: boolean
+}
+
 /**
  * The `FileID` scalar type represents an identifier for an object of type File.
  */
@@ -721,16 +1146,76 @@ export type FunctionID = string & { __FunctionID: never }
  */
 export type GeneratedCodeID = string & { __GeneratedCodeID: never }
 
+/**
+ * The `GitActorID` scalar type represents an identifier for an object of type GitActor.
+ */
+export type GitActorID = string & { __GitActorID: never }
+
+/**
+ * The `GitCommitID` scalar type represents an identifier for an object of type GitCommit.
+ */
+export type GitCommitID = string & { __GitCommitID: never }
+
 /**
  * The `GitModuleSourceID` scalar type represents an identifier for an object of type GitModuleSource.
  */
 export type GitModuleSourceID = string & { __GitModuleSourceID: never }
 
+export type GitRefPushOpts = {
+  /**
+   * Update the ref even if the commit does not descend from its current commit.
+   */
+  force?: boolean
+
+  /**
+   * Set SSH known hosts
+   */
+  sshKnownHosts?: string
+
+  /**
+   * Set SSH auth socket
+   */
+  sshAuthSocket?: Socket
+
+  /**
+   * Secret used to populate the password during basic HTTP Authorization
+   */
+  authToken?: Secret
+
+  /**
+   * Secret used to populate the Authorization HTTP header
+   */
+  authHeader?: Secret
+}
+
+export type GitRefTreeOpts = {
+  /**
+   * Only check out these paths (e.g., ["docs", "go.mod"]).
+   *
+   * Paths are relative to the root of the repository.
+   */
+  sparse?: string[]
+}
+
 /**
  * The `GitRefID` scalar type represents an identifier for an object of type GitRef.
  */
 export type GitRefID = string & { __GitRefID: never }
 
+export type GitRepositoryBranchesOpts = {
+  /**
+   * Glob patterns (e.g., "refs/heads/release/*").
+   */
+  patterns?: string[]
+}
+
+export type GitRepositoryRefsOpts = {
+  /**
+   * Glob patterns (e.g., "refs/pull/*").
+   */
+  patterns?: string[]
+}
+
 export type GitRepositoryTagsOpts = {
   /**
    * Glob patterns (e.g., "refs/tags/v*").
@@ -743,6 +1228,18 @@ export type GitRepositoryTagsOpts = {
  */
 export type GitRepositoryID = string & { __GitRepositoryID: never }
 
+export type HTTPHeader = {
+  /**
+   * The header name.
+   */
+  name: string
+
+  /**
+   * The header value.
+   */
+  value: string
+}
+
 export type HostDirectoryOpts = {
   /**
    * Exclude artifacts that match the given pattern (e.g., ["node_modules/", ".git*"]).
@@ -771,6 +1268,15 @@ export type HostServiceOpts = {
   ports: PortForward[]
 }
 
+export type HostSetSecretFileOpts = {
+  /**
+   * How long the file's contents are valid for (e.g., "15m").
+   *
+   * Once expired, the file is read again from the host.
+   */
+  ttl?: string
+}
+
 export type HostTunnelOpts = {
   /**
    * Configure explicit port forwarding rules for the tunnel.
@@ -791,11 +1297,23 @@ export type HostTunnelOpts = {
   native?: boolean
 }
 
-/**
- * The `HostID` scalar type represents an identifier for an object of type Host.
- */
-export type HostID = string & { __HostID: never }
-
+/**
+ * The `HostID` scalar type represents an identifier for an object of type Host.
+ */
+export type HostID = string & { __HostID: never }
+
+export type ImageAnnotation = {
+  /**
+   * The annotation name, e.g. org.opencontainers.image.source.
+   */
+  name: string
+
+  /**
+   * The annotation value.
+   */
+  value: string
+}
+
 /**
  * Compression algorithm to use for image layers.
  */
@@ -943,6 +1461,15 @@ export type PortForward = {
  */
 export type PortID = string & { __PortID: never }
 
+export type ClientCacheVolumeOpts = {
+  /**
+   * Who the cache volume is shared with. Defaults to MODULE_SCOPE within a
+   *
+   * module, and GLOBAL_SCOPE otherwise.
+   */
+  scope?: CacheVolumeScope
+}
+
 export type ClientContainerOpts = {
   /**
    * Platform to initialize the container with.
@@ -956,6 +1483,18 @@ export type ClientGitOpts = {
    */
   keepGitDir?: boolean
 
+  /**
+   * Depth of history to fetch.
+   *
+   * Set to 0 to fetch the full history.
+   */
+  depth?: number
+
+  /**
+   * Set to false to skip checking out submodules.
+   */
+  withSubmodules?: boolean
+
   /**
    * A service which must be started before the repo is fetched.
    */
@@ -973,6 +1512,35 @@ export type ClientGitOpts = {
 }
 
 export type ClientHttpOpts = {
+  /**
+   * Expected digest of the content (e.g., "sha256:...").
+   *
+   * The download fails if the content doesn't match, and is skipped if
+   *
+   * content with the digest is already cached.
+   */
+  checksum?: string
+
+  /**
+   * Secret used to populate the Authorization HTTP header
+   */
+  authHeader?: Secret
+
+  /**
+   * Additional HTTP headers to send with the request.
+   */
+  headers?: HTTPHeader[]
+
+  /**
+   * File name of the downloaded file.
+   */
+  name?: string
+
+  /**
+   * Permission given to the downloaded file (e.g., 0600).
+   */
+  permissions?: number
+
   /**
    * A service which must be started before the URL is fetched.
    */
@@ -1009,11 +1577,49 @@ export type ClientSecretOpts = {
   accessor?: string
 }
 
+export type ClientSetSecretOpts = {
+  /**
+   * How long the plaintext is valid for (e.g., "15m").
+   *
+   * Once expired, the secret's value is fetched again from the client that set it, by asking its session for the secret's source, or for the secret with this name if it has none. Clients connected through the dagger CLI support this; Dagger Functions get an error.
+   */
+  ttl?: string
+
+  /**
+   * Where the client resolves the secret's value from once its ttl expires (e.g., "env:GITHUB_TOKEN", "file:./token.txt" or "cmd:gh auth token").
+   */
+  source?: string
+}
+
+/**
+ * Expected return type of an execution
+ */
+export enum ReturnType {
+  /**
+   * Any execution (exit codes 0-127)
+   */
+  Any = "ANY",
+
+  /**
+   * A failed execution (exit codes 1-127)
+   */
+  Failure = "FAILURE",
+
+  /**
+   * A successful execution (exit code 0)
+   */
+  Success = "SUCCESS",
+}
 /**
  * The `ScalarTypeDefID` scalar type represents an identifier for an object of type ScalarTypeDef.
  */
 export type ScalarTypeDefID = string & { __ScalarTypeDefID: never }
 
+/**
+ * The `SearchMatchID` scalar type represents an identifier for an object of type SearchMatch.
+ */
+export type SearchMatchID = string & { __SearchMatchID: never }
+
 /**
  * The `SecretID` scalar type represents an identifier for an object of type Secret.
  */
@@ -1031,6 +1637,18 @@ export type ServiceEndpointOpts = {
   scheme?: string
 }
 
+export type ServiceLogsOpts = {
+  /**
+   * Only return output written after this time, as either a duration relative to now (e.g., "5m") or an RFC 3339 timestamp.
+   */
+  since?: string
+
+  /**
+   * Stream output to the caller until the service exits, then return all of it.
+   */
+  follow?: boolean
+}
+
 export type ServiceStopOpts = {
   /**
    * Immediately kill the service without waiting for a graceful exit
@@ -1052,11 +1670,103 @@ export type ServiceUpOpts = {
   random?: boolean
 }
 
+export type ServiceWithExecHealthcheckOpts = {
+  /**
+   * How long to wait between attempts (e.g., "1s").
+   */
+  interval?: string
+
+  /**
+   * How long a single attempt may take before it fails (e.g., "10s").
+   */
+  timeout?: string
+
+  /**
+   * How many times to retry a failed attempt before giving up.
+   */
+  retries?: number
+}
+
+export type ServiceWithHttphealthcheckOpts = {
+  /**
+   * The path to request.
+   */
+  path?: string
+
+  /**
+   * The HTTP status code that indicates the service is ready.
+   */
+  expectedStatus?: number
+
+  /**
+   * How long to wait between attempts (e.g., "1s").
+   */
+  interval?: string
+
+  /**
+   * How long a single attempt may take before it fails (e.g., "10s").
+   */
+  timeout?: string
+
+  /**
+   * How many times to retry a failed attempt before giving up.
+   */
+  retries?: number
+}
+
 /**
  * The `ServiceID` scalar type represents an identifier for an object of type Service.
  */
 export type ServiceID = string & { __ServiceID: never }
 
+/**
+ * When to restart a service that exits on its own.
+ */
+export enum ServiceRestartPolicy {
+  /**
+   * Restart the service whenever it exits without being stopped
+   */
+  Always = "ALWAYS",
+
+  /**
+   * Never restart the service
+   */
+  Never = "NEVER",
+
+  /**
+   * Restart the service when it exits with a non-zero exit code
+   */
+  OnFailure = "ON_FAILURE",
+}
+/**
+ * The lifecycle state of a service.
+ */
+export enum ServiceStatus {
+  /**
+   * The service exited without being stopped and was not restarted
+   */
+  Crashed = "CRASHED",
+
+  /**
+   * The service exited and is being restarted according to its restart policy
+   */
+  Restarting = "RESTARTING",
+
+  /**
+   * The service is running
+   */
+  Running = "RUNNING",
+
+  /**
+   * The service is starting and waiting for its health checks to pass
+   */
+  Starting = "STARTING",
+
+  /**
+   * The service is not running
+   */
+  Stopped = "STOPPED",
+}
 /**
  * The `SocketID` scalar type represents an identifier for an object of type Socket.
  */
@@ -1189,6 +1899,11 @@ export type __TypeFieldsOpts = {
  */
 export class CacheVolume extends BaseClient {
   private readonly _id?: CacheVolumeID = undefined
+  private readonly _clear?: Void = undefined
+  private readonly _namespace?: string = undefined
+  private readonly _scope?: CacheVolumeScope = undefined
+  private readonly _seed?: Void = undefined
+  private readonly _size?: number = undefined
 
   /**
    * Constructor is used for internal usage only, do not create object from it.
@@ -1196,10 +1911,20 @@ export class CacheVolume extends BaseClient {
   constructor(
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: CacheVolumeID,
+    _clear?: Void,
+    _namespace?: string,
+    _scope?: CacheVolumeScope,
+    _seed?: Void,
+    _size?: number,
   ) {
     super(parent)
 
     this._id = _id
+    this._clear = _clear
+    this._namespace = _namespace
+    this._scope = _scope
+    this._seed = _seed
+    this._size = _size
   }
 
   /**
@@ -1222,6 +1947,130 @@ export class CacheVolume extends BaseClient {
 
     return response
   }
+
+  /**
+   * Removes the contents of the cache volume.
+   *
+   * Containers currently using the cache volume will see it emptied.
+   */
+  clear = async (): Promise<void> => {
+    if (this._clear) {
+      return
+    }
+
+    await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "clear",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+  }
+
+  /**
+   * The namespace of the cache volume, derived from its scope. Empty for global cache volumes.
+   */
+  namespace_ = async (): Promise<string> => {
+    if (this._namespace) {
+      return this._namespace
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "namespace",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The scope the cache volume was created with.
+   */
+  scope = async (): Promise<CacheVolumeScope> => {
+    if (this._scope) {
+      return this._scope
+    }
+
+    const response: Awaited<CacheVolumeScope> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "scope",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * Replaces the contents of the cache volume with the contents of the given directory.
+   *
+   * Containers currently using the cache volume will see the new contents.
+   * @param source Directory to copy into the cache volume.
+   */
+  seed = async (source: Directory): Promise<void> => {
+    if (this._seed) {
+      return
+    }
+
+    await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "seed",
+          args: { source },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+  }
+
+  /**
+   * The disk space used by the cache volume, in bytes.
+   */
+  size = async (): Promise<number> => {
+    if (this._size) {
+      return this._size
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "size",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * Returns a copy of the current contents of the cache volume.
+   *
+   * Changes to the cache volume after the snapshot is taken are not reflected in the returned directory.
+   */
+  snapshot = (): Directory => {
+    return new Directory({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "snapshot",
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
 }
 
 /**
@@ -1230,6 +2079,7 @@ export class CacheVolume extends BaseClient {
 export class Container extends BaseClient {
   private readonly _id?: ContainerID = undefined
   private readonly _envVariable?: string = undefined
+  private readonly _exitCode?: number = undefined
   private readonly _export?: string = undefined
   private readonly _imageRef?: string = undefined
   private readonly _label?: string = undefined
@@ -1248,6 +2098,7 @@ export class Container extends BaseClient {
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: ContainerID,
     _envVariable?: string,
+    _exitCode?: number,
     _export?: string,
     _imageRef?: string,
     _label?: string,
@@ -1263,6 +2114,7 @@ export class Container extends BaseClient {
 
     this._id = _id
     this._envVariable = _envVariable
+    this._exitCode = _exitCode
     this._export = _export
     this._imageRef = _imageRef
     this._label = _label
@@ -1300,13 +2152,22 @@ export class Container extends BaseClient {
    * Turn the container into a Service.
    *
    * Be sure to set any exposed ports before this conversion.
+   * @param opts.restartPolicy When to restart the service if it exits without being stopped.
+   * @param opts.maxRetries The maximum number of times to restart the service with the ON_FAILURE restart policy.
+   *
+   * Zero means no limit.
    */
-  asService = (): Service => {
+  asService = (opts?: ContainerAsServiceOpts): Service => {
+    const metadata: Metadata = {
+      restartPolicy: { is_enum: true },
+    }
+
     return new Service({
       queryTree: [
         ...this._queryTree,
         {
           operation: "asService",
+          args: { ...opts, __metadata: metadata },
         },
       ],
       ctx: this._ctx,
@@ -1321,9 +2182,16 @@ export class Container extends BaseClient {
    * @param opts.forcedCompression Force each layer of the image to use the specified compression algorithm.
    *
    * If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
+   * @param opts.compressionLevel The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
    * @param opts.mediaTypes Use the specified media types for the image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
+   * @param opts.provenance Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   * @param opts.annotations OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   * @param opts.annotateFromLabels Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   * @param opts.indexAnnotations OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
    */
   asTarball = (opts?: ContainerAsTarballOpts): File => {
     const metadata: Metadata = {
@@ -1465,21 +2333,44 @@ export class Container extends BaseClient {
       await this._ctx.connection(),
     )
 
-    return response.map(
-      (r) =>
-        new EnvVariable(
-          {
-            queryTree: [
-              {
-                operation: "loadEnvVariableFromID",
-                args: { id: r.id },
-              },
-            ],
-            ctx: this._ctx,
-          },
-          r.id,
-        ),
-    )
+    return response.map(
+      (r) =>
+        new EnvVariable(
+          {
+            queryTree: [
+              {
+                operation: "loadEnvVariableFromID",
+                args: { id: r.id },
+              },
+            ],
+            ctx: this._ctx,
+          },
+          r.id,
+        ),
+    )
+  }
+
+  /**
+   * The exit code of the last executed command.
+   *
+   * Returns an error if no command was set.
+   */
+  exitCode = async (): Promise<number> => {
+    if (this._exitCode) {
+      return this._exitCode
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "exitCode",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
   }
 
   /**
@@ -1535,9 +2426,16 @@ export class Container extends BaseClient {
    * @param opts.forcedCompression Force each layer of the exported image to use the specified compression algorithm.
    *
    * If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
+   * @param opts.compressionLevel The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
    * @param opts.mediaTypes Use the specified media types for the exported image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent container runtimes, but Docker may be needed for older runtimes without OCI support.
+   * @param opts.provenance Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   * @param opts.annotations OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   * @param opts.annotateFromLabels Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   * @param opts.indexAnnotations OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
    */
   export = async (
     path: string,
@@ -1630,14 +2528,20 @@ export class Container extends BaseClient {
    * @param address Image's address from its registry.
    *
    * Formatted as [host]/[user]/[repo]:[tag] (e.g., "docker.io/dagger/dagger:main").
+   * @param opts.verify A PEM encoded public key or certificate to verify the image's signature with, as pushed by cosign or Container.publish.
+   *
+   * The image must have a valid signature made with the matching private key.
+   * @param opts.requireDigest Require the address to be pinned to a digest (e.g., "alpine@sha256:...").
+   *
+   * Unpinned images can be pinned to the digest they resolve to with imageRef.
    */
-  from = (address: string): Container => {
+  from = (address: string, opts?: ContainerFromOpts): Container => {
     return new Container({
       queryTree: [
         ...this._queryTree,
         {
           operation: "from",
-          args: { address },
+          args: { address, ...opts },
         },
       ],
       ctx: this._ctx,
@@ -1646,6 +2550,8 @@ export class Container extends BaseClient {
 
   /**
    * The unique image reference which can only be retrieved immediately after the 'Container.From' call.
+   *
+   * It's the address the container was initialized from, pinned to the digest it resolved to, even if the engine pulled it from a mirror.
    */
   imageRef = async (): Promise<string> => {
     if (this._imageRef) {
@@ -1817,9 +2723,24 @@ export class Container extends BaseClient {
    * @param opts.forcedCompression Force each layer of the published image to use the specified compression algorithm.
    *
    * If this is unset, then if a layer already has a compressed blob in the engine's cache, that will be used (this can result in a mix of compression algorithms for different layers). If this is unset and a layer has no compressed blob in the engine's cache, then it will be compressed using Gzip.
+   * @param opts.compressionLevel The level to compress layers at with forcedCompression, e.g. 1-22 for Zstd. Requires forcedCompression.
    * @param opts.mediaTypes Use the specified media types for the published image's layers.
    *
    * Defaults to OCI, which is largely compatible with most recent registries, but Docker may be needed for older registries without OCI support.
+   * @param opts.provenance Attach SLSA provenance derived from the calls that produced the container and its platform variants to the image.
+   * @param opts.annotations OCI annotations to set on the image's manifests.
+   *
+   * Annotations require OCI media types.
+   * @param opts.annotateFromLabels Also set the org.opencontainers.image.* labels of the container and of each platform variant as annotations on their own manifest, unless overridden by annotations. Requires OCI media types.
+   * @param opts.indexAnnotations OCI annotations to set on the image index, which only exists for multi-platform images or images with attestations.
+   * @param opts.tags Additional tags to push the image to, in the repository of address (e.g. ["v1.2.3", "latest"]).
+   * @param opts.signingKey Sign the published image with this PEM encoded private key, pushing the signature next to it in a format cosign can verify.
+   *
+   * Keys encrypted by cosign generate-key-pair are supported.
+   *
+   * The signature isn't recorded in a transparency log, so verifying it with cosign verify --key requires --insecure-ignore-tlog.
+   * @param opts.signingKeyPassword The password of an encrypted signingKey.
+   * @param opts.signingCertificate A PEM encoded certificate for signingKey, followed by its chain, to attach to the signature.
    */
   publish = async (
     address: string,
@@ -2106,14 +3027,25 @@ export class Container extends BaseClient {
    *
    * Do not use this option unless you trust the command being executed; the command being executed WILL BE GRANTED FULL ACCESS TO YOUR HOST FILESYSTEM.
    * @param opts.insecureRootCapabilities Execute the command with all root capabilities. This is similar to running a command with "sudo" or executing "docker run" with the "--privileged" flag. Containerization does not provide any security guarantees when using this option. It should only be used when absolutely necessary and only with trusted commands.
+   * @param opts.expect Exit codes this command is allowed to exit with without error
+   * @param opts.timeout Maximum duration the command may run for before it is killed and the exec fails (e.g., "30s", "5m").
+   * @param opts.memoryLimit Maximum amount of memory the command may use (e.g., "512m", "2g").
+   * @param opts.cpuQuota Maximum number of CPUs the command may use (e.g., "0.5", "2").
+   * @param opts.pidsLimit Maximum number of processes the command may create.
+   *
+   * If zero, no limit is applied.
    */
   withExec = (args: string[], opts?: ContainerWithExecOpts): Container => {
+    const metadata: Metadata = {
+      expect: { is_enum: true },
+    }
+
     return new Container({
       queryTree: [
         ...this._queryTree,
         {
           operation: "withExec",
-          args: { args, ...opts },
+          args: { args, ...opts, __metadata: metadata },
         },
       ],
       ctx: this._ctx,
@@ -2448,6 +3380,26 @@ export class Container extends BaseClient {
     })
   }
 
+  /**
+   * Retrieves this container with an SPDX SBOM attached to its image as an attestation when it's published or exported.
+   *
+   * If no SBOM is given, one is generated by scanning the container's root filesystem when its image is published or exported, like `docker buildx build --sbom` does.
+   * @param opts.sbom An SPDX JSON document describing the container's contents.
+   * @param opts.scanner A BuildKit SBOM scanner image to generate the SBOM with, if none is given.
+   */
+  withSBOM = (opts?: ContainerWithSbomOpts): Container => {
+    return new Container({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withSBOM",
+          args: { ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Retrieves this container plus an env variable containing the given secret.
    * @param name The name of the secret variable (e.g., "API_SECRET").
@@ -2729,6 +3681,21 @@ export class Container extends BaseClient {
     })
   }
 
+  /**
+   * Retrieves this container without an SBOM attached to its image.
+   */
+  withoutSBOM = (): Container => {
+    return new Container({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withoutSBOM",
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Retrieves this container minus the given environment variable containing the secret.
    * @param name The name of the environment variable (e.g., "HOST").
@@ -3045,13 +4012,32 @@ export class DaggerEngineCache extends BaseClient {
 
   /**
    * The current set of entries in the cache
+   * @param opts.description Only include entries whose description matches this glob, where "*"
+   *
+   * matches any sequence of characters (e.g. "pulled from *alpine*").
+   * @param opts.type Only include entries of this type.
+   * @param opts.inUse Only include entries that are (or aren't) actively being used.
+   * @param opts.minSize Only include entries using at least this many bytes.
+   * @param opts.lastUsedBefore Only include entries last used before this time, given as an RFC 3339
+   *
+   * timestamp or a duration ago (e.g. "24h" or "7d").
+   * @param opts.sortBy The order to list entries in.
+   * @param opts.limit The maximum number of entries to include.
    */
-  entrySet = (): DaggerEngineCacheEntrySet => {
+  entrySet = (
+    opts?: DaggerEngineCacheEntrySetOpts,
+  ): DaggerEngineCacheEntrySet => {
+    const metadata: Metadata = {
+      type_: { is_enum: true },
+      sortBy: { is_enum: true },
+    }
+
     return new DaggerEngineCacheEntrySet({
       queryTree: [
         ...this._queryTree,
         {
           operation: "entrySet",
+          args: { ...opts, __metadata: metadata },
         },
       ],
       ctx: this._ctx,
@@ -3081,17 +4067,30 @@ export class DaggerEngineCache extends BaseClient {
 
   /**
    * Prune the cache of releaseable entries
+   * @param opts.olderThan Only prune entries that have not been used for at least this long,
+   *
+   * e.g. "24h" or "7d".
+   * @param opts.type Only prune entries of this type.
+   * @param opts.minSize Only prune entries using at least this many bytes.
+   * @param opts.keepBytes Only prune while the cache uses more than this many bytes, starting
+   *
+   * with the least recently used entries.
    */
-  prune = async (): Promise<void> => {
+  prune = async (opts?: DaggerEngineCachePruneOpts): Promise<void> => {
     if (this._prune) {
       return
     }
 
+    const metadata: Metadata = {
+      type_: { is_enum: true },
+    }
+
     await computeQuery(
       [
         ...this._queryTree,
         {
           operation: "prune",
+          args: { ...opts, __metadata: metadata },
         },
       ],
       await this._ctx.connection(),
@@ -3105,10 +4104,13 @@ export class DaggerEngineCache extends BaseClient {
 export class DaggerEngineCacheEntry extends BaseClient {
   private readonly _id?: DaggerEngineCacheEntryID = undefined
   private readonly _activelyUsed?: boolean = undefined
+  private readonly _cacheMountKey?: string = undefined
+  private readonly _cacheMountNamespace?: string = undefined
   private readonly _createdTimeUnixNano?: number = undefined
   private readonly _description?: string = undefined
   private readonly _diskSpaceBytes?: number = undefined
   private readonly _mostRecentUseTimeUnixNano?: number = undefined
+  private readonly _recordType?: DaggerEngineCacheEntryType = undefined
 
   /**
    * Constructor is used for internal usage only, do not create object from it.
@@ -3117,19 +4119,25 @@ export class DaggerEngineCacheEntry extends BaseClient {
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: DaggerEngineCacheEntryID,
     _activelyUsed?: boolean,
+    _cacheMountKey?: string,
+    _cacheMountNamespace?: string,
     _createdTimeUnixNano?: number,
     _description?: string,
     _diskSpaceBytes?: number,
     _mostRecentUseTimeUnixNano?: number,
+    _recordType?: DaggerEngineCacheEntryType,
   ) {
     super(parent)
 
     this._id = _id
     this._activelyUsed = _activelyUsed
+    this._cacheMountKey = _cacheMountKey
+    this._cacheMountNamespace = _cacheMountNamespace
     this._createdTimeUnixNano = _createdTimeUnixNano
     this._description = _description
     this._diskSpaceBytes = _diskSpaceBytes
     this._mostRecentUseTimeUnixNano = _mostRecentUseTimeUnixNano
+    this._recordType = _recordType
   }
 
   /**
@@ -3174,6 +4182,48 @@ export class DaggerEngineCacheEntry extends BaseClient {
     return response
   }
 
+  /**
+   * The key of the cache volume owning the cache entry, if it is a cache mount. Only known for cache volumes mounted since the engine started.
+   */
+  cacheMountKey = async (): Promise<string> => {
+    if (this._cacheMountKey) {
+      return this._cacheMountKey
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "cacheMountKey",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The namespace of the cache volume owning the cache entry, derived from its scope. Empty for global cache volumes. Only known for cache volumes mounted since the engine started.
+   */
+  cacheMountNamespace = async (): Promise<string> => {
+    if (this._cacheMountNamespace) {
+      return this._cacheMountNamespace
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "cacheMountNamespace",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * The time the cache entry was created, in Unix nanoseconds.
    */
@@ -3257,6 +4307,27 @@ export class DaggerEngineCacheEntry extends BaseClient {
 
     return response
   }
+
+  /**
+   * The type of data held by the cache entry.
+   */
+  recordType = async (): Promise<DaggerEngineCacheEntryType> => {
+    if (this._recordType) {
+      return this._recordType
+    }
+
+    const response: Awaited<DaggerEngineCacheEntryType> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "recordType",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
 }
 
 /**
@@ -3327,8 +4398,12 @@ export class DaggerEngineCacheEntrySet extends BaseClient {
 
   /**
    * The list of individual cache entries in the set
+   * @param opts.offset The number of entries to skip.
+   * @param opts.limit The maximum number of entries to include.
    */
-  entries = async (): Promise<DaggerEngineCacheEntry[]> => {
+  entries = async (
+    opts?: DaggerEngineCacheEntrySetEntriesOpts,
+  ): Promise<DaggerEngineCacheEntry[]> => {
     type entries = {
       id: DaggerEngineCacheEntryID
     }
@@ -3338,6 +4413,7 @@ export class DaggerEngineCacheEntrySet extends BaseClient {
         ...this._queryTree,
         {
           operation: "entries",
+          args: { ...opts },
         },
         {
           operation: "id",
@@ -3430,6 +4506,31 @@ export class Directory extends BaseClient {
     return response
   }
 
+  /**
+   * Commits the contents of this directory to a new git commit.
+   *
+   * Any .git directory in it is ignored.
+   * @param message The commit message.
+   * @param author The author and committer of the commit (e.g., "Jane Doe <jane@example.com>").
+   * @param opts.parent The commit to build on. If not set, a new repository is created.
+   */
+  asGitCommit = (
+    message: string,
+    author: string,
+    opts?: DirectoryAsGitCommitOpts,
+  ): GitRef => {
+    return new GitRef({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "asGitCommit",
+          args: { message, author, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Load the directory as a Dagger module
    * @param opts.sourceRootPath An optional subpath of the directory which contains the module's configuration file.
@@ -3452,6 +4553,48 @@ export class Directory extends BaseClient {
     })
   }
 
+  /**
+   * Lists the paths that were added, modified or deleted in another directory compared to this one.
+   *
+   * Paths whose metadata changed but whose content and permissions are the same, like files with new timestamps, are not listed.
+   * @param other Identifier of the directory to compare.
+   */
+  changes = async (other: Directory): Promise<DirectoryChange[]> => {
+    type changes = {
+      id: DirectoryChangeID
+    }
+
+    const response: Awaited<changes[]> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "changes",
+          args: { other },
+        },
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response.map(
+      (r) =>
+        new DirectoryChange(
+          {
+            queryTree: [
+              {
+                operation: "loadDirectoryChangeFromID",
+                args: { id: r.id },
+              },
+            ],
+            ctx: this._ctx,
+          },
+          r.id,
+        ),
+    )
+  }
+
   /**
    * Gets the difference between this directory and an another directory.
    * @param other Identifier of the directory to compare.
@@ -3611,6 +4754,56 @@ export class Directory extends BaseClient {
     })
   }
 
+  /**
+   * Searches the contents of the files in this directory, returning the matching lines.
+   *
+   * Binary files are skipped and symlinks are not followed.
+   * @param pattern Text to search for, or a regular expression if regex is true.
+   * @param opts.regex Interpret the pattern as a regular expression, using RE2 syntax (e.g., "TODO\(\w+\)").
+   * @param opts.globs Only search files matching these patterns (e.g., ["**/*.go", "!vendor"]).
+   *
+   * If every pattern is an exclusion, all other files are searched.
+   * @param opts.maxResults Stop after this many matches. Zero means no limit.
+   */
+  search = async (
+    pattern: string,
+    opts?: DirectorySearchOpts,
+  ): Promise<SearchMatch[]> => {
+    type search = {
+      id: SearchMatchID
+    }
+
+    const response: Awaited<search[]> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "search",
+          args: { pattern, ...opts },
+        },
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response.map(
+      (r) =>
+        new SearchMatch(
+          {
+            queryTree: [
+              {
+                operation: "loadSearchMatchFromID",
+                args: { id: r.id },
+              },
+            ],
+            ctx: this._ctx,
+          },
+          r.id,
+        ),
+    )
+  }
+
   /**
    * Force evaluation in the engine.
    */
@@ -3711,17 +4904,82 @@ export class Directory extends BaseClient {
    * @param sources Identifiers of the files to copy.
    * @param opts.permissions Permission given to the copied files (e.g., 0600).
    */
-  withFiles = (
-    path: string,
-    sources: File[],
-    opts?: DirectoryWithFilesOpts,
-  ): Directory => {
+  withFiles = (
+    path: string,
+    sources: File[],
+    opts?: DirectoryWithFilesOpts,
+  ): Directory => {
+    return new Directory({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withFiles",
+          args: { path, sources, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Retrieves this directory plus a new directory created at the given path.
+   * @param path Location of the directory created (e.g., "/logs").
+   * @param opts.permissions Permission granted to the created directory (e.g., 0777).
+   */
+  withNewDirectory = (
+    path: string,
+    opts?: DirectoryWithNewDirectoryOpts,
+  ): Directory => {
+    return new Directory({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withNewDirectory",
+          args: { path, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Retrieves this directory plus a new file written at the given path.
+   * @param path Location of the written file (e.g., "/file.txt").
+   * @param contents Content of the written file (e.g., "Hello world!").
+   * @param opts.permissions Permission given to the copied file (e.g., 0600).
+   */
+  withNewFile = (
+    path: string,
+    contents: string,
+    opts?: DirectoryWithNewFileOpts,
+  ): Directory => {
+    return new Directory({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withNewFile",
+          args: { path, contents, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Retrieves this directory with the owner of all of its files and directories set to the given user and group.
+   * @param owner A user:group to set for the directory and its contents.
+   *
+   * The user and group can either be an ID (1000:1000) or a name (foo:bar) looked up in the directory's own etc/passwd and etc/group files.
+   *
+   * If the group is omitted, it defaults to the same as the user.
+   */
+  withOwner = (owner: string): Directory => {
     return new Directory({
       queryTree: [
         ...this._queryTree,
         {
-          operation: "withFiles",
-          args: { path, sources, ...opts },
+          operation: "withOwner",
+          args: { owner },
         },
       ],
       ctx: this._ctx,
@@ -3729,20 +4987,16 @@ export class Directory extends BaseClient {
   }
 
   /**
-   * Retrieves this directory plus a new directory created at the given path.
-   * @param path Location of the directory created (e.g., "/logs").
-   * @param opts.permissions Permission granted to the created directory (e.g., 0777).
+   * Retrieves this directory with the given patch applied.
+   * @param patch Patch in the unified diff format produced by "git diff", with paths relative to this directory.
    */
-  withNewDirectory = (
-    path: string,
-    opts?: DirectoryWithNewDirectoryOpts,
-  ): Directory => {
+  withPatch = (patch: string): Directory => {
     return new Directory({
       queryTree: [
         ...this._queryTree,
         {
-          operation: "withNewDirectory",
-          args: { path, ...opts },
+          operation: "withPatch",
+          args: { patch },
         },
       ],
       ctx: this._ctx,
@@ -3750,22 +5004,17 @@ export class Directory extends BaseClient {
   }
 
   /**
-   * Retrieves this directory plus a new file written at the given path.
-   * @param path Location of the written file (e.g., "/file.txt").
-   * @param contents Content of the written file (e.g., "Hello world!").
-   * @param opts.permissions Permission given to the copied file (e.g., 0600).
+   * Retrieves this directory plus a symlink at the given path.
+   * @param target Location the symlink points to (e.g., "../lib/libfoo.so.1"), which doesn't need to exist.
+   * @param linkName Location of the symlink to create (e.g., "lib/libfoo.so").
    */
-  withNewFile = (
-    path: string,
-    contents: string,
-    opts?: DirectoryWithNewFileOpts,
-  ): Directory => {
+  withSymlink = (target: string, linkName: string): Directory => {
     return new Directory({
       queryTree: [
         ...this._queryTree,
         {
-          operation: "withNewFile",
-          args: { path, contents, ...opts },
+          operation: "withSymlink",
+          args: { target, linkName },
         },
       ],
       ctx: this._ctx,
@@ -3835,6 +5084,214 @@ export class Directory extends BaseClient {
   }
 }
 
+/**
+ * A path that was added, modified or deleted between two directories.
+ */
+export class DirectoryChange extends BaseClient {
+  private readonly _id?: DirectoryChangeID = undefined
+  private readonly _isDirectory?: boolean = undefined
+  private readonly _kind?: DirectoryChangeKind = undefined
+  private readonly _mode?: number = undefined
+  private readonly _path?: string = undefined
+  private readonly _previousMode?: number = undefined
+  private readonly _previousSize?: number = undefined
+  private readonly _size?: number = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    parent?: { queryTree?: QueryTree[]; ctx: Context },
+    _id?: DirectoryChangeID,
+    _isDirectory?: boolean,
+    _kind?: DirectoryChangeKind,
+    _mode?: number,
+    _path?: string,
+    _previousMode?: number,
+    _previousSize?: number,
+    _size?: number,
+  ) {
+    super(parent)
+
+    this._id = _id
+    this._isDirectory = _isDirectory
+    this._kind = _kind
+    this._mode = _mode
+    this._path = _path
+    this._previousMode = _previousMode
+    this._previousSize = _previousSize
+    this._size = _size
+  }
+
+  /**
+   * A unique identifier for this DirectoryChange.
+   */
+  id = async (): Promise<DirectoryChangeID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const response: Awaited<DirectoryChangeID> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * Whether the path is a directory.
+   */
+  isDirectory = async (): Promise<boolean> => {
+    if (this._isDirectory) {
+      return this._isDirectory
+    }
+
+    const response: Awaited<boolean> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "isDirectory",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * How the path changed.
+   */
+  kind = async (): Promise<DirectoryChangeKind> => {
+    if (this._kind) {
+      return this._kind
+    }
+
+    const response: Awaited<DirectoryChangeKind> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "kind",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The permissions of the path, unless it was deleted.
+   */
+  mode = async (): Promise<number> => {
+    if (this._mode) {
+      return this._mode
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "mode",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The path that changed, relative to the directory.
+   */
+  path = async (): Promise<string> => {
+    if (this._path) {
+      return this._path
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "path",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The previous permissions of the path, unless it was added.
+   */
+  previousMode = async (): Promise<number> => {
+    if (this._previousMode) {
+      return this._previousMode
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "previousMode",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The previous size of the path in bytes, unless it was added.
+   */
+  previousSize = async (): Promise<number> => {
+    if (this._previousSize) {
+      return this._previousSize
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "previousSize",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The size of the path in bytes, unless it was deleted.
+   */
+  size = async (): Promise<number> => {
+    if (this._size) {
+      return this._size
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "size",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+}
+
 /**
  * A definition of a custom enum defined in a Module.
  */
@@ -4271,10 +5728,12 @@ export class FieldTypeDef extends BaseClient {
  */
 export class File extends BaseClient {
   private readonly _id?: FileID = undefined
+  private readonly _asJSON?: JSON = undefined
   private readonly _contents?: string = undefined
   private readonly _digest?: string = undefined
   private readonly _export?: string = undefined
   private readonly _name?: string = undefined
+  private readonly _query?: JSON = undefined
   private readonly _size?: number = undefined
   private readonly _sync?: FileID = undefined
 
@@ -4284,20 +5743,24 @@ export class File extends BaseClient {
   constructor(
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: FileID,
+    _asJSON?: JSON,
     _contents?: string,
     _digest?: string,
     _export?: string,
     _name?: string,
+    _query?: JSON,
     _size?: number,
     _sync?: FileID,
   ) {
     super(parent)
 
     this._id = _id
+    this._asJSON = _asJSON
     this._contents = _contents
     this._digest = _digest
     this._export = _export
     this._name = _name
+    this._query = _query
     this._size = _size
     this._sync = _sync
   }
@@ -4323,6 +5786,33 @@ export class File extends BaseClient {
     return response
   }
 
+  /**
+   * Parses the file as structured data and returns it as JSON.
+   * @param opts.format Format of the file. Inferred from its extension if not set.
+   */
+  asJSON = async (opts?: FileAsJsonOpts): Promise<JSON> => {
+    if (this._asJSON) {
+      return this._asJSON
+    }
+
+    const metadata: Metadata = {
+      format: { is_enum: true },
+    }
+
+    const response: Awaited<JSON> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "asJSON",
+          args: { ...opts, __metadata: metadata },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Retrieves the contents of the file.
    */
@@ -4412,6 +5902,38 @@ export class File extends BaseClient {
     return response
   }
 
+  /**
+   * Parses the file as structured data and returns the JSON value at the given path.
+   *
+   * Returns an error if there is no value at the path.
+   * @param path Path of the value to return, in a subset of JSONPath (e.g., "version", "$.dependencies['@types/node']" or "workspaces[0]").
+   *
+   * Keys are selected with ".key" or "['key']", and array elements with "[n]", counting from the end if negative.
+   * @param opts.format Format of the file. Inferred from its extension if not set.
+   */
+  query = async (path: string, opts?: FileQueryOpts): Promise<JSON> => {
+    if (this._query) {
+      return this._query
+    }
+
+    const metadata: Metadata = {
+      format: { is_enum: true },
+    }
+
+    const response: Awaited<JSON> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "query",
+          args: { path, ...opts, __metadata: metadata },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Retrieves the size of the file, in bytes.
    */
@@ -4450,8 +5972,42 @@ export class File extends BaseClient {
     return new File({
       queryTree: [
         {
-          operation: "loadFileFromID",
-          args: { id: response },
+          operation: "loadFileFromID",
+          args: { id: response },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Retrieves this file with its name set to the given name.
+   * @param name Name to set file to.
+   */
+  withName = (name: string): File => {
+    return new File({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withName",
+          args: { name },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Retrieves this file with its permissions set to the given value.
+   * @param permissions Permissions to set on the file (e.g., 0755).
+   */
+  withPermissions = (permissions: number): File => {
+    return new File({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withPermissions",
+          args: { permissions },
         },
       ],
       ctx: this._ctx,
@@ -4459,16 +6015,24 @@ export class File extends BaseClient {
   }
 
   /**
-   * Retrieves this file with its name set to the given name.
-   * @param name Name to set file to.
+   * Retrieves this file with the first or all occurrences of the given text replaced.
+   *
+   * Fails if the text does not occur in the file.
+   * @param search Text to replace.
+   * @param replace Text to replace it with.
+   * @param opts.all Replace all occurrences instead of only the first one.
    */
-  withName = (name: string): File => {
+  withReplaced = (
+    search: string,
+    replace: string,
+    opts?: FileWithReplacedOpts,
+  ): File => {
     return new File({
       queryTree: [
         ...this._queryTree,
         {
-          operation: "withName",
-          args: { name },
+          operation: "withReplaced",
+          args: { search, replace, ...opts },
         },
       ],
       ctx: this._ctx,
@@ -5218,6 +6782,253 @@ export class GeneratedCode extends BaseClient {
   }
 }
 
+/**
+ * The author or committer of a git commit.
+ */
+export class GitActor extends BaseClient {
+  private readonly _id?: GitActorID = undefined
+  private readonly _email?: string = undefined
+  private readonly _name?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    parent?: { queryTree?: QueryTree[]; ctx: Context },
+    _id?: GitActorID,
+    _email?: string,
+    _name?: string,
+  ) {
+    super(parent)
+
+    this._id = _id
+    this._email = _email
+    this._name = _name
+  }
+
+  /**
+   * A unique identifier for this GitActor.
+   */
+  id = async (): Promise<GitActorID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const response: Awaited<GitActorID> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The email of the author or committer.
+   */
+  email = async (): Promise<string> => {
+    if (this._email) {
+      return this._email
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "email",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The name of the author or committer.
+   */
+  name = async (): Promise<string> => {
+    if (this._name) {
+      return this._name
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "name",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+}
+
+/**
+ * The metadata of a git commit.
+ */
+export class GitCommit extends BaseClient {
+  private readonly _id?: GitCommitID = undefined
+  private readonly _message?: string = undefined
+  private readonly _sha?: string = undefined
+  private readonly _timestamp?: number = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    parent?: { queryTree?: QueryTree[]; ctx: Context },
+    _id?: GitCommitID,
+    _message?: string,
+    _sha?: string,
+    _timestamp?: number,
+  ) {
+    super(parent)
+
+    this._id = _id
+    this._message = _message
+    this._sha = _sha
+    this._timestamp = _timestamp
+  }
+
+  /**
+   * A unique identifier for this GitCommit.
+   */
+  id = async (): Promise<GitCommitID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const response: Awaited<GitCommitID> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The author of the commit.
+   */
+  author = (): GitActor => {
+    return new GitActor({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "author",
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * The committer of the commit.
+   */
+  committer = (): GitActor => {
+    return new GitActor({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "committer",
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * The full commit message.
+   */
+  message = async (): Promise<string> => {
+    if (this._message) {
+      return this._message
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "message",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The SHAs of the parent commits.
+   */
+  parents = async (): Promise<string[]> => {
+    const response: Awaited<string[]> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "parents",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The SHA of the commit.
+   */
+  sha = async (): Promise<string> => {
+    if (this._sha) {
+      return this._sha
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "sha",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The time the commit was made, in Unix seconds.
+   */
+  timestamp = async (): Promise<number> => {
+    if (this._timestamp) {
+      return this._timestamp
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "timestamp",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+}
+
 /**
  * Module source originating from a git repo.
  */
@@ -5423,6 +7234,7 @@ export class GitModuleSource extends BaseClient {
 export class GitRef extends BaseClient {
   private readonly _id?: GitRefID = undefined
   private readonly _commit?: string = undefined
+  private readonly _push?: Void = undefined
 
   /**
    * Constructor is used for internal usage only, do not create object from it.
@@ -5431,11 +7243,13 @@ export class GitRef extends BaseClient {
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: GitRefID,
     _commit?: string,
+    _push?: Void,
   ) {
     super(parent)
 
     this._id = _id
     this._commit = _commit
+    this._push = _push
   }
 
   /**
@@ -5480,15 +7294,67 @@ export class GitRef extends BaseClient {
     return response
   }
 
+  /**
+   * The metadata of the resolved commit at this ref.
+   */
+  commitInfo = (): GitCommit => {
+    return new GitCommit({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "commitInfo",
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Pushes the commit at this ref to a remote repository.
+   * @param remote URL of the repository to push to.
+   * @param ref Ref to update in the remote (e.g., "main" or "refs/tags/v1.0.0").
+   *
+   * Names that are not fully-qualified are treated as branches.
+   * @param opts.force Update the ref even if the commit does not descend from its current commit.
+   * @param opts.sshKnownHosts Set SSH known hosts
+   * @param opts.sshAuthSocket Set SSH auth socket
+   * @param opts.authToken Secret used to populate the password during basic HTTP Authorization
+   * @param opts.authHeader Secret used to populate the Authorization HTTP header
+   */
+  push = async (
+    remote: string,
+    ref: string,
+    opts?: GitRefPushOpts,
+  ): Promise<void> => {
+    if (this._push) {
+      return
+    }
+
+    await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "push",
+          args: { remote, ref, ...opts },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+  }
+
   /**
    * The filesystem tree at this ref.
+   * @param opts.sparse Only check out these paths (e.g., ["docs", "go.mod"]).
+   *
+   * Paths are relative to the root of the repository.
    */
-  tree = (): Directory => {
+  tree = (opts?: GitRefTreeOpts): Directory => {
     return new Directory({
       queryTree: [
         ...this._queryTree,
         {
           operation: "tree",
+          args: { ...opts },
         },
       ],
       ctx: this._ctx,
@@ -5552,6 +7418,25 @@ export class GitRepository extends BaseClient {
     })
   }
 
+  /**
+   * branches that match any of the given glob patterns.
+   * @param opts.patterns Glob patterns (e.g., "refs/heads/release/*").
+   */
+  branches = async (opts?: GitRepositoryBranchesOpts): Promise<string[]> => {
+    const response: Awaited<string[]> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "branches",
+          args: { ...opts },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Returns details of a commit.
    * @param id Identifier of the commit (e.g., "b6315d8f2810962c601af73f86831f6866ea798b").
@@ -5593,12 +7478,31 @@ export class GitRepository extends BaseClient {
       queryTree: [
         ...this._queryTree,
         {
-          operation: "ref",
-          args: { name },
+          operation: "ref",
+          args: { name },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Fully-qualified refs that match any of the given glob patterns.
+   * @param opts.patterns Glob patterns (e.g., "refs/pull/*").
+   */
+  refs = async (opts?: GitRepositoryRefsOpts): Promise<string[]> => {
+    const response: Awaited<string[]> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "refs",
+          args: { ...opts },
         },
       ],
-      ctx: this._ctx,
-    })
+      await this._ctx.connection(),
+    )
+
+    return response
   }
 
   /**
@@ -5784,14 +7688,21 @@ export class Host extends BaseClient {
    * The file is limited to a size of 512000 bytes.
    * @param name The user defined name for this secret.
    * @param path Location of the file to set as a secret.
+   * @param opts.ttl How long the file's contents are valid for (e.g., "15m").
+   *
+   * Once expired, the file is read again from the host.
    */
-  setSecretFile = (name: string, path: string): Secret => {
+  setSecretFile = (
+    name: string,
+    path: string,
+    opts?: HostSetSecretFileOpts,
+  ): Secret => {
     return new Secret({
       queryTree: [
         ...this._queryTree,
         {
           operation: "setSecretFile",
-          args: { name, path },
+          args: { name, path, ...opts },
         },
       ],
       ctx: this._ctx,
@@ -6326,6 +8237,7 @@ export class LocalModuleSource extends BaseClient {
 export class Module_ extends BaseClient {
   private readonly _id?: ModuleID = undefined
   private readonly _description?: string = undefined
+  private readonly _lock?: string = undefined
   private readonly _name?: string = undefined
   private readonly _sdk?: string = undefined
   private readonly _serve?: Void = undefined
@@ -6337,6 +8249,7 @@ export class Module_ extends BaseClient {
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: ModuleID,
     _description?: string,
+    _lock?: string,
     _name?: string,
     _sdk?: string,
     _serve?: Void,
@@ -6345,6 +8258,7 @@ export class Module_ extends BaseClient {
 
     this._id = _id
     this._description = _description
+    this._lock = _lock
     this._name = _name
     this._sdk = _sdk
     this._serve = _serve
@@ -6589,6 +8503,27 @@ export class Module_ extends BaseClient {
     )
   }
 
+  /**
+   * The contents of the module's dagger.lock file, pinning what its functions resolved so far in this session, or an empty string if nothing is pinned.
+   */
+  lock = async (): Promise<string> => {
+    if (this._lock) {
+      return this._lock
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "lock",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * The name of the module
    */
@@ -7956,14 +9891,21 @@ export class Client extends BaseClient {
   /**
    * Constructs a cache volume for a given cache key.
    * @param key A string identifier to target this cache volume (e.g., "modules-cache").
+   * @param opts.scope Who the cache volume is shared with. Defaults to MODULE_SCOPE within a
+   *
+   * module, and GLOBAL_SCOPE otherwise.
    */
-  cacheVolume = (key: string): CacheVolume => {
+  cacheVolume = (key: string, opts?: ClientCacheVolumeOpts): CacheVolume => {
+    const metadata: Metadata = {
+      scope: { is_enum: true },
+    }
+
     return new CacheVolume({
       queryTree: [
         ...this._queryTree,
         {
           operation: "cacheVolume",
-          args: { key },
+          args: { key, ...opts, __metadata: metadata },
         },
       ],
       ctx: this._ctx,
@@ -8148,6 +10090,10 @@ export class Client extends BaseClient {
    *
    * Suffix ".git" is optional.
    * @param opts.keepGitDir Set to true to keep .git directory.
+   * @param opts.depth Depth of history to fetch.
+   *
+   * Set to 0 to fetch the full history.
+   * @param opts.withSubmodules Set to false to skip checking out submodules.
    * @param opts.experimentalServiceHost A service which must be started before the repo is fetched.
    * @param opts.sshKnownHosts Set SSH known hosts
    * @param opts.sshAuthSocket Set SSH auth socket
@@ -8183,6 +10129,15 @@ export class Client extends BaseClient {
   /**
    * Returns a file containing an http remote url content.
    * @param url HTTP url to get the content from (e.g., "https://docs.dagger.io").
+   * @param opts.checksum Expected digest of the content (e.g., "sha256:...").
+   *
+   * The download fails if the content doesn't match, and is skipped if
+   *
+   * content with the digest is already cached.
+   * @param opts.authHeader Secret used to populate the Authorization HTTP header
+   * @param opts.headers Additional HTTP headers to send with the request.
+   * @param opts.name File name of the downloaded file.
+   * @param opts.permissions Permission given to the downloaded file (e.g., 0600).
    * @param opts.experimentalServiceHost A service which must be started before the URL is fetched.
    */
   http = (url: string, opts?: ClientHttpOpts): File => {
@@ -8316,6 +10271,22 @@ export class Client extends BaseClient {
     })
   }
 
+  /**
+   * Load a DirectoryChange from its ID.
+   */
+  loadDirectoryChangeFromID = (id: DirectoryChangeID): DirectoryChange => {
+    return new DirectoryChange({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "loadDirectoryChangeFromID",
+          args: { id },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Load a Directory from its ID.
    */
@@ -8494,6 +10465,38 @@ export class Client extends BaseClient {
     })
   }
 
+  /**
+   * Load a GitActor from its ID.
+   */
+  loadGitActorFromID = (id: GitActorID): GitActor => {
+    return new GitActor({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "loadGitActorFromID",
+          args: { id },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Load a GitCommit from its ID.
+   */
+  loadGitCommitFromID = (id: GitCommitID): GitCommit => {
+    return new GitCommit({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "loadGitCommitFromID",
+          args: { id },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Load a GitModuleSource from its ID.
    */
@@ -8752,6 +10755,22 @@ export class Client extends BaseClient {
     })
   }
 
+  /**
+   * Load a SearchMatch from its ID.
+   */
+  loadSearchMatchFromID = (id: SearchMatchID): SearchMatch => {
+    return new SearchMatch({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "loadSearchMatchFromID",
+          args: { id },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
   /**
    * Load a Secret from its ID.
    */
@@ -8931,14 +10950,22 @@ export class Client extends BaseClient {
    * The plaintext value is limited to a size of 128000 bytes.
    * @param name The user defined name for this secret
    * @param plaintext The plaintext of the secret
+   * @param opts.ttl How long the plaintext is valid for (e.g., "15m").
+   *
+   * Once expired, the secret's value is fetched again from the client that set it, by asking its session for the secret's source, or for the secret with this name if it has none. Clients connected through the dagger CLI support this; Dagger Functions get an error.
+   * @param opts.source Where the client resolves the secret's value from once its ttl expires (e.g., "env:GITHUB_TOKEN", "file:./token.txt" or "cmd:gh auth token").
    */
-  setSecret = (name: string, plaintext: string): Secret => {
+  setSecret = (
+    name: string,
+    plaintext: string,
+    opts?: ClientSetSecretOpts,
+  ): Secret => {
     return new Secret({
       queryTree: [
         ...this._queryTree,
         {
           operation: "setSecret",
-          args: { name, plaintext },
+          args: { name, plaintext, ...opts },
         },
       ],
       ctx: this._ctx,
@@ -9099,6 +11126,118 @@ export class ScalarTypeDef extends BaseClient {
   }
 }
 
+/**
+ * A line matching a search in a directory.
+ */
+export class SearchMatch extends BaseClient {
+  private readonly _id?: SearchMatchID = undefined
+  private readonly _line?: string = undefined
+  private readonly _lineNumber?: number = undefined
+  private readonly _path?: string = undefined
+
+  /**
+   * Constructor is used for internal usage only, do not create object from it.
+   */
+  constructor(
+    parent?: { queryTree?: QueryTree[]; ctx: Context },
+    _id?: SearchMatchID,
+    _line?: string,
+    _lineNumber?: number,
+    _path?: string,
+  ) {
+    super(parent)
+
+    this._id = _id
+    this._line = _line
+    this._lineNumber = _lineNumber
+    this._path = _path
+  }
+
+  /**
+   * A unique identifier for this SearchMatch.
+   */
+  id = async (): Promise<SearchMatchID> => {
+    if (this._id) {
+      return this._id
+    }
+
+    const response: Awaited<SearchMatchID> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "id",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The text of the matching line, without its line ending.
+   */
+  line = async (): Promise<string> => {
+    if (this._line) {
+      return this._line
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "line",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The number of the matching line, starting at 1.
+   */
+  lineNumber = async (): Promise<number> => {
+    if (this._lineNumber) {
+      return this._lineNumber
+    }
+
+    const response: Awaited<number> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "lineNumber",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
+  /**
+   * The path of the matching file, relative to the searched directory.
+   */
+  path = async (): Promise<string> => {
+    if (this._path) {
+      return this._path
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "path",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+}
+
 /**
  * A reference to a secret value, which can be handled more safely than the value itself.
  */
@@ -9193,8 +11332,11 @@ export class Secret extends BaseClient {
 export class Service extends BaseClient {
   private readonly _id?: ServiceID = undefined
   private readonly _endpoint?: string = undefined
+  private readonly _exec?: string = undefined
   private readonly _hostname?: string = undefined
+  private readonly _logs?: string = undefined
   private readonly _start?: ServiceID = undefined
+  private readonly _status?: ServiceStatus = undefined
   private readonly _stop?: ServiceID = undefined
   private readonly _up?: Void = undefined
 
@@ -9205,8 +11347,11 @@ export class Service extends BaseClient {
     parent?: { queryTree?: QueryTree[]; ctx: Context },
     _id?: ServiceID,
     _endpoint?: string,
+    _exec?: string,
     _hostname?: string,
+    _logs?: string,
     _start?: ServiceID,
+    _status?: ServiceStatus,
     _stop?: ServiceID,
     _up?: Void,
   ) {
@@ -9214,8 +11359,11 @@ export class Service extends BaseClient {
 
     this._id = _id
     this._endpoint = _endpoint
+    this._exec = _exec
     this._hostname = _hostname
+    this._logs = _logs
     this._start = _start
+    this._status = _status
     this._stop = _stop
     this._up = _up
   }
@@ -9269,6 +11417,31 @@ export class Service extends BaseClient {
     return response
   }
 
+  /**
+   * Runs a command inside the running service and returns its stdout.
+   *
+   * The command runs with the same environment, user and working directory as the service. The service must already be running.
+   * @param args Command to run.
+   */
+  exec = async (args: string[]): Promise<string> => {
+    if (this._exec) {
+      return this._exec
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "exec",
+          args: { args },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Retrieves a hostname which can be used by clients to reach this container.
    */
@@ -9290,6 +11463,32 @@ export class Service extends BaseClient {
     return response
   }
 
+  /**
+   * Retrieves the output of the running service.
+   *
+   * The service must already be running. Only the most recent output is retained.
+   * @param opts.since Only return output written after this time, as either a duration relative to now (e.g., "5m") or an RFC 3339 timestamp.
+   * @param opts.follow Stream output to the caller until the service exits, then return all of it.
+   */
+  logs = async (opts?: ServiceLogsOpts): Promise<string> => {
+    if (this._logs) {
+      return this._logs
+    }
+
+    const response: Awaited<string> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "logs",
+          args: { ...opts },
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Retrieves the list of ports provided by the service.
    */
@@ -9355,6 +11554,27 @@ export class Service extends BaseClient {
     })
   }
 
+  /**
+   * The current state of the service.
+   */
+  status = async (): Promise<ServiceStatus> => {
+    if (this._status) {
+      return this._status
+    }
+
+    const response: Awaited<ServiceStatus> = await computeQuery(
+      [
+        ...this._queryTree,
+        {
+          operation: "status",
+        },
+      ],
+      await this._ctx.connection(),
+    )
+
+    return response
+  }
+
   /**
    * Stop the service.
    * @param opts.kill Immediately kill the service without waiting for a graceful exit
@@ -9405,6 +11625,67 @@ export class Service extends BaseClient {
       await this._ctx.connection(),
     )
   }
+
+  /**
+   * Adds a command readiness probe to the service.
+   *
+   * The service is only considered started once the command, run inside the service container, exits successfully, after all of its exposed ports are reachable.
+   * @param args Command to run, with the same environment, user and working directory as the service.
+   * @param opts.interval How long to wait between attempts (e.g., "1s").
+   * @param opts.timeout How long a single attempt may take before it fails (e.g., "10s").
+   * @param opts.retries How many times to retry a failed attempt before giving up.
+   */
+  withExecHealthcheck = (
+    args: string[],
+    opts?: ServiceWithExecHealthcheckOpts,
+  ): Service => {
+    return new Service({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withExecHealthcheck",
+          args: { args, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Adds an HTTP readiness probe to the service.
+   *
+   * The service is only considered started once a GET request to the given port and path responds with the expected status, after all of its exposed ports are reachable.
+   * @param port The port to send the request to.
+   * @param opts.path The path to request.
+   * @param opts.expectedStatus The HTTP status code that indicates the service is ready.
+   * @param opts.interval How long to wait between attempts (e.g., "1s").
+   * @param opts.timeout How long a single attempt may take before it fails (e.g., "10s").
+   * @param opts.retries How many times to retry a failed attempt before giving up.
+   */
+  withHTTPHealthcheck = (
+    port: number,
+    opts?: ServiceWithHttphealthcheckOpts,
+  ): Service => {
+    return new Service({
+      queryTree: [
+        ...this._queryTree,
+        {
+          operation: "withHTTPHealthcheck",
+          args: { port, ...opts },
+        },
+      ],
+      ctx: this._ctx,
+    })
+  }
+
+  /**
+   * Call the provided function with current Service.
+   *
+   * This is useful for reusability and readability by not breaking the calling chain.
+   */
+  with = (arg: (param: Service) => Service) => {
+    return arg(this)
+  }
 }
 
 /**